@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/Sha-min/CSCE4600/pkg/render"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region Banker's algorithm
+
+type (
+	// BankerState is a snapshot of a system for the banker's algorithm: one row
+	// of Allocation and Max per process, one column per resource type.
+	BankerState struct {
+		Available  []int
+		Allocation [][]int
+		Max        [][]int
+	}
+	// BankerRequest is a hypothetical request from process PID.
+	BankerRequest struct {
+		PID       int
+		Resources []int
+	}
+	bankerRequests []BankerRequest
+)
+
+var (
+	ErrExceedsNeed = errors.New("request exceeds declared maximum")
+	ErrMustWait    = errors.New("request exceeds available resources")
+	ErrUnsafe      = errors.New("request would leave the system unsafe")
+)
+
+// Need is Max - Allocation for every process.
+func (s BankerState) Need() [][]int {
+	need := make([][]int, len(s.Max))
+	for i := range s.Max {
+		need[i] = make([]int, len(s.Max[i]))
+		for j := range s.Max[i] {
+			need[i][j] = s.Max[i][j] - s.Allocation[i][j]
+		}
+	}
+	return need
+}
+
+// Validate checks that the matrices agree on their dimensions and that no
+// process holds more than it declared.
+func (s BankerState) Validate() error {
+	if len(s.Allocation) != len(s.Max) {
+		return fmt.Errorf("%w: %d allocation rows but %d max rows", scheduler.ErrInvalidArgs, len(s.Allocation), len(s.Max))
+	}
+	for i := range s.Max {
+		if len(s.Allocation[i]) != len(s.Available) || len(s.Max[i]) != len(s.Available) {
+			return fmt.Errorf("%w: P%d does not have %d resource columns", scheduler.ErrInvalidArgs, i, len(s.Available))
+		}
+		for j := range s.Max[i] {
+			if s.Allocation[i][j] > s.Max[i][j] {
+				return fmt.Errorf("%w: P%d holds more of resource %d than its maximum", scheduler.ErrInvalidArgs, i, j)
+			}
+		}
+	}
+	return nil
+}
+
+// SafeSequence runs the safety algorithm, sweeping the processes in order and
+// finishing any whose need fits in the work vector. It returns the order in
+// which processes could finish and whether all of them can.
+func (s BankerState) SafeSequence() ([]int, bool) {
+	var (
+		need     = s.Need()
+		work     = append([]int(nil), s.Available...)
+		finished = make([]bool, len(s.Max))
+		sequence = make([]int, 0, len(s.Max))
+	)
+	for progress := true; progress; {
+		progress = false
+		for i := range need {
+			if finished[i] || !fits(need[i], work) {
+				continue
+			}
+			for j := range work {
+				work[j] += s.Allocation[i][j]
+			}
+			finished[i] = true
+			sequence = append(sequence, i)
+			progress = true
+		}
+	}
+	return sequence, len(sequence) == len(s.Max)
+}
+
+// Request runs the resource-request algorithm and returns the state after
+// granting req, or an error saying why it cannot be granted.
+func (s BankerState) Request(req BankerRequest) (BankerState, error) {
+	if req.PID < 0 || req.PID >= len(s.Max) {
+		return s, fmt.Errorf("%w: no process P%d", scheduler.ErrInvalidArgs, req.PID)
+	}
+	if len(req.Resources) != len(s.Available) {
+		return s, fmt.Errorf("%w: request has %d resources, want %d", scheduler.ErrInvalidArgs, len(req.Resources), len(s.Available))
+	}
+	if !fits(req.Resources, s.Need()[req.PID]) {
+		return s, ErrExceedsNeed
+	}
+	if !fits(req.Resources, s.Available) {
+		return s, ErrMustWait
+	}
+
+	next := BankerState{
+		Available:  make([]int, len(s.Available)),
+		Allocation: make([][]int, len(s.Allocation)),
+		Max:        s.Max,
+	}
+	for i := range s.Allocation {
+		next.Allocation[i] = append([]int(nil), s.Allocation[i]...)
+	}
+	for j := range s.Available {
+		next.Available[j] = s.Available[j] - req.Resources[j]
+		next.Allocation[req.PID][j] += req.Resources[j]
+	}
+	if _, safe := next.SafeSequence(); !safe {
+		return s, ErrUnsafe
+	}
+
+	return next, nil
+}
+
+func fits(v, limit []int) bool {
+	for j := range v {
+		if v[j] > limit[j] {
+			return false
+		}
+	}
+	return true
+}
+
+//endregion
+
+//region banker command
+
+// runBanker is the `banker` subcommand: `banker [-request PID:r1,r2,...]... state.txt`.
+func runBanker(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("banker", flag.ContinueOnError)
+	var requests bankerRequests
+	fs.Var(&requests, "request", "hypothetical request as PID:r1,r2,... (repeatable, applied in order)")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: banker needs a state file", scheduler.ErrInvalidArgs)
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("%v: error opening state file", err)
+	}
+	defer f.Close()
+
+	state, err := loadBankerState(f)
+	if err != nil {
+		return err
+	}
+
+	render.Title(w, "Banker's algorithm")
+	outputBankerState(w, state)
+	for _, req := range requests {
+		_, _ = fmt.Fprintf(w, "Request P%d %v: ", req.PID, req.Resources)
+		next, err := state.Request(req)
+		switch {
+		case errors.Is(err, scheduler.ErrInvalidArgs):
+			return err
+		case err != nil:
+			_, _ = fmt.Fprintf(w, "denied, %v\n\n", err)
+		default:
+			_, _ = fmt.Fprint(w, "granted\n\n")
+			state = next
+			outputBankerState(w, state)
+		}
+	}
+
+	return nil
+}
+
+// loadBankerState reads a state file made of three sections, each introduced
+// by its name on a line of its own:
+//
+//	available
+//	3 3 2
+//	allocation
+//	0 1 0
+//	...
+//	max
+//	7 5 3
+//	...
+//
+// Values may be separated by commas or whitespace; blank lines and lines
+// starting with # are ignored.
+func loadBankerState(r io.Reader) (BankerState, error) {
+	var (
+		state   BankerState
+		section string
+		line    int
+	)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		switch name := strings.ToLower(strings.TrimSuffix(text, ":")); name {
+		case "available", "allocation", "max":
+			section = name
+			continue
+		}
+
+		row, err := parseIntRow(text)
+		if err != nil {
+			return BankerState{}, fmt.Errorf("line %d: %w", line, err)
+		}
+		switch section {
+		case "available":
+			state.Available = row
+		case "allocation":
+			state.Allocation = append(state.Allocation, row)
+		case "max":
+			state.Max = append(state.Max, row)
+		default:
+			return BankerState{}, fmt.Errorf("%w: line %d: values before any section", scheduler.ErrInvalidArgs, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return BankerState{}, fmt.Errorf("%w: reading state file", err)
+	}
+
+	return state, state.Validate()
+}
+
+func parseIntRow(s string) ([]int, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	row := make([]int, len(fields))
+	for i := range fields {
+		v, err := strconv.Atoi(fields[i])
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("%w: bad value %q", scheduler.ErrInvalidArgs, fields[i])
+		}
+		row[i] = v
+	}
+	return row, nil
+}
+
+func (r *bankerRequests) String() string {
+	return fmt.Sprint(*r)
+}
+
+func (r *bankerRequests) Set(s string) error {
+	pid, resources, ok := strings.Cut(s, ":")
+	if !ok {
+		return fmt.Errorf("request %q is not PID:r1,r2,...", s)
+	}
+	req := BankerRequest{}
+	var err error
+	if req.PID, err = strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(pid), "P")); err != nil {
+		return fmt.Errorf("request %q has a bad PID", s)
+	}
+	if req.Resources, err = parseIntRow(resources); err != nil {
+		return err
+	}
+	*r = append(*r, req)
+	return nil
+}
+
+func outputBankerState(w io.Writer, state BankerState) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Process", "Allocation", "Max", "Need"})
+	for i, need := range state.Need() {
+		table.Append([]string{
+			fmt.Sprintf("P%d", i),
+			joinInts(state.Allocation[i]),
+			joinInts(state.Max[i]),
+			joinInts(need),
+		})
+	}
+	table.SetFooter([]string{"", "", "Available", joinInts(state.Available)})
+	table.Render()
+
+	sequence, safe := state.SafeSequence()
+	if !safe {
+		_, _ = fmt.Fprint(w, "State is UNSAFE\n\n")
+		return
+	}
+	names := make([]string, len(sequence))
+	for i := range sequence {
+		names[i] = fmt.Sprintf("P%d", sequence[i])
+	}
+	_, _ = fmt.Fprintf(w, "State is SAFE, sequence: %s\n\n", strings.Join(names, " -> "))
+}
+
+func joinInts(values []int) string {
+	s := make([]string, len(values))
+	for i := range values {
+		s[i] = fmt.Sprint(values[i])
+	}
+	return strings.Join(s, " ")
+}
+
+//endregion
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestBankerState_SafeSequence(t *testing.T) {
+	t.Parallel()
+	state, err := loadBankerState(strings.NewReader(loadFixture(t, "example_banker.txt")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sequence, safe := state.SafeSequence()
+	if !safe {
+		t.Fatal("SafeSequence() reported the textbook state unsafe")
+	}
+	if want := []int{1, 3, 4, 0, 2}; !reflect.DeepEqual(sequence, want) {
+		t.Errorf("SafeSequence() = %v, want %v", sequence, want)
+	}
+}
+
+func TestBankerState_Request(t *testing.T) {
+	t.Parallel()
+	state, err := loadBankerState(strings.NewReader(loadFixture(t, "example_banker.txt")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	granted, err := state.Request(BankerRequest{PID: 1, Resources: []int{1, 0, 2}})
+	if err != nil {
+		t.Fatalf("Request() error = %v, want it granted", err)
+	}
+
+	tests := []struct {
+		name    string
+		req     BankerRequest
+		wantErr error
+	}{
+		{name: "must wait", req: BankerRequest{PID: 4, Resources: []int{3, 3, 0}}, wantErr: ErrMustWait},
+		{name: "unsafe", req: BankerRequest{PID: 0, Resources: []int{0, 2, 0}}, wantErr: ErrUnsafe},
+		{name: "exceeds need", req: BankerRequest{PID: 3, Resources: []int{1, 1, 1}}, wantErr: ErrExceedsNeed},
+		{name: "unknown process", req: BankerRequest{PID: 7, Resources: []int{0, 0, 0}}, wantErr: scheduler.ErrInvalidArgs},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := granted.Request(tt.req); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Request() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_loadBankerState(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{name: "values before section", in: "1 2 3\n"},
+		{name: "bad value", in: "available\n1 x 3\n"},
+		{name: "ragged rows", in: "available\n1 2\nallocation\n0 0 0\nmax\n1 1 1\n"},
+		{name: "allocation above max", in: "available\n1\nallocation\n2\nmax\n1\n"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := loadBankerState(strings.NewReader(tt.in)); !errors.Is(err, scheduler.ErrInvalidArgs) {
+				t.Errorf("loadBankerState() error = %v, want %v", err, scheduler.ErrInvalidArgs)
+			}
+		})
+	}
+}
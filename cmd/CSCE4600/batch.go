@@ -0,0 +1,367 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region Batch simulation
+
+type (
+	// Batch runs many workloads through the same schedulers at once:
+	// • Workers bounds how many simulations run at a time; 0 means GOMAXPROCS
+	// • SummaryOnly keeps just each run's averages, see SummarizeScheduler
+	Batch struct {
+		Schedulers  []string
+		Quantum     int64
+		Workers     int
+		SummaryOnly bool
+	}
+	// BatchJob is one workload of a batch, named for reporting.
+	BatchJob struct {
+		Workload  string
+		Processes []scheduler.Process
+	}
+	// BatchResult is every scheduler's run over one workload, in the order
+	// the schedulers were asked for.
+	BatchResult struct {
+		Workload string                `json:"workload"`
+		Results  []scheduler.RunResult `json:"results"`
+	}
+	// BatchSummary is one scheduler's metrics averaged over every workload
+	// and, once RankBatch has compared the schedulers workload by workload,
+	// how it placed: Wins counts the workloads on which it did best, ties
+	// each winning, and MeanRank is its average place, 1 being best.
+	BatchSummary struct {
+		Scheduler     string  `json:"scheduler"`
+		Workloads     int     `json:"workloads"`
+		AvgWait       float64 `json:"avgWait"`
+		AvgTurnaround float64 `json:"avgTurnaround"`
+		Throughput    float64 `json:"throughput"`
+		Wins          int     `json:"wins"`
+		MeanRank      float64 `json:"meanRank"`
+	}
+)
+
+// Run runs every job through every scheduler on a pool of worker goroutines.
+// Results come back in job order whatever order they finish in, and the
+// first failing job in that order decides the error.
+func (b Batch) Run(jobs []BatchJob) ([]BatchResult, error) {
+	for _, name := range b.Schedulers {
+		if _, err := scheduler.LookupScheduler(name); err != nil {
+			return nil, err
+		}
+	}
+	results := make([]BatchResult, len(jobs))
+	errs := make([]error, len(jobs))
+	parallelFor(len(jobs), b.Workers, func(i int) {
+		results[i], errs[i] = b.runJob(jobs[i])
+	})
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", jobs[i].Workload, err)
+		}
+	}
+	return results, nil
+}
+
+func (b Batch) runJob(job BatchJob) (BatchResult, error) {
+	run := scheduler.RunScheduler
+	if b.SummaryOnly {
+		run = scheduler.SummarizeScheduler
+	}
+	result := BatchResult{Workload: job.Workload}
+	for _, name := range b.Schedulers {
+		r, err := run(name, b.Quantum, job.Processes)
+		if err != nil {
+			return BatchResult{}, err
+		}
+		result.Results = append(result.Results, r)
+	}
+	return result, nil
+}
+
+// SummarizeBatch averages each scheduler's metrics over the workloads, with
+// schedulers in the order they first appear.
+func SummarizeBatch(results []BatchResult) []BatchSummary {
+	var summaries []BatchSummary
+	index := map[string]int{}
+	for _, b := range results {
+		for _, r := range b.Results {
+			i, ok := index[r.Scheduler]
+			if !ok {
+				i = len(summaries)
+				index[r.Scheduler] = i
+				summaries = append(summaries, BatchSummary{Scheduler: r.Scheduler})
+			}
+			s := &summaries[i]
+			s.Workloads++
+			s.AvgWait += r.AvgWait
+			s.AvgTurnaround += r.AvgTurnaround
+			s.Throughput += r.Throughput
+		}
+	}
+	for i := range summaries {
+		n := float64(summaries[i].Workloads)
+		summaries[i].AvgWait /= n
+		summaries[i].AvgTurnaround /= n
+		summaries[i].Throughput /= n
+	}
+	return summaries
+}
+
+// RankBatch places the schedulers on each workload by one of sweepMetrics,
+// a scheduler ranking one more than those that beat it, and records in
+// summaries, as SummarizeBatch made them from results, each one's wins and
+// mean rank. A scheduler can win on one workload and trail on the rest, which
+// the means alone would hide.
+func RankBatch(summaries []BatchSummary, results []BatchResult, metric string) error {
+	m, ok := sweepMetrics[metric]
+	if !ok {
+		return fmt.Errorf("%w: unknown metric %q, want one of %s", scheduler.ErrInvalidArgs, metric, strings.Join(sortedSweepMetrics(), ", "))
+	}
+	index := make(map[string]int, len(summaries))
+	for i := range summaries {
+		summaries[i].Wins, summaries[i].MeanRank = 0, 0
+		index[summaries[i].Scheduler] = i
+	}
+	for _, b := range results {
+		for _, r := range b.Results {
+			rank := 1
+			for _, other := range b.Results {
+				v, o := m.value(r), m.value(other)
+				if (m.lowerBetter && o < v) || (!m.lowerBetter && o > v) {
+					rank++
+				}
+			}
+			s := &summaries[index[r.Scheduler]]
+			if rank == 1 {
+				s.Wins++
+			}
+			s.MeanRank += float64(rank)
+		}
+	}
+	for i := range summaries {
+		if summaries[i].Workloads > 0 {
+			summaries[i].MeanRank /= float64(summaries[i].Workloads)
+		}
+	}
+	return nil
+}
+
+// parallelFor calls fn(i) for every i below n on a pool of workers
+// goroutines, GOMAXPROCS of them if workers is not positive, and returns once
+// every call has. Callers keep results in order by writing them at index i.
+func parallelFor(n, workers int, fn func(i int)) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
+
+//endregion
+
+//region batch command
+
+// runBatch is the `batch` subcommand. Workloads are CSV or JSON files, with
+// glob patterns expanded, or with -random N, that many random workloads. It
+// prints a summary of each scheduler over them all, ranked workload by
+// workload by -rank, and with -matrix that metric for every workload and
+// scheduler:
+// `batch [-schedulers fcfs,rr] [-quantum 2] [-workers n] [-rank turnaround] [-matrix] [-all] [-json] 'sweep/*.csv' more.json...`
+// `batch -random 1000 [-n 20] [-seed 1] ...`.
+func runBatch(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	schedulers := fs.String("schedulers", strings.Join(scheduler.SortedSchedulerNames(), ","), "comma-separated schedulers to run")
+	quantum := fs.Int64("quantum", scheduler.DefaultQuantum, "round-robin quantum")
+	workers := fs.Int("workers", 0, "simulations to run at once; 0 uses every CPU")
+	random := fs.Int("random", 0, "run this many random workloads instead of files")
+	n := fs.Int("n", 20, "processes per random workload")
+	seed := fs.Int64("seed", 1, "seed of the first random workload")
+	all := fs.Bool("all", false, "also list every workload's results")
+	rank := fs.String("rank", "turnaround", "metric to rank the schedulers by on each workload: "+strings.Join(sortedSweepMetrics(), ", "))
+	matrix := fs.Bool("matrix", false, "also print the -rank metric for every workload and scheduler, each workload's best marked")
+	asJSON := fs.Bool("json", false, "print every result as JSON")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+
+	var (
+		jobs []BatchJob
+		err  error
+	)
+	if *random > 0 {
+		jobs, err = randomBatchJobs(*random, *n, *seed)
+	} else {
+		jobs, err = loadBatchJobs(fs.Args())
+	}
+	if err != nil {
+		return err
+	}
+	names, err := parseSchedulers(*schedulers)
+	if err != nil {
+		return err
+	}
+	if _, ok := sweepMetrics[*rank]; !ok {
+		return fmt.Errorf("%w: unknown metric %q, want one of %s", scheduler.ErrInvalidArgs, *rank, strings.Join(sortedSweepMetrics(), ", "))
+	}
+
+	results, err := Batch{Schedulers: names, Quantum: *quantum, Workers: *workers, SummaryOnly: !*asJSON}.Run(jobs)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+	if *all {
+		outputBatchResults(w, results)
+	}
+	if *matrix {
+		outputBatchMatrix(w, results, *rank)
+	}
+	summaries := SummarizeBatch(results)
+	if err := RankBatch(summaries, results, *rank); err != nil {
+		return err
+	}
+	outputBatchSummary(w, summaries, *rank)
+	return nil
+}
+
+// loadBatchJobs loads every file the patterns match, each file once, sorted
+// by name.
+func loadBatchJobs(patterns []string) ([]BatchJob, error) {
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("%w: no workloads given", scheduler.ErrInvalidArgs)
+	}
+	seen := map[string]bool{}
+	var paths []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("%w: no workloads match %s", scheduler.ErrInvalidArgs, pattern)
+		}
+		for _, m := range matches {
+			if !seen[m] {
+				seen[m] = true
+				paths = append(paths, m)
+			}
+		}
+	}
+	sort.Strings(paths)
+
+	jobs := make([]BatchJob, len(paths))
+	for i, path := range paths {
+		processes, err := loader.LoadFile(path, "")
+		if err != nil {
+			return nil, err
+		}
+		jobs[i] = BatchJob{Workload: path, Processes: processes}
+	}
+	return jobs, nil
+}
+
+// randomBatchJobs makes count workloads of n processes, workload i from seed+i
+// so any one of them can be reproduced on its own.
+func randomBatchJobs(count, n int, seed int64) ([]BatchJob, error) {
+	jobs := make([]BatchJob, count)
+	for i := range jobs {
+		processes, err := generateWorkload(rand.New(rand.NewSource(seed+int64(i))), n)
+		if err != nil {
+			return nil, err
+		}
+		jobs[i] = BatchJob{Workload: fmt.Sprintf("seed %d", seed+int64(i)), Processes: processes}
+	}
+	return jobs, nil
+}
+
+func outputBatchResults(w io.Writer, results []BatchResult) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Workload", "Scheduler", "Avg wait", "Avg turnaround", "Throughput"})
+	for _, b := range results {
+		for _, r := range b.Results {
+			table.Append([]string{b.Workload, scheduler.RunLabel(r), fmt.Sprintf("%.2f", r.AvgWait),
+				fmt.Sprintf("%.2f", r.AvgTurnaround), fmt.Sprintf("%.2f/t", r.Throughput)})
+		}
+	}
+	table.Render()
+}
+
+// outputBatchMatrix prints metric for each workload, a row, and scheduler, a
+// column, with an asterisk on each workload's best.
+func outputBatchMatrix(w io.Writer, results []BatchResult, metric string) {
+	if len(results) == 0 {
+		return
+	}
+	m := sweepMetrics[metric]
+	header, align := []string{"Workload"}, []int{tablewriter.ALIGN_LEFT}
+	for _, r := range results[0].Results {
+		header, align = append(header, scheduler.RunLabel(r)), append(align, tablewriter.ALIGN_RIGHT)
+	}
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(header)
+	table.SetColumnAlignment(align)
+	for _, b := range results {
+		best := m.value(b.Results[0])
+		for _, r := range b.Results[1:] {
+			if v := m.value(r); (m.lowerBetter && v < best) || (!m.lowerBetter && v > best) {
+				best = v
+			}
+		}
+		row := []string{b.Workload}
+		for _, r := range b.Results {
+			cell := fmt.Sprintf(m.format, m.value(r))
+			if m.value(r) == best {
+				cell += " *"
+			}
+			row = append(row, cell)
+		}
+		table.Append(row)
+	}
+	table.Render()
+	_, _ = fmt.Fprintf(w, "* best %s on the workload\n", metric)
+}
+
+func outputBatchSummary(w io.Writer, summaries []BatchSummary, metric string) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Scheduler", "Workloads", "Mean avg wait", "Mean avg turnaround", "Mean throughput", "Best " + metric, "Mean rank"})
+	for _, s := range summaries {
+		table.Append([]string{s.Scheduler, fmt.Sprint(s.Workloads), fmt.Sprintf("%.2f", s.AvgWait),
+			fmt.Sprintf("%.2f", s.AvgTurnaround), fmt.Sprintf("%.2f/t", s.Throughput), fmt.Sprint(s.Wins), fmt.Sprintf("%.2f", s.MeanRank)})
+	}
+	table.Render()
+}
+
+//endregion
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestBatch_Run(t *testing.T) {
+	t.Parallel()
+	jobs, err := loadBatchJobs([]string{"testdata/workloads/*.csv"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedulers := scheduler.SortedSchedulerNames()
+
+	sequential, err := Batch{Schedulers: schedulers, Quantum: 3, Workers: 1}.Run(jobs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, b := range sequential {
+		if b.Workload != jobs[i].Workload {
+			t.Fatalf("result %d is for %s, want %s", i, b.Workload, jobs[i].Workload)
+		}
+		for j, name := range schedulers {
+			want, err := scheduler.RunScheduler(name, 3, jobs[i].Processes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(b.Results[j], want) {
+				t.Errorf("%s %s = %+v, want %+v", b.Workload, name, b.Results[j], want)
+			}
+		}
+	}
+
+	parallel, err := Batch{Schedulers: schedulers, Quantum: 3, Workers: 8}.Run(jobs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parallel, sequential) {
+		t.Error("8 workers gave different results from 1")
+	}
+
+	summaries, err := Batch{Schedulers: schedulers, Quantum: 3, SummaryOnly: true}.Run(jobs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, b := range summaries {
+		for j, r := range b.Results {
+			full := sequential[i].Results[j]
+			if r.AvgWait != full.AvgWait || r.AvgTurnaround != full.AvgTurnaround || r.Throughput != full.Throughput {
+				t.Errorf("%s %s summary = %+v, want the averages of %+v", b.Workload, r.Scheduler, r, full)
+			}
+			if r.Gantt != nil || r.Events != nil || r.Processes != nil {
+				t.Errorf("%s %s summary kept details", b.Workload, r.Scheduler)
+			}
+		}
+	}
+
+	if _, err := (Batch{Schedulers: []string{"mlfq"}}).Run(jobs); err == nil {
+		t.Error("Run() with an unknown scheduler succeeded")
+	}
+}
+
+func TestSummarizeBatch(t *testing.T) {
+	t.Parallel()
+	results := []BatchResult{
+		{Workload: "a", Results: []scheduler.RunResult{
+			{Scheduler: "rr", AvgWait: 2, AvgTurnaround: 4, Throughput: 0.5},
+			{Scheduler: "fcfs", AvgWait: 1, AvgTurnaround: 3, Throughput: 0.5},
+		}},
+		{Workload: "b", Results: []scheduler.RunResult{
+			{Scheduler: "rr", AvgWait: 4, AvgTurnaround: 8, Throughput: 0.25},
+			{Scheduler: "fcfs", AvgWait: 3, AvgTurnaround: 5, Throughput: 0.25},
+		}},
+	}
+	want := []BatchSummary{
+		{Scheduler: "rr", Workloads: 2, AvgWait: 3, AvgTurnaround: 6, Throughput: 0.375},
+		{Scheduler: "fcfs", Workloads: 2, AvgWait: 2, AvgTurnaround: 4, Throughput: 0.375},
+	}
+	if got := SummarizeBatch(results); !reflect.DeepEqual(got, want) {
+		t.Errorf("SummarizeBatch() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRankBatch(t *testing.T) {
+	t.Parallel()
+	results := []BatchResult{
+		{Workload: "a", Results: []scheduler.RunResult{
+			{Scheduler: "rr", AvgTurnaround: 4, Throughput: 0.5},
+			{Scheduler: "fcfs", AvgTurnaround: 3, Throughput: 0.5},
+			{Scheduler: "sjf", AvgTurnaround: 3, Throughput: 0.25},
+		}},
+		{Workload: "b", Results: []scheduler.RunResult{
+			{Scheduler: "rr", AvgTurnaround: 5, Throughput: 0.25},
+			{Scheduler: "fcfs", AvgTurnaround: 8, Throughput: 0.25},
+			{Scheduler: "sjf", AvgTurnaround: 6, Throughput: 0.5},
+		}},
+	}
+	tests := []struct {
+		metric    string
+		wantWins  []int
+		wantRanks []float64
+	}{
+		// Tying on a, fcfs and sjf both win it and rr comes third.
+		{metric: "turnaround", wantWins: []int{1, 1, 1}, wantRanks: []float64{2, 2, 1.5}},
+		{metric: "throughput", wantWins: []int{1, 1, 1}, wantRanks: []float64{1.5, 1.5, 2}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.metric, func(t *testing.T) {
+			t.Parallel()
+			summaries := SummarizeBatch(results)
+			if err := RankBatch(summaries, results, tt.metric); err != nil {
+				t.Fatal(err)
+			}
+			for i, s := range summaries {
+				if s.Wins != tt.wantWins[i] || s.MeanRank != tt.wantRanks[i] {
+					t.Errorf("%s won %d with mean rank %g, want %d and %g", s.Scheduler, s.Wins, s.MeanRank, tt.wantWins[i], tt.wantRanks[i])
+				}
+			}
+		})
+	}
+	if err := RankBatch(SummarizeBatch(results), results, "fairness"); err == nil {
+		t.Error("RankBatch() by an unknown metric succeeded")
+	}
+}
+
+func TestRunBatchCommand(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "files",
+			args: []string{"-schedulers", "fcfs,rr", "-all", "testdata/workloads/*.csv"},
+			want: []string{"testdata/workloads/ties.csv", "rr (q=2)", "MEAN AVG WAIT"},
+		},
+		{
+			name: "random",
+			args: []string{"-random", "50", "-n", "10", "-schedulers", "sjf"},
+			want: []string{"| sjf       |        50 |"},
+		},
+		{
+			name: "matrix",
+			args: []string{"-schedulers", "fcfs,sjf", "-matrix", "-rank", "wait", "testdata/workloads/basic.csv", "testdata/workloads/t*.csv"},
+			want: []string{"| testdata/workloads/ties.csv  |", "* best wait on the workload", "BEST WAIT", "MEAN RANK"},
+		},
+		{name: "unknown rank", args: []string{"-rank", "fairness", "testdata/workloads/basic.csv"}, wantErr: true},
+		{name: "no match", args: []string{"testdata/none/*.csv"}, wantErr: true},
+		{name: "no workloads", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var out bytes.Buffer
+			err := runBatch(&out, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runBatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(out.String(), s) {
+					t.Errorf("output missing %q:\n%s", s, out.String())
+				}
+			}
+		})
+	}
+}
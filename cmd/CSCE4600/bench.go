@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region Benchmarks
+
+// benchSizes are the workload sizes the benchmarks and `bench` run at.
+var benchSizes = []int{1_000, 10_000, 100_000, 1_000_000}
+
+type (
+	// Bench times every scheduler in Schedulers over a generated workload of
+	// each size in Sizes, Runs times apiece, one run at a time so runs do not
+	// skew each other's clocks.
+	Bench struct {
+		Schedulers []string
+		Sizes      []int
+		Runs       int
+		Params     scheduler.SchedulerParams
+	}
+	// BenchResult is what one scheduler took at one size, averaged over its
+	// runs: wall-clock time, heap allocations and bytes allocated. PerProcess
+	// is Elapsed over the processes, which stays level as the size grows for
+	// a scheduler linear in it and grows with it for one quadratic in it.
+	BenchResult struct {
+		Scheduler  string        `json:"scheduler"`
+		Processes  int           `json:"processes"`
+		Runs       int           `json:"runs"`
+		Elapsed    time.Duration `json:"elapsed_ns"`
+		PerProcess time.Duration `json:"per_process_ns"`
+		Allocs     uint64        `json:"allocs"`
+		Bytes      uint64        `json:"bytes"`
+	}
+)
+
+// Run returns a result per scheduler and size, sizes in order within each
+// scheduler. Each workload is generated once and shared by the schedulers.
+func (b Bench) Run() ([]BenchResult, error) {
+	if b.Runs < 1 {
+		return nil, fmt.Errorf("%w: bench needs at least 1 run, not %d", scheduler.ErrInvalidArgs, b.Runs)
+	}
+	workloads := make([][]scheduler.Process, len(b.Sizes))
+	for i, n := range b.Sizes {
+		if n < 1 {
+			return nil, fmt.Errorf("%w: workload size %d must be at least 1", scheduler.ErrInvalidArgs, n)
+		}
+		var err error
+		if workloads[i], err = loadBenchWorkload(n); err != nil {
+			return nil, err
+		}
+	}
+	results := make([]BenchResult, 0, len(b.Schedulers)*len(b.Sizes))
+	for _, name := range b.Schedulers {
+		for i, processes := range workloads {
+			r, err := b.measure(name, processes)
+			if err != nil {
+				return nil, err
+			}
+			r.Processes = b.Sizes[i]
+			r.PerProcess = r.Elapsed / time.Duration(r.Processes)
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
+
+// measure runs name over processes b.Runs times, collecting garbage first so
+// an earlier run's is not charged to it.
+func (b Bench) measure(name string, processes []scheduler.Process) (BenchResult, error) {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 0; i < b.Runs; i++ {
+		if _, err := scheduler.RunSchedulerParams(name, b.Params, processes); err != nil {
+			return BenchResult{}, err
+		}
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	runs := uint64(b.Runs)
+	return BenchResult{
+		Scheduler: name,
+		Runs:      b.Runs,
+		Elapsed:   elapsed / time.Duration(b.Runs),
+		Allocs:    (after.Mallocs - before.Mallocs) / runs,
+		Bytes:     (after.TotalAlloc - before.TotalAlloc) / runs,
+	}, nil
+}
+
+// benchWorkload is a CSV of n processes arriving over time with mixed bursts,
+// the same for every run of a given n.
+func benchWorkload(n int) string {
+	rng := rand.New(rand.NewSource(int64(n)))
+	var b strings.Builder
+	var arrival int
+	for pid := 1; pid <= n; pid++ {
+		arrival += rng.Intn(4)
+		_, _ = fmt.Fprintf(&b, "%d,%d,%d,%d\n", pid, rng.Intn(20)+1, arrival, rng.Intn(10))
+	}
+	return b.String()
+}
+
+// loadBenchWorkload is benchWorkload(n) loaded.
+func loadBenchWorkload(n int) ([]scheduler.Process, error) {
+	return loader.LoadCSV(strings.NewReader(benchWorkload(n)))
+}
+
+//endregion
+
+//region bench command
+
+// newBenchCommand is the `bench` subcommand, which times schedulers over
+// generated workloads to show how they scale:
+// `bench [--schedulers fcfs,rr] [--sizes 1000,10000,100000] [--runs 1] [--quantum 2]`.
+// The million-process size is left to be asked for, as the slowest
+// schedulers take minutes over it; `go test -bench` runs every size.
+func newBenchCommand(opts *cliOptions) *cobra.Command {
+	var (
+		bench      Bench
+		schedulers string
+		flags      paramFlags
+	)
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Time schedulers and count their allocations over generated workloads of growing size",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) (err error) {
+			if opts.output != OutputText && opts.output != OutputJSON {
+				return fmt.Errorf("%w: bench prints %s or %s, not %q", scheduler.ErrInvalidArgs, OutputText, OutputJSON, opts.output)
+			}
+			if bench.Params, err = flags.params(); err != nil {
+				return err
+			}
+			if bench.Schedulers, err = parseSchedulers(schedulers); err != nil {
+				return err
+			}
+			results, err := bench.Run()
+			if err != nil {
+				return err
+			}
+			w, closeOutput, err := opts.writer(cmd)
+			if err != nil {
+				return err
+			}
+			defer keepFirstError(&err, closeOutput)
+			if opts.output == OutputJSON {
+				enc := json.NewEncoder(w)
+				enc.SetIndent("", "  ")
+				return enc.Encode(results)
+			}
+			outputBench(w, results)
+			return nil
+		},
+	}
+	fs := cmd.Flags()
+	fs.StringVar(&schedulers, "schedulers", strings.Join(scheduler.SortedSchedulerNames(), ","), "comma-separated schedulers to time")
+	fs.IntSliceVar(&bench.Sizes, "sizes", benchSizes[:len(benchSizes)-1], "comma-separated workload sizes, in processes")
+	fs.IntVar(&bench.Runs, "runs", 1, "runs to average over per scheduler and size")
+	// Every tunable, for whichever schedulers take it.
+	flags.add(fs, scheduler.SchedulerInfo{Quantum: true, Aging: true, Seed: true, Latency: true, Weights: true, Alpha: true, MultiCPU: true, Inherit: true, Ages: true, Nice: true}, true)
+	return cmd
+}
+
+// outputBench prints a row per scheduler and size.
+func outputBench(w io.Writer, results []BenchResult) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Scheduler", "Processes", "Time/run", "Time/process", "Allocs/run", "Bytes/run"})
+	for _, r := range results {
+		table.Append([]string{r.Scheduler, fmt.Sprint(r.Processes), r.Elapsed.Round(time.Microsecond).String(),
+			r.PerProcess.String(), fmt.Sprint(r.Allocs), fmt.Sprint(r.Bytes)})
+	}
+	table.Render()
+}
+
+//endregion
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/render"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func benchProcesses(b *testing.B, n int) []scheduler.Process {
+	b.Helper()
+	processes, err := loadBenchWorkload(n)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return processes
+}
+
+// sizes are the workload sizes to benchmark at, all of benchSizes unless
+// -short leaves out the million:
+// go test -run '^$' -bench . -benchmem [-short]
+func sizes() []int {
+	if testing.Short() {
+		return benchSizes[:len(benchSizes)-1]
+	}
+	return benchSizes
+}
+
+func BenchmarkEngineSchedulers(b *testing.B) {
+	for _, name := range scheduler.SortedSchedulerNames() {
+		for _, n := range sizes() {
+			processes := benchProcesses(b, n)
+			b.Run(fmt.Sprintf("%s/%d", name, n), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := scheduler.RunScheduler(name, 0, processes); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkPrintedSchedulers(b *testing.B) {
+	schedulers := []struct {
+		name string
+		run  func(io.Writer, string, []scheduler.Process)
+	}{
+		{name: "fcfs", run: FCFSSchedule},
+		{name: "sjf", run: SJFSchedule},
+		{name: "priority", run: PriorityNonPreemptiveSchedule},
+		{name: "rr", run: func(w io.Writer, title string, processes []scheduler.Process) {
+			RRSchedule(w, title, processes, scheduler.DefaultQuantum)
+		}},
+		{name: "cooperative", run: CooperativeSchedule},
+	}
+	for _, s := range schedulers {
+		for _, n := range sizes() {
+			processes := benchProcesses(b, n)
+			b.Run(fmt.Sprintf("%s/%d", s.name, n), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					s.run(io.Discard, s.name, processes)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkLoadProcesses(b *testing.B) {
+	for _, n := range sizes() {
+		workload := benchWorkload(n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.SetBytes(int64(len(workload)))
+			for i := 0; i < b.N; i++ {
+				if _, err := loader.LoadCSV(strings.NewReader(workload)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkRenderers(b *testing.B) {
+	for _, n := range sizes() {
+		result, err := scheduler.RunScheduler("rr", 0, benchProcesses(b, n))
+		if err != nil {
+			b.Fatal(err)
+		}
+		rows := make([][]string, len(result.Processes))
+		for i, m := range result.Processes {
+			rows[i] = []string{fmt.Sprint(m.PID), fmt.Sprint(m.Priority), fmt.Sprint(m.Burst),
+				fmt.Sprint(m.Arrival), fmt.Sprint(m.Response), fmt.Sprint(m.Wait), fmt.Sprint(m.Turnaround), fmt.Sprint(m.Exit)}
+		}
+		b.Run(fmt.Sprintf("gantt/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				render.Gantt(io.Discard, result.Gantt)
+			}
+		})
+		b.Run(fmt.Sprintf("schedule/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				render.Schedule(io.Discard, rows, false, result.AvgResponse, result.AvgWait, result.AvgTurnaround, result.Throughput)
+			}
+		})
+	}
+}
+
+func TestBench_Run(t *testing.T) {
+	t.Parallel()
+	results, err := Bench{Schedulers: []string{"fcfs", "rr"}, Sizes: []int{10, 100}, Runs: 2}.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range results {
+		got = append(got, fmt.Sprintf("%s/%d", r.Scheduler, r.Processes))
+		if r.Runs != 2 || r.Elapsed <= 0 || r.Allocs == 0 || r.Bytes == 0 {
+			t.Errorf("%s/%d measured nothing: %+v", r.Scheduler, r.Processes, r)
+		}
+	}
+	if want := "fcfs/10 fcfs/100 rr/10 rr/100"; strings.Join(got, " ") != want {
+		t.Errorf("Run() benched %v, want %s", got, want)
+	}
+
+	for _, bad := range []Bench{
+		{Schedulers: []string{"fcfs"}, Sizes: []int{10}},
+		{Schedulers: []string{"fcfs"}, Sizes: []int{0}, Runs: 1},
+	} {
+		if _, err := bad.Run(); err == nil {
+			t.Errorf("%+v.Run() succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func Test_run_chromeTrace(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "trace.json")
+	workload := "testdata/workloads/idle.csv"
+	var out bytes.Buffer
+	if err := run(&out, []string{"-scheduler", "fcfs,rr", "-cpus", "2", "-context-switch-cost", "1", "-trace", path, workload}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type slice struct {
+		Cat   string `json:"cat"`
+		Phase string `json:"ph"`
+		TS    int64  `json:"ts"`
+		Dur   int64  `json:"dur"`
+		PID   int    `json:"pid"`
+		TID   int    `json:"tid"`
+	}
+	var trace struct {
+		TraceEvents []slice `json:"traceEvents"`
+	}
+	if err := json.Unmarshal(data, &trace); err != nil {
+		t.Fatalf("trace is not JSON: %v\n%s", err, data)
+	}
+	got := make(map[slice]int)
+	for _, e := range trace.TraceEvents {
+		if e.Phase == "X" {
+			got[e]++
+		}
+	}
+
+	// Each run is a trace process, numbered from 1, and each CPU a thread;
+	// every Gantt slice is an "X" event timed in milliseconds of ticks.
+	results, err := runSchedulers([]string{"fcfs", "rr"}, scheduler.SchedulerParams{CPUs: 2, SwitchCost: 1}, mustLoadProcesses(t, workload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make(map[slice]int)
+	cats := make(map[string]bool)
+	for i, r := range results {
+		for _, s := range r.Gantt {
+			cat := "run"
+			switch s.PID {
+			case scheduler.IdlePID:
+				cat = "idle"
+			case scheduler.SwitchPID:
+				cat = "switch"
+			}
+			cats[cat] = true
+			want[slice{Cat: cat, Phase: "X", TS: s.Start * 1000, Dur: (s.Stop - s.Start) * 1000, PID: i + 1, TID: s.CPU}]++
+		}
+	}
+	if !cats["idle"] || !cats["switch"] {
+		t.Fatalf("runs have no idle or switch slices to trace: %v", cats)
+	}
+	for s, n := range want {
+		if got[s] != n {
+			t.Errorf("trace has %d of %+v, want %d", got[s], s, n)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("trace has %d distinct slices, want %d", len(got), len(want))
+	}
+}
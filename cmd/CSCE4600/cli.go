@@ -0,0 +1,325 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/render"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region Command line
+
+type (
+	// cliOptions are the persistent flags every subcommand shares:
+	// • input names the workload, for subcommands that take one, when it is
+	//   not given as an argument
+	// • format is the workload's format, csv or json; "" goes by extension
+	// • output is the report format, text, expanded or json
+	// • outputFile, if set, takes the report instead of stdout
+	cliOptions struct {
+		input      string
+		format     string
+		output     string
+		outputFile string
+	}
+	// paramFlags hold the scheduler tunables a subcommand takes as flags,
+	// which params checks and turns into SchedulerParams.
+	paramFlags struct {
+		quantum    int64
+		aging      int64
+		switchCost int64
+		cpus       int
+		seed       int64
+		latency    int64
+		alpha      float64
+		weights    string
+		mlqConfig  string
+		tieBreak   string
+		sorted     bool
+		inherit    bool
+		ageEvery   int64
+		ageCap     int64
+		nice       string
+		order      string
+		caps       string
+		capPeriod  int64
+		carry      string
+		bankCap    int64
+	}
+)
+
+// newRootCommand is the command line. `schedule`, `compare`, `generate`,
+// `validate`, `scenario`, `optimize-quantum` and `bench` take POSIX-style flags,
+// --input, --format, --output and --output-file among them; the subcommands
+// in commands parse their own;
+// and anything else is runDefault's, e.g. `CSCE4600 -scheduler rr file.csv`.
+func newRootCommand() *cobra.Command {
+	opts := &cliOptions{}
+	root := &cobra.Command{
+		Use:   "CSCE4600 [flags] workload",
+		Short: "Simulate CPU scheduling and other operating system algorithms",
+		Args:  cobra.ArbitraryArgs,
+		// runDefault parses its own Go-style flags, e.g. -scheduler.
+		DisableFlagParsing: true,
+		SilenceErrors:      true,
+		SilenceUsage:       true,
+		CompletionOptions:  cobra.CompletionOptions{DisableDefaultCmd: true},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDefault(cmd.OutOrStdout(), args)
+		},
+	}
+	pf := root.PersistentFlags()
+	pf.StringVarP(&opts.input, "input", "i", "", "workload file, if not given as an argument")
+	pf.StringVar(&opts.format, "format", "", "workload format, csv or json; by default .json files are JSON and others CSV")
+	pf.StringVarP(&opts.output, "output", "o", OutputText, "report format: text, expanded for text with each process's runs, or json")
+	pf.StringVar(&opts.outputFile, "output-file", "", "write the report to this file rather than stdout")
+	root.AddCommand(newScheduleCommand(opts), newCompareCommand(opts), newGenerateCommand(opts), newValidateCommand(opts), newScenarioCommand(), newOptimizeQuantumCommand(opts), newBenchCommand(opts))
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		run := commands[name]
+		root.AddCommand(&cobra.Command{
+			Use:                name,
+			Short:              fmt.Sprintf("Run %s, which takes Go-style flags; see %s -h", name, name),
+			DisableFlagParsing: true,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return run(cmd.OutOrStdout(), args)
+			},
+		})
+	}
+	return root
+}
+
+// newScheduleCommand is `schedule`, with a subcommand for each registered
+// scheduler taking just the tunables it uses: `schedule rr --quantum 4 w.csv`.
+func newScheduleCommand(opts *cliOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schedule scheduler",
+		Short: "Run one scheduler over a workload",
+		// Only a name that is not a scheduler gets here.
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("%w: schedule needs a scheduler, one of %s", scheduler.ErrInvalidArgs, strings.Join(scheduler.SchedulerNames(), ", "))
+			}
+			_, err := scheduler.LookupScheduler(args[0])
+			return err
+		},
+	}
+	for _, name := range scheduler.SchedulerNames() {
+		cmd.AddCommand(newSchedulerCommand(opts, name))
+	}
+	return cmd
+}
+
+// newSchedulerCommand is `schedule name`.
+func newSchedulerCommand(opts *cliOptions, name string) *cobra.Command {
+	info, _ := scheduler.LookupScheduler(name)
+	var (
+		flags   paramFlags
+		trace   string
+		mermaid string
+	)
+	cmd := &cobra.Command{
+		Use:   name + " [workload]",
+		Short: info.Title,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			params, err := flags.params()
+			if err != nil {
+				return err
+			}
+			processes, err := opts.workload(args)
+			if err != nil {
+				return err
+			}
+			if info.Quantum {
+				warning, err := scheduler.CheckQuantum(params.Quantum, processes)
+				if err != nil {
+					return err
+				}
+				if warning != "" {
+					log.Print("warning: ", warning)
+				}
+			}
+			w, closeOutput, err := opts.writer(cmd)
+			if err != nil {
+				return err
+			}
+			defer keepFirstError(&err, closeOutput)
+			results, err := runSchedulers([]string{name}, params, processes)
+			if err != nil {
+				return err
+			}
+			if err := writeReport(w, opts.output, results); err != nil {
+				return err
+			}
+			if mermaid != "" {
+				if err := saveResults(mermaid, results, render.MermaidGantt); err != nil {
+					return err
+				}
+			}
+			if trace != "" {
+				return saveResults(trace, results, render.ChromeTrace)
+			}
+			return nil
+		},
+	}
+	flags.add(cmd.Flags(), info, name == "mlq")
+	cmd.Flags().StringVar(&mermaid, "mermaid", "", "also write the run's Gantt chart to this file as a Mermaid gantt diagram, for Markdown")
+	cmd.Flags().StringVar(&trace, "trace", "", "also write the run's Gantt chart to this file as a Chrome trace, for chrome://tracing or Perfetto")
+	return cmd
+}
+
+// add adds to fs the flags for the tunables info takes, and --mlq-config if
+// mlq, along with the context-switch cost, tie-break rule, group caps and
+// --assume-sorted every scheduler takes.
+func (f *paramFlags) add(fs *pflag.FlagSet, info scheduler.SchedulerInfo, mlq bool) {
+	if info.Quantum {
+		fs.Int64Var(&f.quantum, "quantum", scheduler.DefaultQuantum, "longest a process runs per dispatch")
+		fs.StringVar(&f.carry, "carry", scheduler.CarryDiscard.String(), "what becomes of the rest of a quantum a process yields: discard, carry to its next dispatch, or bank up to --bank-cap")
+		fs.Int64Var(&f.bankCap, "bank-cap", 0, "most quantum --carry bank saves up; 0 for twice the quantum")
+	}
+	if info.Aging {
+		fs.Int64Var(&f.aging, "aging", scheduler.DefaultAgingRate, "ticks of waiting per priority boost")
+	}
+	if info.Seed {
+		fs.Int64Var(&f.seed, "seed", scheduler.DefaultSeed, "seed of the random draws")
+	}
+	if info.Latency {
+		fs.Int64Var(&f.latency, "latency", scheduler.DefaultTargetLatency, "time in which to run every runnable process once")
+	}
+	if info.Alpha {
+		fs.Float64Var(&f.alpha, "alpha", scheduler.DefaultAlpha, "weight of the last burst against the earlier ones in predicting the next, above 0 and at most 1")
+	}
+	if info.Weights {
+		fs.StringVar(&f.weights, "weights", "", "priority:weight pairs, e.g. 0:3,1:2, scaling the quantum; other priorities weigh 1")
+	}
+	if info.MultiCPU {
+		fs.IntVar(&f.cpus, "cpus", 1, "identical CPUs sharing the ready queue")
+	}
+	if info.Ages {
+		fs.Int64Var(&f.ageEvery, "age-every", 0, "boost every waiting process a step of priority each time this many ticks pass; 0 for none beyond --aging")
+		fs.Int64Var(&f.ageCap, "age-cap", 0, "best priority --age-every lifts a process to")
+	}
+	if info.Inherit {
+		fs.BoolVar(&f.inherit, "inherit", false, "priority inheritance: a process holding a lock runs at the best priority of those blocked on it")
+	}
+	if info.Nice {
+		fs.StringVar(&f.nice, "nice-weights", "", "nice:weight pairs, e.g. 0:1024,5:512, in place of Linux's weights of those nice values")
+		fs.StringVar(&f.order, "priority-order", scheduler.LowerFirst.String(), "which priority is better where a process without a nice value is weighed by it: lower-first or higher-first")
+	}
+	if mlq {
+		fs.StringVar(&f.mlqConfig, "mlq-config", "", "JSON file declaring mlq's queues, by default an rr queue for priorities up to 1 over an fcfs one")
+	}
+	fs.Int64Var(&f.switchCost, "context-switch-cost", 0, "time charged per context switch")
+	fs.StringVar(&f.tieBreak, "tie-break", scheduler.TieFIFO.String(), "how tasks that tie, on arrival or on a key such as burst, are ordered: fifo, arrival or pid")
+	fs.BoolVar(&f.sorted, "assume-sorted", false, "take the workload as sorted by arrival rather than sorting it, failing if it is not")
+	fs.StringVar(&f.caps, "caps", "", "group:percent pairs, e.g. batch:40, capping the CPU the processes of each group get in every --cap-period")
+	fs.Int64Var(&f.capPeriod, "cap-period", scheduler.DefaultCapPeriod, "window over which --caps are accounted")
+}
+
+// params checks the flags and turns them into SchedulerParams. Flags that
+// were never added are left zero, which RunSchedulerParams defaults.
+func (f *paramFlags) params() (scheduler.SchedulerParams, error) {
+	switch {
+	case f.quantum < 0:
+		return scheduler.SchedulerParams{}, fmt.Errorf("%w: quantum %d must not be negative", scheduler.ErrInvalidArgs, f.quantum)
+	case f.aging < 0:
+		return scheduler.SchedulerParams{}, fmt.Errorf("%w: aging rate %d must not be negative", scheduler.ErrInvalidArgs, f.aging)
+	case f.switchCost < 0:
+		return scheduler.SchedulerParams{}, fmt.Errorf("%w: context switch cost %d must not be negative", scheduler.ErrInvalidArgs, f.switchCost)
+	case f.latency < 0:
+		return scheduler.SchedulerParams{}, fmt.Errorf("%w: target latency %d must not be negative", scheduler.ErrInvalidArgs, f.latency)
+	case f.cpus < 0:
+		return scheduler.SchedulerParams{}, fmt.Errorf("%w: CPU count %d must not be negative", scheduler.ErrInvalidArgs, f.cpus)
+	case f.alpha < 0 || f.alpha > 1:
+		return scheduler.SchedulerParams{}, fmt.Errorf("%w: alpha %g is not within 0 to 1", scheduler.ErrInvalidArgs, f.alpha)
+	case f.capPeriod < 0:
+		return scheduler.SchedulerParams{}, fmt.Errorf("%w: cap period %d must not be negative", scheduler.ErrInvalidArgs, f.capPeriod)
+	case f.bankCap < 0:
+		return scheduler.SchedulerParams{}, fmt.Errorf("%w: bank cap %d must not be negative", scheduler.ErrInvalidArgs, f.bankCap)
+	}
+	params := scheduler.SchedulerParams{Quantum: f.quantum, Aging: f.aging, SwitchCost: f.switchCost, CPUs: f.cpus, Seed: f.seed, Latency: f.latency, Alpha: f.alpha, AssumeSorted: f.sorted, Inherit: f.inherit,
+		CapPeriod: f.capPeriod, BankCap: f.bankCap}
+	var err error
+	if params.TieBreak, err = scheduler.ParseTieBreak(f.tieBreak); err != nil {
+		return scheduler.SchedulerParams{}, err
+	}
+	if params.Weights, err = parseWeights(f.weights); err != nil {
+		return scheduler.SchedulerParams{}, err
+	}
+	if params.AgingPolicy, err = parseAgingPolicy(f.ageEvery, f.ageCap); err != nil {
+		return scheduler.SchedulerParams{}, err
+	}
+	if params.NiceWeights, err = parseNiceWeights(f.nice); err != nil {
+		return scheduler.SchedulerParams{}, err
+	}
+	if params.Caps, err = parseCaps(f.caps); err != nil {
+		return scheduler.SchedulerParams{}, err
+	}
+	if f.carry != "" {
+		if params.Carry, err = scheduler.ParseCarryPolicy(f.carry); err != nil {
+			return scheduler.SchedulerParams{}, err
+		}
+	}
+	if f.order != "" {
+		if params.PriorityOrder, err = scheduler.ParsePriorityOrder(f.order); err != nil {
+			return scheduler.SchedulerParams{}, err
+		}
+	}
+	for priority, weight := range params.Weights {
+		if f.quantum > 0 && weight > math.MaxInt64/f.quantum {
+			return scheduler.SchedulerParams{}, fmt.Errorf("%w: weight %d of priority %d overflows quantum %d", scheduler.ErrInvalidArgs, weight, priority, f.quantum)
+		}
+	}
+	if f.mlqConfig != "" {
+		if params.MLQ, err = loader.LoadMLQConfigFile(f.mlqConfig); err != nil {
+			return scheduler.SchedulerParams{}, err
+		}
+	}
+	return params, nil
+}
+
+// workload loads the workload given as the only argument, or else by --input.
+func (o *cliOptions) workload(args []string) ([]scheduler.Process, error) {
+	path := o.input
+	if len(args) == 1 {
+		if path != "" {
+			return nil, fmt.Errorf("%w: give the workload as an argument or with --input, not both", scheduler.ErrInvalidArgs)
+		}
+		path = args[0]
+	}
+	if path == "" {
+		return nil, fmt.Errorf("%w: must give a workload, as an argument or with --input", scheduler.ErrInvalidArgs)
+	}
+	return loader.LoadFile(path, o.format)
+}
+
+// writer is where cmd's report goes, --output-file or else cmd's output,
+// and what closes it.
+func (o *cliOptions) writer(cmd *cobra.Command) (io.Writer, func() error, error) {
+	if o.outputFile == "" {
+		return cmd.OutOrStdout(), func() error { return nil }, nil
+	}
+	f, err := os.Create(o.outputFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f.Close, nil
+}
+
+//endregion
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+)
+
+func TestRootCommand(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		args     []string
+		wantErr  string
+		wantOut  []string
+		wantFile string
+	}{
+		{name: "schedule", args: []string{"schedule", "fcfs", "testdata/workloads/basic.csv"}, wantOut: []string{"First-come, first-serve"}},
+		{name: "schedule rr", args: []string{"schedule", "rr", "--quantum", "4", "testdata/workloads/basic.csv"}, wantOut: []string{"Round-robin (quantum 4)"}},
+		{name: "schedule from --input", args: []string{"--input", "testdata/workloads/basic.csv", "schedule", "sjf", "-o", "expanded"}, wantOut: []string{"Shortest-job-first", "PREEMPTIONS"}},
+		{name: "schedule with ties by PID", args: []string{"schedule", "priority", "--tie-break", "pid", "testdata/workloads/basic.csv"}, wantOut: []string{"Priority"}},
+		{name: "priority inheritance", args: []string{"schedule", "priority-preemptive", "--inherit", "testdata/workloads/inversion.csv"}, wantOut: []string{"(priority inheritance)", "1 (low) |##-^^--------#|"}},
+		{name: "inheritance the scheduler cannot do", args: []string{"schedule", "rr", "--inherit", "testdata/workloads/inversion.csv"}, wantErr: "unknown flag: --inherit"},
+		{name: "aging policy", args: []string{"schedule", "priority", "--age-every", "3", "testdata/workloads/mixed.csv"}, wantOut: []string{"Priority (aging every 3)", "AGED TO"}},
+		{name: "aging the scheduler cannot do", args: []string{"schedule", "sjf", "--age-every", "3", "testdata/workloads/mixed.csv"}, wantErr: "unknown flag: --age-every"},
+		{name: "nice weights", args: []string{"schedule", "cfs", "--nice-weights", "10:1024", "testdata/workloads/nice.csv"}, wantOut: []string{"(custom nice weights)", "| 2 (build)  |        0 |   10 |   1024 |"}},
+		{name: "priority order", args: []string{"schedule", "stride", "--priority-order", "higher-first", "testdata/workloads/nice.csv"}, wantOut: []string{"(higher-first priority)", "NICE"}},
+		{name: "unknown priority order", args: []string{"schedule", "cfs", "--priority-order", "up", "testdata/workloads/nice.csv"}, wantErr: `unknown priority order "up"`},
+		{name: "group caps", args: []string{"schedule", "rr", "--caps", "batch:50", "--cap-period", "10", "testdata/workloads/groups.csv"}, wantOut: []string{"(caps batch 50% every 10)", "Group throttling", "| 2 (indexer) | batch |"}},
+		{name: "bad group cap", args: []string{"schedule", "rr", "--caps", "batch", "testdata/workloads/groups.csv"}, wantErr: `cap "batch" is not group:percent`},
+		{name: "carry", args: []string{"schedule", "rr", "--quantum", "3", "--carry", "carry", "testdata/workloads/yields.csv"}, wantOut: []string{"Round-robin (quantum 3) (carry yielded quanta)", "0          1            4         6          11"}},
+		{name: "bank", args: []string{"schedule", "wrr", "--carry", "bank", "--bank-cap", "4", "testdata/workloads/yields.csv"}, wantOut: []string{"(bank yielded quanta up to 4)"}},
+		{name: "unknown carry policy", args: []string{"schedule", "rr", "--carry", "keep", "testdata/workloads/yields.csv"}, wantErr: `unknown carry policy "keep"`},
+		{name: "bank cap without bank", args: []string{"schedule", "rr", "--bank-cap", "4", "testdata/workloads/yields.csv"}, wantErr: "a bank cap needs the bank carry policy"},
+		{name: "carry without a quantum", args: []string{"schedule", "fcfs", "--carry", "carry", "testdata/workloads/yields.csv"}, wantErr: "unknown flag: --carry"},
+		{name: "nice the scheduler cannot weigh", args: []string{"schedule", "fcfs", "--nice-weights", "0:1", "testdata/workloads/nice.csv"}, wantErr: "unknown flag: --nice-weights"},
+		{name: "assume sorted", args: []string{"schedule", "rr", "--assume-sorted", "testdata/workloads/basic.csv"}, wantOut: []string{"Round-robin"}},
+		{name: "flag the scheduler does not take", args: []string{"schedule", "fcfs", "--quantum", "2", "testdata/workloads/basic.csv"}, wantErr: "unknown flag: --quantum"},
+		{name: "workload twice", args: []string{"schedule", "fcfs", "-i", "testdata/workloads/basic.csv", "testdata/workloads/basic.csv"}, wantErr: "not both"},
+		{name: "no workload", args: []string{"schedule", "fcfs"}, wantErr: "must give a workload"},
+		{name: "unknown scheduler", args: []string{"schedule", "nope", "testdata/workloads/basic.csv"}, wantErr: `unknown scheduler "nope"`},
+		{name: "compare as JSON", args: []string{"compare", "--schedulers", "fcfs,sjf", "--output", "json", "testdata/workloads/basic.csv"}, wantOut: []string{`"best"`}},
+		{name: "compare expanded", args: []string{"compare", "-o", "expanded", "testdata/workloads/basic.csv"}, wantErr: "compare prints text or json"},
+		{name: "generate", args: []string{"generate", "--processes", "3", "--seed", "7"}, wantOut: []string{"1,", "2,", "3,"}},
+		{name: "generate nothing", args: []string{"generate", "--processes", "0"}, wantErr: "must be at least 1"},
+		{name: "Go-style subcommand", args: []string{"rm", "-periods", "1", "example_periodic.csv"}, wantErr: "flag provided but not defined: -periods"},
+		{name: "optimize-quantum", args: []string{"optimize-quantum", "--context-switch-cost", "1", "testdata/workloads/mixed.csv"}, wantOut: []string{"Best quantum: 5, turnaround 14.67"}},
+		{name: "optimize-quantum by annealing", args: []string{"optimize-quantum", "--search", "anneal", "--optimize", "switches", "-o", "json", "testdata/workloads/mixed.csv"}, wantOut: []string{`"best_quantum"`, `"context_switches"`}},
+		{name: "optimize-quantum expanded", args: []string{"optimize-quantum", "-o", "expanded", "testdata/workloads/mixed.csv"}, wantErr: "optimize-quantum prints text or json"},
+		{name: "bench", args: []string{"bench", "--schedulers", "fcfs,sjf", "--sizes", "10,20"}, wantOut: []string{"TIME/PROCESS", "sjf", "20"}},
+		{name: "bench no runs", args: []string{"bench", "--runs", "0"}, wantErr: "at least 1 run"},
+		{name: "help", args: []string{"help"}, wantOut: []string{"schedule", "compare", "generate", "validate", "pagesim"}},
+		{name: "schedule help", args: []string{"schedule", "rr", "--help"}, wantOut: []string{"--quantum", "--tie-break", "--input"}},
+		{name: "output file", args: []string{"schedule", "fcfs", "--output-file", filepath.Join(dir, "fcfs.txt"), "testdata/workloads/basic.csv"}, wantFile: filepath.Join(dir, "fcfs.txt")},
+		{name: "Mermaid Gantt", args: []string{"schedule", "sjf", "--mermaid", filepath.Join(dir, "sjf.mmd"), "testdata/workloads/basic.csv"}, wantOut: []string{"Shortest-job-first"}},
+		{name: "Chrome trace", args: []string{"schedule", "rr", "--trace", filepath.Join(dir, "rr.json"), "testdata/workloads/basic.csv"}, wantOut: []string{"Round-robin"}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var out bytes.Buffer
+			err := run(&out, tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("run() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.wantOut {
+				if !strings.Contains(out.String(), s) {
+					t.Errorf("output missing %q:\n%s", s, out.String())
+				}
+			}
+			if tt.wantFile != "" {
+				report, err := os.ReadFile(tt.wantFile)
+				if err != nil || !bytes.Contains(report, []byte("First-come, first-serve")) {
+					t.Errorf("--output-file wrote %q, %v", report, err)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateCommand(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "w.json")
+	if err := run(&bytes.Buffer{}, []string{"generate", "--processes", "6", "--seed", "3", "--output-file", path}); err != nil {
+		t.Fatal(err)
+	}
+	var rows []map[string]interface{}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &rows); err != nil {
+		t.Fatalf("generate wrote %s, not JSON: %v", b, err)
+	}
+	processes, err := loader.LoadFile(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again bytes.Buffer
+	if err := run(&again, []string{"generate", "--processes", "6", "--seed", "3"}); err != nil {
+		t.Fatal(err)
+	}
+	csv, err := loader.LoadCSV(&again)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(processes) != 6 || len(csv) != 6 {
+		t.Fatalf("generated %d and %d processes, want 6", len(processes), len(csv))
+	}
+	for i := range csv {
+		if csv[i].ProcessID != processes[i].ProcessID || csv[i].BurstDuration != processes[i].BurstDuration ||
+			csv[i].ArrivalTime != processes[i].ArrivalTime || csv[i].Priority != processes[i].Priority {
+			t.Errorf("seed 3 gave %+v as CSV but %+v as JSON", csv[i], processes[i])
+		}
+	}
+	if err := loader.Validate(processes); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region compare command
+
+// newCompareCommand is the `compare` subcommand, which runs schedulers over
+// one workload and prints their averages in one table, the best of each
+// marked, or with --output json the comparison as JSON:
+// `compare [--schedulers fcfs,rr] [--quantum 2] [--aging 10] [--context-switch-cost 0] [--cpus 1] [--seed 1] [--latency 12] [--alpha 0.5] [--tie-break fifo] workload.csv`.
+func newCompareCommand(opts *cliOptions) *cobra.Command {
+	var (
+		schedulers string
+		flags      paramFlags
+	)
+	cmd := &cobra.Command{
+		Use:   "compare [workload]",
+		Short: "Compare schedulers' averages over a workload",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if opts.output != OutputText && opts.output != OutputJSON {
+				return fmt.Errorf("%w: compare prints %s or %s, not %q", scheduler.ErrInvalidArgs, OutputText, OutputJSON, opts.output)
+			}
+			params, err := flags.params()
+			if err != nil {
+				return err
+			}
+			names, err := parseSchedulers(schedulers)
+			if err != nil {
+				return err
+			}
+			if params.CPUs > 1 && !cmd.Flags().Changed("schedulers") {
+				names = multiCPUSchedulers(names)
+			}
+			processes, err := opts.workload(args)
+			if err != nil {
+				return err
+			}
+			results := make([]scheduler.RunResult, 0, len(names))
+			for _, name := range names {
+				result, err := scheduler.RunSchedulerParams(name, params, processes)
+				if err != nil {
+					return err
+				}
+				results = append(results, result)
+			}
+			w, closeOutput, err := opts.writer(cmd)
+			if err != nil {
+				return err
+			}
+			defer keepFirstError(&err, closeOutput)
+			c := scheduler.Compare(results)
+			if opts.output == OutputJSON {
+				enc := json.NewEncoder(w)
+				enc.SetIndent("", "  ")
+				return enc.Encode(c)
+			}
+			outputComparison(w, c)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&schedulers, "schedulers", strings.Join(scheduler.SchedulerNames(), ","), "comma-separated schedulers to run")
+	// Every tunable, for whichever schedulers take it.
+	flags.add(cmd.Flags(), scheduler.SchedulerInfo{Quantum: true, Aging: true, Seed: true, Latency: true, Weights: true, Alpha: true, MultiCPU: true, Inherit: true, Ages: true, Nice: true}, true)
+	return cmd
+}
+
+// outputComparison prints a comparison with an asterisk on each column's best
+// values.
+func outputComparison(w io.Writer, c scheduler.Comparison) {
+	header, align := []string{"Scheduler"}, []int{tablewriter.ALIGN_LEFT}
+	for _, m := range scheduler.ComparisonMetrics {
+		header, align = append(header, m.Title), append(align, tablewriter.ALIGN_RIGHT)
+	}
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(header)
+	table.SetColumnAlignment(align)
+	for _, r := range c.Results {
+		label := scheduler.RunLabel(r)
+		row := []string{label}
+		for _, m := range scheduler.ComparisonMetrics {
+			cell := fmt.Sprintf(m.Format, m.Value(r))
+			if c.IsBest(m.Name, label) {
+				cell += " *"
+			}
+			row = append(row, cell)
+		}
+		table.Append(row)
+	}
+	table.Render()
+	_, _ = fmt.Fprintln(w, "* best in its column")
+}
+
+//endregion
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+	results := []scheduler.RunResult{
+		{Scheduler: "fcfs", AvgWait: 4, AvgTurnaround: 7, AvgResponse: 4, Throughput: 0.5, Utilization: 1, Gantt: []scheduler.TimeSlice{{PID: 1, Stop: 3}},
+			WaitStats: &scheduler.Distribution{P95: 8, Stddev: 3}, Fairness: 0.7},
+		{Scheduler: "rr", Quantum: 2, AvgWait: 3, AvgTurnaround: 7, AvgResponse: 1, Throughput: 0.5, Utilization: 0.75,
+			WaitStats: &scheduler.Distribution{P95: 5, Stddev: 3}, Fairness: 0.9},
+		{Scheduler: "sjf", AvgWait: 3, AvgTurnaround: 6, AvgResponse: 3, Throughput: 0.25, Utilization: 1,
+			WaitStats: &scheduler.Distribution{P95: 9, Stddev: 2}, Fairness: 0.6},
+	}
+	c := scheduler.Compare(results)
+	want := map[string][]string{
+		"avg_wait":       {"rr (q=2)", "sjf"},
+		"avg_turnaround": {"sjf"},
+		"avg_response":   {"rr (q=2)"},
+		"throughput":     {"fcfs", "rr (q=2)"},
+		"utilization":    {"fcfs", "sjf"},
+		"p95_wait":       {"rr (q=2)"},
+		"wait_stddev":    {"sjf"},
+		"fairness":       {"rr (q=2)"},
+	}
+	if !reflect.DeepEqual(c.Best, want) {
+		t.Errorf("Best = %v, want %v", c.Best, want)
+	}
+	if c.Results[0].Gantt != nil || results[0].Gantt == nil {
+		t.Error("Compare() kept the Gantt chart or dropped the caller's")
+	}
+}
+
+func TestRunCompareCommand(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	if err := run(&out, []string{"compare", "--schedulers", "fcfs,srtf,rr", "testdata/workloads/mixed.csv"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"| fcfs      |     8.67 |",
+		"| srtf      |   2.83 * |         6.50 * |       0.83 * |",
+		"| rr (q=2)  |     6.33 |",
+		"* best in its column",
+	} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("output missing %q:\n%s", s, out.String())
+		}
+	}
+	if err := run(&out, []string{"compare", "--schedulers", "fcfs,nope", "testdata/workloads/mixed.csv"}); err == nil {
+		t.Error("compare with an unknown scheduler did not fail")
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/Sha-min/CSCE4600/pkg/render"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region Deadlock detection
+
+const (
+	LockAcquire = "acquire"
+	LockRelease = "release"
+)
+
+type (
+	// LockEvent is one row of a lock-event workload: at Time, process PID
+	// acquires or releases a single-instance Resource.
+	LockEvent struct {
+		Time     int64
+		PID      int64
+		Op       string
+		Resource string
+	}
+	// RAG is a resource-allocation graph for single-instance resources. Holder
+	// gives the assignment edges, Waiting the request edges in arrival order.
+	RAG struct {
+		Holder    map[string]int64
+		Waiting   map[string][]int64
+		BlockedOn map[int64]string
+	}
+)
+
+// BuildRAG replays events and returns the graph as it stands afterwards. An
+// acquire of a held resource blocks the process; a release hands the resource
+// to the longest waiter.
+func BuildRAG(events []LockEvent) (*RAG, error) {
+	g := &RAG{
+		Holder:    make(map[string]int64),
+		Waiting:   make(map[string][]int64),
+		BlockedOn: make(map[int64]string),
+	}
+	sorted := append([]LockEvent(nil), events...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time < sorted[j].Time
+	})
+
+	for _, ev := range sorted {
+		if r, blocked := g.BlockedOn[ev.PID]; blocked {
+			return nil, fmt.Errorf("%w: t=%d: P%d acts while blocked on %s", scheduler.ErrInvalidArgs, ev.Time, ev.PID, r)
+		}
+		holder, held := g.Holder[ev.Resource]
+		switch ev.Op {
+		case LockAcquire:
+			switch {
+			case !held:
+				g.Holder[ev.Resource] = ev.PID
+			case holder == ev.PID:
+				return nil, fmt.Errorf("%w: t=%d: P%d already holds %s", scheduler.ErrInvalidArgs, ev.Time, ev.PID, ev.Resource)
+			default:
+				g.Waiting[ev.Resource] = append(g.Waiting[ev.Resource], ev.PID)
+				g.BlockedOn[ev.PID] = ev.Resource
+			}
+		case LockRelease:
+			if !held || holder != ev.PID {
+				return nil, fmt.Errorf("%w: t=%d: P%d releases %s without holding it", scheduler.ErrInvalidArgs, ev.Time, ev.PID, ev.Resource)
+			}
+			g.release(ev.Resource)
+		default:
+			return nil, fmt.Errorf("%w: t=%d: unknown lock operation %q", scheduler.ErrInvalidArgs, ev.Time, ev.Op)
+		}
+	}
+
+	return g, nil
+}
+
+func (g *RAG) release(resource string) {
+	waiters := g.Waiting[resource]
+	if len(waiters) == 0 {
+		delete(g.Holder, resource)
+		return
+	}
+	g.Holder[resource] = waiters[0]
+	delete(g.BlockedOn, waiters[0])
+	if g.Waiting[resource] = waiters[1:]; len(g.Waiting[resource]) == 0 {
+		delete(g.Waiting, resource)
+	}
+}
+
+// waitsFor collapses the graph to process -> process edges: a blocked process
+// waits for whoever holds the resource it asked for.
+func (g *RAG) waitsFor() map[int64]int64 {
+	edges := make(map[int64]int64, len(g.BlockedOn))
+	for pid, r := range g.BlockedOn {
+		edges[pid] = g.Holder[r]
+	}
+	return edges
+}
+
+// Deadlocks returns each set of processes caught in a cycle, sorted by PID.
+// Every blocked process waits on exactly one holder, so cycles are found by
+// walking from each process until the walk repeats or ends.
+func (g *RAG) Deadlocks() [][]int64 {
+	edges := g.waitsFor()
+	seen := make(map[int64]bool, len(edges))
+	var cycles [][]int64
+	for _, start := range sortedPIDs(edges) {
+		path := map[int64]bool{}
+		pid := start
+		for !seen[pid] {
+			seen[pid] = true
+			path[pid] = true
+			next, blocked := edges[pid]
+			if !blocked {
+				break
+			}
+			if path[next] {
+				cycle := []int64{next}
+				for p := edges[next]; p != next; p = edges[p] {
+					cycle = append(cycle, p)
+				}
+				sort.Slice(cycle, func(i, j int) bool { return cycle[i] < cycle[j] })
+				cycles = append(cycles, cycle)
+				break
+			}
+			pid = next
+		}
+	}
+	return cycles
+}
+
+// Victims suggests a smallest set of processes to terminate. A blocked process
+// waits on exactly one resource, so the cycles never share a process and one
+// victim per cycle is both necessary and enough; the lowest PID is chosen.
+func (g *RAG) Victims() []int64 {
+	var victims []int64
+	for _, cycle := range g.Deadlocks() {
+		victims = append(victims, cycle[0])
+	}
+	return victims
+}
+
+func sortedPIDs(edges map[int64]int64) []int64 {
+	pids := make([]int64, 0, len(edges))
+	for pid := range edges {
+		pids = append(pids, pid)
+	}
+	sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+	return pids
+}
+
+func sortedResources(holder map[string]int64) []string {
+	resources := make([]string, 0, len(holder))
+	for r := range holder {
+		resources = append(resources, r)
+	}
+	sort.Strings(resources)
+	return resources
+}
+
+//endregion
+
+//region deadlock command
+
+// runDeadlock is the `deadlock` subcommand: `deadlock events.csv`.
+func runDeadlock(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("deadlock", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: deadlock needs a lock-event file", scheduler.ErrInvalidArgs)
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("%v: error opening lock-event file", err)
+	}
+	defer f.Close()
+
+	events, err := loadLockEvents(f)
+	if err != nil {
+		return err
+	}
+	g, err := BuildRAG(events)
+	if err != nil {
+		return err
+	}
+
+	render.Title(w, "Deadlock detection")
+	outputRAG(w, g)
+	cycles := g.Deadlocks()
+	if len(cycles) == 0 {
+		_, _ = fmt.Fprint(w, "No deadlock\n\n")
+		return nil
+	}
+	for _, cycle := range cycles {
+		_, _ = fmt.Fprintf(w, "Deadlocked: %s\n", joinPIDs(cycle))
+	}
+	_, _ = fmt.Fprintf(w, "Suggested victims: %s\n\n", joinPIDs(g.Victims()))
+
+	return nil
+}
+
+// loadLockEvents reads rows of time,pid,op,resource where op is acquire or release.
+func loadLockEvents(r io.Reader) ([]LockEvent, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading CSV", err)
+	}
+
+	events := make([]LockEvent, len(rows))
+	for i := range rows {
+		if len(rows[i]) != 4 {
+			return nil, fmt.Errorf("%w: line %d: expected 4 fields, got %d", scheduler.ErrInvalidArgs, i+1, len(rows[i]))
+		}
+		t, tErr := strconv.ParseInt(strings.TrimSpace(rows[i][0]), 10, 64)
+		pid, pErr := strconv.ParseInt(strings.TrimSpace(rows[i][1]), 10, 64)
+		if tErr != nil || pErr != nil {
+			return nil, fmt.Errorf("%w: line %d: time and pid must be integers", scheduler.ErrInvalidArgs, i+1)
+		}
+		events[i] = LockEvent{
+			Time:     t,
+			PID:      pid,
+			Op:       strings.ToLower(strings.TrimSpace(rows[i][2])),
+			Resource: strings.TrimSpace(rows[i][3]),
+		}
+	}
+
+	return events, nil
+}
+
+func outputRAG(w io.Writer, g *RAG) {
+	_, _ = fmt.Fprintln(w, "Resource-allocation graph")
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Resource", "Held by", "Requested by"})
+	resources := sortedResources(g.Holder)
+	for _, r := range resources {
+		table.Append([]string{r, fmt.Sprintf("P%d", g.Holder[r]), joinPIDs(g.Waiting[r])})
+	}
+	table.Render()
+}
+
+func joinPIDs(pids []int64) string {
+	names := make([]string, len(pids))
+	for i := range pids {
+		names[i] = fmt.Sprintf("P%d", pids[i])
+	}
+	return strings.Join(names, ", ")
+}
+
+//endregion
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestRAG_Deadlocks(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		events      string
+		wantCycles  [][]int64
+		wantVictims []int64
+	}{
+		{
+			name: "no deadlock",
+			events: `0,1,acquire,A
+1,2,acquire,A
+2,1,release,A`,
+		},
+		{
+			name: "two processes",
+			events: `0,1,acquire,A
+0,2,acquire,B
+1,1,acquire,B
+1,2,acquire,A`,
+			wantCycles:  [][]int64{{1, 2}},
+			wantVictims: []int64{1},
+		},
+		{
+			name: "two independent deadlocks",
+			events: `0,1,acquire,A
+0,2,acquire,B
+0,3,acquire,C
+0,4,acquire,D
+1,4,acquire,C
+1,1,acquire,B
+2,3,acquire,D
+2,2,acquire,A`,
+			wantCycles:  [][]int64{{1, 2}, {3, 4}},
+			wantVictims: []int64{1, 3},
+		},
+		{
+			name: "waiter outside the cycle",
+			events: `0,1,acquire,A
+0,2,acquire,B
+0,3,acquire,C
+1,1,acquire,B
+1,2,acquire,C
+1,3,acquire,A
+2,4,acquire,A`,
+			wantCycles:  [][]int64{{1, 2, 3}},
+			wantVictims: []int64{1},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			events, err := loadLockEvents(strings.NewReader(tt.events))
+			if err != nil {
+				t.Fatal(err)
+			}
+			g, err := BuildRAG(events)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := g.Deadlocks(); !reflect.DeepEqual(got, tt.wantCycles) {
+				t.Errorf("Deadlocks() = %v, want %v", got, tt.wantCycles)
+			}
+			if got := g.Victims(); !reflect.DeepEqual(got, tt.wantVictims) {
+				t.Errorf("Victims() = %v, want %v", got, tt.wantVictims)
+			}
+		})
+	}
+}
+
+func TestBuildRAG_errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		events []LockEvent
+	}{
+		{name: "release unheld", events: []LockEvent{{PID: 1, Op: LockRelease, Resource: "A"}}},
+		{name: "double acquire", events: []LockEvent{
+			{PID: 1, Op: LockAcquire, Resource: "A"},
+			{PID: 1, Op: LockAcquire, Resource: "A"},
+		}},
+		{name: "blocked process acts", events: []LockEvent{
+			{Time: 0, PID: 1, Op: LockAcquire, Resource: "A"},
+			{Time: 1, PID: 2, Op: LockAcquire, Resource: "A"},
+			{Time: 2, PID: 2, Op: LockAcquire, Resource: "B"},
+		}},
+		{name: "unknown op", events: []LockEvent{{PID: 1, Op: "steal", Resource: "A"}}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := BuildRAG(tt.events); !errors.Is(err, scheduler.ErrInvalidArgs) {
+				t.Errorf("BuildRAG() error = %v, want %v", err, scheduler.ErrInvalidArgs)
+			}
+		})
+	}
+}
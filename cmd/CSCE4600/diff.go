@@ -0,0 +1,247 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region Result diff
+
+// ErrRegression is returned by `diff -fail` when B is worse than A anywhere.
+var ErrRegression = errors.New("results regressed")
+
+// Directions a metric can move between two runs.
+const (
+	DiffSame     = "same"
+	DiffBetter   = "better"
+	DiffWorse    = "worse"
+	DiffOnlyInA  = "only in A"
+	DiffOnlyInB  = "only in B"
+	summaryLabel = "summary"
+)
+
+type (
+	// MetricDiff is one metric of one scheduler run compared across two result
+	// sets. Process is "summary" for the averages or the process ID.
+	MetricDiff struct {
+		Scheduler string
+		Process   string
+		Metric    string
+		A, B      float64
+		Change    string
+	}
+	// diffMetric reads one metric; lower is better unless higherIsBetter.
+	diffMetric struct {
+		name           string
+		higherIsBetter bool
+	}
+)
+
+var (
+	summaryMetrics = []diffMetric{{name: "avg_wait"}, {name: "avg_turnaround"}, {name: "throughput", higherIsBetter: true}}
+	processMetrics = []diffMetric{{name: "response"}, {name: "wait"}, {name: "turnaround"}, {name: "exit"}}
+)
+
+// DiffResults compares result sets scheduler by scheduler, matching runs by
+// scheduler name and quantum and processes by ID. Schedulers or processes that
+// appear on one side only are reported once, with their metric left blank.
+func DiffResults(a, b []scheduler.RunResult) []MetricDiff {
+	var diffs []MetricDiff
+	bs := make(map[string]scheduler.RunResult, len(b))
+	for _, r := range b {
+		bs[scheduler.RunLabel(r)] = r
+	}
+	seen := map[string]bool{}
+	for _, ra := range a {
+		label := scheduler.RunLabel(ra)
+		seen[label] = true
+		rb, ok := bs[label]
+		if !ok {
+			diffs = append(diffs, MetricDiff{Scheduler: label, Process: summaryLabel, Change: DiffOnlyInA})
+			continue
+		}
+		diffs = append(diffs, diffRun(label, ra, rb)...)
+	}
+	for _, rb := range b {
+		if label := scheduler.RunLabel(rb); !seen[label] {
+			diffs = append(diffs, MetricDiff{Scheduler: label, Process: summaryLabel, Change: DiffOnlyInB})
+		}
+	}
+	return diffs
+}
+
+func diffRun(label string, a, b scheduler.RunResult) []MetricDiff {
+	summary := func(r scheduler.RunResult) []float64 { return []float64{r.AvgWait, r.AvgTurnaround, r.Throughput} }
+	diffs := compareMetrics(label, summaryLabel, summaryMetrics, summary(a), summary(b))
+
+	perProcess := func(m scheduler.ProcessMetrics) []float64 {
+		return []float64{float64(m.Response), float64(m.Wait), float64(m.Turnaround), float64(m.Exit)}
+	}
+	bs := make(map[int64]scheduler.ProcessMetrics, len(b.Processes))
+	for _, m := range b.Processes {
+		bs[m.PID] = m
+	}
+	seen := map[int64]bool{}
+	for _, ma := range a.Processes {
+		seen[ma.PID] = true
+		pid := fmt.Sprint(ma.PID)
+		mb, ok := bs[ma.PID]
+		if !ok {
+			diffs = append(diffs, MetricDiff{Scheduler: label, Process: pid, Change: DiffOnlyInA})
+			continue
+		}
+		diffs = append(diffs, compareMetrics(label, pid, processMetrics, perProcess(ma), perProcess(mb))...)
+	}
+	for _, mb := range b.Processes {
+		if !seen[mb.PID] {
+			diffs = append(diffs, MetricDiff{Scheduler: label, Process: fmt.Sprint(mb.PID), Change: DiffOnlyInB})
+		}
+	}
+	return diffs
+}
+
+func compareMetrics(label, process string, metrics []diffMetric, a, b []float64) []MetricDiff {
+	diffs := make([]MetricDiff, len(metrics))
+	for i, m := range metrics {
+		d := MetricDiff{Scheduler: label, Process: process, Metric: m.name, A: a[i], B: b[i], Change: DiffSame}
+		switch {
+		case a[i] == b[i]:
+		case (b[i] > a[i]) == m.higherIsBetter:
+			d.Change = DiffBetter
+		default:
+			d.Change = DiffWorse
+		}
+		diffs[i] = d
+	}
+	return diffs
+}
+
+// loadResultSet reads results saved from the server: a POST /runs response,
+// a list of results, or a single result.
+func loadResultSet(r io.Reader) ([]scheduler.RunResult, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var run struct {
+		Results []scheduler.RunResult `json:"results"`
+	}
+	if err := json.Unmarshal(data, &run); err == nil && run.Results != nil {
+		return run.Results, nil
+	}
+	var results []scheduler.RunResult
+	if err := json.Unmarshal(data, &results); err == nil {
+		return results, nil
+	}
+	var single scheduler.RunResult
+	if err := json.Unmarshal(data, &single); err != nil || single.Scheduler == "" {
+		return nil, fmt.Errorf("%w: not a result set: want a run, a list of results or one result", scheduler.ErrInvalidArgs)
+	}
+	return []scheduler.RunResult{single}, nil
+}
+
+func loadResultFile(path string) ([]scheduler.RunResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	results, err := loadResultSet(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	sort.SliceStable(results, func(i, j int) bool { return scheduler.RunLabel(results[i]) < scheduler.RunLabel(results[j]) })
+	return results, nil
+}
+
+//endregion
+
+//region diff command
+
+// runDiff is the `diff` subcommand: `diff [-all] [-fail] runA.json runB.json`.
+func runDiff(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	all := fs.Bool("all", false, "also list metrics that did not change")
+	fail := fs.Bool("fail", false, "exit with an error if anything got worse")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("%w: diff needs two result files", scheduler.ErrInvalidArgs)
+	}
+	a, err := loadResultFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	b, err := loadResultFile(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	diffs := DiffResults(a, b)
+	worse := outputDiff(w, diffs, *all)
+	if *fail && worse > 0 {
+		return fmt.Errorf("%w: %d worse", ErrRegression, worse)
+	}
+	return nil
+}
+
+// outputDiff prints the changed metrics, worse ones marked, and returns how
+// many got worse.
+func outputDiff(w io.Writer, diffs []MetricDiff, all bool) int {
+	var worse, better int
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Scheduler", "Process", "Metric", "A", "B", "Delta", "Change"})
+	for _, d := range diffs {
+		switch d.Change {
+		case DiffWorse:
+			worse++
+		case DiffBetter:
+			better++
+		case DiffSame:
+			if !all {
+				continue
+			}
+		}
+		if d.Metric == "" {
+			table.Append([]string{d.Scheduler, d.Process, "-", "-", "-", "-", d.Change})
+			continue
+		}
+		change := d.Change
+		if change == DiffWorse {
+			change = "!! " + change
+		}
+		table.Append([]string{
+			d.Scheduler,
+			d.Process,
+			d.Metric,
+			formatMetric(d.A),
+			formatMetric(d.B),
+			fmt.Sprintf("%+.2f", d.B-d.A),
+			change,
+		})
+	}
+	if table.NumLines() > 0 {
+		table.Render()
+	}
+	_, _ = fmt.Fprintf(w, "%d worse, %d better\n", worse, better)
+	return worse
+}
+
+func formatMetric(v float64) string {
+	if v == float64(int64(v)) {
+		return fmt.Sprint(int64(v))
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
+//endregion
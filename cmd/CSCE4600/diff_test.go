@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestDiffResults(t *testing.T) {
+	t.Parallel()
+	a := []scheduler.RunResult{
+		{Scheduler: "fcfs", AvgWait: 2, AvgTurnaround: 5, Throughput: 0.5, Processes: []scheduler.ProcessMetrics{
+			{PID: 1, Response: 0, Wait: 0, Turnaround: 3, Exit: 3},
+			{PID: 2, Response: 1, Wait: 1, Turnaround: 4, Exit: 6},
+		}},
+		{Scheduler: "sjf"},
+	}
+	b := []scheduler.RunResult{
+		{Scheduler: "fcfs", AvgWait: 3, AvgTurnaround: 5, Throughput: 0.6, Processes: []scheduler.ProcessMetrics{
+			{PID: 1, Response: 0, Wait: 2, Turnaround: 3, Exit: 3},
+			{PID: 3},
+		}},
+		{Scheduler: "rr", Quantum: 2},
+	}
+	want := []MetricDiff{
+		{Scheduler: "fcfs", Process: "summary", Metric: "avg_wait", A: 2, B: 3, Change: DiffWorse},
+		{Scheduler: "fcfs", Process: "summary", Metric: "avg_turnaround", A: 5, B: 5, Change: DiffSame},
+		{Scheduler: "fcfs", Process: "summary", Metric: "throughput", A: 0.5, B: 0.6, Change: DiffBetter},
+		{Scheduler: "fcfs", Process: "1", Metric: "response", Change: DiffSame},
+		{Scheduler: "fcfs", Process: "1", Metric: "wait", A: 0, B: 2, Change: DiffWorse},
+		{Scheduler: "fcfs", Process: "1", Metric: "turnaround", A: 3, B: 3, Change: DiffSame},
+		{Scheduler: "fcfs", Process: "1", Metric: "exit", A: 3, B: 3, Change: DiffSame},
+		{Scheduler: "fcfs", Process: "2", Change: DiffOnlyInA},
+		{Scheduler: "fcfs", Process: "3", Change: DiffOnlyInB},
+		{Scheduler: "sjf", Process: "summary", Change: DiffOnlyInA},
+		{Scheduler: "rr (q=2)", Process: "summary", Change: DiffOnlyInB},
+	}
+	if got := DiffResults(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffResults() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func Test_loadResultSet(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		input   string
+		want    int
+		wantErr error
+	}{
+		{name: "server run", input: `{"id": "r1", "results": [{"scheduler": "fcfs"}, {"scheduler": "rr"}]}`, want: 2},
+		{name: "list", input: `[{"scheduler": "sjf"}]`, want: 1},
+		{name: "single result", input: `{"scheduler": "sjf", "avg_wait": 1}`, want: 1},
+		{name: "not results", input: `{"hello": "world"}`, wantErr: scheduler.ErrInvalidArgs},
+		{name: "not json", input: `fcfs,1,2`, wantErr: scheduler.ErrInvalidArgs},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := loadResultSet(strings.NewReader(tt.input))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("loadResultSet() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Errorf("loadResultSet() returned %d results, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
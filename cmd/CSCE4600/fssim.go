@@ -0,0 +1,447 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/Sha-min/CSCE4600/pkg/render"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region File allocation
+
+// File operations in an allocation trace.
+const (
+	FileCreate = "create"
+	FileGrow   = "grow"
+	FileDelete = "delete"
+)
+
+// pointerSize is the size in bytes of a block number in a FAT entry or index block.
+const pointerSize = 4
+
+var fileAllocations = []string{"contiguous", "linked", "indexed"}
+
+type (
+	// FileOp is one line of an allocation trace. Bytes is the new file's size
+	// for create and the amount appended for grow.
+	FileOp struct {
+		Op    string
+		Name  string
+		Bytes int64
+	}
+	// Disk describes the volume a trace is replayed on.
+	Disk struct {
+		Blocks    int
+		BlockSize int64
+	}
+	// FileAllocResult is the state of the disk after one strategy replayed a trace.
+	// • Failed counts operations that found no room and were skipped
+	// • Relocations counts contiguous files moved to a bigger hole to grow
+	// • Overhead is the blocks spent on the FAT or index blocks rather than data
+	// • InternalFrag is the unused bytes at the end of each file's last block
+	// • ExternalFrag is the share of free space outside the largest free run
+	// • AvgSeek is the average head movement, in blocks, to read a file front to back
+	FileAllocResult struct {
+		Strategy     string
+		Failed       int
+		Relocations  int
+		Files        int
+		DataBlocks   int
+		Overhead     int
+		InternalFrag int64
+		ExternalFrag float64
+		AvgSeek      float64
+		Map          []string
+	}
+	// fsFile is a file's blocks in reading order; index holds the index
+	// blocks of an indexed file.
+	fsFile struct {
+		bytes  int64
+		blocks []int
+		index  []int
+	}
+	fsVolume struct {
+		Disk
+		owner []string
+		files map[string]*fsFile
+	}
+	// fileAllocator adds n data blocks to the end of f, or reports false
+	// and leaves the volume untouched when there is no room.
+	fileAllocator interface {
+		extend(v *fsVolume, name string, f *fsFile, n int) bool
+	}
+	contiguousAllocator struct {
+		relocations int
+	}
+	linkedAllocator  struct{}
+	indexedAllocator struct{}
+)
+
+// SimulateFileAllocation replays ops on an empty disk using strategy.
+func SimulateFileAllocation(strategy string, ops []FileOp, disk Disk) (FileAllocResult, error) {
+	if disk.Blocks <= 0 || disk.BlockSize < pointerSize {
+		return FileAllocResult{}, fmt.Errorf("%w: need a positive block count and blocks of at least %d bytes", scheduler.ErrInvalidArgs, pointerSize)
+	}
+	var a fileAllocator
+	switch strategy {
+	case "contiguous":
+		a = &contiguousAllocator{}
+	case "linked":
+		a = linkedAllocator{}
+	case "indexed":
+		a = indexedAllocator{}
+	default:
+		return FileAllocResult{}, fmt.Errorf("%w: unknown allocation strategy %q", scheduler.ErrInvalidArgs, strategy)
+	}
+
+	v := &fsVolume{Disk: disk, owner: make([]string, disk.Blocks), files: map[string]*fsFile{}}
+	result := FileAllocResult{Strategy: strategy}
+	if strategy == "linked" {
+		// The FAT has an entry per block and lives at the front of the disk.
+		fat := v.blocksFor(int64(disk.Blocks) * pointerSize)
+		if fat >= disk.Blocks {
+			return FileAllocResult{}, fmt.Errorf("%w: the FAT alone fills the disk", scheduler.ErrInvalidArgs)
+		}
+		for b := 0; b < fat; b++ {
+			v.owner[b] = "FAT"
+		}
+		result.Overhead = fat
+	}
+
+	for i, op := range ops {
+		f := v.files[op.Name]
+		switch op.Op {
+		case FileCreate:
+			if f != nil {
+				return FileAllocResult{}, fmt.Errorf("%w: op %d: %s already exists", scheduler.ErrInvalidArgs, i+1, op.Name)
+			}
+			f = &fsFile{}
+			if !a.extend(v, op.Name, f, v.blocksFor(op.Bytes)) {
+				result.Failed++
+				continue
+			}
+			f.bytes = op.Bytes
+			v.files[op.Name] = f
+		case FileGrow:
+			if f == nil {
+				return FileAllocResult{}, fmt.Errorf("%w: op %d: %s does not exist", scheduler.ErrInvalidArgs, i+1, op.Name)
+			}
+			if !a.extend(v, op.Name, f, v.blocksFor(f.bytes+op.Bytes)-len(f.blocks)) {
+				result.Failed++
+				continue
+			}
+			f.bytes += op.Bytes
+		case FileDelete:
+			if f == nil {
+				return FileAllocResult{}, fmt.Errorf("%w: op %d: %s does not exist", scheduler.ErrInvalidArgs, i+1, op.Name)
+			}
+			v.release(append(f.blocks, f.index...))
+			delete(v.files, op.Name)
+		default:
+			return FileAllocResult{}, fmt.Errorf("%w: op %d: unknown file operation %q", scheduler.ErrInvalidArgs, i+1, op.Op)
+		}
+	}
+
+	if c, ok := a.(*contiguousAllocator); ok {
+		result.Relocations = c.relocations
+	}
+	var seek int
+	for _, f := range v.files {
+		result.Files++
+		result.DataBlocks += len(f.blocks)
+		result.Overhead += len(f.index)
+		result.InternalFrag += int64(len(f.blocks))*disk.BlockSize - f.bytes
+		seek += seekDistance(append(append([]int(nil), f.index...), f.blocks...))
+	}
+	if result.Files > 0 {
+		result.AvgSeek = float64(seek) / float64(result.Files)
+	}
+	if free, largest := v.holes(); free > 0 {
+		result.ExternalFrag = 1 - float64(largest)/float64(free)
+	}
+	result.Map = v.owner
+
+	return result, nil
+}
+
+func (v *fsVolume) blocksFor(bytes int64) int {
+	return int((bytes + v.BlockSize - 1) / v.BlockSize)
+}
+
+func (v *fsVolume) take(name string, blocks []int) {
+	for _, b := range blocks {
+		v.owner[b] = name
+	}
+}
+
+func (v *fsVolume) release(blocks []int) {
+	for _, b := range blocks {
+		v.owner[b] = ""
+	}
+}
+
+// freeBlocks returns the first n free blocks, or nil if there are fewer.
+func (v *fsVolume) freeBlocks(n int) []int {
+	blocks := make([]int, 0, n)
+	for b := 0; b < v.Blocks && len(blocks) < n; b++ {
+		if v.owner[b] == "" {
+			blocks = append(blocks, b)
+		}
+	}
+	if len(blocks) < n {
+		return nil
+	}
+	return blocks
+}
+
+// holes returns the total free blocks and the length of the longest free run.
+func (v *fsVolume) holes() (free, largest int) {
+	run := 0
+	for b := 0; b < v.Blocks; b++ {
+		if v.owner[b] != "" {
+			run = 0
+			continue
+		}
+		free++
+		if run++; run > largest {
+			largest = run
+		}
+	}
+	return free, largest
+}
+
+// extend grows the file in place when the blocks after it are free, and
+// otherwise moves it to the first hole big enough for the whole file.
+func (c *contiguousAllocator) extend(v *fsVolume, name string, f *fsFile, n int) bool {
+	if n <= 0 {
+		return true
+	}
+	if len(f.blocks) > 0 {
+		end := f.blocks[len(f.blocks)-1] + 1
+		if end+n <= v.Blocks && v.runFree(end, n) {
+			for b := end; b < end+n; b++ {
+				f.blocks = append(f.blocks, b)
+			}
+			v.take(name, f.blocks)
+			return true
+		}
+	}
+
+	old := f.blocks
+	v.release(old)
+	size := len(old) + n
+	for start := 0; start+size <= v.Blocks; start++ {
+		if !v.runFree(start, size) {
+			continue
+		}
+		f.blocks = make([]int, size)
+		for i := range f.blocks {
+			f.blocks[i] = start + i
+		}
+		v.take(name, f.blocks)
+		if len(old) > 0 {
+			c.relocations++
+		}
+		return true
+	}
+	v.take(name, old)
+	return false
+}
+
+func (v *fsVolume) runFree(start, n int) bool {
+	for b := start; b < start+n; b++ {
+		if v.owner[b] != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// extend chains any free blocks onto the file; the links live in the FAT.
+func (linkedAllocator) extend(v *fsVolume, name string, f *fsFile, n int) bool {
+	if n <= 0 {
+		return true
+	}
+	blocks := v.freeBlocks(n)
+	if blocks == nil {
+		return false
+	}
+	f.blocks = append(f.blocks, blocks...)
+	v.take(name, blocks)
+	return true
+}
+
+// extend takes any free data blocks plus enough index blocks to point at them
+// all; index blocks are chained when one cannot hold every pointer.
+func (indexedAllocator) extend(v *fsVolume, name string, f *fsFile, n int) bool {
+	if n <= 0 {
+		return true
+	}
+	perIndex := int(v.BlockSize / pointerSize)
+	indexes := (len(f.blocks)+n+perIndex-1)/perIndex - len(f.index)
+	blocks := v.freeBlocks(indexes + n)
+	if blocks == nil {
+		return false
+	}
+	f.index = append(f.index, blocks[:indexes]...)
+	f.blocks = append(f.blocks, blocks[indexes:]...)
+	v.take(name, blocks)
+	return true
+}
+
+// seekDistance is how far the head moves, beyond reading straight on, to
+// visit blocks in order. After reading block b the head is at b+1.
+func seekDistance(blocks []int) int {
+	var distance int
+	for i := 1; i < len(blocks); i++ {
+		gap := blocks[i] - (blocks[i-1] + 1)
+		if gap < 0 {
+			gap = -gap
+		}
+		distance += gap
+	}
+	return distance
+}
+
+//endregion
+
+//region fssim command
+
+// runFSSim is the `fssim` subcommand: `fssim [flags] trace.txt`.
+func runFSSim(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("fssim", flag.ContinueOnError)
+	var (
+		disk    Disk
+		algo    = fs.String("algo", strings.Join(fileAllocations, ","), "comma-separated strategies to compare")
+		showMap = fs.Bool("map", false, "print the final block map for each strategy")
+	)
+	fs.IntVar(&disk.Blocks, "blocks", 32, "blocks on the disk")
+	fs.Int64Var(&disk.BlockSize, "block-size", 512, "bytes per block")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: fssim needs an allocation trace file", scheduler.ErrInvalidArgs)
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("%v: error opening allocation trace", err)
+	}
+	defer f.Close()
+
+	ops, err := loadFileOps(f)
+	if err != nil {
+		return err
+	}
+
+	var results []FileAllocResult
+	for _, name := range strings.Split(*algo, ",") {
+		result, err := SimulateFileAllocation(strings.TrimSpace(name), ops, disk)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+	}
+
+	render.Title(w, "File allocation")
+	outputFileAlloc(w, results)
+	if *showMap {
+		for _, r := range results {
+			outputBlockMap(w, r)
+		}
+	}
+
+	return nil
+}
+
+// loadFileOps reads one operation per line: `create name bytes`,
+// `grow name bytes` or `delete name`. Blank lines and # comments are skipped.
+func loadFileOps(r io.Reader) ([]FileOp, error) {
+	var ops []FileOp
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		op := FileOp{Op: strings.ToLower(fields[0])}
+		want := 3
+		if op.Op == FileDelete {
+			want = 2
+		}
+		if len(fields) != want {
+			return nil, fmt.Errorf("%w: line %d: %s takes %d fields, got %d", scheduler.ErrInvalidArgs, line, op.Op, want, len(fields))
+		}
+		op.Name = fields[1]
+		if want == 3 {
+			bytes, err := strconv.ParseInt(fields[2], 10, 64)
+			if err != nil || bytes < 0 {
+				return nil, fmt.Errorf("%w: line %d: bad size %q", scheduler.ErrInvalidArgs, line, fields[2])
+			}
+			op.Bytes = bytes
+		}
+		ops = append(ops, op)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("%w: reading allocation trace", err)
+	}
+
+	return ops, nil
+}
+
+func outputFileAlloc(w io.Writer, results []FileAllocResult) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Strategy", "Files", "Data blocks", "Overhead", "Failed", "Relocations", "Internal frag", "External frag", "Avg seek"})
+	for _, r := range results {
+		table.Append([]string{
+			r.Strategy,
+			fmt.Sprint(r.Files),
+			fmt.Sprint(r.DataBlocks),
+			fmt.Sprint(r.Overhead),
+			fmt.Sprint(r.Failed),
+			fmt.Sprint(r.Relocations),
+			fmt.Sprintf("%d B", r.InternalFrag),
+			fmt.Sprintf("%.0f%%", 100*r.ExternalFrag),
+			fmt.Sprintf("%.2f", r.AvgSeek),
+		})
+	}
+	table.Render()
+	_, _ = fmt.Fprintln(w)
+}
+
+// outputBlockMap prints the owner of every block, eight to a row.
+func outputBlockMap(w io.Writer, r FileAllocResult) {
+	_, _ = fmt.Fprintf(w, "Block map: %s\n", r.Strategy)
+	table := tablewriter.NewWriter(w)
+	const perRow = 8
+	header := []string{"Block"}
+	for i := 0; i < perRow; i++ {
+		header = append(header, fmt.Sprintf("+%d", i))
+	}
+	table.SetHeader(header)
+	for start := 0; start < len(r.Map); start += perRow {
+		row := []string{fmt.Sprint(start)}
+		for b := start; b < start+perRow; b++ {
+			if b < len(r.Map) {
+				row = append(row, r.Map[b])
+			} else {
+				row = append(row, "")
+			}
+		}
+		table.Append(row)
+	}
+	table.Render()
+	_, _ = fmt.Fprintln(w)
+}
+
+//endregion
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestSimulateFileAllocation(t *testing.T) {
+	t.Parallel()
+	ops, err := loadFileOps(strings.NewReader(loadFixture(t, "example_fs.txt")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		strategy string
+		want     FileAllocResult
+	}{
+		{strategy: "contiguous", want: FileAllocResult{Files: 2, DataBlocks: 13, Failed: 2, Relocations: 2}},
+		{strategy: "linked", want: FileAllocResult{Files: 4, DataBlocks: 23, Overhead: 1, Failed: 1}},
+		{strategy: "indexed", want: FileAllocResult{Files: 3, DataBlocks: 15, Overhead: 3, Failed: 2}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.strategy, func(t *testing.T) {
+			t.Parallel()
+			got, err := SimulateFileAllocation(tt.strategy, ops, Disk{Blocks: 24, BlockSize: 512})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Files != tt.want.Files || got.DataBlocks != tt.want.DataBlocks || got.Overhead != tt.want.Overhead ||
+				got.Failed != tt.want.Failed || got.Relocations != tt.want.Relocations {
+				t.Errorf("SimulateFileAllocation() files/data/overhead/failed/relocations = %d/%d/%d/%d/%d, want %d/%d/%d/%d/%d",
+					got.Files, got.DataBlocks, got.Overhead, got.Failed, got.Relocations,
+					tt.want.Files, tt.want.DataBlocks, tt.want.Overhead, tt.want.Failed, tt.want.Relocations)
+			}
+			if tt.strategy == "contiguous" && got.AvgSeek != 0 {
+				t.Errorf("contiguous AvgSeek = %.2f, want 0", got.AvgSeek)
+			}
+		})
+	}
+}
+
+func Test_loadFileOps(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{name: "missing size", in: "create a\n"},
+		{name: "bad size", in: "create a lots\n"},
+		{name: "extra field", in: "delete a 10\n"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := loadFileOps(strings.NewReader(tt.in)); !errors.Is(err, scheduler.ErrInvalidArgs) {
+				t.Errorf("loadFileOps() error = %v, want %v", err, scheduler.ErrInvalidArgs)
+			}
+		})
+	}
+}
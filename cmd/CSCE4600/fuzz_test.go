@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// The loaders all read files from students or uploads to the server, so each
+// must turn any input into either a value or an error without panicking:
+// go test -run '^$' -fuzz FuzzLoadProcesses
+
+func FuzzLoadResultSet(f *testing.F) {
+	for _, seed := range []string{
+		`{"id": "r1", "results": [{"scheduler": "fcfs", "processes": [{"pid": 1}]}]}`,
+		`[{"scheduler": "rr", "quantum": 2}]`,
+		`{"scheduler": "sjf"}`,
+		`{"results": null}`,
+		`[`,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		if results, err := loadResultSet(strings.NewReader(input)); err == nil {
+			_ = DiffResults(results, results)
+		}
+	})
+}
+
+func FuzzLoadBankerState(f *testing.F) {
+	f.Add(loadFixture(f, "example_banker.txt"))
+	f.Add("available 1 2\nmax 1\n")
+	f.Fuzz(func(t *testing.T, input string) {
+		_, _ = loadBankerState(strings.NewReader(input))
+	})
+}
+
+func FuzzLoadFileOps(f *testing.F) {
+	f.Add(loadFixture(f, "example_fs.txt"))
+	f.Add("create a -1\ngrow\n")
+	f.Fuzz(func(t *testing.T, input string) {
+		_, _ = loadFileOps(strings.NewReader(input))
+	})
+}
+
+func FuzzLoadTables(f *testing.F) {
+	f.Add(loadFixture(f, "example_pages.txt"))
+	f.Add(loadFixture(f, "example_segments.txt"))
+	f.Add("0 -\n1 x\n")
+	f.Fuzz(func(t *testing.T, input string) {
+		_, _ = loadPageTable(strings.NewReader(input))
+		_, _ = loadSegmentTable(strings.NewReader(input))
+		_, _ = loadReferences(strings.NewReader(input))
+		_, _ = loadLockEvents(strings.NewReader(input))
+	})
+}
+
+func FuzzParsePipeline(f *testing.F) {
+	for _, seed := range []string{"ls -l | wc -l > out &", `echo "a | b" < in >> out`, "|", "'", "> "} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, line string) {
+		_, _ = ParsePipeline(line)
+	})
+}
+
+func FuzzParseLogicalAddress(f *testing.F) {
+	for _, seed := range []string{"0x1234", "3:100", "4097", "-1", "0x"} {
+		f.Add(seed, uint(10))
+	}
+	f.Fuzz(func(t *testing.T, s string, offsetBits uint) {
+		_, _ = ParseLogicalAddress(s, offsetBits)
+	})
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region generate command
+
+// newGenerateCommand is the `generate` subcommand, which writes a random
+// workload with the spread of the quiz's, the same seed giving the same one:
+// `generate [--processes 5] [--seed 1] [--format csv|json] [--output-file w.csv]`.
+func newGenerateCommand(opts *cliOptions) *cobra.Command {
+	var (
+		n    int
+		seed int64
+	)
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Write a random workload",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) (err error) {
+			if n < 1 {
+				return fmt.Errorf("%w: process count %d must be at least 1", scheduler.ErrInvalidArgs, n)
+			}
+			processes, err := generateWorkload(rand.New(rand.NewSource(seed)), n)
+			if err != nil {
+				return err
+			}
+			w, closeOutput, err := opts.writer(cmd)
+			if err != nil {
+				return err
+			}
+			defer keepFirstError(&err, closeOutput)
+			return loader.Write(w, loader.FileFormat(opts.outputFile, opts.format), processes)
+		},
+	}
+	cmd.Flags().IntVar(&n, "processes", 5, "how many processes to generate")
+	cmd.Flags().Int64Var(&seed, "seed", 1, "seed of the random workload")
+	return cmd
+}
+
+//endregion
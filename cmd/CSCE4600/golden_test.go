@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+// update rewrites the golden files from the current schedulers:
+// go test -run TestGolden -update
+var update = flag.Bool("update", false, "rewrite testdata/golden from the current results")
+
+// TestGolden runs every engine scheduler over each workload in
+// testdata/workloads and compares the results with testdata/golden, so any
+// change in scheduling shows up as a failing workload.
+func TestGolden(t *testing.T) {
+	t.Parallel()
+	workloads, err := filepath.Glob(filepath.Join("testdata", "workloads", "*.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(workloads) == 0 {
+		t.Fatal("no workloads in testdata/workloads")
+	}
+	for _, workload := range workloads {
+		workload := workload
+		name := strings.TrimSuffix(filepath.Base(workload), ".csv")
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			processes, err := loader.LoadCSV(strings.NewReader(loadFixture(t, workload)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			results := make([]scheduler.RunResult, 0, len(scheduler.SchedulerNames()))
+			for _, algorithm := range scheduler.SortedSchedulerNames() {
+				result, err := scheduler.RunScheduler(algorithm, 0, processes)
+				if err != nil {
+					t.Fatal(err)
+				}
+				result.Events = nil
+				results = append(results, result)
+			}
+			got, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			golden := filepath.Join("testdata", "golden", name+".json")
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run go test -run TestGolden -update to create it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				diffs := DiffResults(mustResultSet(t, want), results)
+				t.Errorf("%s no longer matches %s (run with -update if the change is intended):\n%s",
+					workload, golden, formatDiffs(diffs))
+			}
+		})
+	}
+}
+
+func mustResultSet(t *testing.T, data []byte) []scheduler.RunResult {
+	t.Helper()
+	results, err := loadResultSet(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return results
+}
+
+func formatDiffs(diffs []MetricDiff) string {
+	var b strings.Builder
+	outputDiff(&b, diffs, false)
+	return b.String()
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region Autograder
+
+type (
+	// Grader runs a student's scheduler against a directory of workloads and
+	// scores its results against the reference:
+	// • Command is the student program; it gets the workload CSV on stdin,
+	//   SCHED_ALGORITHM and SCHED_QUANTUM in its environment, and must print
+	//   its result as JSON in the same shape as a POST /runs result
+	// • the reference for name.csv is name.json beside it if there is one,
+	//   otherwise the built-in Algorithm run over the workload
+	// • summary averages may be off by Tolerance; everything else must match
+	Grader struct {
+		Command   []string
+		Algorithm string
+		Quantum   int64
+		Tolerance float64
+		Timeout   time.Duration
+	}
+	// GradeReport is the score for every workload, and the overall score out
+	// of 100 weighting workloads equally.
+	GradeReport struct {
+		Algorithm string          `json:"algorithm"`
+		Workloads []WorkloadGrade `json:"workloads"`
+		Score     float64         `json:"score"`
+	}
+	// WorkloadGrade is how one workload went. Err is set when the student
+	// program failed outright, which scores zero.
+	WorkloadGrade struct {
+		Workload string   `json:"workload"`
+		Passed   int      `json:"passed"`
+		Checks   int      `json:"checks"`
+		Score    float64  `json:"score"`
+		Failures []string `json:"failures,omitempty"`
+		Err      string   `json:"error,omitempty"`
+	}
+)
+
+// Grade scores every *.csv workload in dir.
+func (g Grader) Grade(dir string) (GradeReport, error) {
+	if len(g.Command) == 0 {
+		return GradeReport{}, fmt.Errorf("%w: no student program to grade", scheduler.ErrInvalidArgs)
+	}
+	if _, err := scheduler.LookupScheduler(g.Algorithm); err != nil {
+		return GradeReport{}, err
+	}
+	workloads, err := filepath.Glob(filepath.Join(dir, "*.csv"))
+	if err != nil {
+		return GradeReport{}, err
+	}
+	if len(workloads) == 0 {
+		return GradeReport{}, fmt.Errorf("%w: no .csv workloads in %s", scheduler.ErrInvalidArgs, dir)
+	}
+
+	report := GradeReport{Algorithm: g.Algorithm}
+	for _, path := range workloads {
+		grade := g.gradeWorkload(path)
+		report.Score += grade.Score
+		report.Workloads = append(report.Workloads, grade)
+	}
+	report.Score /= float64(len(workloads))
+	return report, nil
+}
+
+func (g Grader) gradeWorkload(path string) WorkloadGrade {
+	grade := WorkloadGrade{Workload: strings.TrimSuffix(filepath.Base(path), ".csv")}
+	fail := func(err error) WorkloadGrade {
+		grade.Err = err.Error()
+		return grade
+	}
+	workload, err := os.ReadFile(path)
+	if err != nil {
+		return fail(err)
+	}
+	want, err := g.reference(path, workload)
+	if err != nil {
+		return fail(fmt.Errorf("reference: %v", err))
+	}
+	got, err := g.runStudent(workload)
+	if err != nil {
+		return fail(err)
+	}
+
+	grade.Passed, grade.Checks, grade.Failures = compareGrade(want, got, g.Tolerance)
+	grade.Score = 100 * float64(grade.Passed) / float64(grade.Checks)
+	return grade
+}
+
+func (g Grader) reference(path string, workload []byte) (scheduler.RunResult, error) {
+	if f, err := os.Open(strings.TrimSuffix(path, ".csv") + ".json"); err == nil {
+		defer f.Close()
+		results, err := loadResultSet(f)
+		if err != nil {
+			return scheduler.RunResult{}, err
+		}
+		for _, r := range results {
+			if r.Scheduler == g.Algorithm {
+				return r, nil
+			}
+		}
+		return scheduler.RunResult{}, fmt.Errorf("%w: no %s result", scheduler.ErrInvalidArgs, g.Algorithm)
+	}
+	processes, err := loader.LoadCSV(bytes.NewReader(workload))
+	if err != nil {
+		return scheduler.RunResult{}, err
+	}
+	return scheduler.RunScheduler(g.Algorithm, g.Quantum, processes)
+}
+
+// studentWaitDelay is how long a student program that timed out has, once
+// killed, to close its output before runStudent stops waiting for it.
+const studentWaitDelay = time.Second
+
+// runStudent runs the student program on one workload and reads its result.
+// On a timeout the program's whole process group is killed, where the OS has
+// them, so a child it left running cannot keep the grader waiting on its
+// output.
+func (g Grader) runStudent(workload []byte) (scheduler.RunResult, error) {
+	timeout := g.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, g.Command[0], g.Command[1:]...)
+	killProcessGroup(cmd)
+	cmd.WaitDelay = studentWaitDelay
+	cmd.Stdin = bytes.NewReader(workload)
+	cmd.Env = append(os.Environ(),
+		"SCHED_ALGORITHM="+g.Algorithm,
+		fmt.Sprintf("SCHED_QUANTUM=%d", g.Quantum))
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return scheduler.RunResult{}, fmt.Errorf("timed out after %v", timeout)
+		}
+		return scheduler.RunResult{}, fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	results, err := loadResultSet(&stdout)
+	if err != nil {
+		return scheduler.RunResult{}, err
+	}
+	if len(results) != 1 {
+		return scheduler.RunResult{}, fmt.Errorf("%w: printed %d results, want 1", scheduler.ErrInvalidArgs, len(results))
+	}
+	return results[0], nil
+}
+
+// compareGrade checks got against want: one check per summary average, one
+// per process metric, and one for the Gantt chart.
+func compareGrade(want, got scheduler.RunResult, tolerance float64) (passed, checks int, failures []string) {
+	got.Scheduler, got.Quantum = want.Scheduler, want.Quantum
+	for _, d := range DiffResults([]scheduler.RunResult{want}, []scheduler.RunResult{got}) {
+		checks++
+		ok := d.Change == DiffSame
+		if !ok && d.Metric != "" && d.Process == summaryLabel {
+			ok = math.Abs(d.A-d.B) <= tolerance
+		}
+		if ok {
+			passed++
+			continue
+		}
+		switch {
+		case d.Metric == "":
+			failures = append(failures, fmt.Sprintf("process %s %s", d.Process, d.Change))
+		case d.Process == summaryLabel:
+			failures = append(failures, fmt.Sprintf("%s = %s, want %s", d.Metric, formatMetric(d.B), formatMetric(d.A)))
+		default:
+			failures = append(failures, fmt.Sprintf("P%s %s = %s, want %s", d.Process, d.Metric, formatMetric(d.B), formatMetric(d.A)))
+		}
+	}
+
+	checks++
+	if reflect.DeepEqual(scheduler.MergeSlices(want.Gantt), scheduler.MergeSlices(got.Gantt)) {
+		passed++
+	} else {
+		failures = append(failures, "Gantt chart differs")
+	}
+	return passed, checks, failures
+}
+
+//endregion
+
+//region grade command
+
+// runGrade is the `grade` subcommand:
+// `grade -algorithm rr [-quantum 2] -workloads hidden/ [-tolerance 0.01] [-json] -- ./student args...`.
+func runGrade(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("grade", flag.ContinueOnError)
+	algorithm := fs.String("algorithm", "", "the algorithm the student implemented: "+strings.Join(scheduler.SortedSchedulerNames(), ", "))
+	quantum := fs.Int64("quantum", scheduler.DefaultQuantum, "round-robin quantum")
+	workloads := fs.String("workloads", "", "directory of hidden .csv workloads, each with an optional .json reference")
+	tolerance := fs.Float64("tolerance", 0.01, "how far summary averages may be from the reference")
+	timeout := fs.Duration("timeout", 10*time.Second, "time limit per workload")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+	if *workloads == "" {
+		return fmt.Errorf("%w: -workloads is required", scheduler.ErrInvalidArgs)
+	}
+
+	report, err := Grader{
+		Command:   fs.Args(),
+		Algorithm: *algorithm,
+		Quantum:   *quantum,
+		Tolerance: *tolerance,
+		Timeout:   *timeout,
+	}.Grade(*workloads)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	outputGradeReport(w, report)
+	return nil
+}
+
+func outputGradeReport(w io.Writer, report GradeReport) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Workload", "Checks", "Score", "Problems"})
+	table.SetAutoWrapText(false)
+	for _, g := range report.Workloads {
+		problems := g.Err
+		if problems == "" {
+			problems = strings.Join(firstN(g.Failures, 3), "; ")
+			if len(g.Failures) > 3 {
+				problems += fmt.Sprintf("; and %d more", len(g.Failures)-3)
+			}
+		}
+		table.Append([]string{g.Workload, fmt.Sprintf("%d/%d", g.Passed, g.Checks), fmt.Sprintf("%.1f", g.Score), problems})
+	}
+	table.SetFooter([]string{"", "", fmt.Sprintf("%.1f", report.Score), report.Algorithm})
+	table.Render()
+}
+
+func firstN(s []string, n int) []string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+//endregion
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package main
+
+import "os/exec"
+
+// killProcessGroup leaves cmd as it is where there are no process groups to
+// kill; its context kills cmd alone and WaitDelay bounds the wait for the
+// rest.
+func killProcessGroup(*exec.Cmd) {}
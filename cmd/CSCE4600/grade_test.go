@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+// TestGradeStudentProcess is not a real test: Grader runs the test binary as
+// a student program that answers every workload with fcfs.
+func TestGradeStudentProcess(t *testing.T) {
+	if os.Getenv("GRADE_STUDENT") == "" {
+		t.Skip("only run as a student program")
+	}
+	processes, err := loader.LoadCSV(os.Stdin)
+	if err != nil {
+		os.Exit(2)
+	}
+	result, _ := scheduler.RunScheduler("fcfs", 0, processes)
+	_ = json.NewEncoder(os.Stdout).Encode(result)
+	os.Exit(0)
+}
+
+func TestGrader_Grade(t *testing.T) {
+	t.Setenv("GRADE_STUDENT", "1")
+	student := []string{os.Args[0], "-test.run=^TestGradeStudentProcess$"}
+
+	tests := []struct {
+		name      string
+		algorithm string
+		wantScore func(float64) bool
+	}{
+		{name: "matches the reference", algorithm: "fcfs", wantScore: func(s float64) bool { return s == 100 }},
+		{name: "wrong algorithm", algorithm: "rr", wantScore: func(s float64) bool { return s < 100 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Grader{Command: student, Algorithm: tt.algorithm, Tolerance: 0.01}.Grade("testdata/workloads")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.wantScore(report.Score) {
+				t.Errorf("score = %.1f, report %+v", report.Score, report.Workloads)
+			}
+			for _, g := range report.Workloads {
+				if g.Err != "" {
+					t.Errorf("%s: %s", g.Workload, g.Err)
+				}
+			}
+		})
+	}
+}
+
+func Test_compareGrade(t *testing.T) {
+	t.Parallel()
+	want := scheduler.RunResult{
+		Scheduler: "rr", AvgWait: 1.5, AvgTurnaround: 4, Throughput: 0.5,
+		Gantt:     []scheduler.TimeSlice{{PID: 1, Start: 0, Stop: 2}, {PID: 1, Start: 2, Stop: 3}, {PID: 2, Start: 3, Stop: 4}},
+		Processes: []scheduler.ProcessMetrics{{PID: 1, Turnaround: 3, Exit: 3}, {PID: 2, Wait: 3, Turnaround: 4, Exit: 4}},
+	}
+	got := want
+	got.Scheduler = "student"
+	got.AvgWait = 1.504
+	got.Gantt = []scheduler.TimeSlice{{PID: 1, Start: 0, Stop: 3}, {PID: 2, Start: 3, Stop: 4}}
+	got.Processes = []scheduler.ProcessMetrics{{PID: 1, Turnaround: 3, Exit: 3}, {PID: 2, Wait: 2, Turnaround: 4, Exit: 4}}
+
+	passed, checks, failures := compareGrade(want, got, 0.01)
+	if checks != 12 || passed != 11 {
+		t.Errorf("compareGrade() passed %d of %d, want 11 of 12", passed, checks)
+	}
+	if len(failures) != 1 || !strings.Contains(failures[0], "P2 wait = 2, want 3") {
+		t.Errorf("compareGrade() failures = %q, want P2's wait", failures)
+	}
+}
@@ -0,0 +1,24 @@
+//go:build unix
+
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGrader_runStudent_timeout(t *testing.T) {
+	t.Parallel()
+	// The background sleep holds the program's stdout open after the shell
+	// is killed, which would keep cmd.Wait waiting for a minute.
+	g := Grader{Command: []string{"sh", "-c", "sleep 60 & sleep 60"}, Algorithm: "fcfs", Timeout: 100 * time.Millisecond}
+	start := time.Now()
+	_, err := g.runStudent([]byte("1,5,0\n"))
+	if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Errorf("runStudent() error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond+studentWaitDelay+time.Second {
+		t.Errorf("runStudent() took %v to give up", elapsed)
+	}
+}
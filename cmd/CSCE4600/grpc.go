@@ -0,0 +1,166 @@
+//go:build grpc
+
+// The gRPC service is built with -tags grpc on the code generated from
+// proto/scheduler.proto into proto/schedulerpb, which is committed and also
+// built only with the tag. Run go generate after editing the .proto.
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=module=github.com/Sha-min/CSCE4600 --go-grpc_out=../.. --go-grpc_opt=module=github.com/Sha-min/CSCE4600 ../../proto/scheduler.proto
+//go:generate sed -i "1i //go:build grpc\\n" ../../proto/schedulerpb/scheduler.pb.go ../../proto/schedulerpb/scheduler_grpc.pb.go
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+	pb "github.com/Sha-min/CSCE4600/proto/schedulerpb"
+)
+
+func init() {
+	commands["grpc"] = runGRPC
+}
+
+//region gRPC service
+
+// schedulingService implements the Scheduling service on top of RunScheduler,
+// the same entry point the HTTP server uses.
+type schedulingService struct {
+	pb.UnimplementedSchedulingServer
+}
+
+func (schedulingService) ListSchedulers(context.Context, *pb.ListSchedulersRequest) (*pb.ListSchedulersResponse, error) {
+	return &pb.ListSchedulersResponse{Schedulers: scheduler.SortedSchedulerNames()}, nil
+}
+
+func (schedulingService) Run(_ context.Context, req *pb.RunRequest) (*pb.RunResponse, error) {
+	processes, err := processesFromProto(req.GetWorkload())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	configs := req.GetSchedulers()
+	if len(configs) == 0 {
+		for _, name := range scheduler.SortedSchedulerNames() {
+			configs = append(configs, &pb.SchedulerConfig{Scheduler: name})
+		}
+	}
+
+	resp := &pb.RunResponse{Results: make([]*pb.Result, 0, len(configs))}
+	for _, cfg := range configs {
+		result, err := scheduler.RunScheduler(cfg.GetScheduler(), cfg.GetQuantum(), processes)
+		if err != nil {
+			return nil, grpcError(err)
+		}
+		resp.Results = append(resp.Results, resultToProto(result))
+	}
+	return resp, nil
+}
+
+func (schedulingService) RunEvents(req *pb.RunEventsRequest, stream pb.Scheduling_RunEventsServer) error {
+	processes, err := processesFromProto(req.GetWorkload())
+	if err != nil {
+		return grpcError(err)
+	}
+	result, err := scheduler.RunScheduler(req.GetScheduler().GetScheduler(), req.GetScheduler().GetQuantum(), processes)
+	if err != nil {
+		return grpcError(err)
+	}
+	for _, ev := range result.Events {
+		if err := stream.Context().Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		if err := stream.Send(&pb.Event{Time: ev.Time, Kind: ev.Kind.String(), Pid: ev.PID, Note: ev.Note}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func processesFromProto(w *pb.Workload) ([]scheduler.Process, error) {
+	if len(w.GetProcesses()) == 0 {
+		return nil, fmt.Errorf("%w: workload has no processes", scheduler.ErrInvalidArgs)
+	}
+	processes := make([]scheduler.Process, len(w.GetProcesses()))
+	for i, p := range w.GetProcesses() {
+		processes[i] = scheduler.Process{
+			ProcessID:     p.GetPid(),
+			ArrivalTime:   p.GetArrival(),
+			BurstDuration: p.GetBurst(),
+			Priority:      p.GetPriority(),
+			Name:          p.GetName(),
+		}
+	}
+	if err := loader.Validate(processes); err != nil {
+		return nil, err
+	}
+	return processes, nil
+}
+
+func resultToProto(r scheduler.RunResult) *pb.Result {
+	out := &pb.Result{
+		Scheduler:     r.Scheduler,
+		Quantum:       r.Quantum,
+		Gantt:         make([]*pb.TimeSlice, len(r.Gantt)),
+		Processes:     make([]*pb.ProcessMetrics, len(r.Processes)),
+		AvgWait:       r.AvgWait,
+		AvgTurnaround: r.AvgTurnaround,
+		Throughput:    r.Throughput,
+	}
+	for i, s := range r.Gantt {
+		out.Gantt[i] = &pb.TimeSlice{Pid: s.PID, Start: s.Start, Stop: s.Stop}
+	}
+	for i, m := range r.Processes {
+		out.Processes[i] = &pb.ProcessMetrics{
+			Pid:        m.PID,
+			Arrival:    m.Arrival,
+			Burst:      m.Burst,
+			Priority:   m.Priority,
+			Response:   m.Response,
+			Wait:       m.Wait,
+			Turnaround: m.Turnaround,
+			Exit:       m.Exit,
+		}
+	}
+	return out
+}
+
+// grpcError maps bad input to InvalidArgument and anything else to Internal.
+func grpcError(err error) error {
+	if errors.Is(err, scheduler.ErrInvalidArgs) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+//endregion
+
+//region grpc command
+
+// runGRPC is the `grpc` subcommand: `grpc -port 9090`.
+func runGRPC(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("grpc", flag.ContinueOnError)
+	port := fs.Int("port", 9090, "TCP port to listen on")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", *port))
+	if err != nil {
+		return err
+	}
+	srv := grpc.NewServer()
+	pb.RegisterSchedulingServer(srv, schedulingService{})
+	_, _ = fmt.Fprintf(w, "gRPC listening on %s\n", lis.Addr())
+
+	return srv.Serve(lis)
+}
+
+//endregion
@@ -0,0 +1,80 @@
+//go:build grpc
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+	pb "github.com/Sha-min/CSCE4600/proto/schedulerpb"
+)
+
+var grpcWorkload = &pb.Workload{Processes: []*pb.Process{
+	{Pid: 1, Arrival: 0, Burst: 5, Priority: 2},
+	{Pid: 2, Arrival: 3, Burst: 9, Priority: 1},
+	{Pid: 3, Arrival: 6, Burst: 6, Priority: 3},
+}}
+
+func TestSchedulingService_Run(t *testing.T) {
+	t.Parallel()
+	resp, err := schedulingService{}.Run(context.Background(), &pb.RunRequest{
+		Workload:   grpcWorkload,
+		Schedulers: []*pb.SchedulerConfig{{Scheduler: "fcfs"}, {Scheduler: "rr", Quantum: 3}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.GetResults()) != 2 || resp.GetResults()[1].GetQuantum() != 3 {
+		t.Fatalf("Run() results = %v, want fcfs then rr with quantum 3", resp.GetResults())
+	}
+	if got := resp.GetResults()[0].GetAvgTurnaround(); got != 10 {
+		t.Errorf("fcfs average turnaround = %v, want 10", got)
+	}
+
+	_, err = schedulingService{}.Run(context.Background(), &pb.RunRequest{
+		Workload:   grpcWorkload,
+		Schedulers: []*pb.SchedulerConfig{{Scheduler: "mlfq"}},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Run() with an unknown scheduler: code %v, want %v", status.Code(err), codes.InvalidArgument)
+	}
+}
+
+// eventStream collects what RunEvents sends.
+type eventStream struct {
+	grpc.ServerStream
+	events []*pb.Event
+}
+
+func (s *eventStream) Send(ev *pb.Event) error {
+	s.events = append(s.events, ev)
+	return nil
+}
+
+func (s *eventStream) Context() context.Context { return context.Background() }
+
+func TestSchedulingService_RunEvents(t *testing.T) {
+	t.Parallel()
+	stream := &eventStream{}
+	err := schedulingService{}.RunEvents(&pb.RunEventsRequest{
+		Workload:  grpcWorkload,
+		Scheduler: &pb.SchedulerConfig{Scheduler: "fcfs"},
+	}, stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var completes int
+	for _, ev := range stream.events {
+		if ev.GetKind() == scheduler.EventComplete.String() {
+			completes++
+		}
+	}
+	if completes != 3 {
+		t.Errorf("RunEvents() streamed %d complete events, want 3", completes)
+	}
+}
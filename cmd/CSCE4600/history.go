@@ -0,0 +1,309 @@
+package main
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region Result store
+
+// sqlDriver is the database/sql driver used for SQLite. It is set by
+// sqlite.go, which is only built with -tags sqlite so the default build
+// needs no cgo or extra modules.
+var sqlDriver string
+
+// ErrNoSQLite is returned by OpenResultStore in builds without SQLite.
+var ErrNoSQLite = errors.New("built without SQLite support; rebuild with -tags sqlite")
+
+const resultSchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	created_at     TEXT    NOT NULL,
+	workload_hash  TEXT    NOT NULL,
+	processes      INTEGER NOT NULL,
+	algorithm      TEXT    NOT NULL,
+	quantum        INTEGER NOT NULL,
+	avg_wait       REAL    NOT NULL,
+	avg_turnaround REAL    NOT NULL,
+	throughput     REAL    NOT NULL,
+	params         TEXT    NOT NULL DEFAULT '{}'
+);
+CREATE INDEX IF NOT EXISTS runs_workload ON runs (workload_hash);
+CREATE TABLE IF NOT EXISTS run_processes (
+	run_id     INTEGER NOT NULL REFERENCES runs (id),
+	pid        INTEGER NOT NULL,
+	arrival    INTEGER NOT NULL,
+	burst      INTEGER NOT NULL,
+	priority   INTEGER NOT NULL,
+	response   INTEGER NOT NULL,
+	wait       INTEGER NOT NULL,
+	turnaround INTEGER NOT NULL,
+	exit       INTEGER NOT NULL
+);`
+
+type (
+	// ResultStore keeps every run in a SQLite database:
+	// • runs           one row per scheduler run with its summary and, in
+	//                  params, the SchedulerParams it ran with as JSON
+	// • run_processes  the per-process statistics of each run
+	ResultStore struct {
+		db *sql.DB
+	}
+	// StoredRun is a run as read back from the store.
+	StoredRun struct {
+		ID           int64
+		CreatedAt    time.Time
+		WorkloadHash string
+		ProcessCount int
+		Params       scheduler.SchedulerParams
+		scheduler.RunResult
+	}
+	// HistoryFilter narrows History; zero fields match everything.
+	HistoryFilter struct {
+		Workload  string
+		Algorithm string
+		Limit     int
+	}
+)
+
+// OpenResultStore opens or creates the database at path.
+func OpenResultStore(path string) (*ResultStore, error) {
+	if sqlDriver == "" {
+		return nil, ErrNoSQLite
+	}
+	db, err := sql.Open(sqlDriver, path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(resultSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("%v: creating tables in %s", err, path)
+	}
+	// Databases written before runs had a params column get one, their old
+	// runs reading back with zero parameters.
+	if _, err := db.Exec(`SELECT params FROM runs LIMIT 0`); err != nil {
+		if _, err := db.Exec(`ALTER TABLE runs ADD COLUMN params TEXT NOT NULL DEFAULT '{}'`); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("%v: adding params to %s", err, path)
+		}
+	}
+	return &ResultStore{db: db}, nil
+}
+
+func (s *ResultStore) Close() error {
+	return s.db.Close()
+}
+
+// Save records one run of processes under params and returns its id.
+func (s *ResultStore) Save(processes []scheduler.Process, params scheduler.SchedulerParams, r scheduler.RunResult) (int64, error) {
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return 0, err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec(`INSERT INTO runs
+		(created_at, workload_hash, processes, algorithm, quantum, avg_wait, avg_turnaround, throughput, params)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		time.Now().UTC().Format(time.RFC3339), workloadHash(processes), len(processes),
+		r.Scheduler, r.Quantum, r.AvgWait, r.AvgTurnaround, r.Throughput, string(encoded))
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	for _, m := range r.Processes {
+		if _, err := tx.Exec(`INSERT INTO run_processes
+			(run_id, pid, arrival, burst, priority, response, wait, turnaround, exit)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			id, m.PID, m.Arrival, m.Burst, m.Priority, m.Response, m.Wait, m.Turnaround, m.Exit); err != nil {
+			return 0, err
+		}
+	}
+
+	return id, tx.Commit()
+}
+
+// History lists stored runs grouped by workload, best average wait first, so
+// runs of the same workload read as a comparison.
+func (s *ResultStore) History(f HistoryFilter) ([]StoredRun, error) {
+	query := `SELECT id, created_at, workload_hash, processes, algorithm, quantum, avg_wait, avg_turnaround, throughput, params
+		FROM runs WHERE 1 = 1`
+	var args []interface{}
+	if f.Workload != "" {
+		query += ` AND workload_hash LIKE ?`
+		args = append(args, f.Workload+"%")
+	}
+	if f.Algorithm != "" {
+		query += ` AND algorithm = ?`
+		args = append(args, f.Algorithm)
+	}
+	query += ` ORDER BY workload_hash, avg_wait, id`
+	if f.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, f.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var runs []StoredRun
+	for rows.Next() {
+		var r StoredRun
+		var created, params string
+		if err := rows.Scan(&r.ID, &created, &r.WorkloadHash, &r.ProcessCount, &r.Scheduler, &r.Quantum,
+			&r.AvgWait, &r.AvgTurnaround, &r.Throughput, &params); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(params), &r.Params); err != nil {
+			return nil, fmt.Errorf("%v: params of run %d", err, r.ID)
+		}
+		r.CreatedAt, _ = time.Parse(time.RFC3339, created)
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
+
+// ProcessStats returns the per-process rows of one stored run.
+func (s *ResultStore) ProcessStats(runID int64) ([]scheduler.ProcessMetrics, error) {
+	rows, err := s.db.Query(`SELECT pid, arrival, burst, priority, response, wait, turnaround, exit
+		FROM run_processes WHERE run_id = ? ORDER BY pid`, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var stats []scheduler.ProcessMetrics
+	for rows.Next() {
+		var m scheduler.ProcessMetrics
+		if err := rows.Scan(&m.PID, &m.Arrival, &m.Burst, &m.Priority, &m.Response, &m.Wait, &m.Turnaround, &m.Exit); err != nil {
+			return nil, err
+		}
+		stats = append(stats, m)
+	}
+	return stats, rows.Err()
+}
+
+// workloadHash identifies a workload by the fields that affect scheduling, so
+// the same CSV uploaded twice lands under the same hash.
+func workloadHash(processes []scheduler.Process) string {
+	sorted := make([]scheduler.Process, len(processes))
+	copy(sorted, processes)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ProcessID < sorted[j].ProcessID })
+
+	h := sha256.New()
+	for _, p := range sorted {
+		_, _ = fmt.Fprintf(h, "%d,%d,%d,%d\n", p.ProcessID, p.ArrivalTime, p.BurstDuration, p.Priority)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// recordRuns saves the results of runs over processes under params.
+func recordRuns(store *ResultStore, processes []scheduler.Process, params scheduler.SchedulerParams, results []scheduler.RunResult) error {
+	for _, result := range results {
+		if _, err := store.Save(processes, params, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//endregion
+
+//region history command
+
+// runHistory is the `history` subcommand:
+// `history -db results.db [-workload hash] [-algorithm rr] [-limit n] [-run id]`.
+func runHistory(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	dbPath := fs.String("db", "results.db", "SQLite database written by --db")
+	workload := fs.String("workload", "", "only runs whose workload hash starts with this")
+	algorithm := fs.String("algorithm", "", "only runs of this algorithm")
+	limit := fs.Int("limit", 0, "at most this many runs")
+	runID := fs.Int64("run", 0, "show the per-process statistics of one run")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+
+	store, err := OpenResultStore(*dbPath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if *runID > 0 {
+		stats, err := store.ProcessStats(*runID)
+		if err != nil {
+			return err
+		}
+		if len(stats) == 0 {
+			return fmt.Errorf("%w: no run %d", scheduler.ErrInvalidArgs, *runID)
+		}
+		outputProcessStats(w, stats)
+		return nil
+	}
+	runs, err := store.History(HistoryFilter{Workload: *workload, Algorithm: *algorithm, Limit: *limit})
+	if err != nil {
+		return err
+	}
+	outputHistory(w, runs)
+	return nil
+}
+
+func outputHistory(w io.Writer, runs []StoredRun) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Run", "When", "Workload", "Procs", "Algorithm", "Quantum", "Avg wait", "Avg turnaround", "Throughput"})
+	for _, r := range runs {
+		quantum := "-"
+		if r.Scheduler == "rr" {
+			quantum = fmt.Sprint(r.Quantum)
+		}
+		table.Append([]string{
+			fmt.Sprint(r.ID),
+			r.CreatedAt.Local().Format("2006-01-02 15:04"),
+			r.WorkloadHash,
+			fmt.Sprint(r.ProcessCount),
+			r.Scheduler,
+			quantum,
+			fmt.Sprintf("%.2f", r.AvgWait),
+			fmt.Sprintf("%.2f", r.AvgTurnaround),
+			fmt.Sprintf("%.2f/t", r.Throughput),
+		})
+	}
+	table.Render()
+}
+
+func outputProcessStats(w io.Writer, stats []scheduler.ProcessMetrics) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"ID", "Priority", "Burst", "Arrival", "Response", "Wait", "Turnaround", "Exit"})
+	for _, m := range stats {
+		row := []int64{m.PID, m.Priority, m.Burst, m.Arrival, m.Response, m.Wait, m.Turnaround, m.Exit}
+		cells := make([]string, len(row))
+		for i, v := range row {
+			cells[i] = fmt.Sprint(v)
+		}
+		table.Append(cells)
+	}
+	table.Render()
+}
+
+//endregion
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func Test_workloadHash(t *testing.T) {
+	t.Parallel()
+	base := []scheduler.Process{
+		{ProcessID: 1, ArrivalTime: 0, BurstDuration: 5, Priority: 2},
+		{ProcessID: 2, ArrivalTime: 3, BurstDuration: 9, Priority: 1},
+	}
+	tests := []struct {
+		name      string
+		processes []scheduler.Process
+		wantSame  bool
+	}{
+		{name: "reordered", processes: []scheduler.Process{base[1], base[0]}, wantSame: true},
+		{name: "name ignored", processes: []scheduler.Process{base[0], {ProcessID: 2, ArrivalTime: 3, BurstDuration: 9, Priority: 1, Name: "io"}}, wantSame: true},
+		{name: "burst changed", processes: []scheduler.Process{base[0], {ProcessID: 2, ArrivalTime: 3, BurstDuration: 8, Priority: 1}}},
+		{name: "process dropped", processes: base[:1]},
+	}
+	want := workloadHash(base)
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := workloadHash(tt.processes); (got == want) != tt.wantSame {
+				t.Errorf("workloadHash() = %s, base %s, want same = %v", got, want, tt.wantSame)
+			}
+		})
+	}
+}
@@ -0,0 +1,562 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/render"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+// commands are the subcommands that parse their own flags, Go style, which
+// newRootCommand hands every argument after the name,
+// e.g. `CSCE4600 pagesim -frames 3 -refs 7,0,1,2`.
+var commands = map[string]func(w io.Writer, args []string) error{
+	"pagesim":      runPageSim,
+	"banker":       runBanker,
+	"deadlock":     runDeadlock,
+	"prodcons":     runProdCons,
+	"philosophers": runPhilosophers,
+	"rw":           runReadersWriters,
+	"fssim":        runFSSim,
+	"shell":        runShell,
+	"translate":    runTranslate,
+	"sync":         runSync,
+	"serve":        runServe,
+	"history":      runHistory,
+	"diff":         runDiff,
+	"grade":        runGrade,
+	"export":       runExport,
+	"quiz":         runQuiz,
+	"batch":        runBatch,
+	"sweep":        runSweep,
+	"timeline":     runTimeline,
+	"rm":           runRateMonotonic,
+	"tui":          runTUI,
+	"stream":       runStream,
+}
+
+func main() {
+	if err := run(os.Stdout, os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run is the whole command line, as newRootCommand reads it. Every problem
+// comes back as an error, closing whatever was opened, so only main exits.
+func run(w io.Writer, args []string) error {
+	root := newRootCommand()
+	root.SetOut(w)
+	root.SetErr(w)
+	// cobra reads os.Args for nil.
+	root.SetArgs(append([]string{}, args...))
+	return root.Execute()
+}
+
+// runDefault is the command line without a subcommand: a scheduling file run
+// through the schedulers the flags pick, e.g. `CSCE4600 -scheduler rr
+// -quantum 4 workload.csv`.
+func runDefault(w io.Writer, args []string) (err error) {
+	fs := flag.NewFlagSet("CSCE4600", flag.ContinueOnError)
+	dbPath := fs.String("db", "", "SQLite database to record every run in")
+	cpuProfile := fs.String("cpuprofile", "", "write a CPU profile to this file")
+	memProfile := fs.String("memprofile", "", "write a heap profile to this file when the run ends")
+	execTrace := fs.String("exectrace", "", "write a Go execution trace of the simulator to this file, for go tool trace")
+	quantum := fs.Int64("quantum", scheduler.DefaultQuantum, "quantum for the schedulers that take one, such as rr")
+	aging := fs.Int64("aging", scheduler.DefaultAgingRate, "ticks of waiting per priority boost for the schedulers that age, such as ppriority")
+	switchCost := fs.Int64("context-switch-cost", 0, "time every scheduler charges per context switch")
+	cpus := fs.Int("cpus", 1, "identical CPUs sharing the ready queue, for the schedulers that can use several")
+	seed := fs.Int64("seed", scheduler.DefaultSeed, "seed of the random draws of the schedulers that draw, such as lottery")
+	latency := fs.Int64("latency", scheduler.DefaultTargetLatency, "target latency of the schedulers that take one, such as cfs")
+	alpha := fs.Float64("alpha", scheduler.DefaultAlpha, "weight of the last burst in the burst predictions of the schedulers that predict, such as sjf-predict")
+	tieBreak := fs.String("tie-break", scheduler.TieFIFO.String(), "how tasks that tie, on arrival or on a key such as burst, are ordered: fifo, arrival or pid")
+	assumeSorted := fs.Bool("assume-sorted", false, "take the workload as sorted by arrival rather than sorting it, failing if it is not")
+	ageEvery := fs.Int64("age-every", 0, "boost every waiting process a step of priority each time this many ticks pass, under priority, mlq and ppriority; 0 leaves priority and mlq unaged and ppriority to -aging")
+	ageCap := fs.Int64("age-cap", 0, "best priority -age-every lifts a process to")
+	inherit := fs.Bool("inherit", false, "priority inheritance for the schedulers that rank by priority, such as priority-preemptive: a process holding a lock runs at the best priority of those blocked on it")
+	mlqConfig := fs.String("mlq-config", "", "JSON file declaring mlq's queues, by default an rr queue for priorities up to 1 over an fcfs one")
+	weightList := fs.String("weights", "", "priority:weight pairs, e.g. 0:3,1:2, scaling the quantum of the schedulers that weigh processes, such as wrr; other priorities weigh 1")
+	niceList := fs.String("nice-weights", "", "nice:weight pairs, e.g. 0:1024,5:512, in place of Linux's weights of those nice values under the schedulers that weigh by nice value, such as cfs")
+	priorityOrder := fs.String("priority-order", scheduler.LowerFirst.String(), "which priority is better where the schedulers that weigh by nice value weigh a process without one by its priority: lower-first or higher-first")
+	carry := fs.String("carry", scheduler.CarryDiscard.String(), "what becomes of the rest of a quantum a process yields, for the schedulers that take one: discard, carry to its next dispatch, or bank up to -bank-cap")
+	bankCap := fs.Int64("bank-cap", 0, "most quantum -carry bank saves up; 0 for twice the quantum")
+	capList := fs.String("caps", "", "group:percent pairs, e.g. batch:40, capping the CPU the processes of each group get in every -cap-period")
+	capPeriod := fs.Int64("cap-period", scheduler.DefaultCapPeriod, "window over which -caps are accounted")
+	format := fs.String("format", "", "workload format, csv or json; by default .json files are JSON and others CSV")
+	schedulerList := fs.String("scheduler", strings.Join(scheduler.SchedulerNames(), ","), "comma-separated schedulers to run")
+	output := fs.String("output", OutputText, "report format: text, expanded for text with each process's runs, preemptions and context switches, or json")
+	outputFile := fs.String("output-file", "", "write the report to this file rather than stdout")
+	metricsCSV := fs.String("metrics-csv", "", "also write every run's per-process metrics and averages to this CSV file")
+	report := fs.String("report", "", "also write an HTML report of every run, with Gantt charts and a comparison, to this file")
+	mermaid := fs.String("mermaid", "", "also write every run's Gantt chart to this file as a Mermaid gantt diagram, for Markdown")
+	chromeTrace := fs.String("trace", "", "also write every run's Gantt chart to this file as a Chrome trace, for chrome://tracing or Perfetto")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+	names, err := parseSchedulers(*schedulerList)
+	if err != nil {
+		return err
+	}
+	ties, err := scheduler.ParseTieBreak(*tieBreak)
+	if err != nil {
+		return err
+	}
+	weights, err := parseWeights(*weightList)
+	if err != nil {
+		return err
+	}
+	agingPolicy, err := parseAgingPolicy(*ageEvery, *ageCap)
+	if err != nil {
+		return err
+	}
+	niceWeights, err := parseNiceWeights(*niceList)
+	if err != nil {
+		return err
+	}
+	order, err := scheduler.ParsePriorityOrder(*priorityOrder)
+	if err != nil {
+		return err
+	}
+	caps, err := parseCaps(*capList)
+	if err != nil {
+		return err
+	}
+	carryPolicy, err := scheduler.ParseCarryPolicy(*carry)
+	if err != nil {
+		return err
+	}
+	var mlq *scheduler.MLQConfig
+	if *mlqConfig != "" {
+		if mlq, err = loader.LoadMLQConfigFile(*mlqConfig); err != nil {
+			return err
+		}
+	}
+	if *output != OutputText && *output != OutputExpanded && *output != OutputJSON {
+		return fmt.Errorf("%w: unknown output format %q, want %s, %s or %s", scheduler.ErrInvalidArgs, *output, OutputText, OutputExpanded, OutputJSON)
+	}
+	switch {
+	case *aging < 1:
+		return fmt.Errorf("%w: aging rate %d must be at least 1", scheduler.ErrInvalidArgs, *aging)
+	case *switchCost < 0:
+		return fmt.Errorf("%w: context switch cost %d must not be negative", scheduler.ErrInvalidArgs, *switchCost)
+	case *latency < 1:
+		return fmt.Errorf("%w: target latency %d must be at least 1", scheduler.ErrInvalidArgs, *latency)
+	case *cpus < 1:
+		return fmt.Errorf("%w: CPU count %d must be at least 1", scheduler.ErrInvalidArgs, *cpus)
+	case *alpha <= 0 || *alpha > 1:
+		return fmt.Errorf("%w: alpha %g must be above 0 and at most 1", scheduler.ErrInvalidArgs, *alpha)
+	case *capPeriod < 1:
+		return fmt.Errorf("%w: cap period %d must be at least 1", scheduler.ErrInvalidArgs, *capPeriod)
+	case *bankCap < 0:
+		return fmt.Errorf("%w: bank cap %d must not be negative", scheduler.ErrInvalidArgs, *bankCap)
+	case *bankCap > 0 && carryPolicy != scheduler.CarryBank:
+		return fmt.Errorf("%w: -bank-cap needs -carry bank", scheduler.ErrInvalidArgs)
+	}
+	switch {
+	case fs.NArg() == 0:
+		return fmt.Errorf("%w: must give a scheduling file to process", scheduler.ErrInvalidArgs)
+	case fs.NArg() > 1:
+		return fmt.Errorf("%w: give one scheduling file, or run several with batch, e.g. batch %s", scheduler.ErrInvalidArgs, strings.Join(fs.Args(), " "))
+	}
+	stopProfiles, err := Profiles{CPU: *cpuProfile, Memory: *memProfile, Trace: *execTrace}.Start()
+	if err != nil {
+		return err
+	}
+	defer keepFirstError(&err, stopProfiles)
+
+	// Load and parse processes
+	processes, err := loader.LoadFile(fs.Arg(0), *format)
+	if err != nil {
+		return err
+	}
+	warning, err := scheduler.CheckQuantum(*quantum, processes)
+	if err != nil {
+		return err
+	}
+	for priority, weight := range weights {
+		if weight > math.MaxInt64 / *quantum {
+			return fmt.Errorf("%w: weight %d of priority %d overflows quantum %d", scheduler.ErrInvalidArgs, weight, priority, *quantum)
+		}
+	}
+	for _, name := range names {
+		if info, _ := scheduler.LookupScheduler(name); warning != "" && info.Quantum {
+			log.Print("warning: ", warning)
+			break
+		}
+	}
+	if *cpus > 1 && !flagGiven(fs, "scheduler") {
+		names = multiCPUSchedulers(names)
+	}
+	params := scheduler.SchedulerParams{Quantum: *quantum, Aging: *aging, SwitchCost: *switchCost, CPUs: *cpus, Seed: *seed, Latency: *latency, Weights: weights, MLQ: mlq, Alpha: *alpha, TieBreak: ties, AssumeSorted: *assumeSorted, Inherit: *inherit, AgingPolicy: agingPolicy,
+		NiceWeights: niceWeights, PriorityOrder: order, Caps: caps, CapPeriod: *capPeriod,
+		Carry: carryPolicy, BankCap: *bankCap}
+
+	var store *ResultStore
+	if *dbPath != "" {
+		if store, err = OpenResultStore(*dbPath); err != nil {
+			return err
+		}
+		defer keepFirstError(&err, store.Close)
+	}
+	out := w
+	if *outputFile != "" {
+		var f *os.File
+		if f, err = os.Create(*outputFile); err != nil {
+			return err
+		}
+		defer keepFirstError(&err, f.Close)
+		out = f
+	}
+	results, err := runSchedulers(names, params, processes)
+	if err != nil {
+		return err
+	}
+	if err := writeReport(out, *output, results); err != nil {
+		return err
+	}
+	for _, save := range []struct {
+		path  string
+		write func(io.Writer, []scheduler.RunResult) error
+	}{
+		{*metricsCSV, render.MetricsCSV},
+		{*report, render.HTMLReport},
+		{*mermaid, render.MermaidGantt},
+		{*chromeTrace, render.ChromeTrace},
+	} {
+		if save.path == "" {
+			continue
+		}
+		if err := saveResults(save.path, results, save.write); err != nil {
+			return err
+		}
+	}
+	if store != nil {
+		return recordRuns(store, processes, params, results)
+	}
+	return nil
+}
+
+// keepFirstError calls release, as a deferred cleanup, and stores its error
+// in *err unless an earlier one is already there.
+func keepFirstError(err *error, release func() error) {
+	if rerr := release(); *err == nil {
+		*err = rerr
+	}
+}
+
+// flagGiven reports whether the named flag was set on the command line.
+func flagGiven(fs *flag.FlagSet, name string) bool {
+	given := false
+	fs.Visit(func(f *flag.Flag) { given = given || f.Name == name })
+	return given
+}
+
+// parseSchedulers splits a comma-separated list of scheduler names, as the
+// commands' flags take them, checking that each is registered.
+func parseSchedulers(list string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if _, err := scheduler.LookupScheduler(name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// multiCPUSchedulers are those of names that can run on several CPUs.
+func multiCPUSchedulers(names []string) []string {
+	var multi []string
+	for _, name := range names {
+		if info, _ := scheduler.LookupScheduler(name); info.MultiCPU {
+			multi = append(multi, name)
+		}
+	}
+	return multi
+}
+
+// parseAgingPolicy is the AgingPolicy of -age-every and -age-cap, or nil if
+// every is 0, as it must be when the cap is.
+func parseAgingPolicy(every, limit int64) (*scheduler.AgingPolicy, error) {
+	switch {
+	case every < 0:
+		return nil, fmt.Errorf("%w: aging every %d ticks must not be negative", scheduler.ErrInvalidArgs, every)
+	case every == 0 && limit != 0:
+		return nil, fmt.Errorf("%w: an aging cap needs an aging rate, e.g. -age-every 5", scheduler.ErrInvalidArgs)
+	case every == 0:
+		return nil, nil
+	case limit < scheduler.MinPriority || limit > scheduler.MaxPriority:
+		return nil, fmt.Errorf("%w: aging cap %d is not within %d to %d", scheduler.ErrInvalidArgs, limit, scheduler.MinPriority, scheduler.MaxPriority)
+	}
+	return &scheduler.AgingPolicy{Every: every, Cap: limit}, nil
+}
+
+// parseWeights reads a -weights list of priority:weight pairs, each weight
+// at least 1. An empty list maps nothing.
+func parseWeights(list string) (map[int64]int64, error) {
+	return parseWeightsBy(list, "priority")
+}
+
+// parseNiceWeights reads a -nice-weights list of nice:weight pairs as
+// parseWeights reads priorities; a run checks the nice values' range.
+func parseNiceWeights(list string) (map[int64]int64, error) {
+	return parseWeightsBy(list, "nice")
+}
+
+// parseWeightsBy reads a list of key:weight pairs, key naming what the
+// weights are of.
+func parseWeightsBy(list, key string) (map[int64]int64, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+	weights := make(map[int64]int64)
+	for _, pair := range strings.Split(list, ",") {
+		k, weight, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: weight %q is not %s:weight", scheduler.ErrInvalidArgs, pair, key)
+		}
+		p, err := strconv.ParseInt(k, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: weight %q: %s %q is not an integer", scheduler.ErrInvalidArgs, pair, key, k)
+		}
+		w, err := strconv.ParseInt(weight, 10, 64)
+		if err != nil || w < 1 {
+			return nil, fmt.Errorf("%w: weight %q: %q is not a positive integer", scheduler.ErrInvalidArgs, pair, weight)
+		}
+		if _, ok := weights[p]; ok {
+			return nil, fmt.Errorf("%w: %s %d weighed twice", scheduler.ErrInvalidArgs, key, p)
+		}
+		weights[p] = w
+	}
+	return weights, nil
+}
+
+// parseCaps reads a list of group:percent pairs, e.g. batch:40,io:25%.
+func parseCaps(list string) (map[string]int64, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+	caps := make(map[string]int64)
+	for _, pair := range strings.Split(list, ",") {
+		group, pct, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || group == "" {
+			return nil, fmt.Errorf("%w: cap %q is not group:percent", scheduler.ErrInvalidArgs, pair)
+		}
+		p, err := strconv.ParseInt(strings.TrimSuffix(pct, "%"), 10, 64)
+		if err != nil || p < 1 || p > 100 {
+			return nil, fmt.Errorf("%w: cap %q: %q is not a percentage from 1 to 100", scheduler.ErrInvalidArgs, pair, pct)
+		}
+		if _, ok := caps[group]; ok {
+			return nil, fmt.Errorf("%w: group %s capped twice", scheduler.ErrInvalidArgs, group)
+		}
+		caps[group] = p
+	}
+	return caps, nil
+}
+
+//region Schedulers
+
+// FCFSSchedule outputs a schedule of processes in a GANTT chart and a table of timing given:
+// • an output writer
+// • a title for the chart
+// • a slice of processes
+func FCFSSchedule(w io.Writer, title string, processes []scheduler.Process) {
+	render.Result(w, title, scheduler.RunFCFS(processes))
+}
+
+// SRTFSchedule outputs RunSRTF's schedule of processes.
+func SRTFSchedule(w io.Writer, title string, processes []scheduler.Process) {
+	render.Result(w, title, scheduler.RunSRTF(processes))
+}
+
+// PriorityNonPreemptiveSchedule outputs RunPriority's schedule of processes.
+func PriorityNonPreemptiveSchedule(w io.Writer, title string, processes []scheduler.Process) {
+	render.Result(w, title, scheduler.RunPriority(processes))
+}
+
+// SJFPrioritySchedule is PriorityNonPreemptiveSchedule by the name it first
+// had, though it never ordered by burst.
+//
+// Deprecated: use PriorityNonPreemptiveSchedule.
+func SJFPrioritySchedule(w io.Writer, title string, processes []scheduler.Process) {
+	PriorityNonPreemptiveSchedule(w, title, processes)
+}
+
+// PriorityPreemptiveSchedule outputs RunPriorityPreemptive's schedule of
+// processes.
+func PriorityPreemptiveSchedule(w io.Writer, title string, processes []scheduler.Process) {
+	render.Result(w, title, scheduler.RunPriorityPreemptive(processes))
+}
+
+// PreemptivePrioritySchedule outputs RunPreemptivePriority's schedule of
+// processes, with the aging rate used in the title.
+func PreemptivePrioritySchedule(w io.Writer, title string, processes []scheduler.Process, aging int64) {
+	result := scheduler.RunPreemptivePriority(processes, aging)
+	render.Result(w, fmt.Sprintf("%s (aging every %d)", title, result.Aging), result)
+}
+
+// SJFSchedule outputs RunSJF's schedule of processes.
+func SJFSchedule(w io.Writer, title string, processes []scheduler.Process) {
+	render.Result(w, title, scheduler.RunSJF(processes))
+}
+
+// RRSchedule outputs RunRR's schedule of processes, with the quantum used,
+// scheduler.DefaultQuantum if quantum is below 1, in the title.
+func RRSchedule(w io.Writer, title string, processes []scheduler.Process, quantum int) {
+	result := scheduler.RunRR(processes, int64(quantum))
+	render.Result(w, fmt.Sprintf("%s (quantum %d)", title, result.Quantum), result)
+}
+
+// CooperativeSchedule outputs RunCooperative's schedule of processes.
+func CooperativeSchedule(w io.Writer, title string, processes []scheduler.Process) {
+	render.Result(w, title, scheduler.RunCooperative(processes))
+}
+
+// runSchedulers runs each named scheduler over processes, once, for the
+// report and every file written from the same run to share.
+func runSchedulers(names []string, params scheduler.SchedulerParams, processes []scheduler.Process) ([]scheduler.RunResult, error) {
+	results := make([]scheduler.RunResult, 0, len(names))
+	for _, name := range names {
+		result, err := scheduler.RunSchedulerParams(name, params, processes)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// saveResults writes results to the file at path with write, one of the
+// render package's formats for a whole run such as render.MetricsCSV.
+func saveResults(path string, results []scheduler.RunResult, write func(io.Writer, []scheduler.RunResult) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f, results); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// writeReport writes the reports of results in the given output format:
+// the printed tables for text, or for json an array of the full results,
+// Gantt charts, per-process metrics and averages included, for scripts to
+// read.
+func writeReport(w io.Writer, format string, results []scheduler.RunResult) error {
+	switch format {
+	case OutputText, OutputExpanded:
+		for _, result := range results {
+			if err := printResult(w, result, format == OutputExpanded); err != nil {
+				return err
+			}
+		}
+		return nil
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+	return fmt.Errorf("%w: unknown output format %q, want %s, %s or %s", scheduler.ErrInvalidArgs, format, OutputText, OutputExpanded, OutputJSON)
+}
+
+// printSchedule prints the report of the named registered scheduler's run.
+// If expanded, the schedule table shows each process's runs.
+func printSchedule(w io.Writer, name string, params scheduler.SchedulerParams, processes []scheduler.Process, expanded bool) error {
+	result, err := scheduler.RunSchedulerParams(name, params, processes)
+	if err != nil {
+		return err
+	}
+	return printResult(w, result, expanded)
+}
+
+// printResult prints the report of a registered scheduler's run, titled as
+// the scheduler was registered along with the tunables it takes and any
+// parameters that differ from their defaults.
+func printResult(w io.Writer, result scheduler.RunResult, expanded bool) error {
+	info, err := scheduler.LookupScheduler(result.Scheduler)
+	if err != nil {
+		return err
+	}
+	title := info.Title
+	if info.Quantum {
+		title = fmt.Sprintf("%s (quantum %d)", title, result.Quantum)
+	}
+	switch result.Carry {
+	case scheduler.CarryNext:
+		title += " (carry yielded quanta)"
+	case scheduler.CarryBank:
+		title = fmt.Sprintf("%s (bank yielded quanta up to %d)", title, result.BankCap)
+	}
+	switch {
+	case result.AgingCap != 0:
+		title = fmt.Sprintf("%s (aging every %d up to priority %d)", title, result.Aging, result.AgingCap)
+	case info.Aging || result.Aging > 0:
+		title = fmt.Sprintf("%s (aging every %d)", title, result.Aging)
+	}
+	if info.Seed {
+		title = fmt.Sprintf("%s (seed %d)", title, result.Seed)
+	}
+	if info.Latency {
+		title = fmt.Sprintf("%s (target latency %d)", title, result.Latency)
+	}
+	if info.Alpha {
+		title = fmt.Sprintf("%s (alpha %g)", title, result.Alpha)
+	}
+	if result.SwitchCost > 0 {
+		title = fmt.Sprintf("%s (switch cost %d)", title, result.SwitchCost)
+	}
+	if result.Inherit {
+		title += " (priority inheritance)"
+	}
+	if result.PriorityOrder != scheduler.LowerFirst {
+		title = fmt.Sprintf("%s (%s priority)", title, result.PriorityOrder)
+	}
+	if len(result.NiceWeights) > 0 {
+		title += " (custom nice weights)"
+	}
+	if len(result.Caps) > 0 {
+		groups := make([]string, 0, len(result.Caps))
+		for group := range result.Caps {
+			groups = append(groups, group)
+		}
+		sort.Strings(groups)
+		for i, group := range groups {
+			groups[i] = fmt.Sprintf("%s %d%%", group, result.Caps[group])
+		}
+		title = fmt.Sprintf("%s (caps %s every %d)", title, strings.Join(groups, ", "), result.CapPeriod)
+	}
+	if len(result.CPUs) > 1 {
+		title = fmt.Sprintf("%s (%d CPUs)", title, len(result.CPUs))
+	}
+	render.Run(w, title, result, expanded)
+	return nil
+}
+
+//endregion
+
+//region Output formats
+
+// Report formats of the main command's -output flag.
+const (
+	OutputText     = "text"
+	OutputExpanded = "expanded"
+	OutputJSON     = "json"
+)
+
+//endregion
@@ -0,0 +1,906 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/render"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestFCFSSchedule(t *testing.T) {
+	t.Parallel()
+	type args struct {
+		processes []scheduler.Process
+		title     string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantOut string
+	}{
+		{
+			name: "default",
+			args: args{
+				processes: []scheduler.Process{
+					{
+						ProcessID:     1,
+						ArrivalTime:   0,
+						BurstDuration: 5,
+						Priority:      2,
+					},
+					{
+						ProcessID:     2,
+						ArrivalTime:   3,
+						BurstDuration: 9,
+						Priority:      1,
+					},
+					{
+						ProcessID:     3,
+						ArrivalTime:   6,
+						BurstDuration: 6,
+						Priority:      3,
+					},
+				},
+				title: "First-come, First-serve",
+			},
+			wantOut: loadFixture(t, "fcfs_test.txt"),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var w bytes.Buffer
+			FCFSSchedule(&w, tt.args.title, tt.args.processes)
+			if got := w.String(); got != tt.wantOut {
+				t.Errorf("FCFSSchedule() = %v, want %v", got, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestFCFSSchedule_unsorted(t *testing.T) {
+	t.Parallel()
+	// Rows used to run in file order, so a late row listed first made the
+	// others wait a negative time. Ties keep their order in the file.
+	processes, err := loader.LoadCSV(strings.NewReader("3,6,6\n1,5,0\n4,2,6\n2,9,3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := append([]scheduler.Process(nil), processes...)
+	out := &bytes.Buffer{}
+	FCFSSchedule(out, "FCFS", processes)
+	if !reflect.DeepEqual(processes, before) {
+		t.Errorf("FCFSSchedule() changed its input to %+v", processes)
+	}
+	if want := "|  1  |  2  |  3  |  4  |\n0     5     14    20    22"; !strings.Contains(out.String(), want) {
+		t.Errorf("output has no Gantt chart %q:\n%s", want, out)
+	}
+	if strings.Contains(out.String(), "| -") {
+		t.Errorf("output has a negative time:\n%s", out)
+	}
+
+	// The engine's fcfs queue makes the same choices, so its rendering is
+	// the expected output.
+	engine := scheduler.Engine{Queue: &scheduler.FIFOQueue{}}
+	want := &bytes.Buffer{}
+	render.Result(want, "FCFS", engine.Schedule(processes))
+	if out.String() != want.String() {
+		t.Errorf("FCFSSchedule() =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestRRSchedule(t *testing.T) {
+	t.Parallel()
+	processes := []scheduler.Process{
+		{ProcessID: 1, ArrivalTime: 0, BurstDuration: 5, Priority: 2},
+		{ProcessID: 2, ArrivalTime: 3, BurstDuration: 9, Priority: 1},
+		{ProcessID: 3, ArrivalTime: 6, BurstDuration: 6, Priority: 3},
+	}
+	var w bytes.Buffer
+	RRSchedule(&w, "Round-robin", processes, 4)
+	if got, want := w.String(), loadFixture(t, "rr_test.txt"); got != want {
+		t.Errorf("RRSchedule() = %v, want %v", got, want)
+	}
+}
+
+func loadFixture(t testing.TB, p ...string) string {
+	b, err := os.ReadFile(path.Join(p...))
+	if err != nil {
+		t.Fail()
+	}
+
+	return string(b)
+}
+
+func TestSJFSchedule(t *testing.T) {
+	t.Parallel()
+	// Without yields the engine's sjf queue makes the same choices, ties
+	// included, so its rendering is the expected output.
+	for _, name := range []string{"basic", "idle", "mixed", "ties"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			processes := mustLoadProcesses(t, "testdata/workloads/"+name+".csv")
+			sjf, _ := scheduler.LookupScheduler("sjf")
+			want := &bytes.Buffer{}
+			render.Result(want, "SJF", sjf.New(scheduler.SchedulerParams{}).Schedule(processes))
+
+			got := &bytes.Buffer{}
+			SJFSchedule(got, "SJF", processes)
+			if got.String() != want.String() {
+				t.Errorf("SJFSchedule() =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestClassicSchedulers_textbook(t *testing.T) {
+	t.Parallel()
+	// The worked examples of Silberschatz, Galvin and Gagne's Operating
+	// System Concepts, every process arriving at 0.
+	convoy := []scheduler.Process{
+		{ProcessID: 1, BurstDuration: 24},
+		{ProcessID: 2, BurstDuration: 3},
+		{ProcessID: 3, BurstDuration: 3},
+	}
+	tests := []struct {
+		name      string
+		run       func([]scheduler.Process) scheduler.RunResult
+		processes []scheduler.Process
+		wantWaits []int64
+		wantAvg   float64
+	}{
+		{name: "fcfs", run: scheduler.RunFCFS, processes: convoy, wantWaits: []int64{0, 24, 27}, wantAvg: 17},
+		{
+			name: "sjf",
+			run:  scheduler.RunSJF,
+			processes: []scheduler.Process{
+				{ProcessID: 1, BurstDuration: 6},
+				{ProcessID: 2, BurstDuration: 8},
+				{ProcessID: 3, BurstDuration: 7},
+				{ProcessID: 4, BurstDuration: 3},
+			},
+			wantWaits: []int64{3, 16, 9, 0},
+			wantAvg:   7,
+		},
+		{
+			name: "priority",
+			run:  scheduler.RunPriority,
+			processes: []scheduler.Process{
+				{ProcessID: 1, BurstDuration: 10, Priority: 3},
+				{ProcessID: 2, BurstDuration: 1, Priority: 1},
+				{ProcessID: 3, BurstDuration: 2, Priority: 4},
+				{ProcessID: 4, BurstDuration: 1, Priority: 5},
+				{ProcessID: 5, BurstDuration: 5, Priority: 2},
+			},
+			wantWaits: []int64{6, 0, 16, 18, 1},
+			wantAvg:   8.2,
+		},
+		{
+			name:      "rr",
+			run:       func(p []scheduler.Process) scheduler.RunResult { return scheduler.RunRR(p, 4) },
+			processes: convoy,
+			wantWaits: []int64{6, 4, 7},
+			wantAvg:   17.0 / 3,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result := tt.run(tt.processes)
+			var waits []int64
+			for _, m := range result.Processes {
+				waits = append(waits, m.Wait)
+			}
+			if !reflect.DeepEqual(waits, tt.wantWaits) {
+				t.Errorf("waits = %v, want %v", waits, tt.wantWaits)
+			}
+			if math.Abs(result.AvgWait-tt.wantAvg) > 1e-9 {
+				t.Errorf("AvgWait = %v, want %v", result.AvgWait, tt.wantAvg)
+			}
+		})
+	}
+}
+
+func TestSRTFSchedule(t *testing.T) {
+	t.Parallel()
+	// P2 and P3 each preempt P1 on arrival. P4 ties with what P3 has left
+	// and waits, and P6 goes ahead of P5 and P1 since it has the least to run.
+	processes := mustLoadProcesses(t, "testdata/workloads/mixed.csv")
+	out := &bytes.Buffer{}
+	SRTFSchedule(out, "SRTF", processes)
+	if want := "|  1  |  2  |  3  |  4  |  6  |  5  |  1  |\n0     1     2     4     5     8     13    22"; !strings.Contains(out.String(), want) {
+		t.Errorf("output has no Gantt chart %q:\n%s", want, out)
+	}
+	if want := "|  1 |        5 |    10 |       0 |        0 |      12 |         22 |         22 |"; !strings.Contains(out.String(), want) {
+		t.Errorf("output has no row %q:\n%s", want, out)
+	}
+}
+
+func TestPreemptivePrioritySchedule(t *testing.T) {
+	t.Parallel()
+	// Arrivals with better priorities preempt P1, P3 and P5, then every 3
+	// ticks of waiting boosts them: P5 overtakes P3 at 8, and P3 catches up
+	// with P1 at 12 and preempts it at 15 by reaching priority 0.
+	processes := mustLoadProcesses(t, "testdata/workloads/mixed.csv")
+	out := &bytes.Buffer{}
+	PreemptivePrioritySchedule(out, "Preemptive priority", processes, 3)
+	for _, want := range []string{
+		"Preemptive priority (aging every 3)",
+		"|  1  |  2  |  3  |  4  |  5  |  6  |  5  |  1  |  3  |  1  |\n0     1     2     3     4     5     8     12    15    16    22",
+		"|  1 |        5 |      4 |",
+		"|  3 |        4 |      4 |",
+		"|  5 |        3 |      1 |",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output has no %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunLottery(t *testing.T) {
+	t.Parallel()
+	// P1 at priority 0 holds 100 tickets and P2 at priority 3 holds 25, so
+	// over many one-tick draws P1 should win about 80% of them.
+	processes := []scheduler.Process{
+		{ProcessID: 1, ArrivalTime: 0, BurstDuration: 5000, Priority: 0},
+		{ProcessID: 2, ArrivalTime: 0, BurstDuration: 5000, Priority: 3},
+	}
+	result := scheduler.RunLottery(processes, 1, 7)
+	if result.Seed != 7 {
+		t.Errorf("Seed = %d, want 7", result.Seed)
+	}
+	// Once P1 is done P2 wins every draw, so look at P1's draws alone.
+	p1 := result.Processes[0]
+	if p1.Tickets != 100 || result.Processes[1].Tickets != 25 {
+		t.Errorf("tickets = %d and %d, want 100 and 25", p1.Tickets, result.Processes[1].Tickets)
+	}
+	if math.Abs(p1.TicketShare-0.8) > 1e-9 {
+		t.Errorf("P1 ticket share = %v, want 0.8", p1.TicketShare)
+	}
+	if math.Abs(p1.CPUShare-p1.TicketShare) > 0.02 {
+		t.Errorf("P1 CPU share = %v, want within 0.02 of its ticket share %v", p1.CPUShare, p1.TicketShare)
+	}
+
+	if again := scheduler.RunLottery(processes, 1, 7); !reflect.DeepEqual(again.Gantt, result.Gantt) {
+		t.Error("the same seed gave a different run")
+	}
+	if other := scheduler.RunLottery(processes, 1, 8); reflect.DeepEqual(other.Gantt, result.Gantt) {
+		t.Error("another seed gave the same run")
+	}
+}
+
+func TestRunStride(t *testing.T) {
+	t.Parallel()
+	// P1 holds 100 tickets and P2 25, so P1 takes exactly four quanta of
+	// every five while both are running.
+	processes := []scheduler.Process{
+		{ProcessID: 1, ArrivalTime: 0, BurstDuration: 8, Priority: 0},
+		{ProcessID: 2, ArrivalTime: 0, BurstDuration: 8, Priority: 3},
+	}
+	result := scheduler.RunStride(processes, 1)
+	var p1 int
+	for _, s := range result.Gantt[:10] {
+		if s.PID == 1 {
+			p1++
+		}
+	}
+	if p1 != 8 {
+		t.Errorf("P1 ran %d of the first 10 quanta, want 8: %v", p1, result.Gantt)
+	}
+	if m := result.Processes[0]; m.CPUShare != 0.8 || math.Abs(m.TicketShare-0.8) > 1e-9 {
+		t.Errorf("P1 CPU share %v, ticket share %v, want 0.8 and 0.8", m.CPUShare, m.TicketShare)
+	}
+
+	// A process arriving late starts level with the others rather than
+	// taking the CPU until its pass catches up.
+	late := append(processes, scheduler.Process{ProcessID: 3, ArrivalTime: 6, BurstDuration: 4, Priority: 0})
+	result = scheduler.RunStride(late, 1)
+	if m := result.Processes[2]; m.Exit-m.Arrival < 6 {
+		t.Errorf("late P3 ran straight through, exiting at %d", m.Exit)
+	}
+}
+
+func TestRunCFS(t *testing.T) {
+	t.Parallel()
+	// P1 at nice 0 weighs 1024 and P2 at nice 5 weighs 335, so P1 gets
+	// about three quarters of the CPU while both run.
+	processes := []scheduler.Process{
+		{ProcessID: 1, ArrivalTime: 0, BurstDuration: 100, Priority: 0},
+		{ProcessID: 2, ArrivalTime: 0, BurstDuration: 100, Priority: 5},
+	}
+	result := scheduler.RunCFS(processes, 12)
+	if result.Latency != 12 {
+		t.Errorf("Latency = %d, want 12", result.Latency)
+	}
+	p1 := result.Processes[0]
+	if share := 100 / float64(p1.Exit); math.Abs(share-1024.0/1359) > 0.03 {
+		t.Errorf("P1 had %.3f of the CPU until it exited at %d, want about %.3f", share, p1.Exit, 1024.0/1359)
+	}
+	if p1.VRuntime != 100 {
+		t.Errorf("P1 vruntime = %v, want 100, its CPU time at nice 0", p1.VRuntime)
+	}
+	// The least virtual runtime always runs next, so it never goes back.
+	for i := 1; i < len(result.VRuntimes); i++ {
+		if prev, s := result.VRuntimes[i-1], result.VRuntimes[i]; s.VRuntime < prev.VRuntime {
+			t.Errorf("dispatched P%d at %v after P%d at %v", s.PID, s.VRuntime, prev.PID, prev.VRuntime)
+		}
+	}
+	if s := result.Gantt[0]; s.Stop-s.Start != 12*1024/1359 {
+		t.Errorf("P1's first slice = %v, want its share of the latency", s)
+	}
+}
+
+func TestRunEDF(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name            string
+		processes       []scheduler.Process
+		wantLateness    []int64
+		wantMisses      int
+		wantSchedulable bool
+	}{
+		{
+			// P2 arrives due sooner than P1 and preempts it, and P3 goes
+			// ahead of the rest of P1 for the same reason.
+			name: "schedulable",
+			processes: []scheduler.Process{
+				{ProcessID: 1, ArrivalTime: 0, BurstDuration: 4, Deadline: 10},
+				{ProcessID: 2, ArrivalTime: 1, BurstDuration: 2, Deadline: 4},
+				{ProcessID: 3, ArrivalTime: 2, BurstDuration: 3, Deadline: 9},
+			},
+			wantLateness:    []int64{-1, -1, -3},
+			wantSchedulable: true,
+		},
+		{
+			// Eight ticks of work are due by 6, so something must be late.
+			name: "overloaded",
+			processes: []scheduler.Process{
+				{ProcessID: 1, ArrivalTime: 0, BurstDuration: 5, Deadline: 6},
+				{ProcessID: 2, ArrivalTime: 0, BurstDuration: 3, Deadline: 5},
+			},
+			wantLateness: []int64{2, -2},
+			wantMisses:   1,
+		},
+		{
+			// A process without a deadline waits for those with one.
+			name: "no deadline",
+			processes: []scheduler.Process{
+				{ProcessID: 1, ArrivalTime: 0, BurstDuration: 3},
+				{ProcessID: 2, ArrivalTime: 1, BurstDuration: 2, Deadline: 20},
+			},
+			wantLateness:    []int64{0, -17},
+			wantSchedulable: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result := scheduler.RunEDF(tt.processes)
+			for i, m := range result.Processes {
+				if m.Lateness != tt.wantLateness[i] {
+					t.Errorf("P%d lateness = %d, want %d", m.PID, m.Lateness, tt.wantLateness[i])
+				}
+			}
+			if result.DeadlineMisses != tt.wantMisses {
+				t.Errorf("DeadlineMisses = %d, want %d", result.DeadlineMisses, tt.wantMisses)
+			}
+			if result.Schedulable == nil || *result.Schedulable != tt.wantSchedulable {
+				t.Errorf("Schedulable = %v, want %v", result.Schedulable, tt.wantSchedulable)
+			}
+		})
+	}
+}
+
+func TestPrioritySchedulers(t *testing.T) {
+	t.Parallel()
+	// P2 outranks P1 on arriving at 1, which only the preemptive scheduler
+	// acts on; P3 then ties P2 and waits its turn. P1 and P4 tie, and P1,
+	// there first, goes first under both.
+	processes := []scheduler.Process{
+		{ProcessID: 1, ArrivalTime: 0, BurstDuration: 4, Priority: 3},
+		{ProcessID: 2, ArrivalTime: 1, BurstDuration: 2, Priority: 1},
+		{ProcessID: 3, ArrivalTime: 2, BurstDuration: 1, Priority: 1},
+		{ProcessID: 4, ArrivalTime: 3, BurstDuration: 2, Priority: 3},
+	}
+	tests := []struct {
+		name      string
+		run       func([]scheduler.Process) scheduler.RunResult
+		wantExits []int64
+		wantGantt string
+	}{
+		{name: "non-preemptive", run: scheduler.RunPriority, wantExits: []int64{4, 6, 7, 9}, wantGantt: "1:0-4 2:4-6 3:6-7 4:7-9"},
+		{name: "preemptive", run: scheduler.RunPriorityPreemptive, wantExits: []int64{7, 3, 4, 9}, wantGantt: "1:0-1 2:1-3 3:3-4 1:4-7 4:7-9"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result := tt.run(processes)
+			for i, m := range result.Processes {
+				if m.Exit != tt.wantExits[i] {
+					t.Errorf("P%d exit = %d, want %d", m.PID, m.Exit, tt.wantExits[i])
+				}
+			}
+			var gantt []string
+			for _, s := range result.Gantt {
+				gantt = append(gantt, fmt.Sprintf("%d:%d-%d", s.PID, s.Start, s.Stop))
+			}
+			if got := strings.Join(gantt, " "); got != tt.wantGantt {
+				t.Errorf("Gantt = %s, want %s", got, tt.wantGantt)
+			}
+		})
+	}
+}
+
+func TestRunWeightedRR(t *testing.T) {
+	t.Parallel()
+	// P1 weighs 3, so runs 3 ticks a turn to P2's 1.
+	processes := []scheduler.Process{
+		{ProcessID: 1, BurstDuration: 6, Priority: 0},
+		{ProcessID: 2, BurstDuration: 6, Priority: 1},
+	}
+	result := scheduler.RunWeightedRR(processes, 1, map[int64]int64{0: 3})
+	var got []string
+	for _, m := range result.Processes {
+		got = append(got, fmt.Sprintf("P%d weight %d quantum %d exit %d", m.PID, m.Weight, m.Quantum, m.Exit))
+	}
+	want := []string{"P1 weight 3 quantum 3 exit 7", "P2 weight 1 quantum 1 exit 12"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RunWeightedRR() = %v, want %v", got, want)
+	}
+	out := &bytes.Buffer{}
+	render.Quanta(out, result.Processes)
+	if !strings.Contains(out.String(), "Weighted quanta") {
+		t.Errorf("outputQuanta() printed no table:\n%s", out)
+	}
+}
+
+func Test_parseWeights(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		list    string
+		want    map[int64]int64
+		wantErr string
+	}{
+		{list: ""},
+		{list: "0:3, 1:2,-1:5", want: map[int64]int64{0: 3, 1: 2, -1: 5}},
+		{list: "0", wantErr: `weight "0" is not priority:weight`},
+		{list: "x:2", wantErr: `priority "x" is not an integer`},
+		{list: "1:0", wantErr: `"0" is not a positive integer`},
+		{list: "1:2,1:3", wantErr: "priority 1 weighed twice"},
+	}
+	for _, tt := range tests {
+		got, err := parseWeights(tt.list)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseWeights(%q) error = %v, want %q", tt.list, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseWeights(%q) = %v, %v, want %v", tt.list, got, err, tt.want)
+		}
+	}
+}
+
+func TestRunHRRN(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		processes []scheduler.Process
+		wantExits []int64
+	}{
+		{
+			// At 9 P3 has the best ratio, (5+4)/4, though sjf would take P5;
+			// at 13 P5's (5+2)/2 beats P4's (7+5)/5.
+			name: "ratios",
+			processes: []scheduler.Process{
+				{ProcessID: 1, ArrivalTime: 0, BurstDuration: 3},
+				{ProcessID: 2, ArrivalTime: 2, BurstDuration: 6},
+				{ProcessID: 3, ArrivalTime: 4, BurstDuration: 4},
+				{ProcessID: 4, ArrivalTime: 6, BurstDuration: 5},
+				{ProcessID: 5, ArrivalTime: 8, BurstDuration: 2},
+			},
+			wantExits: []int64{3, 9, 13, 20, 15},
+		},
+		{
+			// At 4 P2's (3+3)/3 and P3's (2+2)/2 are both 2, and P2 came first.
+			name: "equal ratios",
+			processes: []scheduler.Process{
+				{ProcessID: 1, ArrivalTime: 0, BurstDuration: 4},
+				{ProcessID: 2, ArrivalTime: 1, BurstDuration: 3},
+				{ProcessID: 3, ArrivalTime: 2, BurstDuration: 2},
+			},
+			wantExits: []int64{4, 7, 9},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result := scheduler.RunHRRN(tt.processes)
+			for i, m := range result.Processes {
+				if m.Exit != tt.wantExits[i] {
+					t.Errorf("P%d exit = %d, want %d", m.PID, m.Exit, tt.wantExits[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRunFunctions(t *testing.T) {
+	t.Parallel()
+	// Each scheduler's result is what the registered one gives, the
+	// hand-written fcfs and sjf included, on workloads without yields.
+	runs := map[string]func([]scheduler.Process) scheduler.RunResult{
+		"fcfs":                scheduler.RunFCFS,
+		"sjf":                 scheduler.RunSJF,
+		"srtf":                scheduler.RunSRTF,
+		"priority":            scheduler.RunPriority,
+		"priority-preemptive": scheduler.RunPriorityPreemptive,
+		"rr":                  func(p []scheduler.Process) scheduler.RunResult { return scheduler.RunRR(p, scheduler.DefaultQuantum) },
+		"wrr": func(p []scheduler.Process) scheduler.RunResult {
+			return scheduler.RunWeightedRR(p, scheduler.DefaultQuantum, nil)
+		},
+		"mlq": func(p []scheduler.Process) scheduler.RunResult {
+			return scheduler.RunMultilevelQueue(p, scheduler.DefaultQuantum, nil)
+		},
+		"ppriority": func(p []scheduler.Process) scheduler.RunResult {
+			return scheduler.RunPreemptivePriority(p, scheduler.DefaultAgingRate)
+		},
+		"lottery": func(p []scheduler.Process) scheduler.RunResult {
+			return scheduler.RunLottery(p, scheduler.DefaultQuantum, scheduler.DefaultSeed)
+		},
+		"stride": func(p []scheduler.Process) scheduler.RunResult {
+			return scheduler.RunStride(p, scheduler.DefaultQuantum)
+		},
+		"cfs": func(p []scheduler.Process) scheduler.RunResult {
+			return scheduler.RunCFS(p, scheduler.DefaultTargetLatency)
+		},
+		"edf":  scheduler.RunEDF,
+		"hrrn": scheduler.RunHRRN,
+	}
+	for _, workload := range []string{"basic", "idle", "mixed", "ties"} {
+		processes := mustLoadProcesses(t, "testdata/workloads/"+workload+".csv")
+		for name, run := range runs {
+			want, err := scheduler.RunScheduler(name, 0, processes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := run(processes)
+			got.Events, want.Events = nil, nil
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s on %s = %+v, want %+v", name, workload, got, want)
+			}
+		}
+	}
+}
+
+func TestEngineSchedules_sparseArrivals(t *testing.T) {
+	t.Parallel()
+	// Idle gaps of millions of ticks must not be stepped through one by one.
+	processes, err := loader.LoadCSV(strings.NewReader("1,3,0,2\n2,2,0,1\n3,2,9000000,1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		run       func(io.Writer, string, []scheduler.Process)
+		wantGantt string
+	}{
+		{
+			name:      "priority",
+			run:       PriorityNonPreemptiveSchedule,
+			wantGantt: "|  2  |  1  |  idle  |   3    |\n0     2     5        9000000  9000002",
+		},
+		{
+			name:      "rr",
+			run:       func(w io.Writer, title string, p []scheduler.Process) { RRSchedule(w, title, p, 2) },
+			wantGantt: "|  1  |  2  |  1  |  idle  |   3    |\n0     2     4     5        9000000  9000002",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			out := &bytes.Buffer{}
+			tt.run(out, tt.name, processes)
+			if !strings.Contains(out.String(), tt.wantGantt) {
+				t.Errorf("output has no Gantt chart %q:\n%s", tt.wantGantt, out)
+			}
+		})
+	}
+}
+
+func TestSJFSchedule_arbitraryPIDs(t *testing.T) {
+	t.Parallel()
+	// Rows used to be placed by PID, which panicked on PIDs that were not
+	// 1..n; they now follow the input order.
+	processes, err := loader.LoadCSV(strings.NewReader("30,4,0\n10,1,1\n20,2,1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := append([]scheduler.Process(nil), processes...)
+	out := &bytes.Buffer{}
+	SJFSchedule(out, "SJF", processes)
+	if !reflect.DeepEqual(processes, before) {
+		t.Errorf("SJFSchedule() changed its input to %+v", processes)
+	}
+	if want := "|  30  |  10  |  20  |\n0      4      5      7"; !strings.Contains(out.String(), want) {
+		t.Errorf("output has no Gantt chart %q:\n%s", want, out)
+	}
+	rows := out.String()[strings.Index(out.String(), "Schedule table"):]
+	if i, j, k := strings.Index(rows, "| 30 |"), strings.Index(rows, "| 10 |"), strings.Index(rows, "| 20 |"); i < 0 || !(i < j && j < k) {
+		t.Errorf("rows are not in input order:\n%s", rows)
+	}
+}
+
+func TestPrintedSchedulers_realPIDs(t *testing.T) {
+	t.Parallel()
+	// PIDs like a real system's are neither small nor contiguous; every
+	// scheduler keeps rows in input order whatever the IDs are.
+	processes, err := loader.LoadCSV(strings.NewReader("1543,4,0,2\n87,3,1,1\n20011,2,2,3\n0,1,9,1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedulers := map[string]func(io.Writer, string, []scheduler.Process){
+		"fcfs":     FCFSSchedule,
+		"sjf":      SJFSchedule,
+		"priority": PriorityNonPreemptiveSchedule,
+		"rr": func(w io.Writer, title string, p []scheduler.Process) {
+			RRSchedule(w, title, p, scheduler.DefaultQuantum)
+		},
+	}
+	for name, run := range schedulers {
+		out := &bytes.Buffer{}
+		run(out, name, processes)
+		rows := out.String()[strings.Index(out.String(), "Schedule table"):]
+		last := -1
+		for _, p := range processes {
+			loc := regexp.MustCompile(fmt.Sprintf(`(?m)^\| +%d \|`, p.ProcessID)).FindStringIndex(rows)
+			if loc == nil || loc[0] <= last {
+				t.Errorf("%s rows are not in input order:\n%s", name, rows)
+				break
+			}
+			last = loc[0]
+		}
+	}
+}
+
+func TestPrintedSchedulers_degenerate(t *testing.T) {
+	t.Parallel()
+	// Empty and single-process workloads used to average to NaN or +Inf.
+	schedulers := map[string]func(io.Writer, string, []scheduler.Process){
+		"fcfs":     FCFSSchedule,
+		"sjf":      SJFSchedule,
+		"priority": PriorityNonPreemptiveSchedule,
+		"rr": func(w io.Writer, title string, p []scheduler.Process) {
+			RRSchedule(w, title, p, scheduler.DefaultQuantum)
+		},
+	}
+	workloads := map[string][]scheduler.Process{
+		"empty":          nil,
+		"single":         {{ProcessID: 1, BurstDuration: 3}},
+		"single, late":   {{ProcessID: 1, ArrivalTime: 4, BurstDuration: 1}},
+		"one tick total": {{ProcessID: 7, BurstDuration: 1}},
+	}
+	for workload, processes := range workloads {
+		for name, run := range schedulers {
+			out := &bytes.Buffer{}
+			run(out, name, processes)
+			if s := strings.ToLower(out.String()); strings.Contains(s, "nan") || strings.Contains(s, "inf") {
+				t.Errorf("%s on %s workload printed a NaN or Inf:\n%s", name, workload, out)
+			}
+		}
+	}
+}
+
+func TestPrintedSchedulers_shareProcesses(t *testing.T) {
+	t.Parallel()
+	// All four schedulers read the same []Process and must leave it as it
+	// was, printing the same kind of report.
+	processes := mustLoadProcesses(t, "testdata/workloads/mixed.csv")
+	before := append([]scheduler.Process(nil), processes...)
+	schedulers := map[string]func(io.Writer, string, []scheduler.Process){
+		"fcfs":     FCFSSchedule,
+		"sjf":      SJFSchedule,
+		"priority": PriorityNonPreemptiveSchedule,
+		"rr": func(w io.Writer, title string, p []scheduler.Process) {
+			RRSchedule(w, title, p, scheduler.DefaultQuantum)
+		},
+	}
+	for name, run := range schedulers {
+		out := &bytes.Buffer{}
+		run(out, name, processes)
+		for _, section := range []string{"Gantt schedule", "Schedule table", "TURNAROUND"} {
+			if !strings.Contains(out.String(), section) {
+				t.Errorf("%s report has no %q:\n%s", name, section, out)
+			}
+		}
+		if !reflect.DeepEqual(processes, before) {
+			t.Fatalf("%s changed its input to %+v", name, processes)
+		}
+	}
+}
+
+func Test_writeReport(t *testing.T) {
+	t.Parallel()
+	processes := mustLoadProcesses(t, "testdata/workloads/mixed.csv")
+	names := []string{"fcfs", "rr"}
+	params := scheduler.SchedulerParams{Quantum: 3}
+
+	results, err := runSchedulers(names, params, processes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := writeReport(&out, OutputJSON, results); err != nil {
+		t.Fatal(err)
+	}
+	var got []scheduler.RunResult
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("report is not JSON: %v\n%s", err, out.String())
+	}
+	for i, name := range names {
+		want, err := scheduler.RunSchedulerParams(name, params, processes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i >= len(got) || !reflect.DeepEqual(got[i], want) {
+			t.Errorf("JSON result %d = %+v, want %+v", i, got, want)
+		}
+	}
+
+	out.Reset()
+	if err := writeReport(&out, OutputText, results); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Round-robin (quantum 3)") {
+		t.Errorf("text report has no round-robin title:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := writeReport(&out, OutputExpanded, results); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "PREEMPTIONS") {
+		t.Errorf("expanded report has no preemptions column:\n%s", out.String())
+	}
+
+	if err := writeReport(&out, "xml", results); !errors.Is(err, scheduler.ErrInvalidArgs) {
+		t.Errorf("writeReport(xml) error = %v, want ErrInvalidArgs", err)
+	}
+	if _, err := runSchedulers([]string{"nope"}, params, processes); err == nil {
+		t.Error("runSchedulers() with an unknown scheduler succeeded")
+	}
+}
+
+func Test_saveResults(t *testing.T) {
+	t.Parallel()
+	processes := mustLoadProcesses(t, "testdata/workloads/basic.csv")
+	results, err := runSchedulers([]string{"fcfs", "sjf"}, scheduler.SchedulerParams{}, processes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "metrics.csv")
+	if err := saveResults(path, results, render.MetricsCSV); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sections := strings.Split(string(data), "\n\n")
+	if len(sections) != 2 {
+		t.Fatalf("metrics CSV has %d sections, want 2:\n%s", len(sections), data)
+	}
+	if rows := len(strings.Split(sections[0], "\n")); rows != 1+2*len(processes) {
+		t.Errorf("process section has %d lines, want %d:\n%s", rows, 1+2*len(processes), sections[0])
+	}
+	if !strings.HasPrefix(sections[1], "scheduler,avg_wait") || !strings.Contains(sections[1], "\nsjf,") {
+		t.Errorf("summary section is not the averages of both runs:\n%s", sections[1])
+	}
+
+	path = filepath.Join(t.TempDir(), "report.html")
+	if err := saveResults(path, results, render.HTMLReport); err != nil {
+		t.Fatal(err)
+	}
+	if data, err = os.ReadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "Shortest") {
+		t.Errorf("report does not cover sjf:\n%s", data)
+	}
+
+	if err := saveResults(filepath.Join(t.TempDir(), "missing", "metrics.csv"), results, render.MetricsCSV); err == nil {
+		t.Error("saveResults() into a missing directory succeeded")
+	}
+}
+
+func Test_run(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad.csv")
+	if err := os.WriteFile(bad, []byte("1,5,0\n2,x,1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		args     []string
+		wantErr  string
+		wantOut  string
+		wantFile string
+	}{
+		{name: "report", args: []string{"-scheduler", "fcfs", "testdata/workloads/basic.csv"}, wantOut: "First-come, first-serve"},
+		{name: "subcommand", args: []string{"rm", "example_periodic.csv"}, wantOut: "Rate monotonic"},
+		{
+			name:     "output file",
+			args:     []string{"-scheduler", "sjf", "-output", "json", "-output-file", filepath.Join(dir, "out.json"), "testdata/workloads/basic.csv"},
+			wantFile: filepath.Join(dir, "out.json"),
+		},
+		{name: "help", args: []string{"-h"}},
+		{name: "unknown flag", args: []string{"-bogus", "testdata/workloads/basic.csv"}, wantErr: "flag provided but not defined"},
+		{name: "no file", args: []string{"-scheduler", "fcfs"}, wantErr: "must give a scheduling file"},
+		{name: "two files", args: []string{"-scheduler", "fcfs", "a.csv", "b.csv"}, wantErr: "run several with batch"},
+		{name: "missing file", args: []string{"nope.csv"}, wantErr: "nope.csv"},
+		{name: "bad workload", args: []string{bad}, wantErr: `bad.csv: invalid args: line 2, column 2: "x" is not an integer`},
+		{name: "bad aging", args: []string{"-aging", "0", "testdata/workloads/basic.csv"}, wantErr: "aging rate 0 must be at least 1"},
+		{name: "aging policy", args: []string{"-scheduler", "priority,mlq", "-age-every", "3", "-age-cap", "1", "testdata/workloads/mixed.csv"}, wantOut: "Multilevel queue (quantum 2) (aging every 3 up to priority 1)"},
+		{name: "nice weights", args: []string{"-scheduler", "fcfs,cfs", "-priority-order", "higher-first", "-nice-weights", "-5:2048", "testdata/workloads/nice.csv"}, wantOut: "Completely fair (target latency 12) (higher-first priority) (custom nice weights)"},
+		{name: "group caps", args: []string{"-scheduler", "fcfs", "-caps", "batch:50%", "testdata/workloads/groups.csv"}, wantOut: "First-come, first-serve (caps batch 50% every 10)"},
+		{name: "group caps on several CPUs", args: []string{"-scheduler", "fcfs", "-cpus", "2", "-caps", "batch:50", "testdata/workloads/groups.csv"}, wantErr: "group caps run on a single CPU"},
+		{name: "inheritance on several CPUs", args: []string{"-scheduler", "priority", "-cpus", "2", "-inherit", "testdata/workloads/inversion.csv"}, wantErr: "priority inheritance runs on a single CPU"},
+		{name: "carry", args: []string{"-scheduler", "fcfs,rr", "-carry", "bank", "testdata/workloads/yields.csv"}, wantOut: "Round-robin (quantum 2) (bank yielded quanta up to 4)"},
+		{name: "bank cap without bank", args: []string{"-bank-cap", "4", "testdata/workloads/yields.csv"}, wantErr: "-bank-cap needs -carry bank"},
+		{name: "nice value out of range", args: []string{"-scheduler", "cfs", "-nice-weights", "20:1", "testdata/workloads/nice.csv"}, wantErr: "nice value 20 is not within -20 to 19"},
+		{name: "aging cap alone", args: []string{"-age-cap", "1", "testdata/workloads/basic.csv"}, wantErr: "an aging cap needs an aging rate"},
+		{name: "bad output", args: []string{"-output", "xml", "testdata/workloads/basic.csv"}, wantErr: `unknown output format "xml"`},
+		{name: "bad tie-break", args: []string{"-tie-break", "lifo", "testdata/workloads/basic.csv"}, wantErr: `unknown tie-break rule "lifo"`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var out bytes.Buffer
+			err := run(&out, tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("run() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out.String(), tt.wantOut) {
+				t.Errorf("run() printed %q, want it to contain %q", out.String(), tt.wantOut)
+			}
+			if tt.wantFile != "" {
+				data, err := os.ReadFile(tt.wantFile)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !json.Valid(data) {
+					t.Errorf("%s is not JSON:\n%s", tt.wantFile, data)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_run_mermaid(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	// The names hold Mermaid's :, # and ;, and P3 arrives after an idle gap.
+	workload := filepath.Join(dir, "names.csv")
+	if err := os.WriteFile(workload, []byte("1,2,0,0,,,,,,init#1\n2,2,0,0,,,,,,shell: bash;zsh\n3,1,8,0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "schedule.mmd")
+	var out bytes.Buffer
+	if err := run(&out, []string{"schedule", "fcfs", "--context-switch-cost", "1", "--mermaid", path, workload}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Switches are crit tasks, idle time a gap, and the names' syntax spaces.
+	want := `gantt
+    title CPU schedule
+    dateFormat X
+    axisFormat %s
+    section fcfs
+    switch :crit, 0, 1
+    P1 (init 1) :1, 3
+    switch :crit, 3, 4
+    P2 (shell  bash zsh) :4, 6
+    switch :crit, 8, 9
+    P3 :9, 10
+`
+	if string(data) != want {
+		t.Errorf("diagram =\n%s\nwant\n%s", data, want)
+	}
+}
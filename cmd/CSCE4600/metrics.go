@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+//region Metrics
+
+var (
+	// durationBuckets are upper bounds in seconds for how long one simulation takes.
+	durationBuckets = []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+	// workloadBuckets are upper bounds on the number of processes in a workload.
+	workloadBuckets = []float64{1, 5, 10, 50, 100, 500, 1000, 5000}
+)
+
+type (
+	// serverMetrics counts what a server has done, written out in the
+	// Prometheus text format at /metrics:
+	// • scheduler_simulations_total            runs per algorithm
+	// • scheduler_simulation_duration_seconds  run time per algorithm
+	// • scheduler_streams_total                WebSocket streams per algorithm
+	// • scheduler_workload_processes           processes per uploaded workload
+	serverMetrics struct {
+		mu        sync.Mutex
+		runs      map[string]uint64
+		durations map[string]*histogram
+		streams   map[string]uint64
+		workloads *histogram
+	}
+	histogram struct {
+		bounds []float64
+		counts []uint64
+		sum    float64
+		count  uint64
+	}
+)
+
+func newServerMetrics() *serverMetrics {
+	return &serverMetrics{
+		runs:      map[string]uint64{},
+		durations: map[string]*histogram{},
+		streams:   map[string]uint64{},
+		workloads: newHistogram(workloadBuckets),
+	}
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]uint64, len(bounds))}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, b := range h.bounds {
+		if v <= b {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+func (m *serverMetrics) observeRun(algorithm string, took time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs[algorithm]++
+	h, ok := m.durations[algorithm]
+	if !ok {
+		h = newHistogram(durationBuckets)
+		m.durations[algorithm] = h
+	}
+	h.observe(took.Seconds())
+}
+
+func (m *serverMetrics) observeStream(algorithm string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.streams[algorithm]++
+}
+
+func (m *serverMetrics) observeWorkload(processes int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.workloads.observe(float64(processes))
+}
+
+// write writes every metric in the Prometheus text exposition format.
+func (m *serverMetrics) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, _ = fmt.Fprintln(w, "# HELP scheduler_simulations_total Simulations run, by algorithm.")
+	_, _ = fmt.Fprintln(w, "# TYPE scheduler_simulations_total counter")
+	for _, name := range sortedKeys(m.runs) {
+		_, _ = fmt.Fprintf(w, "scheduler_simulations_total{algorithm=%q} %d\n", name, m.runs[name])
+	}
+
+	_, _ = fmt.Fprintln(w, "# HELP scheduler_simulation_duration_seconds Wall time of one simulation, by algorithm.")
+	_, _ = fmt.Fprintln(w, "# TYPE scheduler_simulation_duration_seconds histogram")
+	for _, name := range sortedKeys(m.runs) {
+		m.durations[name].writeTo(w, "scheduler_simulation_duration_seconds", fmt.Sprintf("algorithm=%q", name))
+	}
+
+	_, _ = fmt.Fprintln(w, "# HELP scheduler_streams_total WebSocket event streams started, by algorithm.")
+	_, _ = fmt.Fprintln(w, "# TYPE scheduler_streams_total counter")
+	for _, name := range sortedKeys(m.streams) {
+		_, _ = fmt.Fprintf(w, "scheduler_streams_total{algorithm=%q} %d\n", name, m.streams[name])
+	}
+
+	_, _ = fmt.Fprintln(w, "# HELP scheduler_workload_processes Processes in each uploaded workload.")
+	_, _ = fmt.Fprintln(w, "# TYPE scheduler_workload_processes histogram")
+	m.workloads.writeTo(w, "scheduler_workload_processes", "")
+}
+
+func (h *histogram) writeTo(w io.Writer, name, labels string) {
+	sep := ""
+	if labels != "" {
+		sep = ","
+	}
+	for i, b := range h.bounds {
+		_, _ = fmt.Fprintf(w, "%s_bucket{%s%sle=\"%g\"} %d\n", name, labels, sep, b, h.counts[i])
+	}
+	_, _ = fmt.Fprintf(w, "%s_bucket{%s%sle=\"+Inf\"} %d\n", name, labels, sep, h.count)
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+	_, _ = fmt.Fprintf(w, "%s_sum%s %g\n", name, labels, h.sum)
+	_, _ = fmt.Fprintf(w, "%s_count%s %d\n", name, labels, h.count)
+}
+
+func sortedKeys(m map[string]uint64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, errors.New("use GET"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	s.metrics.write(w)
+}
+
+//endregion
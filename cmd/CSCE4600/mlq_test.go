@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestRunMultilevelQueue(t *testing.T) {
+	t.Parallel()
+	processes := mustLoadProcesses(t, "example_processes.csv")
+	f, err := os.Open("example_mlq.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	weighted, err := loader.LoadMLQConfig(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sjfBatch := scheduler.MLQConfig{Queues: []scheduler.MLQLevel{
+		{Name: "interactive", MinPriority: 1, MaxPriority: 1, Policy: "rr", Quantum: 4},
+		{Name: "batch", MinPriority: 2, MaxPriority: 3, Policy: "sjf"},
+	}}
+	tests := []struct {
+		name       string
+		config     *scheduler.MLQConfig
+		wantGantt  string
+		wantQueues string
+	}{
+		{
+			// P2 alone is interactive and preempts batch P1 on arriving.
+			name:       "default",
+			wantGantt:  "1:0-3 2:3-5 2:5-7 2:7-9 2:9-11 2:11-12 1:12-14 3:14-20",
+			wantQueues: "batch interactive batch",
+		},
+		{
+			// Batch gets 3 ticks a turn, interactive 6; P1 finishes 2 into
+			// batch's second turn, leaving 1 for P3.
+			name:       "weighted",
+			config:     &weighted,
+			wantGantt:  "1:0-3 2:3-5 2:5-7 2:7-9 1:9-11 3:11-12 2:12-14 2:14-15 3:15-18 3:18-20",
+			wantQueues: "batch interactive batch",
+		},
+		{
+			name:       "sjf batch",
+			config:     &sjfBatch,
+			wantGantt:  "1:0-3 2:3-7 2:7-11 2:11-12 1:12-14 3:14-20",
+			wantQueues: "batch interactive batch",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result := scheduler.RunMultilevelQueue(processes, 2, tt.config)
+			var gantt, queues []string
+			for _, s := range result.Gantt {
+				gantt = append(gantt, fmt.Sprintf("%d:%d-%d", s.PID, s.Start, s.Stop))
+			}
+			for _, m := range result.Processes {
+				queues = append(queues, m.Queue)
+			}
+			if got := strings.Join(gantt, " "); got != tt.wantGantt {
+				t.Errorf("Gantt = %s, want %s", got, tt.wantGantt)
+			}
+			if got := strings.Join(queues, " "); got != tt.wantQueues {
+				t.Errorf("queues = %s, want %s", got, tt.wantQueues)
+			}
+		})
+	}
+}
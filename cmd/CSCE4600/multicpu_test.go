@@ -0,0 +1,115 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestMultiCPU_oneCPU(t *testing.T) {
+	t.Parallel()
+	// On one CPU the multiprocessor simulation is the engine's, switch
+	// costs included, for workloads without yields or sync operations.
+	for _, workload := range []string{"basic", "idle", "mixed", "ties"} {
+		processes := mustLoadProcesses(t, "testdata/workloads/"+workload+".csv")
+		for _, name := range multiCPUSchedulers(scheduler.SchedulerNames()) {
+			info, _ := scheduler.LookupScheduler(name)
+			for _, switchCost := range []int64{0, 1} {
+				params := scheduler.SchedulerParams{Quantum: scheduler.DefaultQuantum, SwitchCost: switchCost}
+				engine := info.New(params).(*scheduler.Engine)
+				want := engine.Schedule(processes)
+				// A fresh queue, so a lottery draws from the start again.
+				fresh := info.New(params).(*scheduler.Engine)
+				multi := scheduler.MultiCPU{CPUs: 1, Queue: fresh.Queue, Quantum: engine.Quantum, SwitchCost: switchCost}
+				got := multi.Schedule(processes)
+				if !reflect.DeepEqual(got.Gantt, want.Gantt) || !reflect.DeepEqual(got.Processes, want.Processes) {
+					t.Errorf("%s on %s, switch cost %d = %v %v, want %v %v",
+						name, workload, switchCost, got.Gantt, got.Processes, want.Gantt, want.Processes)
+				}
+				if got.Utilization != want.Utilization {
+					t.Errorf("%s on %s utilization = %v, want %v", name, workload, got.Utilization, want.Utilization)
+				}
+			}
+		}
+	}
+}
+
+func TestMultiCPU(t *testing.T) {
+	t.Parallel()
+	processes := []scheduler.Process{
+		{ProcessID: 1, ArrivalTime: 0, BurstDuration: 4},
+		{ProcessID: 2, ArrivalTime: 0, BurstDuration: 3},
+		{ProcessID: 3, ArrivalTime: 0, BurstDuration: 2},
+		{ProcessID: 4, ArrivalTime: 6, BurstDuration: 2},
+	}
+	tests := []struct {
+		name      string
+		scheduler string
+		want      []scheduler.TimeSlice
+		wantCPUs  []scheduler.CPUStats
+	}{
+		{
+			name:      "fcfs",
+			scheduler: "fcfs",
+			want: []scheduler.TimeSlice{
+				{PID: 1, Start: 0, Stop: 4},
+				{PID: scheduler.IdlePID, Start: 4, Stop: 6},
+				{PID: 4, Start: 6, Stop: 8},
+				{PID: 2, Start: 0, Stop: 3, CPU: 1},
+				{PID: 3, Start: 3, Stop: 5, CPU: 1},
+				{PID: scheduler.IdlePID, Start: 5, Stop: 8, CPU: 1},
+			},
+			wantCPUs: []scheduler.CPUStats{{CPU: 0, Busy: 6, Utilization: 0.75}, {CPU: 1, Busy: 5, Utilization: 0.625}},
+		},
+		{
+			name:      "sjf",
+			scheduler: "sjf",
+			want: []scheduler.TimeSlice{
+				{PID: 3, Start: 0, Stop: 2},
+				{PID: 1, Start: 2, Stop: 6},
+				{PID: 4, Start: 6, Stop: 8},
+				{PID: 2, Start: 0, Stop: 3, CPU: 1},
+				{PID: scheduler.IdlePID, Start: 3, Stop: 8, CPU: 1},
+			},
+			wantCPUs: []scheduler.CPUStats{{CPU: 0, Busy: 8, Utilization: 1}, {CPU: 1, Busy: 3, Utilization: 0.375}},
+		},
+		{
+			name:      "rr moves tasks between CPUs",
+			scheduler: "rr",
+			want: []scheduler.TimeSlice{
+				{PID: 1, Start: 0, Stop: 2},
+				{PID: 3, Start: 2, Stop: 4},
+				{PID: 2, Start: 4, Stop: 5},
+				{PID: scheduler.IdlePID, Start: 5, Stop: 6},
+				{PID: 4, Start: 6, Stop: 8},
+				{PID: 2, Start: 0, Stop: 2, CPU: 1},
+				{PID: 1, Start: 2, Stop: 4, CPU: 1},
+				{PID: scheduler.IdlePID, Start: 4, Stop: 8, CPU: 1},
+			},
+			wantCPUs: []scheduler.CPUStats{{CPU: 0, Busy: 7, Utilization: 0.875}, {CPU: 1, Busy: 4, Utilization: 0.5}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := scheduler.RunSchedulerParams(tt.scheduler, scheduler.SchedulerParams{CPUs: 2}, processes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result.Gantt, tt.want) {
+				t.Errorf("gantt = %v, want %v", result.Gantt, tt.want)
+			}
+			if !reflect.DeepEqual(result.CPUs, tt.wantCPUs) {
+				t.Errorf("CPUs = %+v, want %+v", result.CPUs, tt.wantCPUs)
+			}
+			if want := (tt.wantCPUs[0].Utilization + tt.wantCPUs[1].Utilization) / 2; result.Utilization != want {
+				t.Errorf("Utilization = %v, want %v", result.Utilization, want)
+			}
+		})
+	}
+	if _, err := scheduler.RunSchedulerParams("srtf", scheduler.SchedulerParams{CPUs: 2}, processes); err == nil {
+		t.Error("srtf on 2 CPUs did not fail")
+	}
+}
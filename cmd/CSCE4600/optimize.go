@@ -0,0 +1,260 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region Quantum search
+
+// Ways a QuantumSearch can look for the best quantum.
+const (
+	SearchSweep  = "sweep"
+	SearchAnneal = "anneal"
+)
+
+type (
+	// QuantumSearch looks for the quantum that gives a scheduler the best
+	// value of one of sweepMetrics over a workload, among From to To:
+	// • Search is SearchSweep, to try every quantum, or SearchAnneal, to
+	//   try Steps of them by simulated annealing, which suits wide ranges
+	// • Seed starts the annealing's random moves, the same seed giving the
+	//   same search
+	// • Params are the scheduler's other tunables; a context-switch cost
+	//   is what keeps the smallest quantum from being best for turnaround
+	QuantumSearch struct {
+		Scheduler string
+		From, To  int64
+		Metric    string
+		Search    string
+		Steps     int
+		Seed      int64
+		Params    scheduler.SchedulerParams
+	}
+	// QuantumReport is a finished search: every quantum it tried, in
+	// quantum order, and the best of them.
+	QuantumReport struct {
+		Scheduler   string                `json:"scheduler"`
+		Metric      string                `json:"metric"`
+		Search      string                `json:"search"`
+		BestQuantum int64                 `json:"best_quantum"`
+		Results     []scheduler.RunResult `json:"results"`
+		best        int
+	}
+)
+
+// Run searches processes for the best quantum.
+func (s QuantumSearch) Run(processes []scheduler.Process) (QuantumReport, error) {
+	if _, ok := sweepMetrics[s.Metric]; !ok {
+		return QuantumReport{}, fmt.Errorf("%w: unknown metric %q, want one of %s", scheduler.ErrInvalidArgs, s.Metric, strings.Join(sortedSweepMetrics(), ", "))
+	}
+	var (
+		results []scheduler.RunResult
+		err     error
+	)
+	switch s.Search {
+	case SearchSweep:
+		results, err = Sweep{Scheduler: s.Scheduler, From: s.From, To: s.To, Step: 1, Params: s.Params}.Run(processes)
+	case SearchAnneal:
+		results, err = s.anneal(processes)
+	default:
+		err = fmt.Errorf("%w: unknown search %q, want %s or %s", scheduler.ErrInvalidArgs, s.Search, SearchSweep, SearchAnneal)
+	}
+	if err != nil {
+		return QuantumReport{}, err
+	}
+	best, err := bestQuantum(results, s.Metric)
+	if err != nil {
+		return QuantumReport{}, err
+	}
+	return QuantumReport{
+		Scheduler:   s.Scheduler,
+		Metric:      s.Metric,
+		Search:      s.Search,
+		BestQuantum: results[best].Quantum,
+		Results:     results,
+		best:        best,
+	}, nil
+}
+
+// anneal walks the quanta by simulated annealing: from the middle of the
+// range it tries a random quantum within reach, moving there if it is
+// better or, with a chance that shrinks as the search cools, if it is
+// worse, so the walk can climb out of a local best. Reach shrinks with the
+// temperature, from the whole range to a step either way. Each quantum is
+// run once however often the walk comes back to it.
+func (s QuantumSearch) anneal(processes []scheduler.Process) ([]scheduler.RunResult, error) {
+	info, err := scheduler.LookupScheduler(s.Scheduler)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case !info.Quantum:
+		return nil, fmt.Errorf("%w: %s has no quantum to search", scheduler.ErrInvalidArgs, s.Scheduler)
+	case s.From < 1 || s.To < s.From:
+		return nil, fmt.Errorf("%w: search needs 1 <= from <= to", scheduler.ErrInvalidArgs)
+	case s.Steps < 1:
+		return nil, fmt.Errorf("%w: annealing needs at least 1 step, not %d", scheduler.ErrInvalidArgs, s.Steps)
+	}
+	m := sweepMetrics[s.Metric]
+	tried := make(map[int64]scheduler.RunResult)
+	// cost is what the walk minimizes at quantum q.
+	cost := func(q int64) (float64, error) {
+		r, ok := tried[q]
+		if !ok {
+			params := s.Params
+			params.Quantum = q
+			if r, err = scheduler.SummarizeSchedulerParams(s.Scheduler, params, processes); err != nil {
+				return 0, err
+			}
+			tried[q] = r
+		}
+		if m.lowerBetter {
+			return m.value(r), nil
+		}
+		return -m.value(r), nil
+	}
+
+	rng := rand.New(rand.NewSource(s.Seed))
+	current := s.From + (s.To-s.From)/2
+	currentCost, err := cost(current)
+	if err != nil {
+		return nil, err
+	}
+	// The temperature falls from 1 to 0.01 over the steps.
+	temperature, cooling := 1.0, math.Pow(0.01, 1/float64(s.Steps))
+	for i := 0; i < s.Steps; i++ {
+		reach := int64(float64(s.To-s.From) * temperature)
+		if reach < 1 {
+			reach = 1
+		}
+		next := current + rng.Int63n(2*reach+1) - reach
+		if next < s.From {
+			next = s.From
+		} else if next > s.To {
+			next = s.To
+		}
+		nextCost, err := cost(next)
+		if err != nil {
+			return nil, err
+		}
+		// The change is relative, so the temperature means the same
+		// whatever the metric's scale.
+		change := (nextCost - currentCost) / math.Max(math.Abs(currentCost), 1e-9)
+		if change <= 0 || rng.Float64() < math.Exp(-change/temperature) {
+			current, currentCost = next, nextCost
+		}
+		temperature *= cooling
+	}
+
+	results := make([]scheduler.RunResult, 0, len(tried))
+	for _, r := range tried {
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Quantum < results[j].Quantum })
+	return results, nil
+}
+
+//endregion
+
+//region optimize-quantum command
+
+// newOptimizeQuantumCommand is the `optimize-quantum` subcommand, which finds
+// the quantum that gives the best turnaround, wait, throughput or number of
+// context switches:
+// `optimize-quantum [--scheduler rr] [--from 1] [--to 20] [--optimize turnaround] [--search sweep] workload.csv`.
+func newOptimizeQuantumCommand(opts *cliOptions) *cobra.Command {
+	var (
+		search QuantumSearch
+		flags  paramFlags
+	)
+	cmd := &cobra.Command{
+		Use:   "optimize-quantum [workload]",
+		Short: "Find the quantum that gives a scheduler the best turnaround, wait, throughput or switches",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if opts.output != OutputText && opts.output != OutputJSON {
+				return fmt.Errorf("%w: optimize-quantum prints %s or %s, not %q", scheduler.ErrInvalidArgs, OutputText, OutputJSON, opts.output)
+			}
+			if search.Params, err = flags.params(); err != nil {
+				return err
+			}
+			processes, err := opts.workload(args)
+			if err != nil {
+				return err
+			}
+			report, err := search.Run(processes)
+			if err != nil {
+				return err
+			}
+			w, closeOutput, err := opts.writer(cmd)
+			if err != nil {
+				return err
+			}
+			defer keepFirstError(&err, closeOutput)
+			if opts.output == OutputJSON {
+				enc := json.NewEncoder(w)
+				enc.SetIndent("", "  ")
+				return enc.Encode(report)
+			}
+			outputQuantumReport(w, report, search)
+			return nil
+		},
+	}
+	fs := cmd.Flags()
+	fs.StringVar(&search.Scheduler, "scheduler", "rr", "scheduler whose quantum to optimize")
+	fs.Int64Var(&search.From, "from", 1, "smallest quantum to try")
+	fs.Int64Var(&search.To, "to", 20, "largest quantum to try")
+	fs.StringVar(&search.Metric, "optimize", "turnaround", "metric to optimize: "+strings.Join(sortedSweepMetrics(), ", ")+"; throughput is maximized, the others minimized")
+	fs.StringVar(&search.Search, "search", SearchSweep, "how to search: sweep tries every quantum, anneal some by simulated annealing")
+	fs.IntVar(&search.Steps, "steps", 40, "quanta the annealing tries, counting repeats")
+	fs.Int64Var(&search.Seed, "search-seed", 1, "seed of the annealing's random moves")
+	// Every tunable but the quantum, for whichever schedulers take it.
+	flags.add(fs, scheduler.SchedulerInfo{Aging: true, Seed: true, Latency: true, Weights: true, Alpha: true, MultiCPU: true, Ages: true, Nice: true}, true)
+	return cmd
+}
+
+// outputQuantumReport prints each quantum the report tried, with a bar chart of
+// the metric optimized, then the best.
+func outputQuantumReport(w io.Writer, report QuantumReport, search QuantumSearch) {
+	m := sweepMetrics[report.Metric]
+	var top float64
+	for _, r := range report.Results {
+		top = math.Max(top, m.value(r))
+	}
+	how := fmt.Sprintf("sweeping %d to %d", search.From, search.To)
+	if report.Search == SearchAnneal {
+		how = fmt.Sprintf("annealing over %d to %d, trying %d quanta", search.From, search.To, len(report.Results))
+	}
+	_, _ = fmt.Fprintf(w, "Optimizing the %s quantum for %s by %s\n", report.Scheduler, report.Metric, how)
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Quantum", "Avg wait", "Avg turnaround", "Throughput", "Switches", report.Metric, ""})
+	table.SetAutoWrapText(false)
+	for i, r := range report.Results {
+		bar := ""
+		if top > 0 {
+			bar = strings.Repeat("#", int(math.Round(20*m.value(r)/top)))
+		}
+		mark := ""
+		if i == report.best {
+			mark = "best"
+		}
+		table.Append([]string{fmt.Sprint(r.Quantum), fmt.Sprintf("%.2f", r.AvgWait), fmt.Sprintf("%.2f", r.AvgTurnaround),
+			fmt.Sprintf("%.2f/t", r.Throughput), fmt.Sprint(r.ContextSwitches), bar, mark})
+	}
+	table.Render()
+	best := report.Results[report.best]
+	_, _ = fmt.Fprintf(w, "Best quantum: %d, %s "+m.format+"\n", best.Quantum, report.Metric, m.value(best))
+}
+
+//endregion
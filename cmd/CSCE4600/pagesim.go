@@ -0,0 +1,513 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/Sha-min/CSCE4600/pkg/render"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region Page replacement
+
+type (
+	// PageStep is the state of the frames right after one reference was served.
+	PageStep struct {
+		Ref    int
+		Frames []int
+		Fault  bool
+	}
+	// PageResult is the outcome of running one replacement algorithm over a reference string.
+	PageResult struct {
+		Algorithm string
+		Steps     []PageStep
+		Faults    int
+	}
+	// pageReplacer picks the frame to evict when every frame is full. It is told
+	// about each reference so it can keep whatever bookkeeping it needs.
+	pageReplacer interface {
+		touch(frame, step int, loaded bool)
+		victim(frames []int, step int) int
+	}
+	fifoReplacer struct {
+		loaded []int
+	}
+	lruReplacer struct {
+		lastUse []int
+	}
+	optimalReplacer struct {
+		refs []int
+	}
+	clockReplacer struct {
+		hand int
+		ref  []bool
+	}
+	secondChanceReplacer struct {
+		queue []int
+		ref   []bool
+	}
+)
+
+const emptyFrame = -1
+
+var pageAlgorithms = []string{"fifo", "lru", "optimal", "clock", "second-chance"}
+
+// SimulatePaging serves refs with the given number of frames, replacing pages with algorithm.
+func SimulatePaging(algorithm string, refs []int, frames int) (PageResult, error) {
+	if frames <= 0 {
+		return PageResult{}, fmt.Errorf("%w: frame count must be positive, got %d", scheduler.ErrInvalidArgs, frames)
+	}
+	var r pageReplacer
+	switch algorithm {
+	case "fifo":
+		r = &fifoReplacer{loaded: make([]int, frames)}
+	case "lru":
+		r = &lruReplacer{lastUse: make([]int, frames)}
+	case "optimal":
+		r = &optimalReplacer{refs: refs}
+	case "clock":
+		r = &clockReplacer{ref: make([]bool, frames)}
+	case "second-chance":
+		r = &secondChanceReplacer{ref: make([]bool, frames)}
+	default:
+		return PageResult{}, fmt.Errorf("%w: unknown page replacement algorithm %q", scheduler.ErrInvalidArgs, algorithm)
+	}
+
+	result := PageResult{Algorithm: algorithm, Steps: make([]PageStep, len(refs))}
+	state := make([]int, frames)
+	for i := range state {
+		state[i] = emptyFrame
+	}
+	for step, ref := range refs {
+		frame := indexOf(state, ref)
+		fault := frame < 0
+		if fault {
+			result.Faults++
+			if frame = indexOf(state, emptyFrame); frame < 0 {
+				frame = r.victim(state, step)
+			}
+			state[frame] = ref
+		}
+		r.touch(frame, step, fault)
+
+		result.Steps[step] = PageStep{Ref: ref, Frames: append([]int(nil), state...), Fault: fault}
+	}
+
+	return result, nil
+}
+
+func (r *fifoReplacer) touch(frame, step int, loaded bool) {
+	if loaded {
+		r.loaded[frame] = step
+	}
+}
+
+func (r *fifoReplacer) victim(frames []int, _ int) int {
+	return argMin(r.loaded)
+}
+
+func (r *lruReplacer) touch(frame, step int, _ bool) {
+	r.lastUse[frame] = step
+}
+
+func (r *lruReplacer) victim(frames []int, _ int) int {
+	return argMin(r.lastUse)
+}
+
+func (r *optimalReplacer) touch(int, int, bool) {}
+
+// victim evicts the page used farthest in the future; pages never used again
+// go first, lowest frame number winning ties.
+func (r *optimalReplacer) victim(frames []int, step int) int {
+	victim, farthest := 0, -1
+	for i, page := range frames {
+		next := len(r.refs)
+		for j := step + 1; j < len(r.refs); j++ {
+			if r.refs[j] == page {
+				next = j
+				break
+			}
+		}
+		if next > farthest {
+			victim, farthest = i, next
+		}
+	}
+	return victim
+}
+
+func (r *clockReplacer) touch(frame, _ int, _ bool) {
+	r.ref[frame] = true
+}
+
+// victim sweeps the hand around the frames, clearing reference bits until it
+// finds a frame whose bit is already clear.
+func (r *clockReplacer) victim(frames []int, _ int) int {
+	for r.ref[r.hand] {
+		r.ref[r.hand] = false
+		r.hand = (r.hand + 1) % len(frames)
+	}
+	victim := r.hand
+	r.hand = (r.hand + 1) % len(frames)
+	return victim
+}
+
+func (r *secondChanceReplacer) touch(frame, _ int, loaded bool) {
+	if loaded {
+		r.queue = append(r.queue, frame)
+	}
+	r.ref[frame] = true
+}
+
+// victim takes frames off the front of the FIFO queue, sending any with the
+// reference bit set to the back with the bit cleared.
+func (r *secondChanceReplacer) victim([]int, int) int {
+	for {
+		frame := r.queue[0]
+		r.queue = r.queue[1:]
+		if !r.ref[frame] {
+			return frame
+		}
+		r.ref[frame] = false
+		r.queue = append(r.queue, frame)
+	}
+}
+
+func indexOf(values []int, v int) int {
+	for i := range values {
+		if values[i] == v {
+			return i
+		}
+	}
+	return -1
+}
+
+func argMin(values []int) int {
+	min := 0
+	for i := range values {
+		if values[i] < values[min] {
+			min = i
+		}
+	}
+	return min
+}
+
+//endregion
+
+//region Address translation timing
+
+type (
+	// TLBConfig describes the TLB and the costs of each step of a translation.
+	// Times are in whatever unit the user picks, usually nanoseconds.
+	TLBConfig struct {
+		Entries   int
+		Ways      int
+		TLBTime   float64
+		MemTime   float64
+		FaultTime float64
+		Levels    int
+	}
+	// TranslationStats is the TLB behaviour and access timing over one PageResult.
+	TranslationStats struct {
+		Hits       []bool
+		HitCount   int
+		MissCount  int
+		TotalTime  float64
+		MeasuredAT float64
+		FormulaAT  float64
+	}
+	// TLB is a set-associative translation cache with LRU replacement inside each set.
+	TLB struct {
+		sets  [][]tlbEntry
+		clock int
+	}
+	tlbEntry struct {
+		page    int
+		lastUse int
+	}
+)
+
+// NewTLB builds a TLB of entries split into sets of ways entries each. Zero ways
+// means fully associative.
+func NewTLB(entries, ways int) (*TLB, error) {
+	if ways <= 0 {
+		ways = entries
+	}
+	if entries <= 0 || entries%ways != 0 {
+		return nil, fmt.Errorf("%w: %d TLB entries cannot be split into %d-way sets", scheduler.ErrInvalidArgs, entries, ways)
+	}
+	sets := make([][]tlbEntry, entries/ways)
+	for i := range sets {
+		sets[i] = make([]tlbEntry, 0, ways)
+	}
+	return &TLB{sets: sets}, nil
+}
+
+// Lookup reports whether page is cached, refreshing its LRU position on a hit.
+func (t *TLB) Lookup(page int) bool {
+	t.clock++
+	set := t.sets[page%len(t.sets)]
+	for i := range set {
+		if set[i].page == page {
+			set[i].lastUse = t.clock
+			return true
+		}
+	}
+	return false
+}
+
+// Insert caches page, evicting the least recently used entry of its set if full.
+func (t *TLB) Insert(page int) {
+	i := page % len(t.sets)
+	entry := tlbEntry{page: page, lastUse: t.clock}
+	if len(t.sets[i]) < cap(t.sets[i]) {
+		t.sets[i] = append(t.sets[i], entry)
+		return
+	}
+	lru := 0
+	for j := range t.sets[i] {
+		if t.sets[i][j].lastUse < t.sets[i][lru].lastUse {
+			lru = j
+		}
+	}
+	t.sets[i][lru] = entry
+}
+
+// Invalidate drops page from the TLB, as the OS must when it evicts the page.
+func (t *TLB) Invalidate(page int) {
+	i := page % len(t.sets)
+	for j := range t.sets[i] {
+		if t.sets[i][j].page == page {
+			t.sets[i] = append(t.sets[i][:j], t.sets[i][j+1:]...)
+			return
+		}
+	}
+}
+
+// MeasureTranslation replays a page replacement result through a TLB and
+// charges every reference its access time:
+// • TLB hit: TLB lookup + one memory access
+// • TLB miss: TLB lookup + one memory access per page-table level + the access itself
+// • page fault: a TLB miss plus the fault service time
+func MeasureTranslation(result PageResult, cfg TLBConfig) (TranslationStats, error) {
+	tlb, err := NewTLB(cfg.Entries, cfg.Ways)
+	if err != nil {
+		return TranslationStats{}, err
+	}
+	if cfg.Levels <= 0 {
+		cfg.Levels = 1
+	}
+
+	stats := TranslationStats{Hits: make([]bool, len(result.Steps))}
+	var previous []int
+	for i, step := range result.Steps {
+		hit := tlb.Lookup(step.Ref)
+		stats.Hits[i] = hit
+		stats.TotalTime += cfg.TLBTime + cfg.MemTime
+		if hit {
+			stats.HitCount++
+		} else {
+			stats.MissCount++
+			stats.TotalTime += float64(cfg.Levels) * cfg.MemTime
+			if step.Fault {
+				stats.TotalTime += cfg.FaultTime
+				if frame := indexOf(step.Frames, step.Ref); previous != nil && previous[frame] != emptyFrame {
+					tlb.Invalidate(previous[frame])
+				}
+			}
+			tlb.Insert(step.Ref)
+		}
+		previous = step.Frames
+	}
+
+	count := float64(len(result.Steps))
+	hitRatio := float64(stats.HitCount) / count
+	faultRate := float64(result.Faults) / count
+	stats.MeasuredAT = stats.TotalTime / count
+	stats.FormulaAT = EffectiveAccessTime(hitRatio, faultRate, cfg)
+
+	return stats, nil
+}
+
+// EffectiveAccessTime is the textbook EAT for a TLB hit ratio and page fault rate:
+// EAT = h(t + m) + (1 - h)(t + (L+1)m) + p·fault.
+func EffectiveAccessTime(hitRatio, faultRate float64, cfg TLBConfig) float64 {
+	levels := float64(cfg.Levels)
+	if levels <= 0 {
+		levels = 1
+	}
+	return hitRatio*(cfg.TLBTime+cfg.MemTime) +
+		(1-hitRatio)*(cfg.TLBTime+(levels+1)*cfg.MemTime) +
+		faultRate*cfg.FaultTime
+}
+
+//endregion
+
+//region pagesim command
+
+// runPageSim is the `pagesim` subcommand. The reference string comes from -refs,
+// a file named by -file, or is generated with -gen/-pages/-seed.
+func runPageSim(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("pagesim", flag.ContinueOnError)
+	var (
+		frames = fs.Int("frames", 3, "number of physical frames")
+		algo   = fs.String("algo", strings.Join(pageAlgorithms, ","), "comma-separated algorithms to run")
+		refs   = fs.String("refs", "", "reference string, e.g. 7,0,1,2,0,3")
+		file   = fs.String("file", "", "file containing the reference string")
+		gen    = fs.Int("gen", 0, "generate a random reference string of this length")
+		pages  = fs.Int("pages", 10, "number of distinct pages for -gen")
+		seed   = fs.Int64("seed", 1, "random seed for -gen")
+		tlbCfg TLBConfig
+	)
+	fs.IntVar(&tlbCfg.Entries, "tlb", 0, "TLB entries; 0 skips the translation timing model")
+	fs.IntVar(&tlbCfg.Ways, "tlb-ways", 0, "TLB associativity; 0 means fully associative")
+	fs.Float64Var(&tlbCfg.TLBTime, "tlb-time", 20, "time for one TLB lookup")
+	fs.Float64Var(&tlbCfg.MemTime, "mem-time", 100, "time for one memory access")
+	fs.Float64Var(&tlbCfg.FaultTime, "fault-time", 0, "time to service a page fault")
+	fs.IntVar(&tlbCfg.Levels, "levels", 1, "page-table levels walked on a TLB miss")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+
+	var (
+		refString []int
+		err       error
+	)
+	switch {
+	case *refs != "":
+		refString, err = loadReferences(strings.NewReader(*refs))
+	case *file != "":
+		refString, err = loadReferenceFile(*file)
+	case *gen > 0:
+		refString = generateReferences(rand.New(rand.NewSource(*seed)), *gen, *pages)
+	default:
+		err = fmt.Errorf("%w: pagesim needs -refs, -file or -gen", scheduler.ErrInvalidArgs)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, name := range strings.Split(*algo, ",") {
+		result, err := SimulatePaging(strings.TrimSpace(name), refString, *frames)
+		if err != nil {
+			return err
+		}
+		render.Title(w, result.Algorithm)
+		outputPageSteps(w, result, *frames)
+		if tlbCfg.Entries > 0 {
+			stats, err := MeasureTranslation(result, tlbCfg)
+			if err != nil {
+				return err
+			}
+			outputTranslation(w, stats, tlbCfg)
+		}
+	}
+
+	return nil
+}
+
+func loadReferenceFile(name string) ([]int, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("%v: error opening reference file", err)
+	}
+	defer f.Close()
+
+	return loadReferences(f)
+}
+
+// loadReferences reads page numbers separated by commas and/or whitespace.
+func loadReferences(r io.Reader) ([]int, error) {
+	var refs []int
+	sc := bufio.NewScanner(r)
+	sc.Split(bufio.ScanWords)
+	for sc.Scan() {
+		for _, field := range strings.Split(sc.Text(), ",") {
+			if field == "" {
+				continue
+			}
+			page, err := strconv.Atoi(field)
+			if err != nil || page < 0 {
+				return nil, fmt.Errorf("%w: bad page reference %q", scheduler.ErrInvalidArgs, field)
+			}
+			refs = append(refs, page)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("%w: reading reference string", err)
+	}
+	if len(refs) == 0 {
+		return nil, errors.New("reference string is empty")
+	}
+
+	return refs, nil
+}
+
+func generateReferences(rng *rand.Rand, length, pages int) []int {
+	refs := make([]int, length)
+	for i := range refs {
+		refs[i] = rng.Intn(pages)
+	}
+	return refs
+}
+
+func outputPageSteps(w io.Writer, result PageResult, frames int) {
+	_, _ = fmt.Fprintln(w, "Frame table")
+	table := tablewriter.NewWriter(w)
+	header := []string{"Step", "Ref"}
+	for i := 0; i < frames; i++ {
+		header = append(header, fmt.Sprintf("F%d", i))
+	}
+	table.SetHeader(append(header, "Fault"))
+	for i, step := range result.Steps {
+		row := []string{fmt.Sprint(i + 1), fmt.Sprint(step.Ref)}
+		for _, page := range step.Frames {
+			if page == emptyFrame {
+				row = append(row, "")
+			} else {
+				row = append(row, fmt.Sprint(page))
+			}
+		}
+		fault := ""
+		if step.Fault {
+			fault = "*"
+		}
+		table.Append(append(row, fault))
+	}
+	footer := make([]string, len(header)+1)
+	footer[len(footer)-2] = "Faults"
+	footer[len(footer)-1] = fmt.Sprintf("%d (%.0f%%)", result.Faults, 100*float64(result.Faults)/float64(len(result.Steps)))
+	table.SetFooter(footer)
+	table.Render()
+	_, _ = fmt.Fprintln(w)
+}
+
+func outputTranslation(w io.Writer, stats TranslationStats, cfg TLBConfig) {
+	_, _ = fmt.Fprintln(w, "Translation timing")
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"TLB", "Hits", "Misses", "Hit ratio", "Measured EAT", "Formula EAT"})
+	ways := cfg.Ways
+	if ways <= 0 {
+		ways = cfg.Entries
+	}
+	table.Append([]string{
+		fmt.Sprintf("%d entries, %d-way", cfg.Entries, ways),
+		fmt.Sprint(stats.HitCount),
+		fmt.Sprint(stats.MissCount),
+		fmt.Sprintf("%.2f", float64(stats.HitCount)/float64(len(stats.Hits))),
+		fmt.Sprintf("%.2f", stats.MeasuredAT),
+		fmt.Sprintf("%.2f", stats.FormulaAT),
+	})
+	table.Render()
+	_, _ = fmt.Fprintln(w)
+}
+
+//endregion
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestSimulatePaging(t *testing.T) {
+	t.Parallel()
+	textbook := []int{7, 0, 1, 2, 0, 3, 0, 4, 2, 3, 0, 3, 2, 1, 2, 0, 1, 7, 0, 1}
+	belady := []int{1, 2, 3, 4, 1, 2, 5, 1, 2, 3, 4, 5}
+	tests := []struct {
+		name      string
+		algorithm string
+		refs      []int
+		frames    int
+		want      int
+	}{
+		{name: "fifo", algorithm: "fifo", refs: textbook, frames: 3, want: 15},
+		{name: "lru", algorithm: "lru", refs: textbook, frames: 3, want: 12},
+		{name: "optimal", algorithm: "optimal", refs: textbook, frames: 3, want: 9},
+		{name: "clock", algorithm: "clock", refs: textbook, frames: 3, want: 14},
+		{name: "second chance", algorithm: "second-chance", refs: textbook, frames: 3, want: 14},
+		{name: "belady 3 frames", algorithm: "fifo", refs: belady, frames: 3, want: 9},
+		{name: "belady 4 frames", algorithm: "fifo", refs: belady, frames: 4, want: 10},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := SimulatePaging(tt.algorithm, tt.refs, tt.frames)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Faults != tt.want {
+				t.Errorf("SimulatePaging() faults = %d, want %d", got.Faults, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimulatePaging_steps(t *testing.T) {
+	t.Parallel()
+	got, err := SimulatePaging("lru", []int{1, 2, 1, 3}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PageStep{
+		{Ref: 1, Frames: []int{1, emptyFrame}, Fault: true},
+		{Ref: 2, Frames: []int{1, 2}, Fault: true},
+		{Ref: 1, Frames: []int{1, 2}},
+		{Ref: 3, Frames: []int{1, 3}, Fault: true},
+	}
+	if !reflect.DeepEqual(got.Steps, want) {
+		t.Errorf("SimulatePaging() steps = %v, want %v", got.Steps, want)
+	}
+}
+
+func Test_loadReferences(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		in      string
+		want    []int
+		wantErr error
+	}{
+		{name: "commas", in: "7,0,1", want: []int{7, 0, 1}},
+		{name: "mixed", in: "7 0,1\n2", want: []int{7, 0, 1, 2}},
+		{name: "bad page", in: "7,x", wantErr: scheduler.ErrInvalidArgs},
+		{name: "negative page", in: "-1", wantErr: scheduler.ErrInvalidArgs},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := loadReferences(strings.NewReader(tt.in))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadReferences() = %v, want %v", got, tt.want)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTLB(t *testing.T) {
+	t.Parallel()
+	tlb, err := NewTLB(4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, page := range []int{0, 2, 4} {
+		if tlb.Lookup(page) {
+			t.Fatalf("Lookup(%d) hit in a cold TLB", page)
+		}
+		tlb.Insert(page)
+	}
+	for page, want := range map[int]bool{0: false, 2: true, 4: true} {
+		if got := tlb.Lookup(page); got != want {
+			t.Errorf("Lookup(%d) = %v, want %v", page, got, want)
+		}
+	}
+	if _, err := NewTLB(3, 2); !errors.Is(err, scheduler.ErrInvalidArgs) {
+		t.Errorf("NewTLB(3, 2) error = %v, want %v", err, scheduler.ErrInvalidArgs)
+	}
+}
+
+func TestMeasureTranslation(t *testing.T) {
+	t.Parallel()
+	cfg := TLBConfig{Entries: 2, TLBTime: 20, MemTime: 100, Levels: 1}
+	tests := []struct {
+		name      string
+		algorithm string
+		refs      []int
+		wantHits  int
+		wantEAT   float64
+	}{
+		{name: "hit after load", algorithm: "lru", refs: []int{1, 2, 1, 3}, wantHits: 1, wantEAT: 195},
+		{name: "evicted page is invalidated", algorithm: "fifo", refs: []int{1, 2, 3, 1}, wantHits: 0, wantEAT: 220},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := SimulatePaging(tt.algorithm, tt.refs, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := MeasureTranslation(result, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.HitCount != tt.wantHits {
+				t.Errorf("MeasureTranslation() hits = %d, want %d", got.HitCount, tt.wantHits)
+			}
+			if got.MeasuredAT != tt.wantEAT || got.FormulaAT != tt.wantEAT {
+				t.Errorf("MeasureTranslation() EAT = %.2f measured, %.2f formula, want %.2f", got.MeasuredAT, got.FormulaAT, tt.wantEAT)
+			}
+		})
+	}
+}
+
+func TestEffectiveAccessTime(t *testing.T) {
+	t.Parallel()
+	if got := EffectiveAccessTime(0.8, 0, TLBConfig{TLBTime: 20, MemTime: 100}); got != 140 {
+		t.Errorf("EffectiveAccessTime() = %v, want 140", got)
+	}
+}
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region Parquet writer
+
+// parquetType is a Parquet physical type. Only the ones the exports need are
+// supported; strings are BYTE_ARRAY columns annotated as UTF8.
+type parquetType int32
+
+const (
+	parquetInt64     parquetType = 2
+	parquetDouble    parquetType = 5
+	parquetByteArray parquetType = 6
+)
+
+type (
+	// parquetColumn is one required column of a flat table. Exactly one of the
+	// value slices is used, chosen by kind.
+	parquetColumn struct {
+		name    string
+		kind    parquetType
+		int64s  []int64
+		doubles []float64
+		strings []string
+	}
+	// ParquetTable is a flat table written as one Parquet row group with an
+	// uncompressed, PLAIN-encoded page per column, which pandas, Polars and
+	// DuckDB all read.
+	ParquetTable struct {
+		columns []*parquetColumn
+		rows    int
+	}
+)
+
+func (t *ParquetTable) column(name string, kind parquetType) *parquetColumn {
+	for _, c := range t.columns {
+		if c.name == name {
+			return c
+		}
+	}
+	c := &parquetColumn{name: name, kind: kind}
+	t.columns = append(t.columns, c)
+	return c
+}
+
+// AppendInt64 appends v to the named column, creating it on first use.
+func (t *ParquetTable) AppendInt64(name string, v int64) {
+	c := t.column(name, parquetInt64)
+	c.int64s = append(c.int64s, v)
+}
+
+// AppendDouble appends v to the named column, creating it on first use.
+func (t *ParquetTable) AppendDouble(name string, v float64) {
+	c := t.column(name, parquetDouble)
+	c.doubles = append(c.doubles, v)
+}
+
+// AppendString appends v to the named column, creating it on first use.
+func (t *ParquetTable) AppendString(name string, v string) {
+	c := t.column(name, parquetByteArray)
+	c.strings = append(c.strings, v)
+}
+
+// EndRow finishes a row; every column must have had one value since the last.
+func (t *ParquetTable) EndRow() {
+	t.rows++
+}
+
+func (c *parquetColumn) len() int {
+	switch c.kind {
+	case parquetInt64:
+		return len(c.int64s)
+	case parquetDouble:
+		return len(c.doubles)
+	default:
+		return len(c.strings)
+	}
+}
+
+func (c *parquetColumn) plain() []byte {
+	var b bytes.Buffer
+	var buf [8]byte
+	switch c.kind {
+	case parquetInt64:
+		for _, v := range c.int64s {
+			binary.LittleEndian.PutUint64(buf[:], uint64(v))
+			b.Write(buf[:])
+		}
+	case parquetDouble:
+		for _, v := range c.doubles {
+			binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+			b.Write(buf[:])
+		}
+	default:
+		for _, v := range c.strings {
+			binary.LittleEndian.PutUint32(buf[:4], uint32(len(v)))
+			b.Write(buf[:4])
+			b.WriteString(v)
+		}
+	}
+	return b.Bytes()
+}
+
+// WriteTo writes the table as a complete Parquet file.
+func (t *ParquetTable) WriteTo(w io.Writer) (int64, error) {
+	for _, c := range t.columns {
+		if c.len() != t.rows {
+			return 0, fmt.Errorf("%w: parquet column %s has %d values for %d rows", scheduler.ErrInvalidArgs, c.name, c.len(), t.rows)
+		}
+	}
+
+	var file bytes.Buffer
+	file.WriteString("PAR1")
+	var total int64
+	chunks := make(thriftList, len(t.columns))
+	for i, c := range t.columns {
+		data := c.plain()
+		header := thriftStruct{
+			{1, int32(0)}, // DATA_PAGE
+			{2, int32(len(data))},
+			{3, int32(len(data))},
+			{5, thriftStruct{
+				{1, int32(t.rows)},
+				{2, int32(0)}, // PLAIN
+				{3, int32(3)}, // RLE definition levels, none for required columns
+				{4, int32(3)}, // RLE repetition levels
+			}},
+		}
+		offset := int64(file.Len())
+		headerBytes := header.encode()
+		file.Write(headerBytes)
+		file.Write(data)
+		size := int64(len(headerBytes) + len(data))
+		total += size
+
+		chunks[i] = thriftStruct{
+			{2, offset},
+			{3, thriftStruct{
+				{1, int32(c.kind)},
+				{2, thriftList{int32(0)}},
+				{3, thriftList{c.name}},
+				{4, int32(0)}, // UNCOMPRESSED
+				{5, int64(t.rows)},
+				{6, size},
+				{7, size},
+				{9, offset},
+			}},
+		}
+	}
+
+	schema := thriftList{thriftStruct{{4, "schema"}, {5, int32(len(t.columns))}}}
+	for _, c := range t.columns {
+		element := thriftStruct{{1, int32(c.kind)}, {3, int32(0)}, {4, c.name}} // REQUIRED
+		if c.kind == parquetByteArray {
+			element = append(element, thriftField{6, int32(0)}) // UTF8
+		}
+		schema = append(schema, element)
+	}
+	meta := thriftStruct{
+		{1, int32(1)},
+		{2, schema},
+		{3, int64(t.rows)},
+		{4, thriftList{thriftStruct{{1, chunks}, {2, total}, {3, int64(t.rows)}}}},
+		{6, "CSCE4600 scheduler"},
+	}
+	footer := meta.encode()
+	file.Write(footer)
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(footer)))
+	file.Write(size[:])
+	file.WriteString("PAR1")
+
+	return file.WriteTo(w)
+}
+
+// Thrift compact protocol, just enough for Parquet metadata.
+type (
+	thriftField struct {
+		id    int16
+		value interface{}
+	}
+	thriftStruct []thriftField
+	thriftList   []interface{}
+)
+
+const (
+	thriftTypeI32    = 5
+	thriftTypeI64    = 6
+	thriftTypeBinary = 8
+	thriftTypeList   = 9
+	thriftTypeStruct = 12
+)
+
+func thriftTypeOf(v interface{}) byte {
+	switch v.(type) {
+	case int32:
+		return thriftTypeI32
+	case int64:
+		return thriftTypeI64
+	case string:
+		return thriftTypeBinary
+	case thriftList:
+		return thriftTypeList
+	default:
+		return thriftTypeStruct
+	}
+}
+
+func (s thriftStruct) encode() []byte {
+	var b bytes.Buffer
+	s.write(&b)
+	return b.Bytes()
+}
+
+func (s thriftStruct) write(b *bytes.Buffer) {
+	var last int16
+	for _, f := range s {
+		typ := thriftTypeOf(f.value)
+		if delta := f.id - last; delta > 0 && delta <= 15 {
+			b.WriteByte(byte(delta)<<4 | typ)
+		} else {
+			b.WriteByte(typ)
+			writeVarint(b, zigzag(int64(f.id)))
+		}
+		last = f.id
+		writeThriftValue(b, f.value)
+	}
+	b.WriteByte(0)
+}
+
+func writeThriftValue(b *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case int32:
+		writeVarint(b, zigzag(int64(v)))
+	case int64:
+		writeVarint(b, zigzag(v))
+	case string:
+		writeVarint(b, uint64(len(v)))
+		b.WriteString(v)
+	case thriftList:
+		var elem byte = thriftTypeStruct
+		if len(v) > 0 {
+			elem = thriftTypeOf(v[0])
+		}
+		if len(v) < 15 {
+			b.WriteByte(byte(len(v))<<4 | elem)
+		} else {
+			b.WriteByte(0xF0 | elem)
+			writeVarint(b, uint64(len(v)))
+		}
+		for _, e := range v {
+			writeThriftValue(b, e)
+		}
+	case thriftStruct:
+		v.write(b)
+	}
+}
+
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+func writeVarint(b *bytes.Buffer, v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	b.Write(buf[:n])
+}
+
+//endregion
+
+//region Parquet exports
+
+// appendProcessRows adds one row per process per scheduler run.
+func appendProcessRows(t *ParquetTable, workload string, results []scheduler.RunResult) {
+	for _, r := range results {
+		for _, m := range r.Processes {
+			t.AppendString("workload", workload)
+			t.AppendString("scheduler", r.Scheduler)
+			t.AppendInt64("quantum", r.Quantum)
+			t.AppendInt64("pid", m.PID)
+			t.AppendInt64("arrival", m.Arrival)
+			t.AppendInt64("burst", m.Burst)
+			t.AppendInt64("priority", m.Priority)
+			t.AppendInt64("response", m.Response)
+			t.AppendInt64("wait", m.Wait)
+			t.AppendInt64("turnaround", m.Turnaround)
+			t.AppendInt64("exit", m.Exit)
+			t.AppendDouble("avg_wait", r.AvgWait)
+			t.AppendDouble("avg_turnaround", r.AvgTurnaround)
+			t.AppendDouble("throughput", r.Throughput)
+			t.EndRow()
+		}
+	}
+}
+
+// appendEventRows adds one row per engine event per scheduler run.
+func appendEventRows(t *ParquetTable, workload string, results []scheduler.RunResult) {
+	for _, r := range results {
+		for _, ev := range r.Events {
+			t.AppendString("workload", workload)
+			t.AppendString("scheduler", r.Scheduler)
+			t.AppendInt64("quantum", r.Quantum)
+			t.AppendInt64("time", ev.Time)
+			t.AppendString("kind", ev.Kind.String())
+			t.AppendInt64("pid", ev.PID)
+			t.AppendString("note", ev.Note)
+			t.EndRow()
+		}
+	}
+}
+
+//endregion
+
+//region export command
+
+// runExport is the `export` subcommand:
+// `export [-schedulers fcfs,rr] [-quantum 2] -processes p.parquet [-events e.parquet] workload.csv...`.
+// Each workload, with glob patterns expanded, runs through every scheduler and
+// all rows land in the same files, tagged with the workload's file name.
+func runExport(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	schedulers := fs.String("schedulers", strings.Join(scheduler.SortedSchedulerNames(), ","), "comma-separated schedulers to run")
+	quantum := fs.Int64("quantum", scheduler.DefaultQuantum, "round-robin quantum")
+	processesOut := fs.String("processes", "processes.parquet", "per-process results file")
+	eventsOut := fs.String("events", "", "event trace file, skipped if empty")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+	jobs, err := loadBatchJobs(fs.Args())
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, name := range strings.Split(*schedulers, ",") {
+		names = append(names, strings.TrimSpace(name))
+	}
+	results, err := Batch{Schedulers: names, Quantum: *quantum}.Run(jobs)
+	if err != nil {
+		return err
+	}
+	processTable, eventTable := &ParquetTable{}, &ParquetTable{}
+	for _, b := range results {
+		appendProcessRows(processTable, b.Workload, b.Results)
+		appendEventRows(eventTable, b.Workload, b.Results)
+	}
+
+	if err := writeParquetFile(*processesOut, processTable); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(w, "Wrote %d process rows to %s\n", processTable.rows, *processesOut)
+	if *eventsOut != "" {
+		if err := writeParquetFile(*eventsOut, eventTable); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(w, "Wrote %d event rows to %s\n", eventTable.rows, *eventsOut)
+	}
+	return nil
+}
+
+func writeParquetFile(path string, t *ParquetTable) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	if _, err := t.WriteTo(bw); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+//endregion
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParquetTable_WriteTo(t *testing.T) {
+	t.Parallel()
+	table := &ParquetTable{}
+	for _, row := range []struct {
+		name  string
+		pid   int64
+		value float64
+	}{{"fcfs", 1, 0.5}, {"rr", -2, 1.25}} {
+		table.AppendString("scheduler", row.name)
+		table.AppendInt64("pid", row.pid)
+		table.AppendDouble("value", row.value)
+		table.EndRow()
+	}
+	var buf bytes.Buffer
+	if _, err := table.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	file := buf.Bytes()
+
+	if string(file[:4]) != "PAR1" || string(file[len(file)-4:]) != "PAR1" {
+		t.Fatal("missing PAR1 magic")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	meta := readThriftStruct(t, bufio.NewReader(bytes.NewReader(file[len(file)-8-footerLen:])))
+	if meta[3] != int64(2) {
+		t.Errorf("num_rows = %v, want 2", meta[3])
+	}
+	schema := meta[2].([]interface{})
+	var names []string
+	for _, e := range schema[1:] {
+		names = append(names, string(e.(map[int16]interface{})[4].([]byte)))
+	}
+	if want := []string{"scheduler", "pid", "value"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("schema columns = %v, want %v", names, want)
+	}
+
+	// Read each column's page back through its chunk's data_page_offset.
+	chunks := meta[4].([]interface{})[0].(map[int16]interface{})[1].([]interface{})
+	page := func(i int) []byte {
+		md := chunks[i].(map[int16]interface{})[3].(map[int16]interface{})
+		r := bufio.NewReader(bytes.NewReader(file[md[9].(int64):]))
+		header := readThriftStruct(t, r)
+		data := make([]byte, header[3].(int64))
+		if _, err := r.Read(data); err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	if got := page(0); !bytes.Equal(got, []byte("\x04\x00\x00\x00fcfs\x02\x00\x00\x00rr")) {
+		t.Errorf("scheduler page = %q", got)
+	}
+	if got := page(1); int64(binary.LittleEndian.Uint64(got[8:])) != -2 {
+		t.Errorf("pid page = %v, want second value -2", got)
+	}
+	if got := page(2); math.Float64frombits(binary.LittleEndian.Uint64(got[8:])) != 1.25 {
+		t.Errorf("value page = %v, want second value 1.25", got)
+	}
+}
+
+func TestParquetTable_WriteTo_raggedColumns(t *testing.T) {
+	t.Parallel()
+	table := &ParquetTable{}
+	table.AppendInt64("pid", 1)
+	table.AppendInt64("pid", 2)
+	table.EndRow()
+	if _, err := table.WriteTo(&bytes.Buffer{}); err == nil {
+		t.Error("WriteTo() with more values than rows succeeded")
+	}
+}
+
+// readThriftStruct decodes a compact-protocol struct into field id → value,
+// with integers as int64, binaries as []byte, lists as []interface{} and
+// structs as maps.
+func readThriftStruct(t *testing.T, r *bufio.Reader) map[int16]interface{} {
+	t.Helper()
+	fields := map[int16]interface{}{}
+	var id int16
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b == 0 {
+			return fields
+		}
+		if delta := int16(b >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(readZigzag(t, r))
+		}
+		fields[id] = readThriftValue(t, r, b&0x0F)
+	}
+}
+
+func readThriftValue(t *testing.T, r *bufio.Reader, typ byte) interface{} {
+	t.Helper()
+	switch typ {
+	case thriftTypeI32, thriftTypeI64:
+		return readZigzag(t, r)
+	case thriftTypeBinary:
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := make([]byte, n)
+		if _, err := r.Read(b); err != nil && n > 0 {
+			t.Fatal(err)
+		}
+		return b
+	case thriftTypeList:
+		h, err := r.ReadByte()
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := uint64(h >> 4)
+		if n == 15 {
+			if n, err = binary.ReadUvarint(r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		list := make([]interface{}, n)
+		for i := range list {
+			list[i] = readThriftValue(t, r, h&0x0F)
+		}
+		return list
+	case thriftTypeStruct:
+		return readThriftStruct(t, r)
+	default:
+		t.Fatalf("unexpected thrift type %d", typ)
+		return nil
+	}
+}
+
+func readZigzag(t *testing.T, r *bufio.Reader) int64 {
+	t.Helper()
+	v, err := binary.ReadUvarint(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return int64(v>>1) ^ -int64(v&1)
+}
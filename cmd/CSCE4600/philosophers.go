@@ -0,0 +1,332 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/Sha-min/CSCE4600/pkg/render"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region Dining philosophers
+
+// Dining philosophers strategies.
+const (
+	// StrategyNaive picks up the left fork, then the right, and can deadlock.
+	StrategyNaive = "naive"
+	// StrategyOrdered picks up the lower-numbered fork first, breaking circular wait.
+	StrategyOrdered = "ordered"
+	// StrategyArbitrator asks a waiter (a mutex) for permission to pick up both forks.
+	StrategyArbitrator = "arbitrator"
+	// StrategyChandyMisra passes dirty/clean forks on request, which is also starvation-free.
+	StrategyChandyMisra = "chandy-misra"
+)
+
+var philosopherStrategies = []string{StrategyNaive, StrategyOrdered, StrategyArbitrator, StrategyChandyMisra}
+
+type (
+	// DiningPhilosophers describes a table of Seats philosophers who each eat
+	// Meals times. Think, Reach and Eat are CPU costs: Reach is the time between
+	// picking up the first fork and the second, which is what lets the naive
+	// strategy deadlock under round robin.
+	DiningPhilosophers struct {
+		Seats    int
+		Meals    int
+		Think    int64
+		Reach    int64
+		Eat      int64
+		Quantum  int64
+		Strategy string
+		Wakeup   scheduler.WakeupPolicy
+		// Starve is the longest a philosopher may wait for forks before the run
+		// counts it as starved; 0 uses the CPU time for every other seat to think
+		// and eat once.
+		Starve int64
+	}
+	// DiningResult is a finished dining philosophers run.
+	DiningResult struct {
+		scheduler.Trace
+		Strategy string
+		Meals    []int
+		Starved  []int64
+	}
+	// chandyMisra is the fork-passing protocol as a single sync object for the
+	// whole table. Fork f sits between seat f and seat f+1.
+	chandyMisra struct {
+		seat   map[int64]int
+		tasks  []*scheduler.Task
+		owner  []int
+		dirty  []bool
+		hungry []bool
+		eating []bool
+	}
+)
+
+// Workload builds one process per philosopher. The returned sync objects and
+// semaphore values depend on the strategy.
+func (d DiningPhilosophers) Workload() ([]scheduler.Process, map[string]int64, map[string]scheduler.SyncObject, error) {
+	if d.Seats < 2 || d.Meals <= 0 {
+		return nil, nil, nil, fmt.Errorf("%w: need at least 2 seats and 1 meal", scheduler.ErrInvalidArgs)
+	}
+	var (
+		processes = make([]scheduler.Process, d.Seats)
+		sems      = make(map[string]int64, d.Seats+1)
+		objects   map[string]scheduler.SyncObject
+	)
+	for f := 0; f < d.Seats; f++ {
+		sems[forkName(f)] = 1
+	}
+	switch d.Strategy {
+	case StrategyNaive, StrategyOrdered:
+	case StrategyArbitrator:
+		sems["waiter"] = 1
+	case StrategyChandyMisra:
+		objects = map[string]scheduler.SyncObject{"table": newChandyMisra(processes)}
+	default:
+		return nil, nil, nil, fmt.Errorf("%w: unknown strategy %q", scheduler.ErrInvalidArgs, d.Strategy)
+	}
+
+	for i := range processes {
+		p := scheduler.Process{ProcessID: int64(i + 1), Name: fmt.Sprintf("philosopher %d", i)}
+		first, second := forkName(i), forkName((i+1)%d.Seats)
+		if d.Strategy == StrategyOrdered && (i+1)%d.Seats < i {
+			first, second = second, first
+		}
+		for meal := 0; meal < d.Meals; meal++ {
+			p.BurstDuration += d.Think
+			if d.Strategy == StrategyChandyMisra {
+				p.Ops = append(p.Ops, scheduler.SyncOp{At: p.BurstDuration, Op: "hungry", Object: "table"})
+				p.BurstDuration += d.Reach + d.Eat
+				p.Ops = append(p.Ops, scheduler.SyncOp{At: p.BurstDuration, Op: "done", Object: "table"})
+				continue
+			}
+			if d.Strategy == StrategyArbitrator {
+				p.Ops = append(p.Ops, scheduler.SyncOp{At: p.BurstDuration, Op: scheduler.SemWait, Object: "waiter"})
+			}
+			p.Ops = append(p.Ops, scheduler.SyncOp{At: p.BurstDuration, Op: scheduler.SemWait, Object: first})
+			p.BurstDuration += d.Reach
+			p.Ops = append(p.Ops, scheduler.SyncOp{At: p.BurstDuration, Op: scheduler.SemWait, Object: second})
+			if d.Strategy == StrategyArbitrator {
+				p.Ops = append(p.Ops, scheduler.SyncOp{At: p.BurstDuration, Op: scheduler.SemSignal, Object: "waiter"})
+			}
+			p.BurstDuration += d.Eat
+			p.Ops = append(p.Ops,
+				scheduler.SyncOp{At: p.BurstDuration, Op: scheduler.SemSignal, Object: first},
+				scheduler.SyncOp{At: p.BurstDuration, Op: scheduler.SemSignal, Object: second})
+		}
+		processes[i] = p
+	}
+
+	return processes, sems, objects, nil
+}
+
+// Simulate runs the table through the engine and counts meals eaten and
+// philosophers who waited longer than the starvation threshold.
+func (d DiningPhilosophers) Simulate() (DiningResult, error) {
+	processes, sems, objects, err := d.Workload()
+	if err != nil {
+		return DiningResult{}, err
+	}
+	engine := scheduler.Engine{Queue: &scheduler.FIFOQueue{}, Quantum: d.Quantum, Semaphores: sems, Objects: objects, Wakeup: d.Wakeup}
+	result := DiningResult{Trace: engine.Simulate(processes), Strategy: d.Strategy, Meals: make([]int, d.Seats)}
+
+	starve := d.Starve
+	if starve <= 0 {
+		starve = int64(d.Seats-1) * (d.Think + d.Reach + d.Eat)
+	}
+	for i, t := range result.Tasks {
+		// Every meal ends with its last op, so completed ops count meals.
+		opsPerMeal := len(t.Ops) / d.Meals
+		result.Meals[i] = t.NextOp / opsPerMeal
+		if t.LongestBlock > starve {
+			result.Starved = append(result.Starved, t.ProcessID)
+		}
+	}
+
+	return result, nil
+}
+
+func forkName(f int) string {
+	return fmt.Sprintf("fork %d", f)
+}
+
+// newChandyMisra seats processes in order. Each fork starts dirty with the
+// lower-numbered of its two philosophers, which makes the precedence graph acyclic.
+func newChandyMisra(processes []scheduler.Process) *chandyMisra {
+	n := len(processes)
+	c := &chandyMisra{
+		seat:   make(map[int64]int, n),
+		tasks:  make([]*scheduler.Task, n),
+		owner:  make([]int, n),
+		dirty:  make([]bool, n),
+		hungry: make([]bool, n),
+		eating: make([]bool, n),
+	}
+	for f := 0; f < n; f++ {
+		c.owner[f] = f
+		if next := (f + 1) % n; next < f {
+			c.owner[f] = next
+		}
+		c.dirty[f] = true
+	}
+	for i := range processes {
+		c.seat[int64(i+1)] = i
+	}
+	return c
+}
+
+// Do handles "hungry", which requests both forks and blocks until the
+// philosopher holds them, and "done", which dirties the forks and hands them
+// to hungry neighbours.
+func (c *chandyMisra) Do(t *scheduler.Task, op string) (bool, []*scheduler.Task) {
+	i := c.seat[t.ProcessID]
+	c.tasks[i] = t
+	switch op {
+	case "hungry":
+		c.hungry[i] = true
+		return c.tryEat(i), nil
+	case "done":
+		c.eating[i] = false
+		var woken []*scheduler.Task
+		for _, f := range c.forks(i) {
+			c.dirty[f] = true
+		}
+		for _, q := range c.neighbours(i) {
+			if c.hungry[q] && c.tryEat(q) {
+				woken = append(woken, c.tasks[q])
+			}
+		}
+		return true, woken
+	default:
+		return true, nil
+	}
+}
+
+// tryEat requests philosopher i's missing forks. A holder gives up a fork only
+// when it is dirty and the holder is not eating; the fork is cleaned on the way.
+func (c *chandyMisra) tryEat(i int) bool {
+	forks := c.forks(i)
+	for _, f := range forks {
+		if holder := c.owner[f]; holder != i && c.dirty[f] && !c.eating[holder] {
+			c.owner[f] = i
+			c.dirty[f] = false
+		}
+	}
+	if c.owner[forks[0]] != i || c.owner[forks[1]] != i {
+		return false
+	}
+	c.hungry[i] = false
+	c.eating[i] = true
+	return true
+}
+
+func (c *chandyMisra) forks(i int) [2]int {
+	return [2]int{i, (i + len(c.owner) - 1) % len(c.owner)}
+}
+
+func (c *chandyMisra) neighbours(i int) []int {
+	n := len(c.owner)
+	left, right := (i+n-1)%n, (i+1)%n
+	if left == right {
+		return []int{left}
+	}
+	return []int{left, right}
+}
+
+//endregion
+
+//region philosophers command
+
+// runPhilosophers is the `philosophers` subcommand. With -strategy all it
+// compares every strategy on the same table; otherwise it shows one run in detail.
+func runPhilosophers(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("philosophers", flag.ContinueOnError)
+	var (
+		d        DiningPhilosophers
+		strategy string
+		wakeup   string
+	)
+	fs.IntVar(&d.Seats, "seats", 5, "number of philosophers")
+	fs.IntVar(&d.Meals, "meals", 3, "meals each philosopher eats")
+	fs.Int64Var(&d.Think, "think", 2, "CPU time spent thinking before each meal")
+	fs.Int64Var(&d.Reach, "reach", 1, "CPU time between picking up the first and second fork")
+	fs.Int64Var(&d.Eat, "eat", 3, "CPU time spent eating")
+	fs.Int64Var(&d.Quantum, "quantum", 1, "round-robin quantum; 0 runs each philosopher until it blocks")
+	fs.Int64Var(&d.Starve, "starve", 0, "longest acceptable wait for forks; 0 picks one from the table size")
+	fs.StringVar(&strategy, "strategy", "all", "all or one of "+strings.Join(philosopherStrategies, ", "))
+	fs.StringVar(&wakeup, "wakeup", scheduler.WakeFIFO.String(), "semaphore wakeup order: fifo, lifo or priority")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+	var err error
+	if d.Wakeup, err = scheduler.ParseWakeupPolicy(wakeup); err != nil {
+		return err
+	}
+
+	if strategy != "all" {
+		d.Strategy = strategy
+		result, err := d.Simulate()
+		if err != nil {
+			return err
+		}
+		render.Title(w, "Dining philosophers: "+strategy)
+		render.Gantt(w, result.Gantt)
+		outputSyncTable(w, result.Trace)
+		outputDining(w, []DiningResult{result})
+		return nil
+	}
+
+	results := make([]DiningResult, 0, len(philosopherStrategies))
+	for _, s := range philosopherStrategies {
+		d.Strategy = s
+		result, err := d.Simulate()
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+	}
+	render.Title(w, "Dining philosophers")
+	outputDining(w, results)
+
+	return nil
+}
+
+func outputDining(w io.Writer, results []DiningResult) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Strategy", "Deadlock", "Starved", "Meals", "Makespan", "Avg blocked"})
+	for _, r := range results {
+		deadlock := "no"
+		if len(r.Blocked) > 0 {
+			deadlock = "yes"
+		}
+		meals := make([]string, len(r.Meals))
+		var blocked int64
+		for i := range r.Meals {
+			meals[i] = fmt.Sprint(r.Meals[i])
+			blocked += r.Tasks[i].Blocked
+		}
+		// Deadlocked philosophers block forever, so only finished runs have
+		// a makespan and a meaningful average.
+		makespan, avg := "-", "-"
+		if len(r.Blocked) == 0 {
+			makespan = fmt.Sprint(r.Makespan())
+			avg = fmt.Sprintf("%.2f", float64(blocked)/float64(len(r.Tasks)))
+		}
+		table.Append([]string{
+			r.Strategy,
+			deadlock,
+			joinPIDs(r.Starved),
+			strings.Join(meals, " "),
+			makespan,
+			avg,
+		})
+	}
+	table.Render()
+	_, _ = fmt.Fprintln(w)
+}
+
+//endregion
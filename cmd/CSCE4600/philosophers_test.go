@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestDiningPhilosophers_Simulate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		strategy     string
+		wantDeadlock bool
+	}{
+		{name: "naive deadlocks", strategy: StrategyNaive, wantDeadlock: true},
+		{name: "ordered", strategy: StrategyOrdered},
+		{name: "arbitrator", strategy: StrategyArbitrator},
+		{name: "chandy-misra", strategy: StrategyChandyMisra},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			d := DiningPhilosophers{Seats: 5, Meals: 3, Think: 2, Reach: 1, Eat: 3, Quantum: 1, Strategy: tt.strategy}
+			result, err := d.Simulate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(result.Blocked) > 0; got != tt.wantDeadlock {
+				t.Fatalf("deadlock = %v, want %v", got, tt.wantDeadlock)
+			}
+			if tt.wantDeadlock {
+				return
+			}
+			for i, meals := range result.Meals {
+				if meals != d.Meals {
+					t.Errorf("philosopher %d ate %d meals, want %d", i, meals, d.Meals)
+				}
+			}
+		})
+	}
+}
+
+func TestDiningPhilosophers_Workload(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		d    DiningPhilosophers
+	}{
+		{name: "one seat", d: DiningPhilosophers{Seats: 1, Meals: 1, Strategy: StrategyNaive}},
+		{name: "no meals", d: DiningPhilosophers{Seats: 5, Strategy: StrategyNaive}},
+		{name: "unknown strategy", d: DiningPhilosophers{Seats: 5, Meals: 1, Strategy: "telepathy"}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, _, _, err := tt.d.Workload(); !errors.Is(err, scheduler.ErrInvalidArgs) {
+				t.Errorf("Workload() error = %v, want %v", err, scheduler.ErrInvalidArgs)
+			}
+		})
+	}
+}
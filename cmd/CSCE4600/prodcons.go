@@ -0,0 +1,153 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/Sha-min/CSCE4600/pkg/render"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region Producer–consumer
+
+// BoundedBuffer describes a producer–consumer run over a shared buffer of
+// Capacity slots, guarded by the classic empty/full/mutex semaphores. Step
+// costs are CPU time; each producer makes Items items and the consumers
+// share the total between them.
+type BoundedBuffer struct {
+	Producers int
+	Consumers int
+	Items     int
+	Capacity  int64
+	Produce   int64
+	Insert    int64
+	Remove    int64
+	Consume   int64
+	Quantum   int64
+	Wakeup    scheduler.WakeupPolicy
+}
+
+// BoundedBufferResult is a finished producer–consumer run.
+type BoundedBufferResult struct {
+	scheduler.Trace
+	Items            int
+	Throughput       float64
+	ProducerBlocking float64
+	ConsumerBlocking float64
+}
+
+// Workload turns the configuration into processes whose bursts are annotated
+// with the semaphore operations of the bounded-buffer protocol, plus the
+// initial semaphore values.
+func (b BoundedBuffer) Workload() ([]scheduler.Process, map[string]int64) {
+	processes := make([]scheduler.Process, 0, b.Producers+b.Consumers)
+	for i := 0; i < b.Producers; i++ {
+		p := scheduler.Process{ProcessID: int64(len(processes) + 1), Name: fmt.Sprintf("producer %d", i+1)}
+		for item := 0; item < b.Items; item++ {
+			p.BurstDuration += b.Produce
+			p.Ops = append(p.Ops,
+				scheduler.SyncOp{At: p.BurstDuration, Op: scheduler.SemWait, Object: "empty"},
+				scheduler.SyncOp{At: p.BurstDuration, Op: scheduler.SemWait, Object: "mutex"})
+			p.BurstDuration += b.Insert
+			p.Ops = append(p.Ops,
+				scheduler.SyncOp{At: p.BurstDuration, Op: scheduler.SemSignal, Object: "mutex"},
+				scheduler.SyncOp{At: p.BurstDuration, Op: scheduler.SemSignal, Object: "full"})
+		}
+		processes = append(processes, p)
+	}
+
+	total := b.Producers * b.Items
+	for i := 0; i < b.Consumers; i++ {
+		c := scheduler.Process{ProcessID: int64(len(processes) + 1), Name: fmt.Sprintf("consumer %d", i+1)}
+		items := total / b.Consumers
+		if i < total%b.Consumers {
+			items++
+		}
+		for item := 0; item < items; item++ {
+			c.Ops = append(c.Ops,
+				scheduler.SyncOp{At: c.BurstDuration, Op: scheduler.SemWait, Object: "full"},
+				scheduler.SyncOp{At: c.BurstDuration, Op: scheduler.SemWait, Object: "mutex"})
+			c.BurstDuration += b.Remove
+			c.Ops = append(c.Ops,
+				scheduler.SyncOp{At: c.BurstDuration, Op: scheduler.SemSignal, Object: "mutex"},
+				scheduler.SyncOp{At: c.BurstDuration, Op: scheduler.SemSignal, Object: "empty"})
+			c.BurstDuration += b.Consume
+		}
+		processes = append(processes, c)
+	}
+
+	return processes, map[string]int64{"empty": b.Capacity, "full": 0, "mutex": 1}
+}
+
+// Simulate runs the workload through the engine with a FIFO ready queue.
+func (b BoundedBuffer) Simulate() (BoundedBufferResult, error) {
+	if b.Producers <= 0 || b.Consumers <= 0 || b.Items <= 0 || b.Capacity <= 0 {
+		return BoundedBufferResult{}, fmt.Errorf("%w: producers, consumers, items and buffer size must be positive", scheduler.ErrInvalidArgs)
+	}
+	processes, sems := b.Workload()
+	engine := scheduler.Engine{Queue: &scheduler.FIFOQueue{}, Quantum: b.Quantum, Semaphores: sems, Wakeup: b.Wakeup}
+	result := BoundedBufferResult{Trace: engine.Simulate(processes), Items: b.Producers * b.Items}
+
+	for i, t := range result.Tasks {
+		if i < b.Producers {
+			result.ProducerBlocking += float64(t.Blocked) / float64(b.Producers)
+		} else {
+			result.ConsumerBlocking += float64(t.Blocked) / float64(b.Consumers)
+		}
+	}
+	if end := result.Makespan(); end > 0 && len(result.Blocked) == 0 {
+		result.Throughput = float64(result.Items) / float64(end)
+	}
+
+	return result, nil
+}
+
+//endregion
+
+//region prodcons command
+
+// runProdCons is the `prodcons` subcommand.
+func runProdCons(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("prodcons", flag.ContinueOnError)
+	var (
+		b      BoundedBuffer
+		wakeup string
+	)
+	fs.IntVar(&b.Producers, "producers", 2, "number of producers")
+	fs.IntVar(&b.Consumers, "consumers", 2, "number of consumers")
+	fs.IntVar(&b.Items, "items", 5, "items made by each producer")
+	fs.Int64Var(&b.Capacity, "buffer", 3, "buffer slots")
+	fs.Int64Var(&b.Produce, "produce", 2, "CPU time to produce an item")
+	fs.Int64Var(&b.Insert, "insert", 1, "CPU time to insert an item, inside the critical section")
+	fs.Int64Var(&b.Remove, "remove", 1, "CPU time to remove an item, inside the critical section")
+	fs.Int64Var(&b.Consume, "consume", 3, "CPU time to consume an item")
+	fs.Int64Var(&b.Quantum, "quantum", 0, "round-robin quantum; 0 runs each process until it blocks or finishes")
+	fs.StringVar(&wakeup, "wakeup", scheduler.WakeFIFO.String(), "semaphore wakeup order: fifo (strong) or lifo (weak)")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+	var err error
+	if b.Wakeup, err = scheduler.ParseWakeupPolicy(wakeup); err != nil {
+		return err
+	}
+
+	result, err := b.Simulate()
+	if err != nil {
+		return err
+	}
+
+	render.Title(w, "Producer-consumer")
+	render.Gantt(w, result.Gantt)
+	outputSyncTable(w, result.Trace)
+	if len(result.Blocked) > 0 {
+		_, _ = fmt.Fprintf(w, "Deadlock: %d processes blocked forever\n\n", len(result.Blocked))
+		return nil
+	}
+	_, _ = fmt.Fprintf(w, "Items: %d in %d time units, throughput %.2f/t\n", result.Items, result.Makespan(), result.Throughput)
+	_, _ = fmt.Fprintf(w, "Average blocking: producers %.2f, consumers %.2f\n\n", result.ProducerBlocking, result.ConsumerBlocking)
+
+	return nil
+}
+
+//endregion
@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestBoundedBuffer_Simulate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		buffer       BoundedBuffer
+		wantMakespan int64
+		wantBlocking bool
+	}{
+		{
+			name:         "defaults",
+			buffer:       BoundedBuffer{Producers: 2, Consumers: 2, Items: 5, Capacity: 3, Produce: 2, Insert: 1, Remove: 1, Consume: 3},
+			wantMakespan: 70,
+			wantBlocking: true,
+		},
+		{
+			name:         "single slot with round robin",
+			buffer:       BoundedBuffer{Producers: 1, Consumers: 3, Items: 6, Capacity: 1, Produce: 1, Insert: 1, Remove: 1, Consume: 1, Quantum: 2},
+			wantMakespan: 24,
+			wantBlocking: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := tt.buffer.Simulate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Blocked) > 0 {
+				t.Fatalf("Simulate() deadlocked with %d blocked processes", len(got.Blocked))
+			}
+			if end := got.Makespan(); end != tt.wantMakespan {
+				t.Errorf("Simulate() makespan = %d, want %d", end, tt.wantMakespan)
+			}
+			if blocking := got.ProducerBlocking+got.ConsumerBlocking > 0; blocking != tt.wantBlocking {
+				t.Errorf("Simulate() blocking = %v, want %v", blocking, tt.wantBlocking)
+			}
+		})
+	}
+}
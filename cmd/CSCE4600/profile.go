@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+//region Profiling
+
+// Profiles names the files to write profiles to; empty names are skipped:
+// • CPU     a pprof CPU profile of the whole run
+// • Memory  a pprof heap profile taken when the run ends
+// • Trace   an execution trace for `go tool trace`
+type Profiles struct {
+	CPU, Memory, Trace string
+}
+
+// Start begins the CPU profile and trace. The returned stop function ends them
+// and writes the heap profile, and must be called before the program exits.
+func (p Profiles) Start() (stop func() error, err error) {
+	var stops []func() error
+	stopAll := func() error {
+		var first error
+		for i := len(stops) - 1; i >= 0; i-- {
+			if err := stops[i](); err != nil && first == nil {
+				first = err
+			}
+		}
+		return first
+	}
+
+	if p.CPU != "" {
+		f, err := os.Create(p.CPU)
+		if err != nil {
+			return nil, fmt.Errorf("%v: creating CPU profile", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("%v: starting CPU profile", err)
+		}
+		stops = append(stops, func() error {
+			pprof.StopCPUProfile()
+			return f.Close()
+		})
+	}
+	if p.Trace != "" {
+		f, err := os.Create(p.Trace)
+		if err != nil {
+			_ = stopAll()
+			return nil, fmt.Errorf("%v: creating trace", err)
+		}
+		if err := trace.Start(f); err != nil {
+			_ = f.Close()
+			_ = stopAll()
+			return nil, fmt.Errorf("%v: starting trace", err)
+		}
+		stops = append(stops, func() error {
+			trace.Stop()
+			return f.Close()
+		})
+	}
+	if p.Memory != "" {
+		stops = append(stops, func() error {
+			f, err := os.Create(p.Memory)
+			if err != nil {
+				return fmt.Errorf("%v: creating memory profile", err)
+			}
+			runtime.GC()
+			if err := pprof.WriteHeapProfile(f); err != nil {
+				_ = f.Close()
+				return fmt.Errorf("%v: writing memory profile", err)
+			}
+			return f.Close()
+		})
+	}
+
+	return stopAll, nil
+}
+
+//endregion
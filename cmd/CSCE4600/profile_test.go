@@ -0,0 +1,35 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	dir := t.TempDir()
+	p := Profiles{
+		CPU:    filepath.Join(dir, "cpu.pprof"),
+		Memory: filepath.Join(dir, "mem.pprof"),
+		Trace:  filepath.Join(dir, "trace.out"),
+	}
+	stop, err := p.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	CooperativeSchedule(io.Discard, "Cooperative", mustLoadProcesses(t, "example_processes.csv"))
+	if err := stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{p.CPU, p.Memory, p.Trace} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() == 0 {
+			t.Errorf("%s is empty", filepath.Base(name))
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+// TestSchedulerInvariants checks what must hold for any scheduler on any
+// workload, over randomly generated workloads and tunables, yields, donations,
+// locks and semaphores, group caps, carried quanta and aging included:
+// • each CPU's Gantt slices come in time order and never overlap
+// • idle slices fill every gap, from time 0 on
+// • no process runs before it arrives
+// • each process gets exactly its burst of CPU time, so the total matches too
+// • turnaround is exit minus arrival, and waiting is never negative
+func TestSchedulerInvariants(t *testing.T) {
+	t.Parallel()
+	const workloads = 300
+	rng := rand.New(rand.NewSource(4600))
+
+	for _, name := range scheduler.SortedSchedulerNames() {
+		name := name
+		seed := rng.Int63()
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			rng := rand.New(rand.NewSource(seed))
+			for i := 0; i < workloads; i++ {
+				processes := randomWorkload(t, rng)
+				params := randomParams(rng, name)
+				result, err := scheduler.RunSchedulerParams(name, params, processes)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if msg := checkInvariants(processes, result); msg != "" {
+					t.Fatalf("%s (%+v) on %+v: %s", name, params, processes, msg)
+				}
+			}
+		})
+	}
+}
+
+// randomParams picks tunables for a run of name. Several CPUs leave out the
+// ones that run on a single CPU; each of those is otherwise on by chance.
+func randomParams(rng *rand.Rand, name string) scheduler.SchedulerParams {
+	params := scheduler.SchedulerParams{Quantum: rng.Int63n(4) + 1, SwitchCost: rng.Int63n(3)}
+	if info, _ := scheduler.LookupScheduler(name); info.MultiCPU {
+		params.CPUs = rng.Intn(3) + 1
+	}
+	if params.CPUs > 1 {
+		return params
+	}
+	if rng.Intn(3) == 0 {
+		params.Caps = map[string]int64{"a": rng.Int63n(100) + 1}
+		if rng.Intn(2) == 0 {
+			params.Caps["b"] = rng.Int63n(100) + 1
+		}
+		params.CapPeriod = rng.Int63n(12)
+	}
+	if rng.Intn(3) == 0 {
+		params.Carry = scheduler.CarryPolicy(rng.Intn(3))
+		if params.Carry == scheduler.CarryBank {
+			params.BankCap = rng.Int63n(8)
+		}
+	}
+	if rng.Intn(3) == 0 {
+		params.AgingPolicy = &scheduler.AgingPolicy{Every: rng.Int63n(5) + 1, Cap: rng.Int63n(3)}
+	}
+	params.Inherit = rng.Intn(2) == 0
+	return params
+}
+
+// randomWorkload writes a random CSV workload and loads it like any other.
+// Some processes yield, some of those donating to another, and some are in
+// group a or b. Some take the one mutex for a stretch of their burst, and
+// some of the others signal or wait on the one semaphore, never waiting more
+// times in all than it is signalled, so no workload can deadlock.
+func randomWorkload(t *testing.T, rng *rand.Rand) []scheduler.Process {
+	var csv strings.Builder
+	n := rng.Intn(8) + 1
+	var signals int
+	for pid := 1; pid <= n; pid++ {
+		cpu := rng.Intn(10) + 1
+		burst := fmt.Sprint(cpu)
+		ioAt := -1
+		if cpu > 1 && rng.Intn(3) == 0 {
+			ioAt = rng.Intn(cpu-1) + 1
+			burst = fmt.Sprintf("\"%d,io:%d,%d\"", ioAt, rng.Intn(5)+1, cpu-ioAt)
+		}
+		var yields []string
+		for at := 1; at < cpu; at++ {
+			if at != ioAt && rng.Intn(4) == 0 {
+				yields = append(yields, fmt.Sprint(at))
+			}
+		}
+		var donee string
+		if len(yields) > 0 && n > 1 && rng.Intn(2) == 0 {
+			d := rng.Intn(n-1) + 1
+			if d >= pid {
+				d++
+			}
+			donee = fmt.Sprint(d)
+		}
+		group := [...]string{"", "a", "b"}[rng.Intn(3)]
+		var ops string
+		switch rng.Intn(4) {
+		case 0:
+			lock := rng.Intn(cpu)
+			ops = fmt.Sprintf("%d:lock:m;%d:unlock:m", lock, lock+rng.Intn(cpu-lock))
+		case 1:
+			ops = fmt.Sprintf("%d:V:s", rng.Intn(cpu))
+			signals++
+		case 2:
+			if signals > 0 {
+				ops = fmt.Sprintf("%d:P:s", rng.Intn(cpu))
+				signals--
+			}
+		}
+		_, _ = fmt.Fprintf(&csv, "%d,%s,%d,%d,%s,%s,%s,%s\n", pid, burst, rng.Intn(20), rng.Intn(5),
+			strings.Join(yields, ";"), donee, group, ops)
+	}
+	processes, err := loader.LoadCSV(strings.NewReader(csv.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return processes
+}
+
+// checkInvariants returns what is wrong with result, or "" if nothing is.
+func checkInvariants(processes []scheduler.Process, result scheduler.RunResult) string {
+	last := map[int]scheduler.TimeSlice{}
+	for _, s := range result.Gantt {
+		if prev, ok := last[s.CPU]; ok && s.Start < prev.Start {
+			return fmt.Sprintf("slice %v comes after %v on CPU %d", s, prev, s.CPU)
+		}
+		last[s.CPU] = s
+	}
+	slices := append([]scheduler.TimeSlice(nil), result.Gantt...)
+	sort.Slice(slices, func(i, j int) bool {
+		if slices[i].CPU != slices[j].CPU {
+			return slices[i].CPU < slices[j].CPU
+		}
+		return slices[i].Start < slices[j].Start
+	})
+	for i := 1; i < len(slices); i++ {
+		if slices[i].CPU != slices[i-1].CPU {
+			if slices[i].Start != 0 {
+				return fmt.Sprintf("CPU %d starts with %v, not at 0", slices[i].CPU, slices[i])
+			}
+			continue
+		}
+		if slices[i].Start < slices[i-1].Stop {
+			return fmt.Sprintf("slices %v and %v overlap", slices[i-1], slices[i])
+		}
+		if slices[i].Start > slices[i-1].Stop {
+			return fmt.Sprintf("gap between %v and %v has no idle slice", slices[i-1], slices[i])
+		}
+	}
+	if len(slices) > 0 && slices[0].Start != 0 {
+		return fmt.Sprintf("first slice %v does not start at 0", slices[0])
+	}
+
+	arrival := map[int64]int64{}
+	var totalBurst int64
+	for _, p := range processes {
+		arrival[p.ProcessID] = p.ArrivalTime
+		totalBurst += p.BurstDuration
+	}
+	cpu := map[int64]int64{}
+	running := map[int64][]scheduler.TimeSlice{}
+	var totalCPU int64
+	for _, s := range slices {
+		if s.Stop <= s.Start {
+			return fmt.Sprintf("slice %v is empty", s)
+		}
+		if s.PID == scheduler.IdlePID || s.PID == scheduler.SwitchPID {
+			continue
+		}
+		if s.Start < arrival[s.PID] {
+			return fmt.Sprintf("slice %v starts before P%d arrives at %d", s, s.PID, arrival[s.PID])
+		}
+		for _, other := range running[s.PID] {
+			if s.Start < other.Stop && other.Start < s.Stop {
+				return fmt.Sprintf("P%d runs on two CPUs at once in %v and %v", s.PID, other, s)
+			}
+		}
+		running[s.PID] = append(running[s.PID], s)
+		cpu[s.PID] += s.Stop - s.Start
+		totalCPU += s.Stop - s.Start
+	}
+	if totalCPU != totalBurst {
+		return fmt.Sprintf("CPU time %d, want total burst %d", totalCPU, totalBurst)
+	}
+
+	for _, m := range result.Processes {
+		if cpu[m.PID] != m.Burst {
+			return fmt.Sprintf("P%d ran for %d, want its burst %d", m.PID, cpu[m.PID], m.Burst)
+		}
+		if m.Turnaround != m.Exit-m.Arrival {
+			return fmt.Sprintf("P%d turnaround %d, want exit %d - arrival %d", m.PID, m.Turnaround, m.Exit, m.Arrival)
+		}
+		if m.Wait < 0 {
+			return fmt.Sprintf("P%d waited %d", m.PID, m.Wait)
+		}
+		if m.Response < 0 || m.Response > m.Wait {
+			return fmt.Sprintf("P%d response %d outside [0, wait %d]", m.PID, m.Response, m.Wait)
+		}
+	}
+	return ""
+}
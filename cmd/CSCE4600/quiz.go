@@ -0,0 +1,139 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/render"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region Quiz generation
+
+// Quiz is one practice problem: a small workload to schedule by hand and the
+// answer key for it.
+type Quiz struct {
+	Seed      int64
+	Algorithm string
+	Quantum   int64
+	Processes []scheduler.Process
+	Answer    scheduler.RunResult
+}
+
+// GenerateQuiz makes an n-process problem for algorithm from seed, so the same
+// seed always gives the same problem:
+// • arrivals are spread out, with the first process arriving at 0
+// • bursts are 1–8 and priorities 0–4, small enough to work on paper
+func GenerateQuiz(seed int64, algorithm string, quantum int64, n int) (Quiz, error) {
+	if n < 1 {
+		return Quiz{}, fmt.Errorf("%w: a quiz needs at least one process", scheduler.ErrInvalidArgs)
+	}
+	processes, err := generateWorkload(rand.New(rand.NewSource(seed)), n)
+	if err != nil {
+		return Quiz{}, err
+	}
+	answer, err := scheduler.RunScheduler(algorithm, quantum, processes)
+	if err != nil {
+		return Quiz{}, err
+	}
+	return Quiz{
+		Seed:      seed,
+		Algorithm: algorithm,
+		Quantum:   answer.Quantum,
+		Processes: processes,
+		Answer:    answer,
+	}, nil
+}
+
+// generateWorkload makes n processes with the spread of arrivals, bursts and
+// priorities described on GenerateQuiz.
+func generateWorkload(rng *rand.Rand, n int) ([]scheduler.Process, error) {
+	var b strings.Builder
+	var arrival int
+	for pid := 1; pid <= n; pid++ {
+		_, _ = fmt.Fprintf(&b, "%d,%d,%d,%d\n", pid, rng.Intn(8)+1, arrival, rng.Intn(5))
+		arrival += rng.Intn(4)
+	}
+	return loader.LoadCSV(strings.NewReader(b.String()))
+}
+
+//endregion
+
+//region quiz command
+
+// runQuiz is the `quiz` subcommand:
+// `quiz [-algorithm rr] [-quantum 2] [-n 5] [-count 1] [-seed N] [-answers]`.
+// Each problem prints its seed, so rerunning with that -seed and -answers
+// gives its answer key.
+func runQuiz(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("quiz", flag.ContinueOnError)
+	algorithm := fs.String("algorithm", "fcfs", "scheduler to quiz on: "+strings.Join(scheduler.SortedSchedulerNames(), ", "))
+	quantum := fs.Int64("quantum", scheduler.DefaultQuantum, "round-robin quantum")
+	n := fs.Int("n", 5, "processes per problem")
+	count := fs.Int("count", 1, "number of problems")
+	seed := fs.Int64("seed", 0, "seed of the first problem; 0 picks one from the clock")
+	answers := fs.Bool("answers", false, "print the answer key after each problem")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano() % 1_000_000
+	}
+
+	for i := 0; i < *count; i++ {
+		quiz, err := GenerateQuiz(*seed+int64(i), *algorithm, *quantum, *n)
+		if err != nil {
+			return err
+		}
+		outputQuiz(w, i+1, quiz)
+		if *answers {
+			outputAnswerKey(w, quiz.Answer)
+		}
+	}
+	return nil
+}
+
+func outputQuiz(w io.Writer, number int, quiz Quiz) {
+	title := fmt.Sprintf("Problem %d (seed %d)", number, quiz.Seed)
+	render.Title(w, title)
+	task := fmt.Sprintf("Schedule these processes with %s", quiz.Algorithm)
+	if quiz.Algorithm == "rr" {
+		task += fmt.Sprintf(" using a quantum of %d", quiz.Quantum)
+	}
+	_, _ = fmt.Fprintf(w, "%s.\n", task)
+	_, _ = fmt.Fprintln(w, "Draw the Gantt chart and give each process's response, wait and")
+	_, _ = fmt.Fprintln(w, "turnaround time, and the averages.")
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"ID", "Priority", "Burst", "Arrival"})
+	for _, p := range quiz.Processes {
+		table.Append([]string{fmt.Sprint(p.ProcessID), fmt.Sprint(p.Priority), fmt.Sprint(p.BurstDuration), fmt.Sprint(p.ArrivalTime)})
+	}
+	table.Render()
+	_, _ = fmt.Fprintln(w)
+}
+
+func outputAnswerKey(w io.Writer, answer scheduler.RunResult) {
+	_, _ = fmt.Fprintln(w, "Answer key")
+	render.Gantt(w, scheduler.MergeSlices(answer.Gantt))
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"ID", "Response", "Wait", "Turnaround", "Exit"})
+	for _, m := range answer.Processes {
+		table.Append([]string{fmt.Sprint(m.PID), fmt.Sprint(m.Response), fmt.Sprint(m.Wait), fmt.Sprint(m.Turnaround), fmt.Sprint(m.Exit)})
+	}
+	table.SetFooter([]string{"", "",
+		fmt.Sprintf("Average\n%.2f", answer.AvgWait),
+		fmt.Sprintf("Average\n%.2f", answer.AvgTurnaround),
+		fmt.Sprintf("Throughput\n%.2f/t", answer.Throughput)})
+	table.Render()
+	_, _ = fmt.Fprintln(w)
+}
+
+//endregion
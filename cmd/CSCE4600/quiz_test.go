@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestGenerateQuiz(t *testing.T) {
+	t.Parallel()
+	for _, name := range scheduler.SortedSchedulerNames() {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			quiz, err := GenerateQuiz(42, name, 3, 6)
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, err := GenerateQuiz(42, name, 3, 6)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(quiz, again) {
+				t.Error("the same seed gave different quizzes")
+			}
+			if len(quiz.Processes) != 6 || quiz.Processes[0].ArrivalTime != 0 {
+				t.Errorf("processes = %+v, want 6 starting at time 0", quiz.Processes)
+			}
+			want, err := scheduler.RunScheduler(name, 3, quiz.Processes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(quiz.Answer, want) {
+				t.Errorf("answer = %+v, want %+v", quiz.Answer, want)
+			}
+		})
+	}
+}
+
+func TestRunQuiz(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		notWant []string
+		wantErr bool
+	}{
+		{
+			name:    "answers hidden",
+			args:    []string{"-seed", "7", "-count", "2"},
+			want:    []string{"Problem 1 (seed 7)", "Problem 2 (seed 8)", "with fcfs"},
+			notWant: []string{"Answer key"},
+		},
+		{
+			name: "answers shown",
+			args: []string{"-algorithm", "rr", "-quantum", "4", "-seed", "7", "-answers"},
+			want: []string{"quantum of 4", "Answer key", "Gantt schedule", "TURNAROUND"},
+		},
+		{name: "unknown scheduler", args: []string{"-algorithm", "mlfq"}, wantErr: true},
+		{name: "no processes", args: []string{"-n", "0"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var out bytes.Buffer
+			err := runQuiz(&out, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runQuiz() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(out.String(), s) {
+					t.Errorf("output missing %q:\n%s", s, out.String())
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(out.String(), s) {
+					t.Errorf("output has %q:\n%s", s, out.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/render"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region rm command
+
+// runRateMonotonic is the `rm` subcommand, which schedules periodic tasks by
+// rate monotonic over their hyperperiod: `rm [-json] tasks.csv`.
+func runRateMonotonic(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("rm", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the result as JSON")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: rm needs one file of periodic tasks", scheduler.ErrInvalidArgs)
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	tasks, err := loader.LoadPeriodicTasks(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fs.Arg(0), err)
+	}
+
+	result, err := scheduler.RunRateMonotonic(tasks)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fs.Arg(0), err)
+	}
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	outputRateMonotonic(w, result)
+	return nil
+}
+
+// outputRateMonotonic prints the tasks and their utilization, the run's
+// Gantt chart by task ID and every job's completion against its deadline,
+// then the Liu and Layland bound check and the misses.
+func outputRateMonotonic(w io.Writer, result scheduler.RMResult) {
+	render.Title(w, fmt.Sprintf("Rate monotonic (hyperperiod %d)", result.Hyperperiod))
+	_, _ = fmt.Fprintln(w, "Periodic tasks")
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"ID", "Period", "WCET", "Deadline", "Utilization"})
+	for _, t := range result.Tasks {
+		table.Append([]string{fmt.Sprint(t.ID), fmt.Sprint(t.Period), fmt.Sprint(t.WCET),
+			fmt.Sprint(t.RelativeDeadline()), fmt.Sprintf("%.3f", float64(t.WCET)/float64(t.Period))})
+	}
+	table.SetFooter([]string{"", "", "", "Total", fmt.Sprintf("%.3f", result.Utilization)})
+	table.Render()
+
+	render.Gantt(w, result.Run.Gantt)
+	_, _ = fmt.Fprintln(w, "Jobs")
+	table = tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Task", "Job", "Release", "Deadline", "Exit", "Response", "Met"})
+	for _, j := range result.Jobs {
+		met := "yes"
+		if j.Missed {
+			met = "no"
+		}
+		table.Append([]string{fmt.Sprint(j.Task), fmt.Sprint(j.Job), fmt.Sprint(j.Release),
+			fmt.Sprint(j.Deadline), fmt.Sprint(j.Exit), fmt.Sprint(j.Response), met})
+	}
+	table.Render()
+
+	n := len(result.Tasks)
+	switch {
+	case result.Utilization > 1:
+		_, _ = fmt.Fprintf(w, "Utilization %.3f is over 1: no scheduler can meet every deadline\n", result.Utilization)
+	case !result.BoundApplies:
+		_, _ = fmt.Fprintf(w, "Liu & Layland bound %.3f for %d tasks does not apply: some deadlines come before the period ends\n", result.Bound, n)
+	case result.Utilization <= result.Bound:
+		_, _ = fmt.Fprintf(w, "Utilization %.3f is within the Liu & Layland bound %.3f for %d tasks: rate monotonic meets every deadline\n", result.Utilization, result.Bound, n)
+	default:
+		_, _ = fmt.Fprintf(w, "Utilization %.3f is over the Liu & Layland bound %.3f for %d tasks: only the run can tell\n", result.Utilization, result.Bound, n)
+	}
+	_, _ = fmt.Fprintf(w, "%d of %d jobs missed their deadlines\n", result.Misses, len(result.Jobs))
+}
+
+//endregion
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestRunRateMonotonic(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		tasks        []scheduler.PeriodicTask
+		wantHyper    int64
+		wantExits    []int64
+		wantMisses   int
+		withinBound  bool
+		boundApplies bool
+	}{
+		{
+			name:         "within bound",
+			tasks:        []scheduler.PeriodicTask{{ID: 1, Period: 4, WCET: 1}, {ID: 2, Period: 8, WCET: 2}},
+			wantHyper:    8,
+			wantExits:    []int64{1, 5, 3},
+			withinBound:  true,
+			boundApplies: true,
+		},
+		{
+			// Utilization 0.9 is over the bound for three tasks, yet every
+			// job makes it; P1's releases preempt P3 and, at 16, P2.
+			name:         "over bound but schedulable",
+			tasks:        []scheduler.PeriodicTask{{ID: 1, Period: 4, WCET: 1}, {ID: 2, Period: 5, WCET: 2}, {ID: 3, Period: 20, WCET: 5}},
+			wantHyper:    20,
+			wantExits:    []int64{1, 5, 9, 13, 17, 3, 7, 12, 18, 15},
+			boundApplies: true,
+		},
+		{
+			// Full utilization: P1's second release holds P2's first job
+			// past its deadline at 6.
+			name:         "missed deadline",
+			tasks:        []scheduler.PeriodicTask{{ID: 2, Period: 6, WCET: 3}, {ID: 1, Period: 4, WCET: 2}},
+			wantHyper:    12,
+			wantExits:    []int64{7, 12, 2, 6, 10},
+			wantMisses:   1,
+			boundApplies: true,
+		},
+		{
+			name:        "constrained deadline",
+			tasks:       []scheduler.PeriodicTask{{ID: 1, Period: 4, WCET: 1, Deadline: 2}, {ID: 2, Period: 8, WCET: 2}},
+			wantHyper:   8,
+			wantExits:   []int64{1, 5, 3},
+			withinBound: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := scheduler.RunRateMonotonic(tt.tasks)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Hyperperiod != tt.wantHyper {
+				t.Errorf("Hyperperiod = %d, want %d", result.Hyperperiod, tt.wantHyper)
+			}
+			var exits []int64
+			misses := 0
+			for _, j := range result.Jobs {
+				exits = append(exits, j.Exit)
+				if j.Missed {
+					misses++
+				}
+			}
+			if !reflect.DeepEqual(exits, tt.wantExits) {
+				t.Errorf("job exits = %v, want %v", exits, tt.wantExits)
+			}
+			if result.Misses != tt.wantMisses || misses != tt.wantMisses {
+				t.Errorf("Misses = %d with %d jobs missed, want %d", result.Misses, misses, tt.wantMisses)
+			}
+			if got := result.Utilization <= result.Bound; got != tt.withinBound {
+				t.Errorf("utilization %.3f within bound %.3f = %v, want %v", result.Utilization, result.Bound, got, tt.withinBound)
+			}
+			if result.BoundApplies != tt.boundApplies {
+				t.Errorf("BoundApplies = %v, want %v", result.BoundApplies, tt.boundApplies)
+			}
+		})
+	}
+}
+
+func TestRunRateMonotonic_errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		tasks   []scheduler.PeriodicTask
+		wantErr string
+	}{
+		{name: "no tasks", wantErr: "no periodic tasks"},
+		{name: "zero period", tasks: []scheduler.PeriodicTask{{ID: 1, WCET: 1}}, wantErr: "task 1: period 0 is not positive"},
+		{name: "deadline after period", tasks: []scheduler.PeriodicTask{{ID: 1, Period: 4, WCET: 1, Deadline: 5}}, wantErr: "task 1: deadline 5 is after period 4"},
+		{name: "WCET past deadline", tasks: []scheduler.PeriodicTask{{ID: 1, Period: 4, WCET: 3, Deadline: 2}}, wantErr: "task 1: WCET 3 does not fit before deadline 2"},
+		{name: "duplicate ID", tasks: []scheduler.PeriodicTask{{ID: 1, Period: 4, WCET: 1}, {ID: 1, Period: 5, WCET: 1}}, wantErr: "task 1 given twice"},
+		{
+			name:    "hyperperiod too long",
+			tasks:   []scheduler.PeriodicTask{{ID: 1, Period: 1, WCET: 1}, {ID: 2, Period: 1_000_003, WCET: 1}},
+			wantErr: "hyperperiod releases more than 1000000 jobs",
+		},
+		{
+			name:    "hyperperiod overflows",
+			tasks:   []scheduler.PeriodicTask{{ID: 1, Period: math.MaxInt64 - 1, WCET: 1}, {ID: 2, Period: math.MaxInt64 - 2, WCET: 1}},
+			wantErr: "hyperperiod releases more than",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := scheduler.RunRateMonotonic(tt.tasks)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("RunRateMonotonic() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunRateMonotonicCommand(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	if err := runRateMonotonic(&out, []string{"example_periodic.csv"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Rate monotonic (hyperperiod 20)",
+		"Utilization 0.900 is over the Liu & Layland bound 0.780 for 3 tasks",
+		"0 of 10 jobs missed their deadlines",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+	if err := runRateMonotonic(&out, nil); err == nil {
+		t.Error("runRateMonotonic() without a file succeeded")
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/Sha-min/CSCE4600/pkg/render"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region Readers–writers
+
+// Readers–writers lock policies.
+const (
+	// RWReaders lets a reader in whenever no writer holds the lock, so a steady
+	// stream of readers can starve writers.
+	RWReaders = "readers"
+	// RWWriters stops admitting readers as soon as a writer is waiting.
+	RWWriters = "writers"
+	// RWFair admits requests strictly in arrival order, batching adjacent readers.
+	RWFair = "fair"
+)
+
+// Readers–writers lock operations.
+const (
+	RWRead      = "read"
+	RWReadDone  = "end-read"
+	RWWrite     = "write"
+	RWWriteDone = "end-write"
+)
+
+var rwPolicies = []string{RWReaders, RWWriters, RWFair}
+
+type (
+	// ReadersWriters describes Readers and Writers sharing one lock, each
+	// accessing the data Accesses times. Think, Read and Write are CPU costs;
+	// Read and Write are spent holding the lock.
+	ReadersWriters struct {
+		Readers  int
+		Writers  int
+		Accesses int
+		Think    int64
+		Read     int64
+		Write    int64
+		Quantum  int64
+		Policy   string
+		// Starve is the longest a writer may wait for the lock before the run
+		// counts it as starved; 0 uses the CPU time for everyone else to
+		// complete one access.
+		Starve int64
+	}
+	// ReadersWritersResult is a finished readers–writers run. Latencies are
+	// the average time an access waited for the lock.
+	ReadersWritersResult struct {
+		scheduler.Trace
+		Policy         string
+		ReaderLatency  float64
+		WriterLatency  float64
+		LongestWrite   int64
+		StarvedWriters []int64
+	}
+	rwLock struct {
+		policy  string
+		readers int
+		writer  bool
+		waiting []rwWaiter
+	}
+	rwWaiter struct {
+		t     *scheduler.Task
+		write bool
+	}
+)
+
+// Workload builds the readers first, then the writers, and the lock they share.
+func (rw ReadersWriters) Workload() ([]scheduler.Process, map[string]scheduler.SyncObject, error) {
+	if rw.Readers < 0 || rw.Writers < 0 || rw.Readers+rw.Writers == 0 || rw.Accesses <= 0 {
+		return nil, nil, fmt.Errorf("%w: need at least one reader or writer and one access", scheduler.ErrInvalidArgs)
+	}
+	switch rw.Policy {
+	case RWReaders, RWWriters, RWFair:
+	default:
+		return nil, nil, fmt.Errorf("%w: unknown readers-writers policy %q", scheduler.ErrInvalidArgs, rw.Policy)
+	}
+
+	processes := make([]scheduler.Process, 0, rw.Readers+rw.Writers)
+	add := func(name string, acquire, release string, hold int64) {
+		p := scheduler.Process{ProcessID: int64(len(processes) + 1), Name: name}
+		for i := 0; i < rw.Accesses; i++ {
+			p.BurstDuration += rw.Think
+			p.Ops = append(p.Ops, scheduler.SyncOp{At: p.BurstDuration, Op: acquire, Object: "data"})
+			p.BurstDuration += hold
+			p.Ops = append(p.Ops, scheduler.SyncOp{At: p.BurstDuration, Op: release, Object: "data"})
+		}
+		processes = append(processes, p)
+	}
+	for i := 0; i < rw.Readers; i++ {
+		add(fmt.Sprintf("reader %d", i+1), RWRead, RWReadDone, rw.Read)
+	}
+	for i := 0; i < rw.Writers; i++ {
+		add(fmt.Sprintf("writer %d", i+1), RWWrite, RWWriteDone, rw.Write)
+	}
+
+	return processes, map[string]scheduler.SyncObject{"data": &rwLock{policy: rw.Policy}}, nil
+}
+
+// Simulate runs the workload through the engine with a FIFO ready queue.
+func (rw ReadersWriters) Simulate() (ReadersWritersResult, error) {
+	processes, objects, err := rw.Workload()
+	if err != nil {
+		return ReadersWritersResult{}, err
+	}
+	engine := scheduler.Engine{Queue: &scheduler.FIFOQueue{}, Quantum: rw.Quantum, Objects: objects}
+	result := ReadersWritersResult{Trace: engine.Simulate(processes), Policy: rw.Policy}
+
+	starve := rw.Starve
+	if starve <= 0 {
+		starve = int64(rw.Readers)*(rw.Think+rw.Read) + int64(rw.Writers-1)*(rw.Think+rw.Write)
+	}
+	for i, t := range result.Tasks {
+		latency := float64(t.Blocked) / float64(rw.Accesses)
+		if i < rw.Readers {
+			result.ReaderLatency += latency / float64(rw.Readers)
+			continue
+		}
+		result.WriterLatency += latency / float64(rw.Writers)
+		if t.LongestBlock > result.LongestWrite {
+			result.LongestWrite = t.LongestBlock
+		}
+		if t.LongestBlock > starve {
+			result.StarvedWriters = append(result.StarvedWriters, t.ProcessID)
+		}
+	}
+
+	return result, nil
+}
+
+// Do implements SyncObject for the four lock operations.
+func (l *rwLock) Do(t *scheduler.Task, op string) (bool, []*scheduler.Task) {
+	switch op {
+	case RWRead, RWWrite:
+		write := op == RWWrite
+		if l.admits(write) {
+			l.take(write)
+			return true, nil
+		}
+		l.waiting = append(l.waiting, rwWaiter{t: t, write: write})
+		return false, nil
+	case RWReadDone:
+		l.readers--
+	case RWWriteDone:
+		l.writer = false
+	default:
+		return true, nil
+	}
+	return true, l.grant()
+}
+
+// admits reports whether a new request may take the lock right away.
+func (l *rwLock) admits(write bool) bool {
+	if l.writer || (write && l.readers > 0) {
+		return false
+	}
+	switch l.policy {
+	case RWWriters:
+		return write || !l.writersWaiting()
+	case RWFair:
+		return len(l.waiting) == 0
+	default:
+		return true
+	}
+}
+
+// grant hands a free or read-held lock to waiters after a release: the fair
+// policy serves the queue head and any readers right behind it, the others
+// serve their preferred class first.
+func (l *rwLock) grant() []*scheduler.Task {
+	var woken []*scheduler.Task
+	wake := func(i int) {
+		w := l.waiting[i]
+		l.waiting = append(l.waiting[:i], l.waiting[i+1:]...)
+		l.take(w.write)
+		woken = append(woken, w.t)
+	}
+
+	switch l.policy {
+	case RWFair:
+		for len(l.waiting) > 0 && !l.writer && (!l.waiting[0].write || l.readers == 0) {
+			wake(0)
+		}
+	case RWWriters:
+		if i := l.firstWaiting(true); i >= 0 {
+			if l.readers == 0 && !l.writer {
+				wake(i)
+			}
+			return woken
+		}
+		for i := l.firstWaiting(false); i >= 0 && !l.writer; i = l.firstWaiting(false) {
+			wake(i)
+		}
+	default:
+		for i := l.firstWaiting(false); i >= 0 && !l.writer; i = l.firstWaiting(false) {
+			wake(i)
+		}
+		if i := l.firstWaiting(true); i >= 0 && l.readers == 0 && !l.writer {
+			wake(i)
+		}
+	}
+
+	return woken
+}
+
+func (l *rwLock) take(write bool) {
+	if write {
+		l.writer = true
+	} else {
+		l.readers++
+	}
+}
+
+func (l *rwLock) firstWaiting(write bool) int {
+	for i, w := range l.waiting {
+		if w.write == write {
+			return i
+		}
+	}
+	return -1
+}
+
+func (l *rwLock) writersWaiting() bool {
+	return l.firstWaiting(true) >= 0
+}
+
+//endregion
+
+//region rw command
+
+// runReadersWriters is the `rw` subcommand. With -policy all it compares every
+// policy on the same workload; otherwise it shows one run in detail.
+func runReadersWriters(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("rw", flag.ContinueOnError)
+	var (
+		rw     ReadersWriters
+		policy string
+	)
+	fs.IntVar(&rw.Readers, "readers", 4, "number of readers")
+	fs.IntVar(&rw.Writers, "writers", 2, "number of writers")
+	fs.IntVar(&rw.Accesses, "accesses", 3, "times each process reads or writes")
+	fs.Int64Var(&rw.Think, "think", 1, "CPU time between accesses")
+	fs.Int64Var(&rw.Read, "read", 3, "CPU time spent reading, holding the lock")
+	fs.Int64Var(&rw.Write, "write", 2, "CPU time spent writing, holding the lock")
+	fs.Int64Var(&rw.Quantum, "quantum", 1, "round-robin quantum; 0 runs each process until it blocks")
+	fs.Int64Var(&rw.Starve, "starve", 0, "longest acceptable writer wait; 0 picks one from the workload")
+	fs.StringVar(&policy, "policy", "all", "all or one of "+strings.Join(rwPolicies, ", "))
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+
+	if policy != "all" {
+		rw.Policy = policy
+		result, err := rw.Simulate()
+		if err != nil {
+			return err
+		}
+		render.Title(w, "Readers-writers: "+policy+" preference")
+		render.Gantt(w, result.Gantt)
+		outputSyncTable(w, result.Trace)
+		outputReadersWriters(w, []ReadersWritersResult{result})
+		return nil
+	}
+
+	results := make([]ReadersWritersResult, 0, len(rwPolicies))
+	for _, p := range rwPolicies {
+		rw.Policy = p
+		result, err := rw.Simulate()
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+	}
+	render.Title(w, "Readers-writers")
+	outputReadersWriters(w, results)
+
+	return nil
+}
+
+func outputReadersWriters(w io.Writer, results []ReadersWritersResult) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Policy", "Reader latency", "Writer latency", "Longest write wait", "Starved writers", "Makespan"})
+	for _, r := range results {
+		table.Append([]string{
+			r.Policy,
+			fmt.Sprintf("%.2f", r.ReaderLatency),
+			fmt.Sprintf("%.2f", r.WriterLatency),
+			fmt.Sprint(r.LongestWrite),
+			joinPIDs(r.StarvedWriters),
+			fmt.Sprint(r.Makespan()),
+		})
+	}
+	table.Render()
+	_, _ = fmt.Fprintln(w)
+}
+
+//endregion
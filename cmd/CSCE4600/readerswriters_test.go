@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestReadersWriters_Simulate(t *testing.T) {
+	t.Parallel()
+	rw := ReadersWriters{Readers: 4, Writers: 2, Accesses: 3, Think: 1, Read: 3, Write: 2, Quantum: 1}
+	results := make(map[string]ReadersWritersResult, len(rwPolicies))
+	for _, policy := range rwPolicies {
+		rw.Policy = policy
+		result, err := rw.Simulate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Blocked) > 0 {
+			t.Fatalf("%s: %d processes deadlocked", policy, len(result.Blocked))
+		}
+		results[policy] = result
+	}
+
+	if len(results[RWReaders].StarvedWriters) == 0 {
+		t.Error("reader preference starved no writers")
+	}
+	for _, policy := range []string{RWWriters, RWFair} {
+		if got := results[policy].StarvedWriters; len(got) > 0 {
+			t.Errorf("%s: starved writers %v", policy, got)
+		}
+	}
+	if r, w := results[RWReaders].ReaderLatency, results[RWWriters].ReaderLatency; r >= w {
+		t.Errorf("reader latency with reader preference = %.2f, want below writer preference's %.2f", r, w)
+	}
+}
+
+func TestReadersWriters_Workload(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		rw   ReadersWriters
+	}{
+		{name: "nobody", rw: ReadersWriters{Accesses: 1, Policy: RWFair}},
+		{name: "no accesses", rw: ReadersWriters{Readers: 1, Policy: RWFair}},
+		{name: "unknown policy", rw: ReadersWriters{Readers: 1, Accesses: 1, Policy: "random"}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, _, err := tt.rw.Workload(); !errors.Is(err, scheduler.ErrInvalidArgs) {
+				t.Errorf("Workload() error = %v, want %v", err, scheduler.ErrInvalidArgs)
+			}
+		})
+	}
+}
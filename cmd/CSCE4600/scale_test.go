@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+// million runs TestMillionProcesses, which takes several seconds:
+// go test -run MillionProcesses -million
+var million = flag.Bool("million", false, "run the one-million-process budget test")
+
+// The budget for the batch pipeline, loading a one-million-process workload
+// file, scheduling it and summarizing the run, for each scheduler on its own.
+const (
+	millionTimeBudget   = 5 * time.Second
+	millionMemoryBudget = 1 << 30 // bytes obtained from the OS
+)
+
+func TestMillionProcesses(t *testing.T) {
+	if !*million {
+		t.Skip("needs -million")
+	}
+	path := filepath.Join(t.TempDir(), "million.csv")
+	if err := os.WriteFile(path, []byte(benchWorkload(1_000_000)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range scheduler.SortedSchedulerNames() {
+		runtime.GC()
+		start := time.Now()
+		if err := runBatch(io.Discard, []string{"-schedulers", name, path}); err != nil {
+			t.Fatal(err)
+		}
+		elapsed := time.Since(start)
+		var ms runtime.MemStats
+		runtime.ReadMemStats(&ms)
+		t.Logf("%s: %v, %d MiB from the OS", name, elapsed.Round(time.Millisecond), ms.Sys>>20)
+		if elapsed > millionTimeBudget {
+			t.Errorf("%s took %v, over the %v budget", name, elapsed, millionTimeBudget)
+		}
+		if ms.Sys > millionMemoryBudget {
+			t.Errorf("%s used %d MiB, over the %d MiB budget", name, ms.Sys>>20, millionMemoryBudget>>20)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/render"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region scenario command
+
+// Report formats a scenario can write besides the -output ones: the
+// per-process metrics CSV, the HTML report and compare's table.
+const (
+	OutputCSV     = "csv"
+	OutputHTML    = "html"
+	OutputCompare = "compare"
+)
+
+// newScenarioCommand is the `scenario` subcommand, which runs everything a
+// scenario file sets up, e.g. `scenario example_scenario.yaml`.
+func newScenarioCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "scenario file",
+		Short: "Run the schedulers a YAML, TOML or JSON scenario file sets up and write its reports",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := loader.LoadScenarioFile(args[0])
+			if err != nil {
+				return err
+			}
+			return runScenario(cmd.OutOrStdout(), s)
+		},
+	}
+}
+
+// runScenario makes each of s's runs, then writes each of its outputs, to w
+// when one has no path.
+func runScenario(w io.Writer, s loader.Scenario) error {
+	outputs := s.Outputs
+	if len(outputs) == 0 {
+		outputs = []loader.ScenarioOutput{{Format: OutputText}}
+	}
+	for _, o := range outputs {
+		switch o.Format {
+		case OutputText, OutputExpanded, OutputJSON, OutputCSV, OutputHTML, OutputCompare:
+		default:
+			return fmt.Errorf("%w: unknown scenario output %q, want %s, %s, %s, %s, %s or %s", scheduler.ErrInvalidArgs,
+				o.Format, OutputText, OutputExpanded, OutputJSON, OutputCSV, OutputHTML, OutputCompare)
+		}
+	}
+	results := make([]scheduler.RunResult, 0, len(s.Runs))
+	for _, r := range s.Runs {
+		result, err := scheduler.RunSchedulerParams(r.Scheduler, r.Params, s.Processes)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+	}
+	for _, o := range outputs {
+		if o.Path == "" {
+			if err := writeScenarioOutput(w, o.Format, results); err != nil {
+				return err
+			}
+			continue
+		}
+		f, err := os.Create(o.Path)
+		if err != nil {
+			return err
+		}
+		if err := writeScenarioOutput(f, o.Format, results); err != nil {
+			_ = f.Close()
+			return fmt.Errorf("%s: %w", o.Path, err)
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeScenarioOutput writes results to w in format.
+func writeScenarioOutput(w io.Writer, format string, results []scheduler.RunResult) error {
+	switch format {
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	case OutputCSV:
+		return render.MetricsCSV(w, results)
+	case OutputHTML:
+		return render.HTMLReport(w, results)
+	case OutputCompare:
+		outputComparison(w, scheduler.Compare(results))
+		return nil
+	}
+	for _, result := range results {
+		if err := printResult(w, result, format == OutputExpanded); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//endregion
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestRunScenario(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	if err := run(&out, []string{"scenario", "example_scenario.yaml"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"First-come, first-serve", "Round-robin (quantum 4)", "Weighted round-robin", "best in its column"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("output missing %q:\n%s", s, out.String())
+		}
+	}
+
+	dir := t.TempDir()
+	workload, err := os.ReadFile("testdata/workloads/basic.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "basic.csv"), workload, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	scenario := filepath.Join(dir, "s.toml")
+	if err := os.WriteFile(scenario, []byte(`workload = "basic.csv"
+schedulers = ["fcfs", { name = "rr", quantum = 2 }]
+outputs = [{ format = "json", path = "runs.json" }, { format = "csv", path = "metrics.csv" }, { format = "html", path = "report.html" }]
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := run(&out, []string{"scenario", scenario}); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("a scenario writing only files printed %q", out.String())
+	}
+	for file, want := range map[string]string{"runs.json": `"scheduler": "rr"`, "metrics.csv": "rr", "report.html": "<html"} {
+		b, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil || !bytes.Contains(b, []byte(want)) {
+			t.Errorf("%s = %q, %v, want it to contain %q", file, b, err, want)
+		}
+	}
+
+	bad := filepath.Join(dir, "bad.yaml")
+	if err := os.WriteFile(bad, []byte("workload: basic.csv\noutputs: [{format: pdf}]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(&out, []string{"scenario", bad}); !errors.Is(err, scheduler.ErrInvalidArgs) || !strings.Contains(err.Error(), `unknown scenario output "pdf"`) {
+		t.Errorf("scenario with a pdf output = %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func Test_printSchedule(t *testing.T) {
+	t.Parallel()
+	processes := []scheduler.Process{
+		{ProcessID: 1, ArrivalTime: 0, BurstDuration: 5, Priority: 2},
+		{ProcessID: 2, ArrivalTime: 3, BurstDuration: 9, Priority: 1},
+		{ProcessID: 3, ArrivalTime: 6, BurstDuration: 6, Priority: 3},
+	}
+	var w bytes.Buffer
+	if err := printSchedule(&w, "rr", scheduler.SchedulerParams{Quantum: 4}, processes, false); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := w.String(), loadFixture(t, "rr_test.txt"); got != want {
+		t.Errorf("printSchedule() = %v, want %v", got, want)
+	}
+	if err := printSchedule(&w, "nope", scheduler.SchedulerParams{Quantum: 4}, processes, false); err == nil {
+		t.Error("printSchedule() of an unknown scheduler did not fail")
+	}
+}
+
+func Test_parseSchedulers(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		list    string
+		want    []string
+		wantErr error
+	}{
+		{name: "one", list: "rr", want: []string{"rr"}},
+		{name: "several, spaced", list: "sjf, fcfs ,rr", want: []string{"sjf", "fcfs", "rr"}},
+		{name: "unknown", list: "fcfs,mlfq", wantErr: scheduler.ErrInvalidArgs},
+		{name: "empty", list: "", wantErr: scheduler.ErrInvalidArgs},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseSchedulers(tt.list)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSchedulers() = %v, want %v", got, tt.want)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
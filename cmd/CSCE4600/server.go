@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region HTTP server
+
+//go:embed web
+var webFiles embed.FS
+
+// webUI is the single-page UI served at the root of the server.
+var webUI, _ = fs.Sub(webFiles, "web")
+
+// maxUploadBytes bounds request bodies so one upload cannot exhaust memory.
+const maxUploadBytes = 10 << 20
+
+type (
+	// Server keeps uploaded workloads and finished runs in memory and serves
+	// them over a small JSON API:
+	// • GET  /schedulers      the scheduler names a run may ask for
+	// • POST /workloads       upload a CSV workload, returns its id
+	// • GET  /workloads/{id}  the parsed processes
+	// • POST /runs            run schedulers on a workload, returns the results
+	// • GET  /runs/{id}       fetch a finished run again
+	// • GET  /runs/stream     a WebSocket replaying one run's events live
+	// • GET  /metrics         counters in the Prometheus text format
+	// • GET  /                the web UI
+	Server struct {
+		mu        sync.Mutex
+		workloads map[string][]scheduler.Process
+		runs      map[string]ServerRun
+		workloadN int
+		runN      int
+		metrics   *serverMetrics
+		store     *ResultStore
+		// streamDelay is the most a stream spends waiting in all.
+		streamDelay time.Duration
+	}
+	// RunRequest is the body of POST /runs. Without Schedulers every one runs.
+	// Caps maps a process group to the percentage of every CapPeriod it may
+	// run.
+	RunRequest struct {
+		Workload   string           `json:"workload"`
+		Schedulers []string         `json:"schedulers"`
+		Quantum    int64            `json:"quantum"`
+		Aging      int64            `json:"aging"`
+		SwitchCost int64            `json:"switch_cost"`
+		CPUs       int              `json:"cpus"`
+		Seed       int64            `json:"seed"`
+		Latency    int64            `json:"latency"`
+		Alpha      float64          `json:"alpha"`
+		Caps       map[string]int64 `json:"caps"`
+		CapPeriod  int64            `json:"cap_period"`
+		Events     bool             `json:"events"`
+	}
+	// ServerRun is a finished run as stored and returned by the server.
+	ServerRun struct {
+		ID       string                `json:"id"`
+		Workload string                `json:"workload"`
+		Results  []scheduler.RunResult `json:"results"`
+	}
+)
+
+func NewServer() *Server {
+	return &Server{workloads: map[string][]scheduler.Process{}, runs: map[string]ServerRun{}, metrics: newServerMetrics(),
+		streamDelay: maxStreamDelay}
+}
+
+// Handler routes the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/schedulers", s.handleSchedulers)
+	mux.HandleFunc("/workloads", s.handleWorkloads)
+	mux.HandleFunc("/workloads/", s.handleWorkload)
+	mux.HandleFunc("/runs", s.handleRuns)
+	mux.HandleFunc("/runs/", s.handleRun)
+	mux.HandleFunc("/runs/stream", s.handleStream)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.Handle("/", http.FileServer(http.FS(webUI)))
+	return mux
+}
+
+func (s *Server) handleSchedulers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, errors.New("use GET"))
+		return
+	}
+	writeJSON(w, http.StatusOK, scheduler.SortedSchedulerNames())
+}
+
+func (s *Server) handleWorkloads(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, http.StatusMethodNotAllowed, errors.New("use POST with a CSV body"))
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxUploadBytes))
+	if err != nil {
+		writeJSONError(w, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+	processes, err := loader.LoadCSV(bytes.NewReader(body))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(processes) == 0 {
+		writeJSONError(w, http.StatusBadRequest, fmt.Errorf("%w: workload has no processes", scheduler.ErrInvalidArgs))
+		return
+	}
+
+	s.mu.Lock()
+	s.workloadN++
+	id := fmt.Sprintf("w%d", s.workloadN)
+	s.workloads[id] = processes
+	s.mu.Unlock()
+	s.metrics.observeWorkload(len(processes))
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"id": id, "processes": len(processes)})
+}
+
+func (s *Server) handleWorkload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, errors.New("use GET"))
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/workloads/")
+	s.mu.Lock()
+	processes, ok := s.workloads[id]
+	s.mu.Unlock()
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, fmt.Errorf("no workload %q", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, processes)
+}
+
+func (s *Server) handleRuns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, http.StatusMethodNotAllowed, errors.New("use POST with a JSON run request"))
+		return
+	}
+	var req RunRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUploadBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.mu.Lock()
+	processes, ok := s.workloads[req.Workload]
+	s.mu.Unlock()
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, fmt.Errorf("no workload %q", req.Workload))
+		return
+	}
+	if len(req.Schedulers) == 0 {
+		req.Schedulers = scheduler.SortedSchedulerNames()
+	}
+
+	run := ServerRun{Workload: req.Workload, Results: make([]scheduler.RunResult, 0, len(req.Schedulers))}
+	params := scheduler.SchedulerParams{Quantum: req.Quantum, Aging: req.Aging, SwitchCost: req.SwitchCost, CPUs: req.CPUs, Seed: req.Seed, Latency: req.Latency, Alpha: req.Alpha,
+		Caps: req.Caps, CapPeriod: req.CapPeriod}
+	for _, name := range req.Schedulers {
+		start := time.Now()
+		result, err := scheduler.RunSchedulerParams(name, params, processes)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		s.metrics.observeRun(name, time.Since(start))
+		if s.store != nil {
+			if _, err := s.store.Save(processes, params, result); err != nil {
+				writeJSONError(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+		if !req.Events {
+			result.Events = nil
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	s.mu.Lock()
+	s.runN++
+	run.ID = fmt.Sprintf("r%d", s.runN)
+	s.runs[run.ID] = run
+	s.mu.Unlock()
+	writeJSON(w, http.StatusCreated, run)
+}
+
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, errors.New("use GET"))
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/runs/")
+	s.mu.Lock()
+	run, ok := s.runs[id]
+	s.mu.Unlock()
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, fmt.Errorf("no run %q", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, run)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+//endregion
+
+//region serve command
+
+// runServe is the `serve` subcommand: `serve -port 8080 [-db results.db]`.
+func runServe(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	port := fs.Int("port", 8080, "TCP port to listen on")
+	dbPath := fs.String("db", "", "SQLite database to record every run in")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+	srv := NewServer()
+	if *dbPath != "" {
+		store, err := OpenResultStore(*dbPath)
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		srv.store = store
+	}
+	addr := fmt.Sprintf(":%d", *port)
+	_, _ = fmt.Fprintf(w, "Listening on %s\n", addr)
+
+	return http.ListenAndServe(addr, srv.Handler())
+}
+
+//endregion
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(NewServer().Handler())
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Post(srv.URL+"/workloads", "text/csv", strings.NewReader(loadFixture(t, "example_processes.csv")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var workload struct {
+		ID        string `json:"id"`
+		Processes int    `json:"processes"`
+	}
+	decodeResponse(t, resp, http.StatusCreated, &workload)
+	if workload.Processes != 3 {
+		t.Fatalf("uploaded %d processes, want 3", workload.Processes)
+	}
+
+	body := `{"workload": "` + workload.ID + `", "schedulers": ["fcfs", "rr"], "quantum": 2}`
+	resp, err = http.Post(srv.URL+"/runs", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var run ServerRun
+	decodeResponse(t, resp, http.StatusCreated, &run)
+	if len(run.Results) != 2 || run.Results[0].Scheduler != "fcfs" || run.Results[1].Quantum != 2 {
+		t.Fatalf("run results = %+v, want fcfs then rr with quantum 2", run.Results)
+	}
+	wantGantt := []scheduler.TimeSlice{{PID: 1, Start: 0, Stop: 5}, {PID: 2, Start: 5, Stop: 14}, {PID: 3, Start: 14, Stop: 20}}
+	if !reflect.DeepEqual(run.Results[0].Gantt, wantGantt) {
+		t.Errorf("fcfs gantt = %v, want %v", run.Results[0].Gantt, wantGantt)
+	}
+
+	resp, err = http.Get(srv.URL + "/runs/" + run.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fetched ServerRun
+	decodeResponse(t, resp, http.StatusOK, &fetched)
+	if !reflect.DeepEqual(fetched, run) {
+		t.Errorf("GET /runs/%s = %+v, want %+v", run.ID, fetched, run)
+	}
+
+	resp, err = http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`scheduler_simulations_total{algorithm="fcfs"} 1`,
+		`scheduler_simulation_duration_seconds_count{algorithm="rr"} 1`,
+		`scheduler_workload_processes_bucket{le="5"} 1`,
+		`scheduler_workload_processes_sum 3`,
+	} {
+		if !strings.Contains(string(metrics), want) {
+			t.Errorf("GET /metrics is missing %q:\n%s", want, metrics)
+		}
+	}
+
+	resp, err = http.Post(srv.URL+"/workloads", "text/csv", strings.NewReader(loadFixture(t, "testdata/workloads/groups.csv")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decodeResponse(t, resp, http.StatusCreated, &workload)
+	body = `{"workload": "` + workload.ID + `", "schedulers": ["fcfs"], "caps": {"batch": 50}, "cap_period": 10}`
+	resp, err = http.Post(srv.URL+"/runs", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var capped ServerRun
+	decodeResponse(t, resp, http.StatusCreated, &capped)
+	if r := capped.Results[0]; r.Caps["batch"] != 50 || r.CapPeriod != 10 || r.Processes[1].Throttled != 10 {
+		t.Errorf("capped run = %+v, want batch capped at 50%% of 10 and P2 throttled for 10", r)
+	}
+}
+
+func TestServer_errors(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(NewServer().Handler())
+	t.Cleanup(srv.Close)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{name: "bad csv", method: http.MethodPost, path: "/workloads", body: "1,x,0\n", wantStatus: http.StatusBadRequest},
+		{name: "unknown workload", method: http.MethodPost, path: "/runs", body: `{"workload": "w9"}`, wantStatus: http.StatusNotFound},
+		{name: "unknown run", method: http.MethodGet, path: "/runs/r9", wantStatus: http.StatusNotFound},
+		{name: "wrong method", method: http.MethodGet, path: "/workloads", wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req, err := http.NewRequest(tt.method, srv.URL+tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var body map[string]string
+			decodeResponse(t, resp, tt.wantStatus, &body)
+			if body["error"] == "" {
+				t.Errorf("%s %s returned no error message", tt.method, tt.path)
+			}
+		})
+	}
+}
+
+func TestServer_webUI(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(NewServer().Handler())
+	t.Cleanup(srv.Close)
+
+	for _, path := range []string{"/", "/app.js", "/style.css"} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("GET %s status = %d, want 200", path, resp.StatusCode)
+		}
+	}
+}
+
+func decodeResponse(t *testing.T, resp *http.Response, wantStatus int, v interface{}) {
+	t.Helper()
+	defer resp.Body.Close()
+	if resp.StatusCode != wantStatus {
+		t.Fatalf("%s %s status = %d, want %d", resp.Request.Method, resp.Request.URL.Path, resp.StatusCode, wantStatus)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_checkQuantum(t *testing.T) {
+	t.Parallel()
+	processes := []scheduler.Process{{ProcessID: 1, BurstDuration: 3}, {ProcessID: 2, BurstDuration: 5}}
+	tests := []struct {
+		name        string
+		quantum     int64
+		wantWarning bool
+		wantErr     error
+	}{
+		{name: "shorter than a burst", quantum: 4},
+		{name: "as long as every burst", quantum: 5, wantWarning: true},
+		{name: "zero", quantum: 0, wantErr: scheduler.ErrInvalidArgs},
+		{name: "negative", quantum: -2, wantErr: scheduler.ErrInvalidArgs},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			warning, err := scheduler.CheckQuantum(tt.quantum, processes)
+			if (warning != "") != tt.wantWarning {
+				t.Errorf("checkQuantum() warning = %q, want one: %v", warning, tt.wantWarning)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,538 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region Shell
+
+// errExit is returned by the exit builtin to stop the read loop.
+var errExit = errors.New("exit")
+
+type (
+	// Shell is a small interactive shell: commands joined by pipes, with <, >
+	// and >> redirection and & for background jobs. Builtins run in the shell
+	// itself, so its working directory and environment are its own rather than
+	// the process's. Only the first stage of a foreground pipeline reads In,
+	// and only the last writes Out; every stage writes Err.
+	Shell struct {
+		In     io.Reader
+		Out    io.Writer
+		Err    io.Writer
+		Dir    string
+		Env    []string
+		Prompt bool
+
+		mu      sync.Mutex
+		jobs    []*shellJob
+		nextJob int
+		guard   sync.Once
+		out     io.Writer
+		errs    io.Writer
+	}
+	// lockedWriter serializes writes to w, which the shell and the stages
+	// of its jobs share.
+	lockedWriter struct {
+		mu *sync.Mutex
+		w  io.Writer
+	}
+	// Pipeline is one parsed command line.
+	Pipeline struct {
+		Stages     []Stage
+		Background bool
+	}
+	// Stage is one command in a pipeline and its file redirections.
+	Stage struct {
+		Args   []string
+		In     string
+		Out    string
+		Append bool
+	}
+	shellJob struct {
+		id   int
+		line string
+		done bool
+		err  error
+	}
+	shellBuiltin func(s *Shell, args []string) error
+)
+
+var shellBuiltins = map[string]shellBuiltin{
+	"cd":     (*Shell).cd,
+	"pwd":    (*Shell).pwd,
+	"exit":   func(*Shell, []string) error { return errExit },
+	"env":    (*Shell).env,
+	"export": (*Shell).export,
+	"unset":  (*Shell).unset,
+	"jobs":   (*Shell).listJobs,
+}
+
+// Run reads and executes lines until exit or end of input. A failing command
+// is reported and the loop carries on, as an interactive shell would.
+func (s *Shell) Run() error {
+	sc := bufio.NewScanner(s.In)
+	for {
+		s.reportJobs()
+		if s.Prompt {
+			_, _ = fmt.Fprintf(s.stdout(), "%s$ ", filepath.Base(s.Dir))
+		}
+		if !sc.Scan() {
+			break
+		}
+		err := s.Execute(sc.Text())
+		if errors.Is(err, errExit) {
+			return nil
+		}
+		if err != nil {
+			_, _ = fmt.Fprintln(s.stderr(), err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("%w: reading shell input", err)
+	}
+	return nil
+}
+
+// Execute parses and runs one command line.
+func (s *Shell) Execute(line string) error {
+	p, err := ParsePipeline(line)
+	if err != nil || len(p.Stages) == 0 {
+		return err
+	}
+	if builtin, ok := shellBuiltins[p.Stages[0].Args[0]]; ok {
+		if len(p.Stages) > 1 || p.Background {
+			return fmt.Errorf("%w: %s cannot be piped or run in the background", scheduler.ErrInvalidArgs, p.Stages[0].Args[0])
+		}
+		return builtin(s, p.Stages[0].Args[1:])
+	}
+
+	cmds, closers, err := s.start(p)
+	if err != nil {
+		return err
+	}
+	wait := func() error {
+		var first error
+		for _, cmd := range cmds {
+			if err := cmd.Wait(); err != nil && first == nil {
+				first = err
+			}
+		}
+		for _, c := range closers {
+			_ = c.Close()
+		}
+		return first
+	}
+	if !p.Background {
+		return wait()
+	}
+
+	s.mu.Lock()
+	s.nextJob++
+	job := &shellJob{id: s.nextJob, line: strings.TrimSpace(line)}
+	s.jobs = append(s.jobs, job)
+	s.mu.Unlock()
+	_, _ = fmt.Fprintf(s.stdout(), "[%d] %d\n", job.id, cmds[len(cmds)-1].Process.Pid)
+	go func() {
+		err := wait()
+		s.mu.Lock()
+		job.done, job.err = true, err
+		s.mu.Unlock()
+	}()
+	return nil
+}
+
+// start wires up and starts every stage of p, returning the running commands
+// and the files to close once they exit. Stages are joined with OS pipes so
+// they can be waited on in any order: stage i writes the pipe stage i+1
+// reads, the first stage alone reads the shell's input, unless it runs in
+// the background, and the last alone writes its output.
+func (s *Shell) start(p Pipeline) ([]*exec.Cmd, []io.Closer, error) {
+	var (
+		cmds    = make([]*exec.Cmd, len(p.Stages))
+		closers []io.Closer
+		pipes   []io.Closer
+	)
+	fail := func(err error) ([]*exec.Cmd, []io.Closer, error) {
+		for _, c := range append(closers, pipes...) {
+			_ = c.Close()
+		}
+		return nil, nil, err
+	}
+	for i, st := range p.Stages {
+		cmd := exec.Command(st.Args[0], st.Args[1:]...)
+		cmd.Dir, cmd.Env, cmd.Stderr = s.Dir, s.Env, s.stderr()
+		// Background jobs must not compete with the shell for its input.
+		if i == 0 && !p.Background {
+			cmd.Stdin = s.In
+		}
+		if i == len(p.Stages)-1 {
+			cmd.Stdout = s.stdout()
+		}
+		if i > 0 {
+			r, w, err := os.Pipe()
+			if err != nil {
+				return fail(err)
+			}
+			pipes = append(pipes, r, w)
+			cmds[i-1].Stdout, cmd.Stdin = w, r
+		}
+		if st.In != "" {
+			f, err := os.Open(s.path(st.In))
+			if err != nil {
+				return fail(err)
+			}
+			closers = append(closers, f)
+			cmd.Stdin = f
+		}
+		if st.Out != "" {
+			flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+			if st.Append {
+				flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+			}
+			f, err := os.OpenFile(s.path(st.Out), flags, 0o644)
+			if err != nil {
+				return fail(err)
+			}
+			closers = append(closers, f)
+			cmd.Stdout = f
+		}
+		cmds[i] = cmd
+	}
+	for i, cmd := range cmds {
+		if err := cmd.Start(); err != nil {
+			for _, started := range cmds[:i] {
+				_ = started.Process.Kill()
+				_ = started.Wait()
+			}
+			return fail(err)
+		}
+	}
+	// The children hold their own copies of the pipe ends; closing ours lets
+	// each reader see end of file when its writer exits.
+	for _, c := range pipes {
+		_ = c.Close()
+	}
+	return cmds, closers, nil
+}
+
+// path resolves name against the shell's working directory.
+func (s *Shell) path(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(s.Dir, name)
+}
+
+// stdout is Out guarded by the lock every write to Out and Err shares, so
+// the shell, background jobs and every stage's output and errors can write
+// at once. A file is left as it is, for children to write directly.
+func (s *Shell) stdout() io.Writer {
+	s.guardStreams()
+	return s.out
+}
+
+// stderr is Err, guarded as stdout is Out.
+func (s *Shell) stderr() io.Writer {
+	s.guardStreams()
+	return s.errs
+}
+
+func (s *Shell) guardStreams() {
+	s.guard.Do(func() {
+		mu := new(sync.Mutex)
+		s.out, s.errs = lockWriter(mu, s.Out), lockWriter(mu, s.Err)
+	})
+}
+
+// lockWriter is w with its writes made under mu, unless it is a file or
+// nil, which children discard.
+func lockWriter(mu *sync.Mutex, w io.Writer) io.Writer {
+	switch w.(type) {
+	case nil, *os.File:
+		return w
+	}
+	return lockedWriter{mu: mu, w: w}
+}
+
+func (l lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// reportJobs prints and forgets background jobs that have finished.
+func (s *Shell) reportJobs() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	running := s.jobs[:0]
+	for _, job := range s.jobs {
+		if !job.done {
+			running = append(running, job)
+			continue
+		}
+		status := "Done"
+		if job.err != nil {
+			status = "Exit: " + job.err.Error()
+		}
+		_, _ = fmt.Fprintf(s.stdout(), "[%d] %s\t%s\n", job.id, status, job.line)
+	}
+	s.jobs = running
+}
+
+//endregion
+
+//region Shell builtins
+
+func (s *Shell) cd(args []string) error {
+	dir := s.lookup("HOME")
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	if len(args) > 1 || dir == "" {
+		return fmt.Errorf("%w: usage: cd [dir]", scheduler.ErrInvalidArgs)
+	}
+	dir = s.path(dir)
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("cd: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%w: cd: %s is not a directory", scheduler.ErrInvalidArgs, dir)
+	}
+	s.Dir = dir
+	return nil
+}
+
+func (s *Shell) pwd([]string) error {
+	_, _ = fmt.Fprintln(s.stdout(), s.Dir)
+	return nil
+}
+
+func (s *Shell) env([]string) error {
+	env := append([]string(nil), s.Env...)
+	sort.Strings(env)
+	for _, kv := range env {
+		_, _ = fmt.Fprintln(s.stdout(), kv)
+	}
+	return nil
+}
+
+// export takes NAME=value pairs.
+func (s *Shell) export(args []string) error {
+	for _, arg := range args {
+		name, _, ok := strings.Cut(arg, "=")
+		if !ok || name == "" {
+			return fmt.Errorf("%w: usage: export NAME=value", scheduler.ErrInvalidArgs)
+		}
+		s.unsetVar(name)
+		s.Env = append(s.Env, arg)
+	}
+	return nil
+}
+
+func (s *Shell) unset(args []string) error {
+	for _, name := range args {
+		s.unsetVar(name)
+	}
+	return nil
+}
+
+func (s *Shell) listJobs([]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, job := range s.jobs {
+		status := "Running"
+		if job.done {
+			status = "Done"
+		}
+		_, _ = fmt.Fprintf(s.stdout(), "[%d] %s\t%s\n", job.id, status, job.line)
+	}
+	return nil
+}
+
+func (s *Shell) lookup(name string) string {
+	for _, kv := range s.Env {
+		if k, v, _ := strings.Cut(kv, "="); k == name {
+			return v
+		}
+	}
+	return ""
+}
+
+func (s *Shell) unsetVar(name string) {
+	env := s.Env[:0]
+	for _, kv := range s.Env {
+		if k, _, _ := strings.Cut(kv, "="); k != name {
+			env = append(env, kv)
+		}
+	}
+	s.Env = env
+}
+
+//endregion
+
+//region Shell parsing
+
+// ParsePipeline splits a command line into pipeline stages. Words may be
+// quoted with ' or " and a backslash escapes the next character outside
+// single quotes. A trailing & runs the pipeline in the background.
+func ParsePipeline(line string) (Pipeline, error) {
+	tokens, err := shellTokens(line)
+	if err != nil {
+		return Pipeline{}, err
+	}
+	var p Pipeline
+	if n := len(tokens); n > 0 && tokens[n-1] == (shellToken{text: "&", op: true}) {
+		p.Background = true
+		tokens = tokens[:n-1]
+	}
+	if len(tokens) == 0 {
+		if p.Background {
+			return Pipeline{}, fmt.Errorf("%w: nothing to run in the background", scheduler.ErrInvalidArgs)
+		}
+		return p, nil
+	}
+
+	var st Stage
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if !tok.op {
+			st.Args = append(st.Args, tok.text)
+			continue
+		}
+		switch tok.text {
+		case "|":
+			if len(st.Args) == 0 {
+				return Pipeline{}, fmt.Errorf("%w: empty command in pipeline", scheduler.ErrInvalidArgs)
+			}
+			p.Stages = append(p.Stages, st)
+			st = Stage{}
+		case "<", ">", ">>":
+			if i+1 == len(tokens) || tokens[i+1].op {
+				return Pipeline{}, fmt.Errorf("%w: %s needs a file name", scheduler.ErrInvalidArgs, tok.text)
+			}
+			i++
+			if tok.text == "<" {
+				st.In = tokens[i].text
+			} else {
+				st.Out, st.Append = tokens[i].text, tok.text == ">>"
+			}
+		default:
+			return Pipeline{}, fmt.Errorf("%w: %s is only allowed at the end of a line", scheduler.ErrInvalidArgs, tok.text)
+		}
+	}
+	if len(st.Args) == 0 {
+		return Pipeline{}, fmt.Errorf("%w: empty command in pipeline", scheduler.ErrInvalidArgs)
+	}
+	p.Stages = append(p.Stages, st)
+
+	for i, stage := range p.Stages {
+		if stage.Out != "" && i < len(p.Stages)-1 {
+			return Pipeline{}, fmt.Errorf("%w: %s: output is both redirected and piped", scheduler.ErrInvalidArgs, stage.Args[0])
+		}
+		if stage.In != "" && i > 0 {
+			return Pipeline{}, fmt.Errorf("%w: %s: input is both redirected and piped", scheduler.ErrInvalidArgs, stage.Args[0])
+		}
+	}
+
+	return p, nil
+}
+
+type shellToken struct {
+	text string
+	op   bool
+}
+
+func shellTokens(line string) ([]shellToken, error) {
+	var (
+		tokens []shellToken
+		word   strings.Builder
+		inWord bool
+		quote  rune
+		escape bool
+	)
+	flush := func() {
+		if inWord {
+			tokens = append(tokens, shellToken{text: word.String()})
+			word.Reset()
+			inWord = false
+		}
+	}
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case escape:
+			word.WriteRune(r)
+			escape = false
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' {
+				escape = true
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == '\\':
+			escape, inWord = true, true
+		case r == ' ' || r == '\t':
+			flush()
+		case r == '|' || r == '<' || r == '&':
+			flush()
+			tokens = append(tokens, shellToken{text: string(r), op: true})
+		case r == '>':
+			flush()
+			if i+1 < len(runes) && runes[i+1] == '>' {
+				i++
+				tokens = append(tokens, shellToken{text: ">>", op: true})
+			} else {
+				tokens = append(tokens, shellToken{text: ">", op: true})
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escape {
+		return nil, fmt.Errorf("%w: unterminated quote or escape", scheduler.ErrInvalidArgs)
+	}
+	flush()
+	return tokens, nil
+}
+
+//endregion
+
+//region shell command
+
+// runShell is the `shell` subcommand. It reads commands from stdin, showing a
+// prompt unless -q is given, e.g. when a script is piped in.
+func runShell(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("shell", flag.ContinueOnError)
+	quiet := fs.Bool("q", false, "do not print a prompt")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	s := &Shell{In: os.Stdin, Out: w, Err: os.Stderr, Dir: dir, Env: os.Environ(), Prompt: !*quiet}
+
+	return s.Run()
+}
+
+//endregion
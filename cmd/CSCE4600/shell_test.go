@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestParsePipeline(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		line    string
+		want    Pipeline
+		wantErr error
+	}{
+		{name: "empty", line: "   "},
+		{
+			name: "quotes and escapes",
+			line: `echo "a b" 'c|d' e\ f`,
+			want: Pipeline{Stages: []Stage{{Args: []string{"echo", "a b", "c|d", "e f"}}}},
+		},
+		{
+			name: "pipes and redirection",
+			line: "sort < in.txt | uniq -c >> out.txt &",
+			want: Pipeline{
+				Stages: []Stage{
+					{Args: []string{"sort"}, In: "in.txt"},
+					{Args: []string{"uniq", "-c"}, Out: "out.txt", Append: true},
+				},
+				Background: true,
+			},
+		},
+		{name: "operators without spaces", line: "ls>out", want: Pipeline{Stages: []Stage{{Args: []string{"ls"}, Out: "out"}}}},
+		{name: "empty stage", line: "ls | | wc", wantErr: scheduler.ErrInvalidArgs},
+		{name: "missing file", line: "cat <", wantErr: scheduler.ErrInvalidArgs},
+		{name: "redirect then pipe", line: "ls > out | wc", wantErr: scheduler.ErrInvalidArgs},
+		{name: "ampersand mid-line", line: "sleep 1 & ls", wantErr: scheduler.ErrInvalidArgs},
+		{name: "unterminated quote", line: `echo "oops`, wantErr: scheduler.ErrInvalidArgs},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParsePipeline(tt.line)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParsePipeline() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePipeline() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShell_Run(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	script := strings.Join([]string{
+		"cd sub",
+		"pwd",
+		"export GREETING=hello",
+		`sh -c 'echo $GREETING world' | tr a-z A-Z > out.txt`,
+		"echo again >> out.txt",
+		"cat < out.txt",
+		"cd missing",
+		"exit",
+		"echo never",
+	}, "\n")
+	var out, errOut bytes.Buffer
+	s := &Shell{In: strings.NewReader(script), Out: &out, Err: &errOut, Dir: dir, Env: []string{"PATH=" + os.Getenv("PATH")}}
+	if err := s.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := filepath.Join(dir, "sub") + "\nHELLO WORLD\nagain\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+	if !strings.Contains(errOut.String(), "cd:") {
+		t.Errorf("errors = %q, want a cd failure", errOut.String())
+	}
+}
+
+func TestShell_Run_sharedOutput(t *testing.T) {
+	t.Parallel()
+	// Both stages of the pipeline write errors while the background job
+	// writes output between the shell's prompts, which must not race.
+	script := strings.Join([]string{
+		"sh -c 'echo background' &",
+		"sh -c 'echo first; echo first >&2' | sh -c 'cat; echo second >&2'",
+	}, "\n")
+	var out, errOut bytes.Buffer
+	s := &Shell{In: strings.NewReader(script), Out: &out, Err: &errOut, Dir: t.TempDir(), Env: []string{"PATH=" + os.Getenv("PATH")}, Prompt: true}
+	if err := s.Run(); err != nil {
+		t.Fatal(err)
+	}
+	for running := true; running; time.Sleep(time.Millisecond) {
+		s.mu.Lock()
+		running = false
+		for _, job := range s.jobs {
+			running = running || !job.done
+		}
+		s.mu.Unlock()
+	}
+
+	for _, want := range []string{"background\n", "first\n", "$ "} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output = %q, want %q in it", out.String(), want)
+		}
+	}
+	if !strings.Contains(errOut.String(), "first\n") || !strings.Contains(errOut.String(), "second\n") {
+		t.Errorf("errors = %q, want both stages'", errOut.String())
+	}
+}
@@ -0,0 +1,12 @@
+//go:build sqlite
+
+// SQLite support for --db and the history command uses a pure Go driver, so
+// -tags sqlite needs no cgo.
+
+package main
+
+import _ "modernc.org/sqlite"
+
+func init() {
+	sqlDriver = "sqlite"
+}
@@ -0,0 +1,60 @@
+//go:build sqlite
+
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestResultStore(t *testing.T) {
+	t.Parallel()
+	store, err := OpenResultStore(filepath.Join(t.TempDir(), "results.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	processes := mustLoadProcesses(t, "example_processes.csv")
+	params := scheduler.SchedulerParams{Quantum: 2, SwitchCost: 1, Caps: map[string]int64{"batch": 40}, Carry: scheduler.CarryBank}
+	var rr scheduler.RunResult
+	for _, name := range []string{"fcfs", "rr"} {
+		result, err := scheduler.RunSchedulerParams(name, params, processes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.Save(processes, params, result); err != nil {
+			t.Fatal(err)
+		}
+		rr = result
+	}
+
+	runs, err := store.History(HistoryFilter{Workload: workloadHash(processes)[:6]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].AvgWait > runs[1].AvgWait {
+		t.Fatalf("History() = %+v, want both runs, best average wait first", runs)
+	}
+	runs, err = store.History(HistoryFilter{Algorithm: "rr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].Quantum != 2 || runs[0].ProcessCount != 3 {
+		t.Fatalf("History(rr) = %+v, want the one rr run over 3 processes", runs)
+	}
+	if !reflect.DeepEqual(runs[0].Params, params) {
+		t.Errorf("History(rr) params = %+v, want %+v", runs[0].Params, params)
+	}
+
+	stats, err := store.ProcessStats(runs[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stats, rr.Processes) {
+		t.Errorf("ProcessStats() = %+v, want %+v", stats, rr.Processes)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/render"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region Streaming fcfs
+
+// StreamFCFS runs the CSV workload read from r first-come, first-serve as it
+// reads it, handing each process's metrics to yield as soon as they are
+// known, and returns the run's averages without a Gantt chart,
+// per-process metrics or their spread. Nothing is kept of a process once
+// yielded but its PID, to catch one repeated as the batch loaders do, so
+// memory stays small however long the workload. That takes a workload sorted
+// by arrival, which fcfs alone can run in the order read: a process arriving
+// before the one read ahead of it is an error, as is one with yields, sync
+// ops or I/O, which need the engine. Errors name the process by PID and by
+// its row, counting from 1.
+func StreamFCFS(r io.Reader, yield func(scheduler.ProcessMetrics) error) (scheduler.RunResult, error) {
+	var (
+		result      = scheduler.RunResult{Scheduler: "fcfs"}
+		serviceTime int64
+		lastArrival int64
+		busy        int64
+		n           int64
+		firstRow    = map[int64]int64{}
+	)
+	err := loader.ScanCSV(r, func(p scheduler.Process) error {
+		n++
+		switch first, repeated := firstRow[p.ProcessID]; {
+		case repeated:
+			return fmt.Errorf("%w: row %d: PID %d repeats row %d", scheduler.ErrInvalidArgs, n, p.ProcessID, first)
+		case p.ArrivalTime < lastArrival:
+			return fmt.Errorf("%w: row %d: PID %d arrives at %d, before the one ahead of it at %d; only a workload sorted by arrival can stream",
+				scheduler.ErrInvalidArgs, n, p.ProcessID, p.ArrivalTime, lastArrival)
+		case len(p.Yields) > 0 || len(p.Ops) > 0 || len(p.IO) > 0:
+			return fmt.Errorf("%w: row %d: PID %d yields, syncs or does I/O, which only a loaded workload can run",
+				scheduler.ErrInvalidArgs, n, p.ProcessID)
+		}
+		firstRow[p.ProcessID] = n
+		lastArrival = p.ArrivalTime
+		if p.ArrivalTime > serviceTime {
+			serviceTime = p.ArrivalTime
+		}
+		m := scheduler.RunToCompletion(&p, serviceTime)
+		serviceTime = m.Exit
+		busy += m.Burst
+		// Each process runs in one go, a switch of its own.
+		if m.Burst > 0 {
+			result.ContextSwitches++
+		}
+		result.AvgWait += float64(m.Wait)
+		result.AvgTurnaround += float64(m.Turnaround)
+		result.AvgResponse += float64(m.Response)
+		return yield(m)
+	})
+	if err != nil {
+		return scheduler.RunResult{}, err
+	}
+	if n > 0 {
+		result.AvgWait /= float64(n)
+		result.AvgTurnaround /= float64(n)
+		result.AvgResponse /= float64(n)
+		if serviceTime > 0 {
+			result.Throughput = float64(n) / float64(serviceTime)
+			result.Utilization = float64(busy) / float64(serviceTime)
+		}
+	}
+	return result, nil
+}
+
+//endregion
+
+//region stream command
+
+// runStream is the `stream` subcommand, which runs a CSV workload sorted by
+// arrival through fcfs without loading it, writing the metrics CSV that
+// -metrics-csv does a process at a time: `stream workload.csv`, or - for
+// stdin.
+func runStream(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("stream", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: stream needs one workload", scheduler.ErrInvalidArgs)
+	}
+	in := io.Reader(os.Stdin)
+	if path := fs.Arg(0); path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	cw := csv.NewWriter(w)
+	_ = cw.Write(render.MetricsCSVProcessHeader)
+	result, err := StreamFCFS(in, func(m scheduler.ProcessMetrics) error {
+		return cw.Write(render.MetricsCSVProcessRow("fcfs", m))
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", fs.Arg(0), err)
+	}
+	_ = cw.Write(nil)
+	_ = cw.Write(render.MetricsCSVSummaryHeader)
+	_ = cw.Write(render.MetricsCSVSummaryRow(result))
+	cw.Flush()
+	return cw.Error()
+}
+
+//endregion
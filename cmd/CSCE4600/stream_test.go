@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestStreamFCFS(t *testing.T) {
+	t.Parallel()
+	// Streaming a workload sorted by arrival gives what loading it does.
+	for _, workload := range []string{"basic", "idle", "mixed", "ties", "deadlines"} {
+		path := "testdata/workloads/" + workload + ".csv"
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []scheduler.ProcessMetrics
+		summary, err := StreamFCFS(f, func(m scheduler.ProcessMetrics) error {
+			got = append(got, m)
+			return nil
+		})
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", workload, err)
+		}
+		want := scheduler.RunFCFS(mustLoadProcesses(t, path))
+		if !reflect.DeepEqual(got, want.Processes) {
+			t.Errorf("%s: StreamFCFS() yielded %+v, want %+v", workload, got, want.Processes)
+		}
+		// Nothing is kept to spread the metrics over either.
+		want.Gantt, want.Processes = nil, nil
+		want.WaitStats, want.TurnaroundStats, want.Fairness = nil, nil, 0
+		if !reflect.DeepEqual(summary, want) {
+			t.Errorf("%s: StreamFCFS() = %+v, want %+v", workload, summary, want)
+		}
+	}
+}
+
+func TestStreamFCFS_errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "unsorted", input: "1,2,0\n2,2,5\n7,2,4\n", wantErr: "row 3: PID 7 arrives at 4, before the one ahead of it at 5"},
+		{name: "I/O", input: "1,2,0\n9,\"1,io:2,1\",1\n", wantErr: "row 2: PID 9 yields, syncs or does I/O"},
+		{name: "repeated PID", input: "4,2,0\n5,2,1\n4,1,2\n", wantErr: "row 3: PID 4 repeats row 1"},
+		{name: "bad row", input: "1,2,0\n2,x,1\n", wantErr: `line 2, column 2: "x" is not an integer`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := StreamFCFS(strings.NewReader(tt.input), func(scheduler.ProcessMetrics) error { return nil })
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("StreamFCFS() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunStreamCommand(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	if err := runStream(&out, []string{"testdata/workloads/basic.csv"}); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"scheduler,pid,arrival,burst,wait,turnaround,response,completion",
+		"fcfs,1,0,5,0,5,0,5",
+		"fcfs,2,3,9,2,11,2,14",
+		"fcfs,3,6,6,8,14,8,20",
+		"",
+		"scheduler,avg_wait,avg_turnaround,avg_response,throughput,utilization",
+		"fcfs,3.3333333333333335,10,3.3333333333333335,0.15,1",
+		"",
+	}, "\n")
+	if out.String() != want {
+		t.Errorf("stream output =\n%s\nwant\n%s", out.String(), want)
+	}
+	if err := runStream(&out, nil); err == nil {
+		t.Error("runStream() without a workload succeeded")
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region Quantum sweep
+
+// sweepMetrics are what a sweep can tune the quantum for, whether lower
+// values are better and how to print them.
+var sweepMetrics = map[string]struct {
+	value       func(scheduler.RunResult) float64
+	lowerBetter bool
+	format      string
+}{
+	"wait":       {func(r scheduler.RunResult) float64 { return r.AvgWait }, true, "%.2f"},
+	"turnaround": {func(r scheduler.RunResult) float64 { return r.AvgTurnaround }, true, "%.2f"},
+	"throughput": {func(r scheduler.RunResult) float64 { return r.Throughput }, false, "%.2f/t"},
+	"switches":   {func(r scheduler.RunResult) float64 { return float64(r.ContextSwitches) }, true, "%.0f"},
+}
+
+// Sweep runs one scheduler over a workload at every quantum from From to To
+// in steps of Step:
+// • Workers bounds how many runs go at once; 0 means GOMAXPROCS
+// • KeepDetails keeps full results rather than SummarizeScheduler's averages
+// • Params are the scheduler's other tunables, the same for every run
+type Sweep struct {
+	Scheduler   string
+	From, To    int64
+	Step        int64
+	Workers     int
+	KeepDetails bool
+	Params      scheduler.SchedulerParams
+}
+
+// Run returns one result per quantum, in quantum order.
+func (s Sweep) Run(processes []scheduler.Process) ([]scheduler.RunResult, error) {
+	info, err := scheduler.LookupScheduler(s.Scheduler)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Quantum {
+		return nil, fmt.Errorf("%w: %s has no quantum to sweep", scheduler.ErrInvalidArgs, s.Scheduler)
+	}
+	if s.From < 1 || s.To < s.From || s.Step < 1 {
+		return nil, fmt.Errorf("%w: sweep needs 1 <= from <= to and step >= 1", scheduler.ErrInvalidArgs)
+	}
+	run := scheduler.SummarizeSchedulerParams
+	if s.KeepDetails {
+		run = scheduler.RunSchedulerParams
+	}
+
+	n := int((s.To-s.From)/s.Step) + 1
+	results := make([]scheduler.RunResult, n)
+	errs := make([]error, n)
+	parallelFor(n, s.Workers, func(i int) {
+		params := s.Params
+		params.Quantum = s.From + int64(i)*s.Step
+		results[i], errs[i] = run(s.Scheduler, params, processes)
+	})
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// bestQuantum is the index of the result with the best value of metric, the
+// smallest quantum on ties.
+func bestQuantum(results []scheduler.RunResult, metric string) (int, error) {
+	m, ok := sweepMetrics[metric]
+	if !ok {
+		return 0, fmt.Errorf("%w: unknown metric %q, want one of %s", scheduler.ErrInvalidArgs, metric, strings.Join(sortedSweepMetrics(), ", "))
+	}
+	best := 0
+	for i, r := range results {
+		v, b := m.value(r), m.value(results[best])
+		if (m.lowerBetter && v < b) || (!m.lowerBetter && v > b) {
+			best = i
+		}
+	}
+	return best, nil
+}
+
+func sortedSweepMetrics() []string {
+	names := make([]string, 0, len(sweepMetrics))
+	for name := range sweepMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//endregion
+
+//region sweep command
+
+// runSweep is the `sweep` subcommand, which tunes the quantum for a workload:
+// `sweep [-scheduler rr] [-from 1] [-to 20] [-step 1] [-optimize wait] [-workers n] [-json] workload.csv`.
+func runSweep(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("sweep", flag.ContinueOnError)
+	algorithm := fs.String("scheduler", "rr", "scheduler to sweep: "+strings.Join(scheduler.SortedSchedulerNames(), ", "))
+	from := fs.Int64("from", 1, "first quantum")
+	to := fs.Int64("to", 20, "last quantum")
+	step := fs.Int64("step", 1, "quantum step")
+	optimize := fs.String("optimize", "wait", "metric to pick the best quantum by: "+strings.Join(sortedSweepMetrics(), ", "))
+	workers := fs.Int("workers", 0, "runs at once; 0 uses every CPU")
+	asJSON := fs.Bool("json", false, "print every result as JSON")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: sweep needs one workload", scheduler.ErrInvalidArgs)
+	}
+	if _, ok := sweepMetrics[*optimize]; !ok {
+		return fmt.Errorf("%w: unknown metric %q", scheduler.ErrInvalidArgs, *optimize)
+	}
+
+	processes, err := loader.LoadFile(fs.Arg(0), "")
+	if err != nil {
+		return err
+	}
+
+	results, err := Sweep{Scheduler: *algorithm, From: *from, To: *to, Step: *step, Workers: *workers, KeepDetails: *asJSON}.Run(processes)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+	best, err := bestQuantum(results, *optimize)
+	if err != nil {
+		return err
+	}
+	outputSweep(w, results, best, *optimize)
+	return nil
+}
+
+func outputSweep(w io.Writer, results []scheduler.RunResult, best int, metric string) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Quantum", "Avg wait", "Avg turnaround", "Throughput", "Switches", ""})
+	for i, r := range results {
+		mark := ""
+		if i == best {
+			mark = "best " + metric
+		}
+		table.Append([]string{fmt.Sprint(r.Quantum), fmt.Sprintf("%.2f", r.AvgWait),
+			fmt.Sprintf("%.2f", r.AvgTurnaround), fmt.Sprintf("%.2f/t", r.Throughput), fmt.Sprint(r.ContextSwitches), mark})
+	}
+	table.Render()
+}
+
+//endregion
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestSweep_Run(t *testing.T) {
+	t.Parallel()
+	processes := mustLoadProcesses(t, "testdata/workloads/mixed.csv")
+	results, err := Sweep{Scheduler: "rr", From: 1, To: 9, Step: 2, Workers: 3}.Run(processes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var quanta []int64
+	for i, r := range results {
+		quanta = append(quanta, r.Quantum)
+		want, err := scheduler.SummarizeScheduler("rr", r.Quantum, processes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(r, want) {
+			t.Errorf("result %d = %+v, want %+v", i, r, want)
+		}
+	}
+	if want := []int64{1, 3, 5, 7, 9}; !reflect.DeepEqual(quanta, want) {
+		t.Errorf("quanta = %v, want %v", quanta, want)
+	}
+
+	for _, bad := range []Sweep{
+		{Scheduler: "fcfs", From: 1, To: 2, Step: 1},
+		{Scheduler: "rr", From: 0, To: 2, Step: 1},
+		{Scheduler: "rr", From: 3, To: 2, Step: 1},
+		{Scheduler: "rr", From: 1, To: 2, Step: 0},
+	} {
+		if _, err := bad.Run(processes); err == nil {
+			t.Errorf("%+v.Run() succeeded", bad)
+		}
+	}
+}
+
+func Test_bestQuantum(t *testing.T) {
+	t.Parallel()
+	results := []scheduler.RunResult{
+		{Quantum: 1, AvgWait: 5, AvgTurnaround: 9, Throughput: 0.2},
+		{Quantum: 2, AvgWait: 3, AvgTurnaround: 9, Throughput: 0.3},
+		{Quantum: 3, AvgWait: 3, AvgTurnaround: 8, Throughput: 0.1},
+	}
+	tests := []struct {
+		metric  string
+		want    int
+		wantErr bool
+	}{
+		{metric: "wait", want: 1},
+		{metric: "turnaround", want: 2},
+		{metric: "throughput", want: 1},
+		{metric: "fairness", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.metric, func(t *testing.T) {
+			t.Parallel()
+			got, err := bestQuantum(results, tt.metric)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("bestQuantum() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("bestQuantum() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunSweep(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	if err := runSweep(&out, []string{"-to", "4", "-optimize", "turnaround", "testdata/workloads/mixed.csv"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(out.String(), "/t"); got != 4 {
+		t.Errorf("output has %d rows, want 4:\n%s", got, out.String())
+	}
+	if !strings.Contains(out.String(), "best turnaround") {
+		t.Errorf("output marks no best quantum:\n%s", out.String())
+	}
+}
+
+func TestQuantumSearch_Run(t *testing.T) {
+	t.Parallel()
+	processes := mustLoadProcesses(t, "testdata/workloads/mixed.csv")
+	params := scheduler.SchedulerParams{SwitchCost: 1}
+	sweep, err := QuantumSearch{Scheduler: "rr", From: 1, To: 20, Metric: "turnaround", Search: SearchSweep, Params: params}.Run(processes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sweep.Results) != 20 {
+		t.Errorf("sweep tried %d quanta, want 20", len(sweep.Results))
+	}
+	// A switch cost makes the smallest quanta the slowest.
+	if sweep.BestQuantum != 5 {
+		t.Errorf("sweep's best quantum = %d, want 5", sweep.BestQuantum)
+	}
+
+	anneal := QuantumSearch{Scheduler: "rr", From: 1, To: 20, Metric: "turnaround", Search: SearchAnneal, Steps: 40, Seed: 1, Params: params}
+	got, err := anneal.Run(processes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.BestQuantum != sweep.BestQuantum {
+		t.Errorf("annealing's best quantum = %d, want the sweep's %d", got.BestQuantum, sweep.BestQuantum)
+	}
+	for i, r := range got.Results {
+		if want := sweep.Results[r.Quantum-1]; !reflect.DeepEqual(r, want) {
+			t.Errorf("annealing result %d = %+v, want %+v", i, r, want)
+		}
+		if i > 0 && r.Quantum <= got.Results[i-1].Quantum {
+			t.Errorf("annealing results are not in quantum order: %d after %d", r.Quantum, got.Results[i-1].Quantum)
+		}
+	}
+	again, err := anneal.Run(processes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again, got) {
+		t.Error("annealing twice with the same seed searched differently")
+	}
+
+	for _, bad := range []QuantumSearch{
+		{Scheduler: "rr", From: 1, To: 2, Metric: "fairness", Search: SearchSweep},
+		{Scheduler: "rr", From: 1, To: 2, Metric: "wait", Search: "bisect"},
+		{Scheduler: "fcfs", From: 1, To: 2, Metric: "wait", Search: SearchAnneal, Steps: 5},
+		{Scheduler: "rr", From: 0, To: 2, Metric: "wait", Search: SearchAnneal, Steps: 5},
+		{Scheduler: "rr", From: 1, To: 2, Metric: "wait", Search: SearchAnneal},
+	} {
+		if _, err := bad.Run(processes); err == nil {
+			t.Errorf("%+v.Run() succeeded", bad)
+		}
+	}
+}
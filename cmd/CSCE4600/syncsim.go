@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/render"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+// semValues is the -sem flag: repeated or comma-separated name=value pairs.
+type semValues map[string]int64
+
+//region Synchronization simulations
+
+// outputSyncTable prints the per-process view shared by the synchronization
+// simulations: CPU used, time spent blocked on semaphores, time spent ready but
+// not running, and completion time. Deadlocked processes have no exit time.
+func outputSyncTable(w io.Writer, tr scheduler.Trace) {
+	blocked := make(map[*scheduler.Task]bool, len(tr.Blocked))
+	for _, t := range tr.Blocked {
+		blocked[t] = true
+	}
+
+	_, _ = fmt.Fprintln(w, "Process table")
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"ID", "Name", "CPU", "Blocked", "Ready", "Exit"})
+	var totalBlocked int64
+	for _, t := range tr.Tasks {
+		exit, ready := "deadlocked", "-"
+		if !blocked[t] {
+			exit = fmt.Sprint(t.Exit)
+			ready = fmt.Sprint(t.Exit - t.ArrivalTime - t.BurstDuration - t.Blocked)
+		}
+		totalBlocked += t.Blocked
+		table.Append([]string{
+			fmt.Sprint(t.ProcessID),
+			t.Name,
+			fmt.Sprint(t.Used),
+			fmt.Sprint(t.Blocked),
+			ready,
+			exit,
+		})
+	}
+	table.SetFooter([]string{"", "", "", fmt.Sprintf("Average\n%.2f", float64(totalBlocked)/float64(len(tr.Tasks))), "", ""})
+	table.Render()
+}
+
+//endregion
+
+//region sync command
+
+// runSync is the `sync` subcommand: it runs a workload whose eighth column
+// holds sync ops through the engine once per wakeup policy, so the policies
+// can be compared on the same semaphores and mutexes.
+func runSync(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("sync", flag.ContinueOnError)
+	var (
+		sems    = semValues{}
+		wakeups = fs.String("wakeup", "fifo,priority", "comma-separated wakeup policies to compare: fifo, lifo, priority")
+		quantum = fs.Int64("quantum", 1, "round-robin quantum; 0 runs each process until it blocks or finishes")
+	)
+	fs.Var(sems, "sem", "initial semaphore value as name=value; may be repeated")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: sync needs a workload file", scheduler.ErrInvalidArgs)
+	}
+
+	processes, err := loader.LoadFile(fs.Arg(0), loader.FormatCSV)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range strings.Split(*wakeups, ",") {
+		policy, err := scheduler.ParseWakeupPolicy(strings.TrimSpace(name))
+		if err != nil {
+			return err
+		}
+		engine := scheduler.Engine{Queue: &scheduler.FIFOQueue{}, Quantum: *quantum, Semaphores: sems, Wakeup: policy}
+		tr := engine.Simulate(processes)
+		render.Title(w, "Synchronization: "+policy.String()+" wakeup")
+		render.Gantt(w, tr.Gantt)
+		outputSyncTable(w, tr)
+		if len(tr.Blocked) > 0 {
+			_, _ = fmt.Fprintf(w, "Deadlock: %d processes blocked forever\n", len(tr.Blocked))
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+
+	return nil
+}
+
+func (s semValues) String() string {
+	return fmt.Sprint(map[string]int64(s))
+}
+
+func (s semValues) Set(v string) error {
+	for _, pair := range strings.Split(v, ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if !ok || name == "" || err != nil || n < 0 {
+			return fmt.Errorf("semaphore %q is not name=value", pair)
+		}
+		s[strings.TrimSpace(name)] = n
+	}
+	return nil
+}
+
+//endregion
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func Test_parseSyncOps(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		field   string
+		want    []scheduler.SyncOp
+		wantErr error
+	}{
+		{
+			name:  "sorted by time",
+			field: "4:unlock:m;1:lock:m;2:P:s",
+			want: []scheduler.SyncOp{
+				{At: 1, Op: scheduler.MutexLock, Object: "m"},
+				{At: 2, Op: scheduler.SemWait, Object: "s"},
+				{At: 4, Op: scheduler.MutexUnlock, Object: "m"},
+			},
+		},
+		{name: "missing object", field: "1:P", wantErr: scheduler.ErrInvalidArgs},
+		{name: "bad time", field: "x:P:s", wantErr: scheduler.ErrInvalidArgs},
+		{name: "unknown op", field: "1:grab:s", wantErr: scheduler.ErrInvalidArgs},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := loader.ParseSyncOps(tt.field)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parseSyncOps() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSyncOps() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region Merged timeline
+
+type (
+	// TimelineEntry is one event of a merged timeline, tagged with the run it
+	// came from as scheduler.RunLabel names it.
+	TimelineEntry struct {
+		Scheduler string `json:"scheduler"`
+		scheduler.Event
+	}
+	// Timeline interleaves the events of several runs of one workload.
+	// Diverge is the time of the first dispatch the runs disagree on, or -1
+	// if they never do.
+	Timeline struct {
+		Entries []TimelineEntry `json:"entries"`
+		Diverge int64           `json:"diverge"`
+	}
+)
+
+// MergeTimelines merges the events of results in time order. Events at the
+// same time keep the order of results, and each run's own order among them.
+func MergeTimelines(results []scheduler.RunResult) Timeline {
+	var n int
+	for _, r := range results {
+		n += len(r.Events)
+	}
+	tl := Timeline{Entries: make([]TimelineEntry, 0, n), Diverge: firstDivergence(results)}
+	for _, r := range results {
+		label := scheduler.RunLabel(r)
+		for _, ev := range r.Events {
+			tl.Entries = append(tl.Entries, TimelineEntry{Scheduler: label, Event: ev})
+		}
+	}
+	sort.SliceStable(tl.Entries, func(i, j int) bool { return tl.Entries[i].Time < tl.Entries[j].Time })
+	return tl
+}
+
+// firstDivergence compares the runs' dispatches in order and returns the
+// earliest time at which one run dispatches a different process, or at a
+// different time, from the others. It is -1 if every run dispatches alike.
+func firstDivergence(results []scheduler.RunResult) int64 {
+	dispatches := make([][]scheduler.Event, len(results))
+	longest := 0
+	for i, r := range results {
+		for _, ev := range r.Events {
+			if ev.Kind == scheduler.EventDispatch {
+				dispatches[i] = append(dispatches[i], ev)
+			}
+		}
+		if len(dispatches[i]) > longest {
+			longest = len(dispatches[i])
+		}
+	}
+	for k := 0; k < longest; k++ {
+		diverge, agree := int64(-1), true
+		for _, d := range dispatches {
+			if k >= len(d) {
+				agree = false
+				continue
+			}
+			ev := d[k]
+			if k >= len(dispatches[0]) || ev.Time != dispatches[0][k].Time || ev.PID != dispatches[0][k].PID {
+				agree = false
+			}
+			if diverge < 0 || ev.Time < diverge {
+				diverge = ev.Time
+			}
+		}
+		if !agree {
+			return diverge
+		}
+	}
+	return -1
+}
+
+//endregion
+
+//region timeline command
+
+// runTimeline is the `timeline` subcommand, which prints every event of the
+// schedulers' runs over one workload as a single log:
+// `timeline [-schedulers sjf,rr] [-quantum 2] [-json] workload.csv`.
+func runTimeline(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("timeline", flag.ContinueOnError)
+	schedulers := fs.String("schedulers", strings.Join(scheduler.SortedSchedulerNames(), ","), "comma-separated schedulers to run")
+	quantum := fs.Int64("quantum", scheduler.DefaultQuantum, "round-robin quantum")
+	asJSON := fs.Bool("json", false, "print the timeline as JSON")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: timeline needs one workload", scheduler.ErrInvalidArgs)
+	}
+
+	processes, err := loader.LoadFile(fs.Arg(0), "")
+	if err != nil {
+		return err
+	}
+
+	names, err := parseSchedulers(*schedulers)
+	if err != nil {
+		return err
+	}
+	var results []scheduler.RunResult
+	for _, name := range names {
+		result, err := scheduler.RunScheduler(name, *quantum, processes)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+	}
+	tl := MergeTimelines(results)
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(tl)
+	}
+	outputTimeline(w, tl)
+	return nil
+}
+
+func outputTimeline(w io.Writer, tl Timeline) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Time", "Scheduler", "Event", "PID", "Note", ""})
+	for _, e := range tl.Entries {
+		mark := ""
+		if e.Time == tl.Diverge && e.Kind == scheduler.EventDispatch {
+			mark = "first divergence"
+		}
+		table.Append([]string{fmt.Sprint(e.Time), e.Scheduler, e.Kind.String(), fmt.Sprint(e.PID), e.Note, mark})
+	}
+	table.Render()
+	if tl.Diverge < 0 {
+		_, _ = fmt.Fprintln(w, "Every scheduler made the same dispatches.")
+	}
+}
+
+//endregion
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestMergeTimelines(t *testing.T) {
+	t.Parallel()
+	a := scheduler.RunResult{Scheduler: "fcfs", Events: []scheduler.Event{
+		{Time: 0, Kind: scheduler.EventDispatch, PID: 1},
+		{Time: 3, Kind: scheduler.EventComplete, PID: 1},
+		{Time: 3, Kind: scheduler.EventDispatch, PID: 2},
+	}}
+	b := scheduler.RunResult{Scheduler: "rr", Quantum: 2, Events: []scheduler.Event{
+		{Time: 0, Kind: scheduler.EventDispatch, PID: 1},
+		{Time: 2, Kind: scheduler.EventPreempt, PID: 1},
+		{Time: 2, Kind: scheduler.EventDispatch, PID: 2},
+	}}
+	tl := MergeTimelines([]scheduler.RunResult{a, b})
+	var got []string
+	for _, e := range tl.Entries {
+		got = append(got, e.Scheduler+" "+e.Kind.String())
+	}
+	want := "fcfs dispatch,rr (q=2) dispatch,rr (q=2) preempt,rr (q=2) dispatch,fcfs complete,fcfs dispatch"
+	if strings.Join(got, ",") != want {
+		t.Errorf("MergeTimelines() = %v, want %v", strings.Join(got, ","), want)
+	}
+	if tl.Diverge != 2 {
+		t.Errorf("Diverge = %d, want 2", tl.Diverge)
+	}
+}
+
+func Test_firstDivergence(t *testing.T) {
+	t.Parallel()
+	processes := mustLoadProcesses(t, "testdata/workloads/mixed.csv")
+	run := func(name string, quantum int64) scheduler.RunResult {
+		r, err := scheduler.RunScheduler(name, quantum, processes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	tests := []struct {
+		name    string
+		results []scheduler.RunResult
+		want    int64
+	}{
+		{name: "same run", results: []scheduler.RunResult{run("sjf", 0), run("sjf", 0)}, want: -1},
+		{name: "one run", results: []scheduler.RunResult{run("rr", 0)}, want: -1},
+		{name: "sjf and rr", results: []scheduler.RunResult{run("sjf", 0), run("rr", 2)}, want: 2},
+		{name: "quantum longer than every burst", results: []scheduler.RunResult{run("fcfs", 0), run("rr", 100)}, want: -1},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := firstDivergence(tt.results); got != tt.want {
+				t.Errorf("firstDivergence() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunTimelineCommand(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	if err := runTimeline(&out, []string{"-schedulers", "sjf,rr", "testdata/workloads/mixed.csv"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"| sjf ", "| rr (q=2) ", "first divergence"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("output missing %q:\n%s", s, out.String())
+		}
+	}
+	if err := runTimeline(&out, []string{"-schedulers", "sjf,nope", "testdata/workloads/mixed.csv"}); err == nil {
+		t.Error("runTimeline() with an unknown scheduler did not fail")
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/Sha-min/CSCE4600/pkg/render"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region Address translation
+
+// Translation faults.
+const (
+	FaultPage    = "page fault"
+	FaultBounds  = "offset beyond limit"
+	FaultSegment = "no such segment"
+)
+
+type (
+	// PageTable maps resident pages to frames; a page missing from the map is
+	// not in memory.
+	PageTable map[int64]int64
+	// Segment is one entry of a segment table.
+	Segment struct {
+		Base  int64
+		Limit int64
+	}
+	// LogicalAddress is either a flat address for paging or, for segmentation,
+	// a segment number and offset.
+	LogicalAddress struct {
+		Text    string
+		Unit    int64
+		Offset  int64
+		Address int64
+	}
+	// Translation is the outcome of translating one logical address. Unit is
+	// the page or segment number; Fault is empty when Physical is valid.
+	Translation struct {
+		Logical  LogicalAddress
+		Unit     int64
+		Offset   int64
+		Physical int64
+		Fault    string
+	}
+)
+
+// TranslatePaged splits addr into page and offset and looks the page up.
+func TranslatePaged(table PageTable, pageSize int64, addr LogicalAddress) Translation {
+	tr := Translation{Logical: addr, Unit: addr.Address / pageSize, Offset: addr.Address % pageSize}
+	frame, ok := table[tr.Unit]
+	if !ok {
+		tr.Fault = FaultPage
+		return tr
+	}
+	tr.Physical = frame*pageSize + tr.Offset
+	return tr
+}
+
+// TranslateSegmented checks the offset against the segment's limit and adds its base.
+func TranslateSegmented(table []Segment, addr LogicalAddress) Translation {
+	tr := Translation{Logical: addr, Unit: addr.Unit, Offset: addr.Offset}
+	switch {
+	case addr.Unit < 0 || addr.Unit >= int64(len(table)):
+		tr.Fault = FaultSegment
+	case addr.Offset >= table[addr.Unit].Limit:
+		tr.Fault = FaultBounds
+	default:
+		tr.Physical = table[addr.Unit].Base + addr.Offset
+	}
+	return tr
+}
+
+// ParseLogicalAddress reads a decimal or 0x-prefixed hex address. A
+// segment:offset pair is taken as is; with offsetBits > 0 a flat address is
+// split into segment and offset, as the hardware would.
+func ParseLogicalAddress(s string, offsetBits uint) (LogicalAddress, error) {
+	addr := LogicalAddress{Text: s}
+	if seg, off, ok := strings.Cut(s, ":"); ok {
+		var err1, err2 error
+		addr.Unit, err1 = strconv.ParseInt(seg, 0, 64)
+		addr.Offset, err2 = strconv.ParseInt(off, 0, 64)
+		if err1 != nil || err2 != nil || addr.Unit < 0 || addr.Offset < 0 {
+			return LogicalAddress{}, fmt.Errorf("%w: bad segment:offset address %q", scheduler.ErrInvalidArgs, s)
+		}
+		return addr, nil
+	}
+
+	v, err := strconv.ParseInt(s, 0, 64)
+	if err != nil || v < 0 {
+		return LogicalAddress{}, fmt.Errorf("%w: bad logical address %q", scheduler.ErrInvalidArgs, s)
+	}
+	addr.Address = v
+	if offsetBits > 0 {
+		addr.Unit, addr.Offset = v>>offsetBits, v&(1<<offsetBits-1)
+	}
+	return addr, nil
+}
+
+//endregion
+
+//region translate command
+
+// runTranslate is the `translate` subcommand. Exactly one of -pages and
+// -segments names the table; addresses come from -addrs or the remaining
+// arguments.
+func runTranslate(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("translate", flag.ContinueOnError)
+	var (
+		pagesFile    = fs.String("pages", "", "page table file: one 'page frame' pair per line, frame - if not resident")
+		segmentsFile = fs.String("segments", "", "segment table file: one 'base limit' pair per line, in segment order")
+		pageSize     = fs.Int64("page-size", 1024, "page size in bytes")
+		offsetBits   = fs.Uint("offset-bits", 0, "with -segments, split flat addresses into segment and this many offset bits")
+		addrs        = fs.String("addrs", "", "comma-separated logical addresses, decimal or 0x hex, or seg:offset for segmentation")
+	)
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+	if (*pagesFile == "") == (*segmentsFile == "") {
+		return fmt.Errorf("%w: translate needs exactly one of -pages and -segments", scheduler.ErrInvalidArgs)
+	}
+
+	var fields []string
+	if *addrs != "" {
+		fields = strings.Split(*addrs, ",")
+	}
+	fields = append(fields, fs.Args()...)
+	if len(fields) == 0 {
+		return fmt.Errorf("%w: translate needs logical addresses", scheduler.ErrInvalidArgs)
+	}
+	if *segmentsFile == "" {
+		// Flat addresses are the only kind paging understands.
+		*offsetBits = 0
+	}
+	logical := make([]LogicalAddress, len(fields))
+	for i := range fields {
+		addr, err := ParseLogicalAddress(strings.TrimSpace(fields[i]), *offsetBits)
+		if err != nil {
+			return err
+		}
+		logical[i] = addr
+	}
+
+	results := make([]Translation, len(logical))
+	if *pagesFile != "" {
+		if *pageSize <= 0 {
+			return fmt.Errorf("%w: page size must be positive", scheduler.ErrInvalidArgs)
+		}
+		f, err := openTableFile(*pagesFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		table, err := loadPageTable(f)
+		if err != nil {
+			return err
+		}
+		for i, addr := range logical {
+			if strings.Contains(addr.Text, ":") {
+				return fmt.Errorf("%w: %s is a segment:offset address", scheduler.ErrInvalidArgs, addr.Text)
+			}
+			results[i] = TranslatePaged(table, *pageSize, addr)
+		}
+		render.Title(w, fmt.Sprintf("Paging, %d-byte pages", *pageSize))
+		outputTranslations(w, "Page", results)
+		return nil
+	}
+
+	f, err := openTableFile(*segmentsFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	table, err := loadSegmentTable(f)
+	if err != nil {
+		return err
+	}
+	for i, addr := range logical {
+		if *offsetBits == 0 && !strings.Contains(addr.Text, ":") {
+			return fmt.Errorf("%w: %s needs to be seg:offset, or set -offset-bits", scheduler.ErrInvalidArgs, addr.Text)
+		}
+		results[i] = TranslateSegmented(table, addr)
+	}
+	render.Title(w, "Segmentation")
+	outputTranslations(w, "Segment", results)
+
+	return nil
+}
+
+func openTableFile(name string) (*os.File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("%v: error opening table file", err)
+	}
+	return f, nil
+}
+
+// loadPageTable reads `page frame` lines; a frame of - marks a page that is
+// not resident. Blank lines and # comments are skipped.
+func loadPageTable(r io.Reader) (PageTable, error) {
+	table := PageTable{}
+	err := scanTableRows(r, func(line int, fields []string) error {
+		page, err := strconv.ParseInt(fields[0], 0, 64)
+		if err != nil || page < 0 {
+			return fmt.Errorf("%w: line %d: bad page number %q", scheduler.ErrInvalidArgs, line, fields[0])
+		}
+		if fields[1] == "-" {
+			return nil
+		}
+		frame, err := strconv.ParseInt(fields[1], 0, 64)
+		if err != nil || frame < 0 {
+			return fmt.Errorf("%w: line %d: bad frame number %q", scheduler.ErrInvalidArgs, line, fields[1])
+		}
+		table[page] = frame
+		return nil
+	})
+	return table, err
+}
+
+// loadSegmentTable reads `base limit` lines, the first being segment 0.
+func loadSegmentTable(r io.Reader) ([]Segment, error) {
+	var table []Segment
+	err := scanTableRows(r, func(line int, fields []string) error {
+		base, err1 := strconv.ParseInt(fields[0], 0, 64)
+		limit, err2 := strconv.ParseInt(fields[1], 0, 64)
+		if err1 != nil || err2 != nil || base < 0 || limit < 0 {
+			return fmt.Errorf("%w: line %d: base and limit must be non-negative integers", scheduler.ErrInvalidArgs, line)
+		}
+		table = append(table, Segment{Base: base, Limit: limit})
+		return nil
+	})
+	return table, err
+}
+
+func scanTableRows(r io.Reader, row func(line int, fields []string) error) error {
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return fmt.Errorf("%w: line %d: expected 2 fields, got %d", scheduler.ErrInvalidArgs, line, len(fields))
+		}
+		if err := row(line, fields); err != nil {
+			return err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("%w: reading table", err)
+	}
+	return nil
+}
+
+func outputTranslations(w io.Writer, unit string, results []Translation) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Logical", "Logical hex", unit, "Offset", "Physical", "Physical hex"})
+	for _, tr := range results {
+		logical := tr.Logical.Text
+		hex := fmt.Sprintf("0x%X", tr.Logical.Address)
+		if strings.Contains(logical, ":") {
+			hex = fmt.Sprintf("0x%X:0x%X", tr.Logical.Unit, tr.Logical.Offset)
+		} else {
+			logical = fmt.Sprint(tr.Logical.Address)
+		}
+		physical, physicalHex := tr.Fault, ""
+		if tr.Fault == "" {
+			physical, physicalHex = fmt.Sprint(tr.Physical), fmt.Sprintf("0x%X", tr.Physical)
+		}
+		table.Append([]string{logical, hex, fmt.Sprint(tr.Unit), fmt.Sprint(tr.Offset), physical, physicalHex})
+	}
+	table.Render()
+	_, _ = fmt.Fprintln(w)
+}
+
+//endregion
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestTranslatePaged(t *testing.T) {
+	t.Parallel()
+	table, err := loadPageTable(strings.NewReader(loadFixture(t, "example_pages.txt")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		addr      string
+		wantPage  int64
+		wantPhys  int64
+		wantFault string
+	}{
+		{addr: "1234", wantPage: 1, wantPhys: 2258},
+		{addr: "0xC10", wantPage: 3, wantPhys: 7184},
+		{addr: "2100", wantPage: 2, wantFault: FaultPage},
+		{addr: "9000", wantPage: 8, wantFault: FaultPage},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.addr, func(t *testing.T) {
+			t.Parallel()
+			addr, err := ParseLogicalAddress(tt.addr, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := TranslatePaged(table, 1024, addr)
+			if got.Unit != tt.wantPage || got.Fault != tt.wantFault || (tt.wantFault == "" && got.Physical != tt.wantPhys) {
+				t.Errorf("TranslatePaged(%s) = page %d, physical %d, fault %q; want page %d, physical %d, fault %q",
+					tt.addr, got.Unit, got.Physical, got.Fault, tt.wantPage, tt.wantPhys, tt.wantFault)
+			}
+		})
+	}
+}
+
+func TestTranslateSegmented(t *testing.T) {
+	t.Parallel()
+	table, err := loadSegmentTable(strings.NewReader(loadFixture(t, "example_segments.txt")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		addr       string
+		offsetBits uint
+		wantPhys   int64
+		wantFault  string
+	}{
+		{addr: "0:430", wantPhys: 649},
+		{addr: "1:10", wantPhys: 2310},
+		{addr: "2:500", wantFault: FaultBounds},
+		{addr: "4:112", wantFault: FaultBounds},
+		{addr: "9:0", wantFault: FaultSegment},
+		{addr: "0x3190", offsetBits: 12, wantPhys: 1727},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.addr, func(t *testing.T) {
+			t.Parallel()
+			addr, err := ParseLogicalAddress(tt.addr, tt.offsetBits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := TranslateSegmented(table, addr)
+			if got.Fault != tt.wantFault || (tt.wantFault == "" && got.Physical != tt.wantPhys) {
+				t.Errorf("TranslateSegmented(%s) = physical %d, fault %q; want physical %d, fault %q",
+					tt.addr, got.Physical, got.Fault, tt.wantPhys, tt.wantFault)
+			}
+		})
+	}
+}
+
+func TestParseLogicalAddress(t *testing.T) {
+	t.Parallel()
+	for _, in := range []string{"", "-4", "0xZZ", "1:", "a:4"} {
+		in := in
+		t.Run(in, func(t *testing.T) {
+			t.Parallel()
+			if _, err := ParseLogicalAddress(in, 0); !errors.Is(err, scheduler.ErrInvalidArgs) {
+				t.Errorf("ParseLogicalAddress(%q) error = %v, want %v", in, err, scheduler.ErrInvalidArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/render"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region Step-through TUI
+
+const (
+	// tuiClear homes the cursor and clears the terminal before each frame.
+	tuiClear = "\x1b[H\x1b[2J"
+	// Playback speeds in ticks per second: faster and slower double and
+	// halve it within these bounds.
+	defaultTUISpeed = 2
+	minTUISpeed     = 0.25
+	maxTUISpeed     = 64
+)
+
+type (
+	// TUI plays a finished run back tick by tick, redrawing the running
+	// process, the ready queue and the Gantt chart so far on each one. It
+	// reads one command per line from In: an empty line steps a tick, and
+	// the rest are listed under each frame. When In ends it quits, unless it
+	// is playing, in which case it plays on to the end of the run first.
+	// Clear redraws in place on an ANSI terminal rather than printing frames
+	// one after another. Speed is in ticks per second.
+	TUI struct {
+		In     io.Reader
+		Out    io.Writer
+		Clear  bool
+		Speed  float64
+		Title  string
+		Result scheduler.RunResult
+
+		now     int64
+		end     int64
+		playing bool
+	}
+	// TUIFrame is a run at one tick: on each CPU the PID running from Time,
+	// or IdlePID, the processes ready to run, in the order they became ready,
+	// the events logged at Time and the Gantt chart up to it.
+	TUIFrame struct {
+		Time    int64
+		Running []int64
+		Ready   []int64
+		Events  []scheduler.Event
+		Gantt   []scheduler.TimeSlice
+	}
+)
+
+// Frame returns result as it stood at time now.
+func Frame(result scheduler.RunResult, now int64) TUIFrame {
+	cpus := len(result.CPUs)
+	if cpus < 1 {
+		cpus = 1
+	}
+	frame := TUIFrame{Time: now, Running: make([]int64, cpus)}
+	for i := range frame.Running {
+		frame.Running[i] = scheduler.IdlePID
+	}
+	for _, s := range result.Gantt {
+		if s.Start >= now {
+			if s.Start == now && s.Stop > now {
+				frame.Running[s.CPU] = s.PID
+			}
+			continue
+		}
+		if s.Stop > now {
+			frame.Running[s.CPU] = s.PID
+			s.Stop = now
+		}
+		frame.Gantt = append(frame.Gantt, s)
+	}
+
+	// Replay the events up to now: arriving, being preempted or yielding,
+	// waking and being let back under a cap join the queue, and being
+	// dispatched, blocking, being throttled or completing leave it. The
+	// engine logs arrivals at its next decision, so they join at the
+	// process's arrival time rather than when logged.
+	arrival := make(map[int64]int64, len(result.Processes))
+	for _, m := range result.Processes {
+		arrival[m.PID] = m.Arrival
+	}
+	replay := make([]scheduler.Event, 0, len(result.Events))
+	for _, ev := range result.Events {
+		if ev.Time == now {
+			frame.Events = append(frame.Events, ev)
+		}
+		if at, ok := arrival[ev.PID]; ok && ev.Kind == scheduler.EventArrive {
+			ev.Time = at
+		}
+		if ev.Time <= now {
+			replay = append(replay, ev)
+		}
+	}
+	sort.SliceStable(replay, func(i, j int) bool { return replay[i].Time < replay[j].Time })
+	for _, ev := range replay {
+		switch ev.Kind {
+		case scheduler.EventArrive, scheduler.EventPreempt, scheduler.EventYield, scheduler.EventWake, scheduler.EventUnthrottle:
+			frame.Ready = removePID(frame.Ready, ev.PID)
+			frame.Ready = append(frame.Ready, ev.PID)
+		case scheduler.EventDispatch, scheduler.EventBlock, scheduler.EventThrottle, scheduler.EventComplete:
+			frame.Ready = removePID(frame.Ready, ev.PID)
+		}
+	}
+	return frame
+}
+
+// removePID removes the first pid from pids, if it is there.
+func removePID(pids []int64, pid int64) []int64 {
+	for i, p := range pids {
+		if p == pid {
+			return append(pids[:i], pids[i+1:]...)
+		}
+	}
+	return pids
+}
+
+// Run plays the run back until the user quits or In ends.
+func (t *TUI) Run() error {
+	if t.Speed <= 0 {
+		t.Speed = defaultTUISpeed
+	}
+	for _, s := range t.Result.Gantt {
+		if s.Stop > t.end {
+			t.end = s.Stop
+		}
+	}
+	lines := make(chan string)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(lines)
+		sc := bufio.NewScanner(t.In)
+		for sc.Scan() {
+			select {
+			case lines <- sc.Text():
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var tick *time.Ticker
+	setPlaying := func(playing bool) {
+		if tick != nil {
+			tick.Stop()
+			tick = nil
+		}
+		t.playing = playing && t.now < t.end
+		if t.playing {
+			tick = time.NewTicker(time.Duration(float64(time.Second) / t.Speed))
+		}
+	}
+	defer setPlaying(false)
+
+	t.render()
+	for {
+		var ticks <-chan time.Time
+		if tick != nil {
+			ticks = tick.C
+		}
+		select {
+		case <-ticks:
+			t.now++
+			if t.now >= t.end {
+				setPlaying(false)
+			}
+		case line, ok := <-lines:
+			if !ok {
+				if !t.playing {
+					return nil
+				}
+				// Play on to the end with no more input to wait for.
+				lines = nil
+				continue
+			}
+			switch strings.TrimSpace(line) {
+			case "", "s", "step":
+				setPlaying(false)
+				if t.now < t.end {
+					t.now++
+				}
+			case "b", "back":
+				setPlaying(false)
+				if t.now > 0 {
+					t.now--
+				}
+			case "p", "play", "pause":
+				setPlaying(!t.playing)
+			case "+", "faster":
+				if t.Speed *= 2; t.Speed > maxTUISpeed {
+					t.Speed = maxTUISpeed
+				}
+				setPlaying(t.playing)
+			case "-", "slower":
+				if t.Speed /= 2; t.Speed < minTUISpeed {
+					t.Speed = minTUISpeed
+				}
+				setPlaying(t.playing)
+			case "r", "restart":
+				setPlaying(false)
+				t.now = 0
+			case "q", "quit":
+				return nil
+			default:
+				_, _ = fmt.Fprintf(t.Out, "unknown command %q\n", line)
+				continue
+			}
+		}
+		t.render()
+		if lines == nil && !t.playing {
+			return nil
+		}
+	}
+}
+
+// render draws the frame at the current tick.
+func (t *TUI) render() {
+	frame := Frame(t.Result, t.now)
+	if t.Clear {
+		_, _ = io.WriteString(t.Out, tuiClear)
+	}
+	render.Title(t.Out, t.Title)
+	state := "paused"
+	if t.playing {
+		state = "playing"
+	}
+	_, _ = fmt.Fprintf(t.Out, "Time %d of %d, %s at %g ticks/s\n", t.now, t.end, state, t.Speed)
+	for i, pid := range frame.Running {
+		if len(frame.Running) > 1 {
+			_, _ = fmt.Fprintf(t.Out, "CPU %d running: %s\n", i, tuiPID(pid))
+		} else {
+			_, _ = fmt.Fprintf(t.Out, "Running: %s\n", tuiPID(pid))
+		}
+	}
+	ready := make([]string, len(frame.Ready))
+	for i, pid := range frame.Ready {
+		ready[i] = fmt.Sprint(pid)
+	}
+	if len(ready) == 0 {
+		ready = []string{"empty"}
+	}
+	_, _ = fmt.Fprintf(t.Out, "Ready: %s\n", strings.Join(ready, " "))
+	for _, ev := range frame.Events {
+		if ev.Note != "" {
+			_, _ = fmt.Fprintf(t.Out, "  %s %d (%s)\n", ev.Kind, ev.PID, ev.Note)
+		} else {
+			_, _ = fmt.Fprintf(t.Out, "  %s %d\n", ev.Kind, ev.PID)
+		}
+	}
+	_, _ = fmt.Fprintln(t.Out)
+	names := render.ProcessNames(t.Result.Processes)
+	if len(frame.Running) > 1 {
+		rows := make([][]scheduler.TimeSlice, len(frame.Running))
+		for _, s := range frame.Gantt {
+			rows[s.CPU] = append(rows[s.CPU], s)
+		}
+		for i, row := range rows {
+			_, _ = fmt.Fprintf(t.Out, "CPU %d ", i)
+			render.NamedGantt(t.Out, row, names)
+		}
+	} else {
+		render.NamedGantt(t.Out, frame.Gantt, names)
+	}
+	_, _ = fmt.Fprintln(t.Out, "[enter] step  b back  p play/pause  + faster  - slower  r restart  q quit")
+}
+
+// tuiPID names what a CPU is running.
+func tuiPID(pid int64) string {
+	switch pid {
+	case scheduler.IdlePID:
+		return "idle"
+	case scheduler.SwitchPID:
+		return "context switch"
+	}
+	return fmt.Sprint(pid)
+}
+
+//endregion
+
+//region tui command
+
+// runTUI is the `tui` subcommand, which steps through one scheduler's run
+// over a workload on the terminal:
+// `tui [-scheduler rr] [-quantum 2] [-cpus 2] [-speed 4] workload.csv`.
+func runTUI(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("tui", flag.ContinueOnError)
+	algorithm := fs.String("scheduler", "rr", "scheduler to step through")
+	quantum := fs.Int64("quantum", scheduler.DefaultQuantum, "quantum for the schedulers that take one, such as rr")
+	cpus := fs.Int("cpus", 1, "identical CPUs sharing the ready queue, for the schedulers that can use several")
+	speed := fs.Float64("speed", defaultTUISpeed, "ticks per second when playing")
+	plain := fs.Bool("plain", false, "print frames one after another rather than redrawing the screen")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: tui needs one workload", scheduler.ErrInvalidArgs)
+	}
+	if *speed < minTUISpeed || *speed > maxTUISpeed {
+		return fmt.Errorf("%w: speed %g must be from %g to %g ticks per second", scheduler.ErrInvalidArgs, *speed, float64(minTUISpeed), float64(maxTUISpeed))
+	}
+	info, err := scheduler.LookupScheduler(*algorithm)
+	if err != nil {
+		return err
+	}
+
+	processes, err := loader.LoadFile(fs.Arg(0), "")
+	if err != nil {
+		return err
+	}
+	result, err := scheduler.RunSchedulerParams(*algorithm, scheduler.SchedulerParams{Quantum: *quantum, CPUs: *cpus}, processes)
+	if err != nil {
+		return err
+	}
+	t := &TUI{In: os.Stdin, Out: w, Clear: !*plain, Speed: *speed, Title: info.Title, Result: result}
+	return t.Run()
+}
+
+//endregion
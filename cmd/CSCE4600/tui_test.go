@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestFrame(t *testing.T) {
+	t.Parallel()
+	processes := []scheduler.Process{
+		{ProcessID: 1, ArrivalTime: 0, BurstDuration: 3},
+		{ProcessID: 2, ArrivalTime: 1, BurstDuration: 2},
+		{ProcessID: 3, ArrivalTime: 2, BurstDuration: 1},
+	}
+	result, err := scheduler.RunScheduler("fcfs", 0, processes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		now         int64
+		wantRunning int64
+		wantReady   []int64
+		wantGantt   []scheduler.TimeSlice
+		wantEvents  int
+	}{
+		{now: 0, wantRunning: 1, wantEvents: 2},
+		{now: 2, wantRunning: 1, wantReady: []int64{2, 3}, wantGantt: []scheduler.TimeSlice{{PID: 1, Start: 0, Stop: 2}}},
+		{now: 3, wantRunning: 2, wantReady: []int64{3}, wantGantt: []scheduler.TimeSlice{{PID: 1, Start: 0, Stop: 3}}, wantEvents: 4},
+		{now: 6, wantRunning: scheduler.IdlePID, wantGantt: []scheduler.TimeSlice{{PID: 1, Start: 0, Stop: 3}, {PID: 2, Start: 3, Stop: 5}, {PID: 3, Start: 5, Stop: 6}}, wantEvents: 1},
+	}
+	for _, tt := range tests {
+		frame := Frame(result, tt.now)
+		if len(frame.Running) != 1 || frame.Running[0] != tt.wantRunning {
+			t.Errorf("Frame(%d).Running = %v, want [%d]", tt.now, frame.Running, tt.wantRunning)
+		}
+		if len(frame.Ready) != 0 || len(tt.wantReady) != 0 {
+			if !reflect.DeepEqual(frame.Ready, tt.wantReady) {
+				t.Errorf("Frame(%d).Ready = %v, want %v", tt.now, frame.Ready, tt.wantReady)
+			}
+		}
+		if len(frame.Gantt) != 0 || len(tt.wantGantt) != 0 {
+			if !reflect.DeepEqual(frame.Gantt, tt.wantGantt) {
+				t.Errorf("Frame(%d).Gantt = %v, want %v", tt.now, frame.Gantt, tt.wantGantt)
+			}
+		}
+		if len(frame.Events) != tt.wantEvents {
+			t.Errorf("Frame(%d).Events = %v, want %d of them", tt.now, frame.Events, tt.wantEvents)
+		}
+	}
+}
+
+func TestTUI_Run(t *testing.T) {
+	t.Parallel()
+	processes := []scheduler.Process{
+		{ProcessID: 1, ArrivalTime: 0, BurstDuration: 3},
+		{ProcessID: 2, ArrivalTime: 1, BurstDuration: 2},
+	}
+	result, err := scheduler.RunScheduler("fcfs", 0, processes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+		last  string
+	}{
+		{
+			name:  "step and back",
+			input: "\n\n\nb\nq\nnot read\n",
+			want:  []string{"Time 1 of 5", "Time 3 of 5, paused", "Running: 2", "complete 1"},
+			last:  "Time 2 of 5",
+		},
+		{name: "input ends", input: "s\n", last: "Time 1 of 5"},
+		{name: "plays to the end", input: "+\np\n", want: []string{"playing at 64 ticks/s"}, last: "Time 5 of 5, paused"},
+		{name: "unknown command", input: "jump\n", want: []string{`unknown command "jump"`}, last: "Time 0 of 5"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var out bytes.Buffer
+			tui := &TUI{In: strings.NewReader(tt.input), Out: &out, Speed: 32, Title: "First-come, first-serve", Result: result}
+			if err := tui.Run(); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, out.String())
+				}
+			}
+			frames := strings.Split(out.String(), "Time ")
+			if last := "Time " + frames[len(frames)-1]; !strings.HasPrefix(last, tt.last) {
+				t.Errorf("last frame = %q, want it to start %q", last, tt.last)
+			}
+		})
+	}
+}
+
+func TestRunTUI_errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "no workload", wantErr: "tui needs one workload"},
+		{name: "unknown scheduler", args: []string{"-scheduler", "nope", "example_processes.csv"}, wantErr: `unknown scheduler "nope"`},
+		{name: "too fast", args: []string{"-speed", "100", "example_processes.csv"}, wantErr: "speed 100 must be from 0.25 to 64"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := runTUI(&bytes.Buffer{}, tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("runTUI() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
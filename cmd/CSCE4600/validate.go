@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region validate command
+
+// newValidateCommand is the `validate` subcommand, which loads each workload
+// and lists every problem with it by line, without scheduling anything:
+// `validate [--format csv|json] workload.csv...`.
+func newValidateCommand(opts *cliOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate [workload...]",
+		Short: "List every problem with workloads",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			paths := args
+			if opts.input != "" {
+				paths = append([]string{opts.input}, paths...)
+			}
+			if len(paths) == 0 {
+				return fmt.Errorf("%w: validate needs at least one workload", scheduler.ErrInvalidArgs)
+			}
+
+			w := cmd.OutOrStdout()
+			var invalid int
+			for _, path := range paths {
+				processes, err := loader.LoadFile(path, opts.format)
+				var problems loader.Problems
+				switch {
+				case err == nil:
+					_, _ = fmt.Fprintf(w, "%s: ok, %d processes\n", path, len(processes))
+					continue
+				case errors.As(err, &problems):
+					_, _ = fmt.Fprintf(w, "%s:\n", path)
+					for _, problem := range problems {
+						_, _ = fmt.Fprintf(w, "\t%v\n", problem)
+					}
+				case errors.Is(err, scheduler.ErrInvalidArgs), errors.Is(err, scheduler.ErrNoProcesses):
+					_, _ = fmt.Fprintf(w, "%v\n", err)
+				default:
+					return err
+				}
+				invalid++
+			}
+			if invalid > 0 {
+				return fmt.Errorf("%w: %d of %d workloads are invalid", scheduler.ErrInvalidArgs, invalid, len(paths))
+			}
+			return nil
+		},
+	}
+}
+
+//endregion
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestRunValidate(t *testing.T) {
+	t.Parallel()
+	bad := filepath.Join(t.TempDir(), "bad.csv")
+	if err := os.WriteFile(bad, []byte("1,5,0\n2,0,1\n1,3,2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	err := run(&out, []string{"validate", "testdata/workloads/mixed.csv", bad})
+	if !errors.Is(err, scheduler.ErrInvalidArgs) || !strings.Contains(err.Error(), "1 of 2 workloads are invalid") {
+		t.Errorf("validate = %v, want 1 of 2 invalid", err)
+	}
+	for _, s := range []string{
+		"testdata/workloads/mixed.csv: ok, 6 processes",
+		bad + ":\n",
+		"line 2: burst 0 is not positive\n",
+		"line 3: PID 1 repeats line 1\n",
+	} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("output missing %q:\n%s", s, out.String())
+		}
+	}
+	if err := run(&out, []string{"validate", "testdata/workloads/missing.csv"}); err == nil || errors.Is(err, scheduler.ErrInvalidArgs) {
+		t.Errorf("validate of a missing file = %v, want the open error", err)
+	}
+}
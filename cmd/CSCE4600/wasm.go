@@ -0,0 +1,109 @@
+//go:build js && wasm
+
+// The WebAssembly build runs the simulator in a browser with no server:
+//
+//	GOOS=js GOARCH=wasm go build -o wasm/scheduler.wasm ./cmd/CSCE4600
+//	cp "$(go env GOROOT)/lib/wasm/wasm_exec.js" wasm/
+//
+// then serve the wasm directory as static files and open index.html.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"syscall/js"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func init() {
+	commands["wasm"] = runWasm
+}
+
+//region JavaScript bindings
+
+// runWasm is the `wasm` subcommand, which the page starts by running the
+// module with go.argv = ["scheduler", "wasm"]. It installs a global
+// `scheduler` object and keeps the program alive to serve calls on it:
+// • schedulers()                     the names run accepts
+// • loadProcesses(csv)               the parsed processes
+// • run(name, processes, quantum)    one scheduler's result, as from POST /runs
+// • report(csv, quantum)             the command-line tables for a workload
+// Failures come back as {error: "..."}, like the server's JSON errors.
+func runWasm(io.Writer, []string) error {
+	api := js.Global().Get("Object").New()
+	api.Set("schedulers", js.FuncOf(func(js.Value, []js.Value) interface{} {
+		return toJS(scheduler.SortedSchedulerNames(), nil)
+	}))
+	api.Set("loadProcesses", js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		if len(args) < 1 {
+			return toJS(nil, fmt.Errorf("%w: loadProcesses(csv)", scheduler.ErrInvalidArgs))
+		}
+		return toJS(loader.LoadCSV(strings.NewReader(args[0].String())))
+	}))
+	api.Set("run", js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		if len(args) < 2 {
+			return toJS(nil, fmt.Errorf("%w: run(name, processes, quantum)", scheduler.ErrInvalidArgs))
+		}
+		var processes []scheduler.Process
+		if err := fromJS(args[1], &processes); err != nil {
+			return toJS(nil, err)
+		}
+		var quantum int64
+		if len(args) > 2 && args[2].Truthy() {
+			quantum = int64(args[2].Int())
+		}
+		return toJS(scheduler.RunScheduler(args[0].String(), quantum, processes))
+	}))
+	api.Set("report", js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		if len(args) < 1 {
+			return toJS(nil, fmt.Errorf("%w: report(csv, quantum)", scheduler.ErrInvalidArgs))
+		}
+		quantum := scheduler.DefaultQuantum
+		if len(args) > 1 && args[1].Truthy() {
+			quantum = args[1].Int()
+		}
+		processes, err := loader.LoadCSV(strings.NewReader(args[0].String()))
+		if err != nil {
+			return toJS(nil, err)
+		}
+		var b strings.Builder
+		FCFSSchedule(&b, "First-come, first-serve", processes)
+		SJFSchedule(&b, "Shortest-job-first", processes)
+		PriorityNonPreemptiveSchedule(&b, "Priority", processes)
+		PriorityPreemptiveSchedule(&b, "Preemptive priority", processes)
+		RRSchedule(&b, "Round-robin", processes, quantum)
+		CooperativeSchedule(&b, "Cooperative", processes)
+		return b.String()
+	}))
+	js.Global().Set("scheduler", api)
+
+	select {}
+}
+
+// toJS hands v to JavaScript by way of JSON, so values look the same as the
+// server's responses.
+func toJS(v interface{}, err error) interface{} {
+	if err != nil {
+		v = map[string]string{"error": err.Error()}
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return toJS(nil, err)
+	}
+	return js.Global().Get("JSON").Call("parse", string(data))
+}
+
+func fromJS(v js.Value, out interface{}) error {
+	data := js.Global().Get("JSON").Call("stringify", v).String()
+	if err := json.Unmarshal([]byte(data), out); err != nil {
+		return fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+	return nil
+}
+
+//endregion
@@ -0,0 +1,321 @@
+'use strict';
+
+// The page talks to the same JSON API as any other client: it uploads the
+// CSV to /workloads, runs it through /runs and plays it back over the
+// /runs/stream WebSocket.
+
+const $ = (id) => document.getElementById(id);
+const SVG = 'http://www.w3.org/2000/svg';
+const ROW = 22;
+
+// Results shown on the page by scheduler name, so zooming can redraw them.
+const shown = new Map();
+// The last uploaded CSV and its workload id, to avoid re-uploading it.
+let uploaded = { text: null, id: null };
+
+function setStatus(msg) {
+  $('status').textContent = msg || '';
+}
+
+async function api(method, path, body, type) {
+  const resp = await fetch(path, {
+    method,
+    body,
+    headers: body ? { 'Content-Type': type } : {},
+  });
+  const data = await resp.json();
+  if (!resp.ok) {
+    throw new Error(data.error || resp.statusText);
+  }
+  return data;
+}
+
+async function loadSchedulers() {
+  const names = await api('GET', '/schedulers');
+  const box = $('schedulers');
+  for (const name of names) {
+    const label = document.createElement('label');
+    const input = document.createElement('input');
+    input.type = 'checkbox';
+    input.value = name;
+    input.checked = true;
+    label.append(input, ' ', name);
+    box.append(label);
+  }
+}
+
+function selectedSchedulers() {
+  return [...$('schedulers').querySelectorAll('input:checked')].map((el) => el.value);
+}
+
+async function workloadID() {
+  const text = $('csv-text').value.trim() + '\n';
+  if (uploaded.text !== text) {
+    const w = await api('POST', '/workloads', text, 'text/csv');
+    uploaded = { text, id: w.id };
+  }
+  return uploaded.id;
+}
+
+//region Rendering
+
+// IDLE is the pid of the slices where the CPU had nothing to run, and
+// SWITCH of those it spent switching context.
+const IDLE = -1;
+const SWITCH = -2;
+
+function colour(pid) {
+  if (pid === IDLE) return '#ddd';
+  if (pid === SWITCH) return '#888';
+  return `hsl(${(pid * 67) % 360} 60% 65%)`;
+}
+
+function label(pid) {
+  if (pid === IDLE) return 'idle';
+  if (pid === SWITCH) return 'switch';
+  return `P${pid}`;
+}
+
+function svgEl(name, attrs, text) {
+  const el = document.createElementNS(SVG, name);
+  for (const [k, v] of Object.entries(attrs)) {
+    el.setAttribute(k, v);
+  }
+  if (text !== undefined) {
+    el.textContent = text;
+  }
+  return el;
+}
+
+// drawGantt draws one row per process, and one each for idle time and
+// context switches, with a time axis underneath.
+function drawGantt(gantt, pids) {
+  const scale = Number($('zoom').value);
+  const end = gantt.reduce((m, s) => Math.max(m, s.stop), 0);
+  const rows = new Map(pids.map((pid, i) => [pid, i]));
+  const svg = svgEl('svg', { width: 40 + end * scale + 20, height: (pids.length + 1) * ROW + 4 });
+
+  pids.forEach((pid, i) => {
+    svg.append(svgEl('text', { x: 4, y: i * ROW + 15 }, label(pid)));
+  });
+  for (const s of gantt) {
+    const y = rows.get(s.pid) * ROW;
+    const rect = svgEl('rect', {
+      x: 40 + s.start * scale,
+      y: y + 2,
+      width: Math.max((s.stop - s.start) * scale, 1),
+      height: ROW - 4,
+      fill: colour(s.pid),
+    });
+    rect.append(svgEl('title', {}, `${label(s.pid)}: ${s.start}–${s.stop}`));
+    svg.append(rect);
+  }
+  const step = Math.max(1, Math.ceil(30 / scale));
+  const axis = pids.length * ROW + 15;
+  for (let t = 0; t <= end; t += step) {
+    svg.append(svgEl('text', { x: 40 + t * scale, y: axis, 'text-anchor': 'middle' }, t));
+  }
+  return svg;
+}
+
+const columns = [
+  ['pid', 'ID'],
+  ['priority', 'Priority'],
+  ['burst', 'Burst'],
+  ['arrival', 'Arrival'],
+  ['response', 'Response'],
+  ['wait', 'Wait'],
+  ['turnaround', 'Turnaround'],
+  ['exit', 'Exit'],
+];
+
+// drawTable renders the per-process metrics; clicking a header sorts by it.
+function drawTable(result) {
+  const table = document.createElement('table');
+  const head = table.createTHead().insertRow();
+  const body = table.createTBody();
+  let sortKey = 'pid';
+  let asc = true;
+
+  const fill = () => {
+    const rows = [...result.processes].sort((a, b) => (asc ? 1 : -1) * (a[sortKey] - b[sortKey]));
+    body.replaceChildren();
+    for (const p of rows) {
+      const tr = body.insertRow();
+      for (const [key] of columns) {
+        tr.insertCell().textContent = p[key];
+      }
+    }
+    for (const th of head.cells) {
+      th.removeAttribute('aria-sort');
+      if (th.dataset.key === sortKey) {
+        th.setAttribute('aria-sort', asc ? 'ascending' : 'descending');
+      }
+    }
+  };
+  for (const [key, title] of columns) {
+    const th = document.createElement('th');
+    th.dataset.key = key;
+    th.textContent = title;
+    th.addEventListener('click', () => {
+      asc = sortKey === key ? !asc : true;
+      sortKey = key;
+      fill();
+    });
+    head.append(th);
+  }
+  fill();
+
+  const foot = table.createTFoot().insertRow();
+  const summary = foot.insertCell();
+  summary.colSpan = columns.length;
+  summary.textContent =
+    `avg wait ${result.avg_wait.toFixed(2)} · avg turnaround ${result.avg_turnaround.toFixed(2)} · ` +
+    `throughput ${result.throughput.toFixed(3)}/t · utilization ${(result.utilization * 100).toFixed(1)}%`;
+  return table;
+}
+
+function resultPanel(name) {
+  let panel = document.querySelector(`.result[data-scheduler="${name}"]`);
+  if (!panel) {
+    panel = document.createElement('section');
+    panel.className = 'result';
+    panel.dataset.scheduler = name;
+    $('results').append(panel);
+  }
+  return panel;
+}
+
+// show draws a result's Gantt chart and, once the run is finished, its table.
+function show(result, finished) {
+  shown.set(result.scheduler, { result, finished });
+  const pids = [...new Set(uploadedPIDs().concat(result.gantt.map((s) => s.pid)))].sort((a, b) => a - b);
+  const heading = document.createElement('h2');
+  heading.textContent = result.quantum && result.scheduler === 'rr' ? `rr (q=${result.quantum})` : result.scheduler;
+  const gantt = document.createElement('div');
+  gantt.className = 'gantt';
+  gantt.append(drawGantt(result.gantt, pids));
+  const panel = resultPanel(result.scheduler);
+  panel.replaceChildren(heading, gantt);
+  if (finished) {
+    panel.append(drawTable(result));
+  }
+}
+
+function uploadedPIDs() {
+  return (uploaded.text || '')
+    .split('\n')
+    .map((line) => parseInt(line.split(',')[0], 10))
+    .filter((pid) => !Number.isNaN(pid));
+}
+
+function redraw() {
+  for (const { result, finished } of shown.values()) {
+    show(result, finished);
+  }
+}
+
+//endregion
+
+//region Actions
+
+async function run(event) {
+  event.preventDefault();
+  setStatus('');
+  try {
+    const id = await workloadID();
+    const run = await api(
+      'POST',
+      '/runs',
+      JSON.stringify({ workload: id, schedulers: selectedSchedulers(), quantum: Number($('quantum').value) }),
+      'application/json',
+    );
+    $('results').replaceChildren();
+    shown.clear();
+    for (const result of run.results) {
+      show(result, true);
+    }
+  } catch (err) {
+    setStatus(err.message);
+  }
+}
+
+// playLive opens one stream per selected scheduler and grows each Gantt
+// chart as dispatch and the events that end a slice arrive.
+async function playLive() {
+  setStatus('');
+  let id;
+  try {
+    id = await workloadID();
+  } catch (err) {
+    setStatus(err.message);
+    return;
+  }
+  $('results').replaceChildren();
+  shown.clear();
+
+  const proto = location.protocol === 'https:' ? 'wss:' : 'ws:';
+  for (const name of selectedSchedulers()) {
+    const params = new URLSearchParams({
+      workload: id,
+      scheduler: name,
+      quantum: $('quantum').value,
+      tick: `${Number($('tick').value)}ms`,
+    });
+    const ws = new WebSocket(`${proto}//${location.host}/runs/stream?${params}`);
+    const live = { scheduler: name, quantum: Number($('quantum').value), gantt: [] };
+    let open = null;
+
+    ws.onmessage = (msg) => {
+      const data = JSON.parse(msg.data);
+      if (data.done) {
+        show(data.result, true);
+        ws.close();
+        return;
+      }
+      if (data.kind === 'dispatch') {
+        open = { pid: data.pid, start: data.time, stop: data.time };
+        live.gantt.push(open);
+      } else if (open && open.pid === data.pid && data.kind !== 'arrive' && data.kind !== 'wake') {
+        open.stop = data.time;
+        open = null;
+      }
+      if (open) {
+        open.stop = Math.max(open.stop, data.time);
+      }
+      show(live, false);
+    };
+    ws.onerror = () => setStatus(`stream for ${name} failed`);
+    ws.onclose = (event) => {
+      if (event.code !== 1000 && event.reason) {
+        setStatus(`stream for ${name} refused: ${event.reason}`);
+      }
+    };
+  }
+}
+
+//endregion
+
+$('run-form').addEventListener('submit', run);
+$('live').addEventListener('click', playLive);
+$('zoom').addEventListener('input', redraw);
+$('results').addEventListener(
+  'wheel',
+  (event) => {
+    if (!event.ctrlKey) {
+      return;
+    }
+    event.preventDefault();
+    const zoom = $('zoom');
+    zoom.value = Number(zoom.value) - Math.sign(event.deltaY) * 4;
+    redraw();
+  },
+  { passive: false },
+);
+$('csv-file').addEventListener('change', async (event) => {
+  const file = event.target.files[0];
+  if (file) {
+    $('csv-text').value = await file.text();
+  }
+});
+loadSchedulers().catch((err) => setStatus(err.message));
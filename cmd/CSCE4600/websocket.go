@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+//region WebSocket
+
+// wsGUID is the fixed key suffix from RFC 6455 section 1.3.
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+	wsOpText  = 0x1
+	wsOpClose = 0x8
+	wsOpPing  = 0x9
+	wsOpPong  = 0xA
+)
+
+// Close codes from RFC 6455 section 7.4.1.
+const (
+	wsCloseNormal = 1000
+	wsClosePolicy = 1008
+)
+
+// wsConn is the server side of a WebSocket connection. It only sends text
+// frames; whatever the client sends is read and dropped, except that a close
+// frame or a broken connection closes Done.
+type wsConn struct {
+	conn net.Conn
+	buf  *bufio.ReadWriter
+	mu   sync.Mutex
+	done chan struct{}
+}
+
+// upgradeWebSocket completes the opening handshake and takes over the
+// connection from the HTTP server.
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	if r.Method != http.MethodGet ||
+		!headerHasToken(r.Header, "Connection", "upgrade") ||
+		!headerHasToken(r.Header, "Upgrade", "websocket") {
+		return nil, errors.New("expected a WebSocket upgrade request")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		return nil, errors.New("unsupported WebSocket version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return nil, errors.New("missing Sec-WebSocket-Key")
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, errors.New("connection cannot be upgraded")
+	}
+	conn, buf, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	_, _ = fmt.Fprintf(buf, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", wsAccept(key))
+	if err := buf.Flush(); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	ws := &wsConn{conn: conn, buf: buf, done: make(chan struct{})}
+	go ws.readLoop()
+	return ws, nil
+}
+
+func wsAccept(key string) string {
+	sum := sha1.Sum([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Done is closed once the client has gone away.
+func (c *wsConn) Done() <-chan struct{} {
+	return c.done
+}
+
+// WriteJSON sends v as one text frame.
+func (c *wsConn) WriteJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.writeFrame(wsOpText, data)
+}
+
+// Close sends a normal closure and drops the connection.
+func (c *wsConn) Close() error {
+	return c.CloseWith(wsCloseNormal, "")
+}
+
+// CloseWith sends a closure with code and reason, which a control frame
+// limits to 123 bytes, and drops the connection.
+func (c *wsConn) CloseWith(code uint16, reason string) error {
+	if len(reason) > 123 {
+		reason = reason[:123]
+	}
+	payload := binary.BigEndian.AppendUint16(nil, code)
+	_ = c.writeFrame(wsOpClose, append(payload, reason...))
+	return c.conn.Close()
+}
+
+func (c *wsConn) writeFrame(op byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	header := []byte{0x80 | op}
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(n))
+	default:
+		header = append(header, 127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(n))
+	}
+	if _, err := c.buf.Write(header); err != nil {
+		return err
+	}
+	if _, err := c.buf.Write(payload); err != nil {
+		return err
+	}
+	return c.buf.Flush()
+}
+
+// readLoop answers pings and watches for the client closing.
+func (c *wsConn) readLoop() {
+	defer close(c.done)
+	for {
+		op, payload, err := c.readFrame()
+		if err != nil {
+			return
+		}
+		switch op {
+		case wsOpClose:
+			return
+		case wsOpPing:
+			_ = c.writeFrame(wsOpPong, payload)
+		}
+	}
+}
+
+func (c *wsConn) readFrame() (byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.buf, head[:]); err != nil {
+		return 0, nil, err
+	}
+	n := uint64(head[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.buf, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.buf, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > maxUploadBytes {
+		return 0, nil, errors.New("WebSocket frame too large")
+	}
+	var mask [4]byte
+	if head[1]&0x80 != 0 {
+		if _, err := io.ReadFull(c.buf, mask[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(c.buf, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return head[0] & 0x0F, payload, nil
+}
+
+//endregion
+
+//region Event streaming
+
+const (
+	// defaultTick is how long one unit of simulated time lasts on a stream.
+	defaultTick = 100 * time.Millisecond
+	// maxTick is the longest a stream may ask one unit to last.
+	maxTick = 10 * time.Second
+	// maxStreamDelay is the most a stream spends waiting in all; the events
+	// left once it is used up are sent as fast as they come.
+	maxStreamDelay = 5 * time.Minute
+)
+
+type (
+	// StreamEvent is one engine event as sent over /runs/stream.
+	StreamEvent struct {
+		Scheduler string `json:"scheduler"`
+		scheduler.Event
+	}
+	// StreamDone is the last message on a stream, carrying the finished run.
+	StreamDone struct {
+		Done   bool                `json:"done"`
+		Result scheduler.RunResult `json:"result"`
+	}
+)
+
+// handleStream upgrades to a WebSocket and plays a run's events back in
+// simulated time:
+// • workload   an uploaded workload id
+// • scheduler  the scheduler to run, fcfs by default
+// • quantum    the round-robin quantum
+// • tick       the wall time per unit of simulated time, 0 for no delay
+//
+// A tick over maxTick, or below 0, closes the stream with a policy violation,
+// and the stream waits no more than the server's streamDelay in all.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	s.mu.Lock()
+	processes, ok := s.workloads[q.Get("workload")]
+	s.mu.Unlock()
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, fmt.Errorf("no workload %q", q.Get("workload")))
+		return
+	}
+	name := q.Get("scheduler")
+	if name == "" {
+		name = "fcfs"
+	}
+	var quantum int64
+	if v := q.Get("quantum"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			writeJSONError(w, http.StatusBadRequest, fmt.Errorf("%w: quantum %q is not a non-negative integer", scheduler.ErrInvalidArgs, v))
+			return
+		}
+		quantum = n
+	}
+	tick := defaultTick
+	if v := q.Get("tick"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Errorf("%w: tick %q is not a duration", scheduler.ErrInvalidArgs, v))
+			return
+		}
+		tick = d
+	}
+
+	if _, err := scheduler.LookupScheduler(name); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	ws, err := upgradeWebSocket(w, r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	if tick < 0 || tick > maxTick {
+		_ = ws.CloseWith(wsClosePolicy, fmt.Sprintf("tick %v is not within 0 to %v", tick, maxTick))
+		return
+	}
+	defer ws.Close()
+	s.metrics.observeStream(name)
+
+	// The engine runs alongside the writer and blocks on each event until the
+	// stream has caught up to it in simulated time.
+	events := make(chan scheduler.Event)
+	results := make(chan scheduler.RunResult, 1)
+	go func() {
+		defer close(events)
+		result, _ := scheduler.RunSchedulerEvents(name, quantum, processes, func(ev scheduler.Event) {
+			select {
+			case events <- ev:
+			case <-ws.Done():
+			}
+		})
+		result.Events = nil
+		results <- result
+	}()
+
+	var now int64
+	delay := s.streamDelay
+	for ev := range events {
+		if ev.Time > now && tick > 0 && delay > 0 {
+			// Comparing in ticks keeps a long gap from overflowing.
+			wait := delay
+			if gap := ev.Time - now; gap < int64(delay/tick) {
+				wait = time.Duration(gap) * tick
+			}
+			delay -= wait
+			select {
+			case <-time.After(wait):
+			case <-ws.Done():
+				continue
+			}
+		}
+		now = ev.Time
+		select {
+		case <-ws.Done():
+			continue
+		default:
+		}
+		_ = ws.WriteJSON(StreamEvent{Scheduler: name, Event: ev})
+	}
+	_ = ws.WriteJSON(StreamDone{Done: true, Result: <-results})
+}
+
+//endregion
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sha-min/CSCE4600/pkg/loader"
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func TestServer_stream(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(NewServer().Handler())
+	t.Cleanup(srv.Close)
+
+	r := dialStream(t, srv, "scheduler=rr&quantum=2&tick=0")
+	want, err := scheduler.RunScheduler("rr", 2, mustLoadProcesses(t, "example_processes.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ev := range want.Events {
+		var got StreamEvent
+		readTextFrame(t, r, &got)
+		if got.Scheduler != "rr" || got.Event != ev {
+			t.Fatalf("event %d = %+v, want %+v", i, got, ev)
+		}
+	}
+	var done StreamDone
+	readTextFrame(t, r, &done)
+	if !done.Done || done.Result.AvgWait != want.AvgWait {
+		t.Errorf("final message = %+v, want the finished rr run", done)
+	}
+}
+
+func TestServer_stream_tickOutOfRange(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(NewServer().Handler())
+	t.Cleanup(srv.Close)
+
+	for _, tick := range []string{"1h", "-1s"} {
+		r := dialStream(t, srv, "tick="+tick)
+		var head [4]byte
+		if _, err := io.ReadFull(r, head[:]); err != nil {
+			t.Fatal(err)
+		}
+		if head[0] != 0x80|wsOpClose {
+			t.Fatalf("tick %s: frame header = %#x, want a close frame", tick, head[0])
+		}
+		reason := make([]byte, int(head[1])-2)
+		if _, err := io.ReadFull(r, reason); err != nil {
+			t.Fatal(err)
+		}
+		if code := binary.BigEndian.Uint16(head[2:]); code != wsClosePolicy || !strings.Contains(string(reason), "is not within 0 to 10s") {
+			t.Errorf("tick %s: closed with %d %q, want %d and the range", tick, code, reason, wsClosePolicy)
+		}
+	}
+}
+
+func TestServer_stream_totalDelay(t *testing.T) {
+	t.Parallel()
+	s := NewServer()
+	s.streamDelay = 50 * time.Millisecond
+	srv := httptest.NewServer(s.Handler())
+	t.Cleanup(srv.Close)
+
+	// At the longest tick the run would take minutes to play back.
+	start := time.Now()
+	r := dialStream(t, srv, "tick=10s")
+	want, err := scheduler.RunScheduler("fcfs", 0, mustLoadProcesses(t, "example_processes.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range want.Events {
+		var ev StreamEvent
+		readTextFrame(t, r, &ev)
+	}
+	var done StreamDone
+	readTextFrame(t, r, &done)
+	if elapsed := time.Since(start); !done.Done || elapsed > 5*time.Second {
+		t.Errorf("stream ended with %+v after %v, want it done within the total delay", done, elapsed)
+	}
+}
+
+// dialStream uploads example_processes.csv to srv and opens /runs/stream on
+// it with query, returning the connection after the handshake.
+func dialStream(t *testing.T, srv *httptest.Server, query string) *bufio.Reader {
+	t.Helper()
+	resp, err := http.Post(srv.URL+"/workloads", "text/csv", strings.NewReader(loadFixture(t, "example_processes.csv")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var workload struct {
+		ID string `json:"id"`
+	}
+	decodeResponse(t, resp, http.StatusCreated, &workload)
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	const key = "dGhlIHNhbXBsZSBub25jZQ=="
+	_, _ = io.WriteString(conn, "GET /runs/stream?workload="+workload.ID+"&"+query+" HTTP/1.1\r\n"+
+		"Host: test\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: "+key+"\r\nSec-WebSocket-Version: 13\r\n\r\n")
+
+	r := bufio.NewReader(conn)
+	upgrade, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upgrade.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("handshake status = %d, want 101", upgrade.StatusCode)
+	}
+	if got, want := upgrade.Header.Get("Sec-WebSocket-Accept"), "s3pPLMBiTxaQ9kYGzzhZRbK+xOo="; got != want {
+		t.Fatalf("Sec-WebSocket-Accept = %q, want %q", got, want)
+	}
+	return r
+}
+
+func mustLoadProcesses(t *testing.T, name string) []scheduler.Process {
+	t.Helper()
+	processes, err := loader.LoadCSV(strings.NewReader(loadFixture(t, name)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return processes
+}
+
+// readTextFrame reads one unmasked server frame and decodes its JSON payload.
+func readTextFrame(t *testing.T, r *bufio.Reader, v interface{}) {
+	t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		t.Fatal(err)
+	}
+	if head[0] != 0x80|wsOpText {
+		t.Fatalf("frame header = %#x, want a final text frame", head[0])
+	}
+	n := int(head[1])
+	if n == 126 {
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			t.Fatal(err)
+		}
+		n = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		t.Fatalf("%v: %s", err, payload)
+	}
+}
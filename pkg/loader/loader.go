@@ -0,0 +1,329 @@
+// Package loader reads workloads for the scheduler package from CSV and JSON
+// files, along with MLQ configurations and periodic task sets.
+package loader
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+// Workload file formats, chosen by extension unless a caller names one.
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// LoadFile loads the processes in the file at path, in format or,
+// if format is "", the one its extension implies: JSON for .json, else CSV.
+func LoadFile(path, format string) ([]scheduler.Process, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	processes, err := Load(f, FileFormat(path, format))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(processes) == 0 {
+		return nil, fmt.Errorf("%s: %w", path, scheduler.ErrNoProcesses)
+	}
+	return processes, nil
+}
+
+// FileFormat returns format, or if it is "" the one path's extension implies.
+func FileFormat(path, format string) string {
+	if format == "" && strings.EqualFold(filepath.Ext(path), ".json") {
+		return FormatJSON
+	}
+	if format == "" {
+		return FormatCSV
+	}
+	return format
+}
+
+// Load reads processes in the named format.
+func Load(r io.Reader, format string) ([]scheduler.Process, error) {
+	switch format {
+	case FormatCSV:
+		return LoadCSV(r)
+	case FormatJSON:
+		return LoadJSON(r)
+	default:
+		return nil, fmt.Errorf("%w: unknown workload format %q, want %s or %s", scheduler.ErrInvalidArgs, format, FormatCSV, FormatJSON)
+	}
+}
+
+// jsonProcess is a process in a JSON workload, which is an array of them.
+// BurstSequence, if set, takes the place of Burst with alternating CPU and
+// I/O bursts, as parseBurstSequence reads them.
+type jsonProcess struct {
+	PID           int64  `json:"pid"`
+	Burst         int64  `json:"burst"`
+	BurstSequence string `json:"burst_sequence,omitempty"`
+	Arrival       int64  `json:"arrival"`
+	Priority      int64  `json:"priority"`
+	Name          string `json:"name,omitempty"`
+	Deadline      int64  `json:"deadline,omitempty"`
+	Nice          *int64 `json:"nice,omitempty"`
+}
+
+// LoadJSON reads a JSON workload, holding each process to the same
+// rules as a CSV row and reporting problems by position in the array.
+func LoadJSON(r io.Reader) ([]scheduler.Process, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var rows []jsonProcess
+	if err := dec.Decode(&rows); err != nil {
+		return nil, fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+	return jsonProcesses(rows)
+}
+
+// jsonProcesses turns the rows of a JSON workload into processes, reporting
+// every problem with them by position.
+func jsonProcesses(rows []jsonProcess) ([]scheduler.Process, error) {
+	var (
+		processes = make([]scheduler.Process, 0, len(rows))
+		positions = make([]int, 0, len(rows))
+		problems  Problems
+	)
+	for i, row := range rows {
+		process := scheduler.Process{
+			ProcessID:     row.PID,
+			ArrivalTime:   row.Arrival,
+			BurstDuration: row.Burst,
+			Priority:      row.Priority,
+			Name:          row.Name,
+			Deadline:      row.Deadline,
+			Nice:          row.Nice,
+		}
+		if row.BurstSequence != "" {
+			if row.Burst != 0 {
+				problems = append(problems, fmt.Errorf("%w: process %d: give burst or burst_sequence, not both", scheduler.ErrInvalidArgs, i+1))
+				continue
+			}
+			burst, ios, err := parseBurstSequence(row.BurstSequence)
+			if err != nil {
+				problems = append(problems, fmt.Errorf("%w: process %d", err, i+1))
+				continue
+			}
+			process.BurstDuration, process.IO = burst, ios
+		}
+		processes = append(processes, process)
+		positions = append(positions, i+1)
+	}
+	problems = append(problems, checkWorkload(processes, func(i int) string { return fmt.Sprintf("process %d", positions[i]) })...)
+	if len(problems) > 0 {
+		return nil, problems
+	}
+	return processes, nil
+}
+
+// parseBurstSequence reads alternating CPU and I/O bursts such as
+// "5,io:3,4", which starts and ends on the CPU, as a process's total CPU
+// burst and its I/O bursts.
+func parseBurstSequence(field string) (int64, []scheduler.IOBurst, error) {
+	var (
+		burst int64
+		ios   []scheduler.IOBurst
+	)
+	for i, entry := range strings.Split(field, ",") {
+		entry = strings.TrimSpace(entry)
+		isIO := strings.HasPrefix(entry, "io:")
+		if isIO != (i%2 == 1) {
+			return 0, nil, fmt.Errorf("%w: burst sequence %q does not alternate CPU and I/O", scheduler.ErrInvalidArgs, field)
+		}
+		v, err := strconv.ParseInt(strings.TrimPrefix(entry, "io:"), 10, 64)
+		if err != nil || v < 1 {
+			return 0, nil, fmt.Errorf("%w: burst sequence %q: %q is not a positive length", scheduler.ErrInvalidArgs, field, entry)
+		}
+		if isIO {
+			ios = append(ios, scheduler.IOBurst{At: burst, Duration: v})
+		} else {
+			burst += v
+		}
+	}
+	if len(ios) > 0 && ios[len(ios)-1].At == burst {
+		return 0, nil, fmt.Errorf("%w: burst sequence %q ends with I/O", scheduler.ErrInvalidArgs, field)
+	}
+	return burst, ios, nil
+}
+
+// LoadCSV reads a whole CSV workload, checking each row and then the rows
+// together as checkWorkload does, and reports every problem it finds against
+// its line as Problems.
+func LoadCSV(r io.Reader) ([]scheduler.Process, error) {
+	var (
+		processes []scheduler.Process
+		lines     []int
+		problems  Problems
+	)
+	err := scanRows(r, func(row []string, line int) error {
+		process, err := parseProcess(row, line)
+		if err != nil {
+			problems = append(problems, err)
+			return nil
+		}
+		processes = append(processes, process)
+		lines = append(lines, line)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// parseProcess has checked each row alone; checking again here is
+	// cheap and finds the problems between rows.
+	problems = append(problems, checkWorkload(processes, func(i int) string { return fmt.Sprintf("line %d", lines[i]) })...)
+	if len(problems) > 0 {
+		return nil, problems
+	}
+	return processes, nil
+}
+
+// ScanCSV parses the workload one CSV record at a time, handing each
+// process to yield as soon as it is read, so a huge file never has to be in
+// memory at once. It stops at the first error, including one from yield, and
+// checks each row alone: finding repeated PIDs would mean holding them all.
+func ScanCSV(r io.Reader, yield func(scheduler.Process) error) error {
+	return scanRows(r, func(row []string, line int) error {
+		process, err := parseProcess(row, line)
+		if err != nil {
+			return err
+		}
+		return yield(process)
+	})
+}
+
+// scanRows hands each CSV record to fn with the line it starts on, stopping
+// at the first error. The record is reused, so fn must copy what it keeps.
+func scanRows(r io.Reader, fn func(row []string, line int) error) error {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+	// Rows may leave off the optional trailing fields, which parseProcess
+	// checks instead of the reader.
+	cr.FieldsPerRecord = -1
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: reading CSV", err)
+		}
+		line, _ := cr.FieldPos(0)
+		if err := fn(row, line); err != nil {
+			return err
+		}
+	}
+}
+
+// Workload rows have the ID, burst, or a quoted burst sequence such as
+// "5,io:3,4", and arrival, then optionally priority, yields, donee, group,
+// sync ops, deadline, name and nice value.
+const (
+	minProcessFields = 3
+	maxProcessFields = 11
+)
+
+// parseProcess reads one workload row, reporting the first problem with it
+// against its line in the file.
+func parseProcess(row []string, line int) (scheduler.Process, error) {
+	var process scheduler.Process
+	// toInt keeps the first bad integer on the row rather than exiting,
+	// so a bad upload to the server cannot take the process down.
+	var bad error
+	toInt := func(column int, field string) int64 {
+		v, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+		if err != nil && bad == nil {
+			bad = fmt.Errorf("%w: line %d, column %d: %q is not an integer", scheduler.ErrInvalidArgs, line, column, field)
+		}
+		return v
+	}
+	if len(row) < minProcessFields || len(row) > maxProcessFields {
+		return scheduler.Process{}, fmt.Errorf("%w: line %d: expected %d–%d fields, got %d",
+			scheduler.ErrInvalidArgs, line, minProcessFields, maxProcessFields, len(row))
+	}
+	process.ProcessID = toInt(1, row[0])
+	if strings.Contains(row[1], ",") {
+		burst, ios, err := parseBurstSequence(row[1])
+		if err != nil {
+			return scheduler.Process{}, fmt.Errorf("%w: line %d, column 2", err, line)
+		}
+		process.BurstDuration, process.IO = burst, ios
+	} else {
+		process.BurstDuration = toInt(2, row[1])
+	}
+	process.ArrivalTime = toInt(3, row[2])
+	if len(row) >= 4 {
+		process.Priority = toInt(4, row[3])
+	}
+	if len(row) >= 5 && row[4] != "" {
+		for _, y := range strings.Split(row[4], ";") {
+			process.Yields = append(process.Yields, toInt(5, y))
+		}
+	}
+	if len(row) >= 6 && row[5] != "" {
+		process.DonateTo = toInt(6, row[5])
+	}
+	if len(row) >= 9 && row[8] != "" {
+		process.Deadline = toInt(9, row[8])
+	}
+	if len(row) >= 11 && strings.TrimSpace(row[10]) != "" {
+		nice := toInt(11, row[10])
+		process.Nice = &nice
+	}
+	if bad != nil {
+		return scheduler.Process{}, bad
+	}
+	if problem := process.Problem(); problem != "" {
+		return scheduler.Process{}, fmt.Errorf("%w: line %d: %s", scheduler.ErrInvalidArgs, line, problem)
+	}
+	if len(row) >= 7 {
+		process.Group = row[6]
+	}
+	if len(row) >= 10 {
+		process.Name = strings.TrimSpace(row[9])
+	}
+	if len(row) >= 8 && row[7] != "" {
+		ops, err := ParseSyncOps(row[7])
+		if err != nil {
+			return scheduler.Process{}, fmt.Errorf("%w: line %d, column 8", err, line)
+		}
+		process.Ops = ops
+	}
+	return process, nil
+}
+
+// ParseSyncOps reads a workload's sync column: semicolon-separated at:op:object
+// entries such as 2:lock:m;5:unlock:m or 1:P:empty. Ops are sorted by At.
+func ParseSyncOps(field string) ([]scheduler.SyncOp, error) {
+	var ops []scheduler.SyncOp
+	for _, entry := range strings.Split(field, ";") {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) != 3 || parts[2] == "" {
+			return nil, fmt.Errorf("%w: sync op %q is not at:op:object", scheduler.ErrInvalidArgs, entry)
+		}
+		at, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil || at < 0 {
+			return nil, fmt.Errorf("%w: sync op %q has a bad time", scheduler.ErrInvalidArgs, entry)
+		}
+		switch parts[1] {
+		case scheduler.SemWait, scheduler.SemSignal, scheduler.MutexLock, scheduler.MutexUnlock:
+		default:
+			return nil, fmt.Errorf("%w: sync op %q: unknown operation %q", scheduler.ErrInvalidArgs, entry, parts[1])
+		}
+		ops = append(ops, scheduler.SyncOp{At: at, Op: parts[1], Object: parts[2]})
+	}
+	sort.SliceStable(ops, func(i, j int) bool { return ops[i].At < ops[j].At })
+	return ops, nil
+}
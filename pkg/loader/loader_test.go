@@ -0,0 +1,692 @@
+package loader
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func Test_loadProcesses(t *testing.T) {
+	t.Parallel()
+	type args struct {
+		r io.Reader
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []scheduler.Process
+		wantErr error
+	}{
+		{
+			name: "bad CSV",
+			args: args{
+				r: iotest.ErrReader(io.ErrUnexpectedEOF),
+			},
+			wantErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name: "success",
+			args: args{
+				r: strings.NewReader(`1,5,0,2
+2,9,3,1
+3,6,3,3`),
+			},
+			want: []scheduler.Process{
+				{
+					ProcessID:     1,
+					ArrivalTime:   0,
+					BurstDuration: 5,
+					Priority:      2,
+				},
+				{
+					ProcessID:     2,
+					ArrivalTime:   3,
+					BurstDuration: 9,
+					Priority:      1,
+				},
+				{
+					ProcessID:     3,
+					ArrivalTime:   3,
+					BurstDuration: 6,
+					Priority:      3,
+				},
+			},
+		},
+		{
+			name: "named",
+			args: args{
+				r: strings.NewReader("1,5,0,2,,,,,,init\n2,9,3,1,,,,,, shell \n3,6,3,3,,,,,,\n"),
+			},
+			want: []scheduler.Process{
+				{ProcessID: 1, ArrivalTime: 0, BurstDuration: 5, Priority: 2, Name: "init"},
+				{ProcessID: 2, ArrivalTime: 3, BurstDuration: 9, Priority: 1, Name: "shell"},
+				{ProcessID: 3, ArrivalTime: 3, BurstDuration: 6, Priority: 3},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := LoadCSV(tt.args.r)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadProcesses() = %v, want %v", got, tt.want)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_scanProcesses(t *testing.T) {
+	t.Parallel()
+	stop := errors.New("stop")
+	tests := []struct {
+		name     string
+		r        io.Reader
+		stopAt   int64 // yield fails on this PID
+		wantPIDs []int64
+		wantErr  error
+	}{
+		{
+			name:     "every row",
+			r:        strings.NewReader("1,5,0\n2,9,3\n3,6,3\n"),
+			wantPIDs: []int64{1, 2, 3},
+		},
+		{
+			name:     "yield stops the scan",
+			r:        strings.NewReader("1,5,0\n2,9,3\n3,6,3\n"),
+			stopAt:   2,
+			wantPIDs: []int64{1, 2},
+			wantErr:  stop,
+		},
+		{
+			name:     "rows before a read error are yielded",
+			r:        io.MultiReader(strings.NewReader("1,5,0\n2,9,3\n"), iotest.ErrReader(io.ErrUnexpectedEOF)),
+			wantPIDs: []int64{1, 2},
+			wantErr:  io.ErrUnexpectedEOF,
+		},
+		{
+			name:     "bad row",
+			r:        strings.NewReader("1,5,0\n2,x,3\n3,6,3\n"),
+			wantPIDs: []int64{1},
+			wantErr:  scheduler.ErrInvalidArgs,
+		},
+		{
+			name:     "idle PID",
+			r:        strings.NewReader("1,5,0\n-1,9,3\n"),
+			wantPIDs: []int64{1},
+			wantErr:  scheduler.ErrInvalidArgs,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var pids []int64
+			err := ScanCSV(tt.r, func(p scheduler.Process) error {
+				pids = append(pids, p.ProcessID)
+				if p.ProcessID == tt.stopAt {
+					return stop
+				}
+				return nil
+			})
+			if !reflect.DeepEqual(pids, tt.wantPIDs) {
+				t.Errorf("scanProcesses() yielded %v, want %v", pids, tt.wantPIDs)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_loadProcesses_errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "too few fields", input: "1,5,0\n2,9\n", wantErr: "line 2: expected 3–11 fields, got 2"},
+		{name: "too many fields", input: "1,5,0,1,,,,,9,x,0,y\n", wantErr: "line 1: expected 3–11 fields, got 12"},
+		{name: "nice not an integer", input: "1,5,0,1,,,,,,x,y\n", wantErr: `line 1, column 11: "y" is not an integer`},
+		{name: "nice out of range", input: "1,5,0,1,,,,,,x,20\n", wantErr: "line 1: nice 20 is not within -20 to 19"},
+		{name: "deadline not an integer", input: "1,5,0,1,,,,,x\n", wantErr: `line 1, column 9: "x" is not an integer`},
+		{name: "deadline at arrival", input: "1,5,3,1,,,,,3\n", wantErr: "line 1: deadline 3 is not after arrival 3"},
+		{name: "not an integer", input: "1,5,0\n\n3,x,1\n", wantErr: `line 3, column 2: "x" is not an integer`},
+		{name: "zero burst", input: "1,0,0\n", wantErr: "line 1: burst 0 is not positive"},
+		{name: "negative arrival", input: "1,5,-2\n", wantErr: "line 1: arrival -2 is negative"},
+		{name: "yield past the burst", input: "1,5,0,1,2;5\n", wantErr: "line 1: yield at 5 is not within burst 5"},
+		{name: "quoted line break", input: "1,5,0,1,,,\"a\nb\"\n2,0,0\n", wantErr: "line 3: burst 0"},
+		{name: "burst sequence ends on io", input: "1,\"2,io:3\",0\n", wantErr: `burst sequence "2,io:3" ends with I/O: line 1`},
+		{name: "burst sequence not alternating", input: "1,\"2,3\",0\n", wantErr: "does not alternate CPU and I/O"},
+		{name: "burst sequence zero io", input: "1,\"2,io:0,1\",0\n", wantErr: `"io:0" is not a positive length`},
+		{name: "yield at an io burst", input: "1,\"2,io:3,1\",0,1,2\n", wantErr: "yield at 2 is also an I/O burst"},
+		{name: "yields out of order", input: "1,9,0,1,5;3\n", wantErr: "line 1: yield at 3 does not come after yield at 5"},
+		{name: "priority out of range", input: "1,5,0,140\n", wantErr: "line 1: priority 140 is not within -20 to 139"},
+		{name: "repeated PID", input: "1,5,0\n2,3,1\n1,4,2\n", wantErr: "line 3: PID 1 repeats line 1"},
+		{name: "donation to itself", input: "1,5,0,1,2,1\n", wantErr: "line 1: PID 1 donates to itself"},
+		{name: "donation to no one", input: "1,5,0,1,2,7\n", wantErr: "line 1: donates to PID 7, which is not in the workload"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := LoadCSV(strings.NewReader(tt.input))
+			if got != nil {
+				t.Errorf("loadProcesses() = %v, want nil", got)
+			}
+			if !errors.Is(err, scheduler.ErrInvalidArgs) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_loadProcesses_everyProblem(t *testing.T) {
+	t.Parallel()
+	input := "1,5,0\n2,0,1\n3,x,2\n1,4,-3\n4,6,3,1,2,9\n1,2,4\n"
+	_, err := LoadCSV(strings.NewReader(input))
+	var problems Problems
+	if !errors.As(err, &problems) {
+		t.Fatalf("error = %v, want Problems", err)
+	}
+	want := []string{
+		"line 2: burst 0 is not positive",
+		`line 3, column 2: "x" is not an integer`,
+		"line 4: arrival -3 is negative",
+		"line 6: PID 1 repeats line 1",
+		"line 5: donates to PID 9, which is not in the workload",
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("got %d problems, want %d:\n%v", len(problems), len(want), err)
+	}
+	for i, problem := range problems {
+		if !errors.Is(problem, scheduler.ErrInvalidArgs) || !strings.Contains(problem.Error(), want[i]) {
+			t.Errorf("problem %d = %v, want %q", i+1, problem, want[i])
+		}
+	}
+	if !errors.Is(err, scheduler.ErrInvalidArgs) || !strings.HasPrefix(err.Error(), "5 problems with the workload:") {
+		t.Errorf("error = %v, want 5 problems wrapping ErrInvalidArgs", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		processes []scheduler.Process
+		wantErr   string
+	}{
+		{name: "valid", processes: []scheduler.Process{{ProcessID: 1, BurstDuration: 5}, {ProcessID: 2, BurstDuration: 3, DonateTo: 1}}},
+		{name: "bad process", processes: []scheduler.Process{{ProcessID: 1, BurstDuration: 5}, {ProcessID: 2}}, wantErr: "process 2: burst 0 is not positive"},
+		{name: "repeated PID", processes: []scheduler.Process{{ProcessID: 3, BurstDuration: 5}, {ProcessID: 3, BurstDuration: 2}}, wantErr: "process 2: PID 3 repeats process 1"},
+		{name: "priority too low", processes: []scheduler.Process{{ProcessID: 1, BurstDuration: 5, Priority: -21}}, wantErr: "priority -21 is not within -20 to 139"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := Validate(tt.processes)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, scheduler.ErrInvalidArgs) || !strings.Contains(fmt.Sprint(err), tt.wantErr) {
+				t.Errorf("Validate() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_loadWorkload(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		input   string
+		format  string
+		want    []scheduler.Process
+		wantErr string
+	}{
+		{
+			name:   "csv",
+			input:  "1,5,0,2\n2,9,3,1\n",
+			format: FormatCSV,
+			want: []scheduler.Process{
+				{ProcessID: 1, BurstDuration: 5, ArrivalTime: 0, Priority: 2},
+				{ProcessID: 2, BurstDuration: 9, ArrivalTime: 3, Priority: 1},
+			},
+		},
+		{
+			name:   "json",
+			input:  `[{"pid": 1, "burst": 5, "arrival": 0, "priority": 2, "name": "init"}, {"pid": 2, "burst": 9, "arrival": 3}]`,
+			format: FormatJSON,
+			want: []scheduler.Process{
+				{ProcessID: 1, BurstDuration: 5, ArrivalTime: 0, Priority: 2, Name: "init"},
+				{ProcessID: 2, BurstDuration: 9, ArrivalTime: 3},
+			},
+		},
+		{
+			name:   "csv burst sequence",
+			input:  "1,\"5,io:3,4,io:1,2\",0\n",
+			format: FormatCSV,
+			want:   []scheduler.Process{{ProcessID: 1, BurstDuration: 11, IO: []scheduler.IOBurst{{At: 5, Duration: 3}, {At: 9, Duration: 1}}}},
+		},
+		{
+			name:   "json burst sequence",
+			input:  `[{"pid": 1, "burst_sequence": "5,io:3,4", "arrival": 2}]`,
+			format: FormatJSON,
+			want:   []scheduler.Process{{ProcessID: 1, BurstDuration: 9, ArrivalTime: 2, IO: []scheduler.IOBurst{{At: 5, Duration: 3}}}},
+		},
+		{
+			name:   "csv deadline",
+			input:  "1,5,0,2,,,,,12\n2,3,1\n",
+			format: FormatCSV,
+			want:   []scheduler.Process{{ProcessID: 1, BurstDuration: 5, Priority: 2, Deadline: 12}, {ProcessID: 2, BurstDuration: 3, ArrivalTime: 1}},
+		},
+		{
+			name:   "json deadline",
+			input:  `[{"pid": 1, "burst": 5, "arrival": 2, "deadline": 9}]`,
+			format: FormatJSON,
+			want:   []scheduler.Process{{ProcessID: 1, BurstDuration: 5, ArrivalTime: 2, Deadline: 9}},
+		},
+		{
+			name:   "csv nice",
+			input:  "1,5,0,2,,,,,,,-5\n2,3,1,0,,,,,,shell, \n",
+			format: FormatCSV,
+			want:   []scheduler.Process{{ProcessID: 1, BurstDuration: 5, Priority: 2, Nice: nice(-5)}, {ProcessID: 2, BurstDuration: 3, ArrivalTime: 1, Name: "shell"}},
+		},
+		{
+			name:   "json nice",
+			input:  `[{"pid": 1, "burst": 5, "nice": 0}, {"pid": 2, "burst": 3}]`,
+			format: FormatJSON,
+			want:   []scheduler.Process{{ProcessID: 1, BurstDuration: 5, Nice: nice(0)}, {ProcessID: 2, BurstDuration: 3}},
+		},
+		{name: "json nice out of range", input: `[{"pid": 1, "burst": 5, "nice": -21}]`, format: FormatJSON, wantErr: "process 1: nice -21 is not within -20 to 19"},
+		{name: "json burst and sequence", input: `[{"pid": 1, "burst": 9, "burst_sequence": "5,io:3,4"}]`, format: FormatJSON, wantErr: "process 1: give burst or burst_sequence, not both"},
+		{name: "json bad process", input: `[{"pid": 1, "burst": 5}, {"pid": 2}]`, format: FormatJSON, wantErr: "process 2: burst 0 is not positive"},
+		{name: "json unknown field", input: `[{"pid": 1, "burst": 5, "bursts": 3}]`, format: FormatJSON, wantErr: `unknown field "bursts"`},
+		{name: "json not an array", input: `{"pid": 1}`, format: FormatJSON, wantErr: "cannot unmarshal"},
+		{name: "unknown format", input: "1,5,0\n", format: "yaml", wantErr: `unknown workload format "yaml"`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := Load(strings.NewReader(tt.input), tt.format)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadWorkload() = %+v, want %+v", got, tt.want)
+			}
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("error = %v", err)
+			}
+			if tt.wantErr != "" && (!errors.Is(err, scheduler.ErrInvalidArgs) || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// nice is a pointer to a nice value, as a Process holds one.
+func nice(n int64) *int64 { return &n }
+
+func Test_workloadFormat(t *testing.T) {
+	t.Parallel()
+	for _, tt := range []struct{ path, format, want string }{
+		{"w.csv", "", FormatCSV},
+		{"w.JSON", "", FormatJSON},
+		{"w", "", FormatCSV},
+		{"w.json", FormatCSV, FormatCSV},
+		{"w.txt", FormatJSON, FormatJSON},
+	} {
+		if got := FileFormat(tt.path, tt.format); got != tt.want {
+			t.Errorf("workloadFormat(%q, %q) = %q, want %q", tt.path, tt.format, got, tt.want)
+		}
+	}
+}
+
+func Test_loadMLQConfig(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "no queues", input: `{"queues": []}`, wantErr: "no queues"},
+		{name: "unknown field", input: `{"queues": [], "levels": 2}`, wantErr: `unknown field "levels"`},
+		{name: "unknown service", input: `{"service": "lottery", "queues": [{"name": "a", "policy": "rr"}]}`, wantErr: `unknown service "lottery"`},
+		{name: "unknown policy", input: `{"queues": [{"name": "a", "policy": "srtf"}]}`, wantErr: `queue "a": unknown policy "srtf"`},
+		{name: "inverted band", input: `{"queues": [{"name": "a", "min_priority": 3, "max_priority": 1, "policy": "rr"}]}`, wantErr: "min priority 3 is above max priority 1"},
+		{
+			name:    "overlapping bands",
+			input:   `{"queues": [{"name": "a", "max_priority": 2, "policy": "rr"}, {"name": "b", "min_priority": 2, "max_priority": 5, "policy": "fcfs"}]}`,
+			wantErr: `queues "a" and "b" share priorities`,
+		},
+		{name: "quantum on fcfs", input: `{"queues": [{"name": "a", "policy": "fcfs", "quantum": 2}]}`, wantErr: "only rr takes a quantum"},
+		{name: "weighted without slice", input: `{"service": "weighted", "queues": [{"name": "a", "policy": "rr"}]}`, wantErr: "needs a slice of at least 1"},
+		{name: "slice when strict", input: `{"queues": [{"name": "a", "policy": "rr", "slice": 4}]}`, wantErr: "only weighted service takes a slice"},
+		{name: "valid", input: `{"queues": [{"name": "a", "max_priority": 1, "policy": "rr"}, {"name": "b", "min_priority": 2, "max_priority": 9, "policy": "sjf"}]}`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := LoadMLQConfig(strings.NewReader(tt.input))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("loadMLQConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_loadPeriodicTasks(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		input   string
+		want    []scheduler.PeriodicTask
+		wantErr string
+	}{
+		{
+			name:  "optional deadline",
+			input: "1,4,1\n2,10,3,8\n3,5,2,\n",
+			want:  []scheduler.PeriodicTask{{ID: 1, Period: 4, WCET: 1}, {ID: 2, Period: 10, WCET: 3, Deadline: 8}, {ID: 3, Period: 5, WCET: 2}},
+		},
+		{name: "too few fields", input: "1,4,1\n2,5\n", wantErr: "line 2: expected 3–4 fields, got 2"},
+		{name: "not an integer", input: "1,four,1\n", wantErr: `line 1, column 2: "four" is not an integer`},
+		{name: "bad task", input: "1,4,1\n2,4,0\n", wantErr: "line 2: WCET 0 is not positive"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := LoadPeriodicTasks(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadPeriodicTasks() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadPeriodicTasks() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzLoadProcesses(f *testing.F) {
+	for _, seed := range []string{
+		"1,5,0,2\n2,9,3,1\n3,6,6,3\n",
+		"1,6,0,3,,,,1:lock:m;5:unlock:m\n4,3,1,2,,,,1:P:slots;2:V:slots\n",
+		"1,5,0,2,1;3,2,io,1:P:s\n",
+		"1,5\n",
+		"1,x,0\n",
+		"\"1,5,0\n",
+		"1,99999999999999999999,0\n",
+		"\xff\xfe1,5,0\n",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		processes, err := LoadCSV(strings.NewReader(input))
+		if err != nil && processes != nil {
+			t.Errorf("loadProcesses() returned processes with error %v", err)
+		}
+	})
+}
+
+func FuzzLoadJSON(f *testing.F) {
+	for _, seed := range []string{
+		`[{"pid": 1, "burst": 5}, {"pid": 2, "burst": 9, "arrival": 3, "priority": 1}]`,
+		`[{"pid": 1, "burst_sequence": "5,io:3,4", "name": "io", "deadline": 20, "nice": -5}]`,
+		`[{"pid": 1, "burst": 5, "burst_sequence": "5"}]`,
+		`[{"pid": 1, "burst": 5}, {"pid": 1, "burst": 2}]`,
+		`[{"pid": 1, "burst": 5, "quantum": 4}]`,
+		`[{"pid": -1, "burst": 0, "arrival": -3}]`,
+		`{"pid": 1}`,
+		`[{"pid": 1, "burst": 1e30}]`,
+		`[`,
+		``,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		processes, err := LoadJSON(strings.NewReader(input))
+		if err != nil {
+			if processes != nil {
+				t.Errorf("LoadJSON() returned processes with error %v", err)
+			}
+			if !errors.Is(err, scheduler.ErrInvalidArgs) {
+				t.Errorf("LoadJSON() error %v is not ErrInvalidArgs", err)
+			}
+			return
+		}
+		if err := Validate(processes); err != nil {
+			t.Errorf("LoadJSON() accepted processes that fail Validate: %v", err)
+		}
+	})
+}
+
+// FuzzParseScenario feeds scenarios in every format through parseScenario,
+// the format picked by the first byte so one corpus covers all three.
+func FuzzParseScenario(f *testing.F) {
+	formats := []string{FormatYAML, FormatTOML, FormatJSON}
+	for _, seed := range []struct {
+		format int
+		input  string
+	}{
+		{0, "processes:\n  - {pid: 1, burst: 5}\n  - {pid: 2, burst: 3, arrival: 1, priority: 1}\nschedulers:\n  - fcfs\n  - {name: wrr, quantum: 3, weights: {1: 2}, tie_break: pid}\n"},
+		{0, "processes: [{pid: 1, burst: 5, nice: -5}]\nschedulers: [{name: cfs, nice_weights: {0: 2048, -5: 4096}, priority_order: higher-first}]"},
+		{0, "processes: [{pid: 1, burst: 5}]\nschedulers: [{name: rr, caps: {batch: 50}, carry: bank, bank_cap: 4, aging_policy: {every: 2}}]"},
+		{0, "processes: [{pid: 1, burst: 5}]\nschedulers: [{name: mlq, mlq: {queues: [{name: all, min_priority: 0, max_priority: 9, policy: fcfs}]}}]"},
+		{0, "processes: [{pid: 1, burst: 5}]\nschedulers: [{name: sjf, tie_break: lifo}]"},
+		{0, "{1.5: [a, b], ? [x]: y}"},
+		{0, "processes: ["},
+		{1, "schedulers = [\"rr\", { name = \"ppriority\", aging = 4, context_switch_cost = 1 }]\n[[processes]]\npid = 1\nburst = 5\n"},
+		{1, "[[processes]]\npid = 1\nburst = 5\nwhen = 1979-05-27T07:32:00Z\n"},
+		{2, `{"processes": [{"pid": 1, "burst": 5}], "schedulers": [{"name": "mlq", "mlq": {"queues": []}}]}`},
+		{2, `{"workload": "w.csv", "processes": [{"pid": 1, "burst": 5}]}`},
+	} {
+		f.Add(byte(seed.format), seed.input)
+	}
+	f.Fuzz(func(t *testing.T, format byte, input string) {
+		s, err := parseScenario([]byte(input), formats[int(format)%len(formats)], t.TempDir())
+		if err != nil {
+			return
+		}
+		if len(s.Runs) == 0 {
+			t.Error("parseScenario() accepted a scenario with no runs")
+		}
+		for _, run := range s.Runs {
+			if _, err := scheduler.LookupScheduler(run.Scheduler); err != nil {
+				t.Errorf("parseScenario() accepted scheduler %q: %v", run.Scheduler, err)
+			}
+		}
+		if err := Validate(s.Processes); err != nil {
+			t.Errorf("parseScenario() accepted processes that fail Validate: %v", err)
+		}
+	})
+}
+
+func FuzzParseSyncOps(f *testing.F) {
+	for _, seed := range []string{"1:P:s;2:V:s", "4:unlock:m;1:lock:m", "1:P", ":::", "-1:P:s"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, field string) {
+		_, _ = ParseSyncOps(field)
+	})
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+	processes := []scheduler.Process{
+		{ProcessID: 1, BurstDuration: 5, ArrivalTime: 0, Priority: 2},
+		{ProcessID: 2, BurstDuration: 9, ArrivalTime: 3, Priority: -1, Name: "shell", Deadline: 20, Nice: nice(5)},
+	}
+	for _, format := range []string{FormatCSV, FormatJSON} {
+		var b strings.Builder
+		if err := Write(&b, format, processes); err != nil {
+			t.Fatal(err)
+		}
+		got, err := Load(strings.NewReader(b.String()), format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := processes
+		if format == FormatCSV {
+			want = []scheduler.Process{processes[0], {ProcessID: 2, BurstDuration: 9, ArrivalTime: 3, Priority: -1}}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s read back %+v, want %+v", format, got, want)
+		}
+	}
+	if err := Write(io.Discard, "xml", processes); !errors.Is(err, scheduler.ErrInvalidArgs) {
+		t.Errorf("Write(xml) = %v, want ErrInvalidArgs", err)
+	}
+}
+
+func Test_parseScenario(t *testing.T) {
+	t.Parallel()
+	inline := []scheduler.Process{{ProcessID: 1, BurstDuration: 5}, {ProcessID: 2, BurstDuration: 3, ArrivalTime: 1, Priority: 1}}
+	tests := []struct {
+		name     string
+		input    string
+		format   string
+		want     Scenario
+		wantRuns int
+		wantErr  string
+	}{
+		{
+			name:   "yaml",
+			format: FormatYAML,
+			input: `
+processes:
+  - {pid: 1, burst: 5}
+  - {pid: 2, burst: 3, arrival: 1, priority: 1}
+schedulers:
+  - fcfs
+  - {name: wrr, quantum: 3, weights: {1: 2}, tie_break: pid}
+outputs:
+  - {format: json, path: out.json}
+  - {format: text}
+`,
+			want: Scenario{
+				Processes: inline,
+				Runs: []ScenarioRun{
+					{Scheduler: "fcfs"},
+					{Scheduler: "wrr", Params: scheduler.SchedulerParams{Quantum: 3, Weights: map[int64]int64{1: 2}, TieBreak: scheduler.TiePID}},
+				},
+				Outputs: []ScenarioOutput{{Format: "json", Path: "dir/out.json"}, {Format: "text"}},
+			},
+		},
+		{
+			name:   "toml",
+			format: FormatTOML,
+			input: `
+schedulers = ["rr", { name = "ppriority", aging = 4, context_switch_cost = 1 }]
+
+[[processes]]
+pid = 1
+burst = 5
+
+[[processes]]
+pid = 2
+burst = 3
+arrival = 1
+priority = 1
+`,
+			want: Scenario{
+				Processes: inline,
+				Runs: []ScenarioRun{
+					{Scheduler: "rr"},
+					{Scheduler: "ppriority", Params: scheduler.SchedulerParams{Aging: 4, SwitchCost: 1}},
+				},
+			},
+		},
+		{
+			name:   "json with an mlq",
+			format: FormatJSON,
+			input:  `{"processes": [{"pid": 1, "burst": 5}, {"pid": 2, "burst": 3, "arrival": 1, "priority": 1}], "schedulers": [{"name": "mlq", "mlq": {"queues": [{"name": "all", "min_priority": 0, "max_priority": 9, "policy": "fcfs"}]}}]}`,
+			want: Scenario{
+				Processes: inline,
+				Runs: []ScenarioRun{{Scheduler: "mlq", Params: scheduler.SchedulerParams{MLQ: &scheduler.MLQConfig{
+					Queues: []scheduler.MLQLevel{{Name: "all", MaxPriority: 9, Policy: "fcfs"}},
+				}}}},
+			},
+		},
+		{
+			name:   "yaml with nice weights",
+			format: FormatYAML,
+			input: `
+processes:
+  - {pid: 1, burst: 5, nice: -5}
+  - {pid: 2, burst: 3, arrival: 1, priority: 1}
+schedulers:
+  - {name: cfs, nice_weights: {0: 2048, -5: 4096}, priority_order: higher-first}
+`,
+			want: Scenario{
+				Processes: []scheduler.Process{{ProcessID: 1, BurstDuration: 5, Nice: nice(-5)}, inline[1]},
+				Runs: []ScenarioRun{{Scheduler: "cfs", Params: scheduler.SchedulerParams{
+					NiceWeights:   map[int64]int64{0: 2048, -5: 4096},
+					PriorityOrder: scheduler.HigherFirst,
+				}}},
+			},
+		},
+		{name: "every scheduler by default", format: FormatYAML, input: "processes: [{pid: 1, burst: 5}]", wantRuns: len(scheduler.SchedulerNames())},
+		{name: "no workload", format: FormatYAML, input: "schedulers: [fcfs]", wantErr: "needs a workload file or processes"},
+		{name: "workload and processes", format: FormatYAML, input: "workload: w.csv\nprocesses: [{pid: 1, burst: 5}]", wantErr: "not both"},
+		{name: "missing workload", format: FormatYAML, input: "workload: w.csv", wantErr: "w.csv"},
+		{name: "unknown key", format: FormatYAML, input: "processes: [{pid: 1, burst: 5}]\nquantum: 4", wantErr: `unknown field "quantum"`},
+		{name: "unknown tunable", format: FormatTOML, input: "schedulers = [{ name = \"rr\", quanta = 4 }]\n[[processes]]\npid = 1\nburst = 5", wantErr: `unknown field "quanta"`},
+		{name: "unknown scheduler", format: FormatYAML, input: "processes: [{pid: 1, burst: 5}]\nschedulers: [fcfs, nope]", wantErr: `unknown scheduler "nope" (scheduler 2)`},
+		{name: "bad tie-break", format: FormatYAML, input: "processes: [{pid: 1, burst: 5}]\nschedulers: [{name: sjf, tie_break: lifo}]", wantErr: `unknown tie-break rule "lifo"`},
+		{name: "bad priority order", format: FormatYAML, input: "processes: [{pid: 1, burst: 5}]\nschedulers: [{name: cfs, priority_order: up}]", wantErr: `unknown priority order "up"`},
+		{name: "bad nice weight", format: FormatYAML, input: "processes: [{pid: 1, burst: 5}]\nschedulers: [{name: cfs, nice_weights: {20: 5}}]", wantErr: "nice value 20 is not within -20 to 19 (scheduler 1)"},
+		{name: "bad process", format: FormatYAML, input: "processes: [{pid: 1, burst: 5}, {pid: 1, burst: 2}]", wantErr: "process 2: PID 1 repeats process 1"},
+		{name: "bad yaml", format: FormatYAML, input: "processes: [", wantErr: "yaml"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseScenario([]byte(tt.input), tt.format, "dir")
+			if tt.wantErr != "" {
+				if !strings.Contains(fmt.Sprint(err), tt.wantErr) {
+					t.Errorf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantRuns > 0 {
+				if len(got.Runs) != tt.wantRuns {
+					t.Errorf("got %d runs, want %d", len(got.Runs), tt.wantRuns)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseScenario() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
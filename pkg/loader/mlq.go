@@ -0,0 +1,35 @@
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+// LoadMLQConfig reads a multilevel queue declared as JSON and validates it.
+func LoadMLQConfig(r io.Reader) (scheduler.MLQConfig, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var c scheduler.MLQConfig
+	if err := dec.Decode(&c); err != nil {
+		return scheduler.MLQConfig{}, fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+	return c, c.Validate()
+}
+
+// LoadMLQConfigFile is LoadMLQConfig on the file at path.
+func LoadMLQConfigFile(path string) (*scheduler.MLQConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	c, err := LoadMLQConfig(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &c, nil
+}
@@ -0,0 +1,54 @@
+package loader
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+// Periodic task rows have the ID, period and WCET, then optionally the
+// deadline relative to each release.
+const (
+	minPeriodicFields = 3
+	maxPeriodicFields = 4
+)
+
+// LoadPeriodicTasks reads periodic tasks from CSV rows of ID, period, WCET and
+// optional deadline, stopping at the first row with a Problem.
+func LoadPeriodicTasks(r io.Reader) ([]scheduler.PeriodicTask, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	var tasks []scheduler.PeriodicTask
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return tasks, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: reading CSV", err)
+		}
+		line, _ := cr.FieldPos(0)
+		if len(row) < minPeriodicFields || len(row) > maxPeriodicFields {
+			return nil, fmt.Errorf("%w: line %d: expected %d–%d fields, got %d",
+				scheduler.ErrInvalidArgs, line, minPeriodicFields, maxPeriodicFields, len(row))
+		}
+		fields := make([]int64, maxPeriodicFields)
+		for i, field := range row {
+			if i == 3 && field == "" {
+				continue
+			}
+			if fields[i], err = strconv.ParseInt(strings.TrimSpace(field), 10, 64); err != nil {
+				return nil, fmt.Errorf("%w: line %d, column %d: %q is not an integer", scheduler.ErrInvalidArgs, line, i+1, field)
+			}
+		}
+		task := scheduler.PeriodicTask{ID: fields[0], Period: fields[1], WCET: fields[2], Deadline: fields[3]}
+		if problem := task.Problem(); problem != "" {
+			return nil, fmt.Errorf("%w: line %d: %s", scheduler.ErrInvalidArgs, line, problem)
+		}
+		tasks = append(tasks, task)
+	}
+}
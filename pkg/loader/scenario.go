@@ -0,0 +1,235 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+// Scenario file formats besides JSON, chosen by extension.
+const (
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+)
+
+type (
+	// Scenario is a whole simulation set up in one file, so an assignment
+	// can be re-run with one command: the workload, each scheduler to run
+	// over it with its tunables, and the reports to write of the runs.
+	Scenario struct {
+		Processes []scheduler.Process
+		Runs      []ScenarioRun
+		Outputs   []ScenarioOutput
+	}
+	// ScenarioRun is one scheduler a scenario runs and its tunables.
+	ScenarioRun struct {
+		Scheduler string
+		Params    scheduler.SchedulerParams
+	}
+	// ScenarioOutput is a report of every run in a scenario, in a format the
+	// caller names, such as text or json, written to Path or, if Path is "",
+	// to standard output.
+	ScenarioOutput struct {
+		Format string `json:"format"`
+		Path   string `json:"path,omitempty"`
+	}
+	// scenarioFile is a scenario as written, in JSON or in YAML or TOML with
+	// the same keys. It gives either a Workload file, relative to the
+	// scenario's own directory like Output paths, or inline Processes in a
+	// JSON workload's form. No Schedulers runs every registered one, and no
+	// Outputs prints text.
+	scenarioFile struct {
+		Workload   string              `json:"workload,omitempty"`
+		Format     string              `json:"format,omitempty"`
+		Processes  []jsonProcess       `json:"processes,omitempty"`
+		Schedulers []scenarioScheduler `json:"schedulers,omitempty"`
+		Outputs    []ScenarioOutput    `json:"outputs,omitempty"`
+	}
+	// scenarioScheduler is a scheduler in a scenario file, either just its
+	// name or an object with the name and its tunables, zero for defaults.
+	scenarioScheduler struct {
+		Name              string                  `json:"name"`
+		Quantum           int64                   `json:"quantum,omitempty"`
+		Aging             int64                   `json:"aging,omitempty"`
+		Seed              int64                   `json:"seed,omitempty"`
+		Latency           int64                   `json:"latency,omitempty"`
+		Alpha             float64                 `json:"alpha,omitempty"`
+		CPUs              int                     `json:"cpus,omitempty"`
+		ContextSwitchCost int64                   `json:"context_switch_cost,omitempty"`
+		TieBreak          scheduler.TieBreak      `json:"tie_break,omitempty"`
+		AssumeSorted      bool                    `json:"assume_sorted,omitempty"`
+		Inherit           bool                    `json:"inherit,omitempty"`
+		AgingPolicy       *scheduler.AgingPolicy  `json:"aging_policy,omitempty"`
+		NiceWeights       map[int64]int64         `json:"nice_weights,omitempty"`
+		PriorityOrder     scheduler.PriorityOrder `json:"priority_order,omitempty"`
+		Caps              map[string]int64        `json:"caps,omitempty"`
+		CapPeriod         int64                   `json:"cap_period,omitempty"`
+		Carry             scheduler.CarryPolicy   `json:"carry,omitempty"`
+		BankCap           int64                   `json:"bank_cap,omitempty"`
+		Weights           map[int64]int64         `json:"weights,omitempty"`
+		MLQ               *scheduler.MLQConfig    `json:"mlq,omitempty"`
+	}
+)
+
+// UnmarshalJSON reads a scheduler as its bare name or as an object.
+func (s *scenarioScheduler) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &s.Name); err == nil {
+		return nil
+	}
+	// A distinct type, so decoding the object does not recurse.
+	type object scenarioScheduler
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*object)(s))
+}
+
+// LoadScenarioFile reads the scenario at path as YAML for .yaml or .yml,
+// TOML for .toml, and otherwise JSON, checking its schedulers and loading
+// its workload.
+func LoadScenarioFile(path string) (Scenario, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Scenario{}, err
+	}
+	format := FormatJSON
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		format = FormatYAML
+	case ".toml":
+		format = FormatTOML
+	}
+	s, err := parseScenario(b, format, filepath.Dir(path))
+	if err != nil {
+		return Scenario{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// parseScenario reads a scenario in format, resolving its relative paths
+// against dir. YAML and TOML are read as JSON would be, so all three share
+// scenarioFile's keys and its check for unknown ones.
+func parseScenario(b []byte, format, dir string) (Scenario, error) {
+	if format != FormatJSON {
+		var v interface{}
+		var err error
+		if format == FormatYAML {
+			err = yaml.Unmarshal(b, &v)
+		} else {
+			err = toml.Unmarshal(b, &v)
+		}
+		if err != nil {
+			return Scenario{}, fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+		}
+		if b, err = json.Marshal(stringKeys(v)); err != nil {
+			return Scenario{}, fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	var f scenarioFile
+	if err := dec.Decode(&f); err != nil {
+		return Scenario{}, fmt.Errorf("%w: %v", scheduler.ErrInvalidArgs, err)
+	}
+
+	var (
+		s   Scenario
+		err error
+	)
+	switch {
+	case f.Workload != "" && f.Processes != nil:
+		return Scenario{}, fmt.Errorf("%w: give a workload file or processes, not both", scheduler.ErrInvalidArgs)
+	case f.Workload != "":
+		s.Processes, err = LoadFile(resolve(dir, f.Workload), f.Format)
+	case len(f.Processes) > 0:
+		s.Processes, err = jsonProcesses(f.Processes)
+	default:
+		err = fmt.Errorf("%w: a scenario needs a workload file or processes", scheduler.ErrInvalidArgs)
+	}
+	if err != nil {
+		return Scenario{}, err
+	}
+	if len(f.Schedulers) == 0 {
+		for _, name := range scheduler.SchedulerNames() {
+			f.Schedulers = append(f.Schedulers, scenarioScheduler{Name: name})
+		}
+	}
+	for i, c := range f.Schedulers {
+		if _, err := scheduler.LookupScheduler(c.Name); err != nil {
+			return Scenario{}, fmt.Errorf("%w (scheduler %d)", err, i+1)
+		}
+		if c.MLQ != nil {
+			if err := c.MLQ.Validate(); err != nil {
+				return Scenario{}, fmt.Errorf("%w (scheduler %d)", err, i+1)
+			}
+		}
+		if _, err := scheduler.NiceWeightTable(c.NiceWeights); err != nil {
+			return Scenario{}, fmt.Errorf("%w (scheduler %d)", err, i+1)
+		}
+		s.Runs = append(s.Runs, ScenarioRun{Scheduler: c.Name, Params: scheduler.SchedulerParams{
+			Quantum:       c.Quantum,
+			Aging:         c.Aging,
+			SwitchCost:    c.ContextSwitchCost,
+			CPUs:          c.CPUs,
+			Seed:          c.Seed,
+			Latency:       c.Latency,
+			Alpha:         c.Alpha,
+			Weights:       c.Weights,
+			MLQ:           c.MLQ,
+			TieBreak:      c.TieBreak,
+			AssumeSorted:  c.AssumeSorted,
+			Inherit:       c.Inherit,
+			AgingPolicy:   c.AgingPolicy,
+			NiceWeights:   c.NiceWeights,
+			PriorityOrder: c.PriorityOrder,
+			Caps:          c.Caps,
+			CapPeriod:     c.CapPeriod,
+			Carry:         c.Carry,
+			BankCap:       c.BankCap,
+		}})
+	}
+	s.Outputs = f.Outputs
+	for i := range s.Outputs {
+		if s.Outputs[i].Path != "" {
+			s.Outputs[i].Path = resolve(dir, s.Outputs[i].Path)
+		}
+	}
+	return s, nil
+}
+
+// resolve is path taken relative to dir unless it is absolute.
+func resolve(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// stringKeys turns the maps YAML gives non-string keys, such as a weights
+// table keyed by priority, into maps JSON can encode.
+func stringKeys(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = stringKeys(e)
+		}
+		return m
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = stringKeys(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = stringKeys(e)
+		}
+	}
+	return v
+}
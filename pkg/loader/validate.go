@@ -0,0 +1,83 @@
+package loader
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+// Problems is every problem found in a workload, in the order they were
+// found, each an error wrapping scheduler.ErrInvalidArgs that names its
+// row. Loading reports them all at once rather than stopping at the first,
+// so a workload can be fixed in one pass.
+type Problems []error
+
+func (p Problems) Error() string {
+	if len(p) == 1 {
+		return p[0].Error()
+	}
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "%d problems with the workload:", len(p))
+	for _, err := range p {
+		b.WriteString("\n\t")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Is reports whether any of the problems is target.
+func (p Problems) Is(target error) bool {
+	for _, err := range p {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks a workload built without a loader, such as from another
+// program or a random generator, by the same rules the loaders hold files to,
+// numbering the processes from 1. It returns Problems, or nil if there are
+// none.
+func Validate(processes []scheduler.Process) error {
+	problems := checkWorkload(processes, func(i int) string { return fmt.Sprintf("process %d", i+1) })
+	if len(problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+// checkWorkload finds the problems with processes on their own and with
+// processes as a whole, which the schedulers rely on not having: a PID used
+// twice makes the Gantt chart and metrics ambiguous, and a donation can only
+// go to a process in the workload. row names where the i'th process came from.
+func checkWorkload(processes []scheduler.Process, row func(i int) string) Problems {
+	var problems Problems
+	firstRow := make(map[int64]int, len(processes))
+	for i := range processes {
+		p := &processes[i]
+		if problem := p.Problem(); problem != "" {
+			problems = append(problems, fmt.Errorf("%w: %s: %s", scheduler.ErrInvalidArgs, row(i), problem))
+			continue
+		}
+		if j, ok := firstRow[p.ProcessID]; ok {
+			problems = append(problems, fmt.Errorf("%w: %s: PID %d repeats %s", scheduler.ErrInvalidArgs, row(i), p.ProcessID, row(j)))
+			continue
+		}
+		firstRow[p.ProcessID] = i
+	}
+	for i := range processes {
+		switch donee := processes[i].DonateTo; {
+		case donee == 0:
+		case donee == processes[i].ProcessID:
+			problems = append(problems, fmt.Errorf("%w: %s: PID %d donates to itself", scheduler.ErrInvalidArgs, row(i), donee))
+		default:
+			if _, ok := firstRow[donee]; !ok {
+				problems = append(problems, fmt.Errorf("%w: %s: donates to PID %d, which is not in the workload", scheduler.ErrInvalidArgs, row(i), donee))
+			}
+		}
+	}
+	return problems
+}
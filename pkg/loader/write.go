@@ -0,0 +1,41 @@
+package loader
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+// Write saves processes in the named format, so that Load reads them back:
+// CSV rows of ID, burst, arrival and priority, or a JSON array that also
+// keeps names, deadlines and nice values. Neither keeps yields, I/O or sync operations.
+func Write(w io.Writer, format string, processes []scheduler.Process) error {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		for _, p := range processes {
+			_ = cw.Write([]string{
+				strconv.FormatInt(p.ProcessID, 10),
+				strconv.FormatInt(p.BurstDuration, 10),
+				strconv.FormatInt(p.ArrivalTime, 10),
+				strconv.FormatInt(p.Priority, 10),
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatJSON:
+		rows := make([]jsonProcess, len(processes))
+		for i, p := range processes {
+			rows[i] = jsonProcess{PID: p.ProcessID, Burst: p.BurstDuration, Arrival: p.ArrivalTime, Priority: p.Priority, Name: p.Name, Deadline: p.Deadline, Nice: p.Nice}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	default:
+		return fmt.Errorf("%w: unknown workload format %q, want %s or %s", scheduler.ErrInvalidArgs, format, FormatCSV, FormatJSON)
+	}
+}
@@ -0,0 +1,66 @@
+package render
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+var (
+	MetricsCSVProcessHeader = []string{"scheduler", "pid", "arrival", "burst", "wait", "turnaround", "response", "completion"}
+	MetricsCSVSummaryHeader = []string{"scheduler", "avg_wait", "avg_turnaround", "avg_response", "throughput", "utilization"}
+)
+
+// MetricsCSV writes results as CSV for spreadsheets and dataframes: a
+// row per scheduler and process, then, after a blank line, a row of averages
+// per scheduler, each section under its own header.
+func MetricsCSV(w io.Writer, results []scheduler.RunResult) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write(MetricsCSVProcessHeader)
+	for _, r := range results {
+		label := scheduler.RunLabel(r)
+		for _, m := range r.Processes {
+			_ = cw.Write(MetricsCSVProcessRow(label, m))
+		}
+	}
+	_ = cw.Write(nil)
+	_ = cw.Write(MetricsCSVSummaryHeader)
+	for _, r := range results {
+		_ = cw.Write(MetricsCSVSummaryRow(r))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// MetricsCSVProcessRow is m's row of the process section.
+func MetricsCSVProcessRow(label string, m scheduler.ProcessMetrics) []string {
+	return []string{
+		label,
+		strconv.FormatInt(m.PID, 10),
+		strconv.FormatInt(m.Arrival, 10),
+		strconv.FormatInt(m.Burst, 10),
+		strconv.FormatInt(m.Wait, 10),
+		strconv.FormatInt(m.Turnaround, 10),
+		strconv.FormatInt(m.Response, 10),
+		strconv.FormatInt(m.Exit, 10),
+	}
+}
+
+// MetricsCSVSummaryRow is r's row of the summary section.
+func MetricsCSVSummaryRow(r scheduler.RunResult) []string {
+	return []string{
+		scheduler.RunLabel(r),
+		csvFloat(r.AvgWait),
+		csvFloat(r.AvgTurnaround),
+		csvFloat(r.AvgResponse),
+		csvFloat(r.Throughput),
+		csvFloat(r.Utilization),
+	}
+}
+
+// csvFloat writes v in as few digits as read back exactly.
+func csvFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,223 @@
+package render
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+// Layout of a report's Gantt charts, in SVG pixels.
+const (
+	reportGanttWidth = 880
+	reportRowHeight  = 28
+	reportAxisHeight = 18
+	// reportLabelWidth is the slice width under which the PID is left to
+	// the tooltip, and reportTickGap the least room between axis labels.
+	reportLabelWidth = 18
+	reportTickGap    = 24
+)
+
+type (
+	// htmlReport is what the report template renders: the comparison of the
+	// runs and, for each, its Gantt chart and schedule.
+	htmlReport struct {
+		Comparison []reportComparisonRow
+		Metrics    []string
+		Runs       []reportRun
+	}
+	reportComparisonRow struct {
+		Label string
+		Cells []reportCell
+	}
+	reportCell struct {
+		Value string
+		Best  bool
+	}
+	reportRun struct {
+		ID        string
+		Label     string
+		Title     string
+		Result    scheduler.RunResult
+		Gantt     reportGantt
+		Processes []scheduler.ProcessMetrics
+	}
+	// reportGantt is a run's Gantt chart as SVG, a row per CPU.
+	reportGantt struct {
+		Width, Height int
+		Rows          []reportRow
+		Bars          []reportBar
+		Ticks         []reportTick
+	}
+	reportRow struct {
+		Y     int
+		Label string
+	}
+	reportBar struct {
+		X, Y, W, H float64
+		Class      string
+		Color      template.CSS
+		Label      string
+		Tooltip    string
+	}
+	reportTick struct {
+		X     float64
+		Y     int
+		Label string
+	}
+)
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>CPU scheduling report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+th { background: #f0f0f0; }
+td.best { font-weight: bold; background: #e6f4e6; }
+tfoot td { font-style: italic; }
+svg text { font-size: 11px; }
+svg .bar:hover { stroke: #000; stroke-width: 2; }
+svg .idle { fill: #eee; }
+svg .switch { fill: #888; }
+nav a { margin-right: 1em; }
+</style>
+</head>
+<body>
+<h1>CPU scheduling report</h1>
+<nav>{{range .Runs}}<a href="#{{.ID}}">{{.Label}}</a>{{end}}</nav>
+<h2>Comparison</h2>
+<table>
+<thead><tr><th>Scheduler</th>{{range .Metrics}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Comparison}}<tr><td>{{.Label}}</td>{{range .Cells}}<td{{if .Best}} class="best"{{end}}>{{.Value}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+<p>Highlighted values are the best in their column.</p>
+{{range .Runs}}
+<h2 id="{{.ID}}">{{.Title}}</h2>
+<svg width="{{.Gantt.Width}}" height="{{.Gantt.Height}}" role="img" aria-label="Gantt chart of {{.Label}}">
+{{range .Gantt.Rows}}<text x="0" y="{{.Y}}">{{.Label}}</text>
+{{end}}{{range .Gantt.Bars}}<rect class="bar {{.Class}}" x="{{.X}}" y="{{.Y}}" width="{{.W}}" height="{{.H}}"{{if .Color}} style="fill: {{.Color}}"{{end}}><title>{{.Tooltip}}</title></rect>
+{{if .Label}}<text x="{{.X}}" y="{{.Y}}" dx="4" dy="18" pointer-events="none">{{.Label}}</text>
+{{end}}{{end}}{{range .Gantt.Ticks}}<text x="{{.X}}" y="{{.Y}}" text-anchor="middle">{{.Label}}</text>
+{{end}}</svg>
+<table>
+<thead><tr><th>ID</th><th>Priority</th><th>Burst</th><th>Arrival</th><th>Response</th><th>Wait</th><th>Turnaround</th><th>Exit</th></tr></thead>
+<tbody>
+{{range .Processes}}<tr><td>{{.PID}}{{with .Name}} ({{.}}){{end}}</td><td>{{.Priority}}</td><td>{{.Burst}}</td><td>{{.Arrival}}</td><td>{{.Response}}</td><td>{{.Wait}}</td><td>{{.Turnaround}}</td><td>{{.Exit}}</td></tr>
+{{end}}</tbody>
+<tfoot><tr><td colspan="4">Average</td><td>{{printf "%.2f" .Result.AvgResponse}}</td><td>{{printf "%.2f" .Result.AvgWait}}</td><td>{{printf "%.2f" .Result.AvgTurnaround}}</td><td>Throughput {{printf "%.2f/t" .Result.Throughput}}</td></tr></tfoot>
+</table>
+{{end}}
+</body>
+</html>
+`))
+
+// HTMLReport writes results as one self-contained HTML page: a
+// comparison of the runs' averages, then each run's Gantt chart, whose
+// slices show their PID and duration on hover, and its schedule table.
+func HTMLReport(w io.Writer, results []scheduler.RunResult) error {
+	report := htmlReport{}
+	for _, m := range scheduler.ComparisonMetrics {
+		report.Metrics = append(report.Metrics, m.Title)
+	}
+	c := scheduler.Compare(results)
+	for _, r := range c.Results {
+		label := scheduler.RunLabel(r)
+		row := reportComparisonRow{Label: label}
+		for _, m := range scheduler.ComparisonMetrics {
+			row.Cells = append(row.Cells, reportCell{Value: fmt.Sprintf(m.Format, m.Value(r)), Best: c.IsBest(m.Name, label)})
+		}
+		report.Comparison = append(report.Comparison, row)
+	}
+	for i, r := range results {
+		title := scheduler.RunLabel(r)
+		if info, err := scheduler.LookupScheduler(r.Scheduler); err == nil {
+			title = fmt.Sprintf("%s: %s", info.Title, scheduler.RunLabel(r))
+		}
+		report.Runs = append(report.Runs, reportRun{
+			ID:        fmt.Sprintf("run-%d", i+1),
+			Label:     scheduler.RunLabel(r),
+			Title:     title,
+			Result:    r,
+			Gantt:     ganttSVG(r),
+			Processes: r.Processes,
+		})
+	}
+	return reportTemplate.Execute(w, report)
+}
+
+// ganttSVG lays r's Gantt chart out to scale, a row per CPU, with the times
+// at which slices start and end along the bottom where there is room.
+func ganttSVG(r scheduler.RunResult) reportGantt {
+	cpus := len(r.CPUs)
+	if cpus < 1 {
+		cpus = 1
+	}
+	labelWidth := 0
+	if cpus > 1 {
+		labelWidth = 50
+	}
+	g := reportGantt{Width: reportGanttWidth, Height: cpus*reportRowHeight + reportAxisHeight}
+	var end int64
+	for _, s := range r.Gantt {
+		if s.Stop > end {
+			end = s.Stop
+		}
+	}
+	if end == 0 {
+		return g
+	}
+	scale := float64(reportGanttWidth-labelWidth-20) / float64(end)
+	x := func(t int64) float64 { return float64(labelWidth+10) + float64(t)*scale }
+	if cpus > 1 {
+		for i := 0; i < cpus; i++ {
+			g.Rows = append(g.Rows, reportRow{Y: i*reportRowHeight + 18, Label: fmt.Sprintf("CPU %d", i)})
+		}
+	}
+	names := ProcessNames(r.Processes)
+	var times []int64
+	for _, s := range r.Gantt {
+		bar := reportBar{
+			X: x(s.Start),
+			Y: float64(s.CPU * reportRowHeight),
+			W: float64(s.Stop-s.Start) * scale,
+			H: reportRowHeight - 4,
+		}
+		duration := s.Stop - s.Start
+		switch s.PID {
+		case scheduler.IdlePID:
+			bar.Class = "idle"
+			bar.Tooltip = fmt.Sprintf("idle, %d–%d (%d)", s.Start, s.Stop, duration)
+		case scheduler.SwitchPID:
+			bar.Class = "switch"
+			bar.Tooltip = fmt.Sprintf("context switch, %d–%d (%d)", s.Start, s.Stop, duration)
+		default:
+			bar.Color = template.CSS(fmt.Sprintf("hsl(%d, 60%%, 70%%)", (s.PID*137%360+360)%360))
+			bar.Tooltip = fmt.Sprintf("PID %s, %d–%d (%d)", processLabel(s.PID, names[s.PID]), s.Start, s.Stop, duration)
+			if bar.W >= reportLabelWidth {
+				bar.Label = fmt.Sprint(s.PID)
+				if name, ok := names[s.PID]; ok {
+					bar.Label = name
+				}
+			}
+		}
+		g.Bars = append(g.Bars, bar)
+		times = append(times, s.Start, s.Stop)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	axisY := cpus*reportRowHeight + reportAxisHeight - 4
+	for _, t := range times {
+		if n := len(g.Ticks); n == 0 || x(t)-g.Ticks[n-1].X >= reportTickGap {
+			g.Ticks = append(g.Ticks, reportTick{X: x(t), Y: axisY, Label: fmt.Sprint(t)})
+		}
+	}
+	return g
+}
@@ -0,0 +1,53 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+// mermaidUnsafe replaces what Mermaid reads as syntax in a task or section
+// name: a colon ends the name, and # and ; start entities and statements.
+var mermaidUnsafe = strings.NewReplacer(":", " ", "#", " ", ";", " ")
+
+// MermaidGantt writes results' Gantt charts as one Mermaid gantt diagram, to
+// paste into Markdown between ```mermaid fences. Times are ticks, read as
+// seconds from 0 so the axis counts them. Each run has a section, or one per
+// CPU if it used several, with a task per slice a process ran and a crit
+// task per context switch; idle time is left as a gap.
+func MermaidGantt(w io.Writer, results []scheduler.RunResult) error {
+	bw := bufio.NewWriter(w)
+	_, _ = io.WriteString(bw, "gantt\n    title CPU schedule\n    dateFormat X\n    axisFormat %s\n")
+	for _, r := range results {
+		label := mermaidUnsafe.Replace(scheduler.RunLabel(r))
+		cpus := len(r.CPUs)
+		if cpus < 1 {
+			cpus = 1
+		}
+		names := ProcessNames(r.Processes)
+		for cpu := 0; cpu < cpus; cpu++ {
+			if cpus > 1 {
+				_, _ = fmt.Fprintf(bw, "    section %s CPU %d\n", label, cpu)
+			} else {
+				_, _ = fmt.Fprintf(bw, "    section %s\n", label)
+			}
+			for _, s := range r.Gantt {
+				if s.CPU != cpu {
+					continue
+				}
+				switch s.PID {
+				case scheduler.IdlePID:
+				case scheduler.SwitchPID:
+					_, _ = fmt.Fprintf(bw, "    switch :crit, %d, %d\n", s.Start, s.Stop)
+				default:
+					name := mermaidUnsafe.Replace(processLabel(s.PID, names[s.PID]))
+					_, _ = fmt.Fprintf(bw, "    P%s :%d, %d\n", name, s.Start, s.Stop)
+				}
+			}
+		}
+	}
+	return bw.Flush()
+}
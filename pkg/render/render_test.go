@@ -0,0 +1,400 @@
+package render
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+// basicWorkload is the command's testdata/workloads/basic.csv.
+var basicWorkload = []scheduler.Process{
+	{ProcessID: 1, BurstDuration: 5, ArrivalTime: 0, Priority: 2},
+	{ProcessID: 2, BurstDuration: 9, ArrivalTime: 3, Priority: 1},
+	{ProcessID: 3, BurstDuration: 6, ArrivalTime: 6, Priority: 3},
+}
+
+func Test_writeGantt(t *testing.T) {
+	t.Parallel()
+	gantt := []scheduler.TimeSlice{
+		{PID: 1, Start: 0, Stop: 2},
+		{PID: 1, Start: 2, Stop: 4},
+		{PID: 12, Start: 4, Stop: 1000},
+		{PID: scheduler.IdlePID, Start: 1000, Stop: 123456},
+		{PID: 3, Start: 123456, Stop: 123458},
+	}
+	tests := []struct {
+		name  string
+		width int
+		want  string
+	}{
+		{
+			// P1's two slices merge, and the bars widen for long times.
+			name:  "one row",
+			width: 80,
+			want: "Gantt schedule\n" +
+				"|  1  |  12  |  idle  |   3   |\n" +
+				"0     4      1000     123456  123458\n\n",
+		},
+		{
+			name:  "wrapped",
+			width: 16,
+			want: "Gantt schedule\n" +
+				"|  1  |  12  |\n" +
+				"0     4      1000\n\n" +
+				"|  idle  |\n" +
+				"1000     123456\n\n" +
+				"|   3   |\n" +
+				"123456  123458\n\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var out bytes.Buffer
+			WriteGantt(&out, gantt, nil, tt.width)
+			if out.String() != tt.want {
+				t.Errorf("writeGantt() =\n%s\nwant\n%s", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_outputResult_names(t *testing.T) {
+	t.Parallel()
+	processes := []scheduler.Process{
+		{ProcessID: 1, BurstDuration: 5, ArrivalTime: 0, Priority: 2, Name: "init"},
+		{ProcessID: 2, BurstDuration: 9, ArrivalTime: 3, Priority: 1},
+	}
+	result, err := scheduler.RunScheduler("fcfs", 0, processes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Processes[0].Name != "init" || result.Processes[1].Name != "" {
+		t.Errorf("metrics names = %q, %q, want init and none", result.Processes[0].Name, result.Processes[1].Name)
+	}
+	var out bytes.Buffer
+	Result(&out, "FCFS", result)
+	for _, want := range []string{"|  init  |  2  |\n0        5     14", "| 1 (init) |"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output has no %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func Test_runActivity(t *testing.T) {
+	t.Parallel()
+	r := scheduler.RunResult{
+		Gantt: []scheduler.TimeSlice{
+			{PID: 1, Start: 0, Stop: 2},
+			{PID: 1, Start: 2, Stop: 4},
+			{PID: scheduler.SwitchPID, Start: 4, Stop: 5},
+			{PID: 2, Start: 5, Stop: 6},
+			{PID: scheduler.IdlePID, Start: 6, Stop: 8},
+			{PID: 1, Start: 8, Stop: 9},
+		},
+		Events: []scheduler.Event{
+			{Time: 2, Kind: scheduler.EventPreempt, PID: 1},
+			{Time: 4, Kind: scheduler.EventPreempt, PID: 1},
+			{Time: 6, Kind: scheduler.EventBlock, PID: 2},
+		},
+	}
+	activity := runActivity(r)
+	tests := []struct {
+		pid  int64
+		want []string
+	}{
+		// P1 carrying on at 2 makes one run and no preemption of it.
+		{pid: 1, want: []string{"0-4 8-9", "1", "2"}},
+		{pid: 2, want: []string{"5-6", "0", "1"}},
+		{pid: 3, want: []string{"", "0", "0"}},
+	}
+	for _, tt := range tests {
+		if got := activity[tt.pid].cells(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("P%d cells = %q, want %q", tt.pid, got, tt.want)
+		}
+	}
+}
+
+func Test_outputCPUTime(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		gantt []scheduler.TimeSlice
+		want  []string
+	}{
+		{
+			// Idle before the first arrival and between bursts.
+			name: "idle",
+			gantt: []scheduler.TimeSlice{
+				{PID: scheduler.IdlePID, Start: 0, Stop: 2},
+				{PID: 1, Start: 2, Stop: 5},
+				{PID: scheduler.IdlePID, Start: 5, Stop: 8},
+				{PID: 2, Start: 8, Stop: 10},
+			},
+			want: []string{"| CPU | TOTAL | BUSY | IDLE | UTILIZATION |", "|   0 |    10 |    5 |    5 | 50.0%       |"},
+		},
+		{
+			name: "switching",
+			gantt: []scheduler.TimeSlice{
+				{PID: scheduler.SwitchPID, Start: 0, Stop: 1},
+				{PID: 1, Start: 1, Stop: 4},
+				{PID: scheduler.SwitchPID, Start: 4, Stop: 5},
+				{PID: 2, Start: 5, Stop: 8},
+			},
+			want: []string{"| CPU | TOTAL | BUSY | SWITCHING | IDLE | UTILIZATION |", "|   0 |     8 |    6 |         2 |    0 | 75.0%       |"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var out bytes.Buffer
+			CPUTime(&out, tt.gantt, scheduler.CPUUsage(tt.gantt, 1))
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output has no %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestWriteLocks(t *testing.T) {
+	t.Parallel()
+	processes := []scheduler.Process{
+		{ProcessID: 1, BurstDuration: 5, Priority: 3, Ops: []scheduler.SyncOp{{At: 1, Op: scheduler.MutexLock, Object: "r"}, {At: 4, Op: scheduler.MutexUnlock, Object: "r"}}},
+		{ProcessID: 2, BurstDuration: 3, ArrivalTime: 2, Priority: 1, Ops: []scheduler.SyncOp{{At: 1, Op: scheduler.MutexLock, Object: "r"}, {At: 2, Op: scheduler.MutexUnlock, Object: "r"}}},
+		{ProcessID: 3, BurstDuration: 6, ArrivalTime: 3, Priority: 2},
+	}
+	tests := []struct {
+		name    string
+		inherit bool
+		want    []string
+	}{
+		{
+			// 3 runs while 2 is blocked, so it is charted too.
+			name: "inversion",
+			want: []string{
+				"  0         10\n" +
+					"1|##-------###  |\n" +
+					"2|  #bbbbbbbb-##|\n" +
+					"3|   ######     |\n",
+				"|  2 | r    |       1 |    3 | 11 |       8 |",
+			},
+		},
+		{
+			name:    "inheritance",
+			inherit: true,
+			want: []string{
+				"  0         10\n" +
+					"1|##-^^--------#|\n" +
+					"2|  #bb##       |\n\n",
+				"|  2 | r    |       1 |    3 |  5 |       2 |",
+				"|    3 |  1 | r    |    3 |  1 |",
+				"|    5 |  1 | r    |    1 |  3 |",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := scheduler.RunSchedulerParams("priority-preemptive", scheduler.SchedulerParams{Inherit: tt.inherit}, processes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			WriteLocks(&out, result, nil, 80)
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("WriteLocks() has no %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+
+	result, err := scheduler.RunScheduler("fcfs", 0, basicWorkload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if WriteLocks(&out, result, nil, 80); out.Len() > 0 {
+		t.Errorf("WriteLocks() without locks =\n%s\nwant nothing", out.String())
+	}
+}
+
+func Test_writeHTMLReport(t *testing.T) {
+	t.Parallel()
+	processes := basicWorkload
+	var results []scheduler.RunResult
+	for _, name := range []string{"fcfs", "rr"} {
+		r, err := scheduler.RunScheduler(name, 2, processes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, r)
+	}
+	var out bytes.Buffer
+	if err := HTMLReport(&out, results); err != nil {
+		t.Fatal(err)
+	}
+	html := out.String()
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		`<h2 id="run-1">First-come, first-serve: fcfs</h2>`,
+		`<h2 id="run-2">Round-robin: rr (q=2)</h2>`,
+		"<title>PID 1, 0–",
+		`class="best"`,
+		"style=\"fill: hsl(",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report missing %q", want)
+		}
+	}
+	if strings.Contains(html, "ZgotmplZ") {
+		t.Error("report has values the template escaped away")
+	}
+	if got, want := strings.Count(html, "<rect "), len(results[0].Gantt)+len(results[1].Gantt); got != want {
+		t.Errorf("report has %d Gantt slices, want %d", got, want)
+	}
+}
+
+func Test_ganttSVG(t *testing.T) {
+	t.Parallel()
+	g := ganttSVG(scheduler.RunResult{Gantt: []scheduler.TimeSlice{
+		{PID: scheduler.IdlePID, Start: 0, Stop: 1},
+		{PID: 1, Start: 1, Stop: 2},
+		{PID: 2, Start: 2, Stop: 100},
+	}})
+	if len(g.Bars) != 3 {
+		t.Fatalf("ganttSVG() has %d bars, want 3", len(g.Bars))
+	}
+	if g.Bars[0].Class != "idle" || g.Bars[0].Label != "" {
+		t.Errorf("idle bar = %+v", g.Bars[0])
+	}
+	if g.Bars[1].Label != "" || g.Bars[2].Label != "2" {
+		t.Errorf("labels = %q, %q, want only the wide slice labeled", g.Bars[1].Label, g.Bars[2].Label)
+	}
+	var ticks []string
+	for _, tick := range g.Ticks {
+		ticks = append(ticks, tick.Label)
+	}
+	if got := strings.Join(ticks, ","); got != "0,100" {
+		t.Errorf("ticks = %s, want the crowded 1 and 2 left out", got)
+	}
+}
+
+func TestChromeTrace(t *testing.T) {
+	t.Parallel()
+	results := []scheduler.RunResult{{
+		Scheduler: "rr",
+		Quantum:   2,
+		Gantt: []scheduler.TimeSlice{
+			{PID: scheduler.IdlePID, Start: 0, Stop: 1},
+			{PID: 1, Start: 1, Stop: 3},
+			{PID: scheduler.SwitchPID, Start: 3, Stop: 4},
+		},
+		Processes: []scheduler.ProcessMetrics{{PID: 1, Name: "init"}},
+	}}
+	var out bytes.Buffer
+	if err := ChromeTrace(&out, results); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"displayTimeUnit":"ms"`,
+		`{"name":"process_name","ph":"M","ts":0,"pid":1,"tid":0,"args":{"name":"rr (q=2)"}}`,
+		`{"name":"thread_name","ph":"M","ts":0,"pid":1,"tid":0,"args":{"name":"CPU 0"}}`,
+		`{"name":"idle","cat":"idle","ph":"X","ts":0,"dur":1000,"pid":1,"tid":0}`,
+		`{"name":"PID 1 (init)","cat":"run","ph":"X","ts":1000,"dur":2000,"pid":1,"tid":0,"args":{"pid":1,"start":1,"stop":3}}`,
+		`{"name":"context switch","cat":"switch","ph":"X","ts":3000,"dur":1000,"pid":1,"tid":0}`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("trace missing %s:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestMermaidGantt(t *testing.T) {
+	t.Parallel()
+	results := []scheduler.RunResult{
+		{
+			Scheduler: "fcfs",
+			Gantt: []scheduler.TimeSlice{
+				{PID: scheduler.IdlePID, Start: 0, Stop: 1},
+				{PID: 1, Start: 1, Stop: 3},
+				{PID: scheduler.SwitchPID, Start: 3, Stop: 4},
+				{PID: 2, Start: 4, Stop: 6},
+			},
+			Processes: []scheduler.ProcessMetrics{{PID: 2, Name: "shell: bash"}},
+		},
+		{
+			Scheduler: "rr",
+			Quantum:   2,
+			Gantt: []scheduler.TimeSlice{
+				{PID: 1, Start: 0, Stop: 2},
+				{PID: 2, Start: 0, Stop: 2, CPU: 1},
+			},
+			CPUs: make([]scheduler.CPUStats, 2),
+		},
+	}
+	var out bytes.Buffer
+	if err := MermaidGantt(&out, results); err != nil {
+		t.Fatal(err)
+	}
+	want := `gantt
+    title CPU schedule
+    dateFormat X
+    axisFormat %s
+    section fcfs
+    P1 :1, 3
+    switch :crit, 3, 4
+    P2 (shell  bash) :4, 6
+    section rr (q=2) CPU 0
+    P1 :0, 2
+    section rr (q=2) CPU 1
+    P2 :0, 2
+`
+	if out.String() != want {
+		t.Errorf("MermaidGantt() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func Test_writeMetricsCSV(t *testing.T) {
+	t.Parallel()
+	results := []scheduler.RunResult{
+		{
+			Scheduler: "fcfs",
+			Processes: []scheduler.ProcessMetrics{
+				{PID: 1, Arrival: 0, Burst: 3, Wait: 0, Turnaround: 3, Response: 0, Exit: 3},
+				{PID: 2, Arrival: 1, Burst: 2, Wait: 2, Turnaround: 4, Response: 2, Exit: 5},
+			},
+			AvgWait: 1, AvgTurnaround: 3.5, AvgResponse: 1, Throughput: 0.4, Utilization: 1,
+		},
+		{
+			Scheduler: "rr",
+			Quantum:   2,
+			Processes: []scheduler.ProcessMetrics{{PID: 1, Burst: 3, Turnaround: 3, Exit: 3}},
+			AvgWait:   0, AvgTurnaround: 3, Throughput: 1.0 / 3, Utilization: 1,
+		},
+	}
+	var out bytes.Buffer
+	if err := MetricsCSV(&out, results); err != nil {
+		t.Fatal(err)
+	}
+	want := `scheduler,pid,arrival,burst,wait,turnaround,response,completion
+fcfs,1,0,3,0,3,0,3
+fcfs,2,1,2,2,4,2,5
+rr (q=2),1,0,3,0,3,0,3
+
+scheduler,avg_wait,avg_turnaround,avg_response,throughput,utilization
+fcfs,1,3.5,1,0.4,1
+rr (q=2),0,3,0,0.3333333333333333,1
+`
+	if out.String() != want {
+		t.Errorf("writeMetricsCSV() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
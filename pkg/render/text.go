@@ -0,0 +1,710 @@
+// Package render writes scheduler runs out as ASCII Gantt charts and tables,
+// HTML reports and CSV metrics.
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+func Title(w io.Writer, title string) {
+	_, _ = fmt.Fprintln(w, strings.Repeat("-", len(title)*2))
+	_, _ = fmt.Fprintln(w, strings.Repeat(" ", len(title)/2), title)
+	_, _ = fmt.Fprintln(w, strings.Repeat("-", len(title)*2))
+}
+
+// defaultChartWidth is the columns a row of the Gantt chart may take when
+// $COLUMNS does not give the terminal's width.
+const defaultChartWidth = 80
+
+// Gantt prints the Gantt chart, back-to-back slices of a process
+// merged, as a row of bars over a time axis that labels every boundary
+// under its edge. Each bar is wide enough for its PID and the time under
+// its left edge, and a chart wider than the terminal wraps onto more rows.
+func Gantt(w io.Writer, gantt []scheduler.TimeSlice) {
+	WriteGantt(w, gantt, nil, chartWidth())
+}
+
+// NamedGantt is outputGantt with the processes in names shown by name.
+func NamedGantt(w io.Writer, gantt []scheduler.TimeSlice, names map[int64]string) {
+	WriteGantt(w, gantt, names, chartWidth())
+}
+
+// chartWidth is the terminal's width as $COLUMNS gives it, or
+// defaultChartWidth.
+func chartWidth() int {
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	return defaultChartWidth
+}
+
+// WriteGantt is NamedGantt wrapping rows at width columns. It builds
+// the rows in reused buffers rather than formatting every slice separately,
+// which dominated long runs.
+func WriteGantt(w io.Writer, gantt []scheduler.TimeSlice, names map[int64]string, width int) {
+	bw := bufio.NewWriter(w)
+	_, _ = bw.WriteString("Gantt schedule\n")
+	gantt = scheduler.MergeSlices(gantt)
+	bars, axis := []byte{'|'}, []byte(nil)
+	var name, start []byte
+	for i, s := range gantt {
+		switch s.PID {
+		case scheduler.IdlePID:
+			name = append(name[:0], "idle"...)
+		case scheduler.SwitchPID:
+			name = append(name[:0], "switch"...)
+		default:
+			if n, ok := names[s.PID]; ok {
+				name = append(name[:0], n...)
+			} else {
+				name = strconv.AppendInt(name[:0], s.PID, 10)
+			}
+		}
+		start = strconv.AppendInt(start[:0], s.Start, 10)
+		cell := len(name) + 4
+		if len(start) >= cell {
+			cell = len(start) + 1
+		}
+		if len(bars)+cell+1 > width && len(bars) > 1 {
+			// The row ends at this slice's start, labeled under the last edge.
+			axis = append(axis, start...)
+			_, _ = bw.Write(append(bars, '\n'))
+			_, _ = bw.Write(append(axis, '\n', '\n'))
+			bars, axis = append(bars[:0], '|'), axis[:0]
+		}
+		axis = append(axis, start...)
+		for len(axis) < len(bars)+cell {
+			axis = append(axis, ' ')
+		}
+		left := (cell - len(name)) / 2
+		for j := 0; j < left; j++ {
+			bars = append(bars, ' ')
+		}
+		bars = append(bars, name...)
+		for j := left + len(name); j < cell; j++ {
+			bars = append(bars, ' ')
+		}
+		bars = append(bars, '|')
+		if i == len(gantt)-1 {
+			axis = strconv.AppendInt(axis, s.Stop, 10)
+		}
+	}
+	_, _ = bw.Write(append(bars, '\n'))
+	_, _ = bw.Write(append(axis, '\n', '\n'))
+	_ = bw.Flush()
+}
+
+// makeScheduleRows makes the rows of an n-process schedule table out of one
+// backing array, for fillScheduleRow to fill in.
+func makeScheduleRows(n int) [][]string {
+	cells := make([]string, 8*n)
+	rows := make([][]string, n)
+	for i := range rows {
+		rows[i] = cells[8*i : 8*i+8 : 8*i+8]
+	}
+	return rows
+}
+
+// processActivity is how a process ran, for the expanded schedule table:
+// Runs are the stretches it held a CPU, its adjoining Gantt slices on one
+// CPU merged, and Preemptions how many of them a preemption ended. Every
+// run begins with a context switch onto the CPU.
+type processActivity struct {
+	Runs        []scheduler.TimeSlice
+	Preemptions int
+}
+
+// runActivity works out each process's activity in r, by PID. Preemptions
+// are read from r's events, so a run without events has none.
+func runActivity(r scheduler.RunResult) map[int64]*processActivity {
+	type stop struct{ pid, time int64 }
+	preempted := make(map[stop]bool)
+	for _, ev := range r.Events {
+		if ev.Kind == scheduler.EventPreempt {
+			preempted[stop{ev.PID, ev.Time}] = true
+		}
+	}
+	activity := make(map[int64]*processActivity, len(r.Processes))
+	for _, s := range r.Gantt {
+		if s.PID == scheduler.IdlePID || s.PID == scheduler.SwitchPID {
+			continue
+		}
+		a := activity[s.PID]
+		if a == nil {
+			a = &processActivity{}
+			activity[s.PID] = a
+		}
+		if n := len(a.Runs); n > 0 && a.Runs[n-1].Stop == s.Start && a.Runs[n-1].CPU == s.CPU {
+			a.Runs[n-1].Stop = s.Stop
+			continue
+		}
+		a.Runs = append(a.Runs, s)
+	}
+	for pid, a := range activity {
+		for _, run := range a.Runs {
+			if preempted[stop{pid, run.Stop}] {
+				a.Preemptions++
+			}
+		}
+	}
+	return activity
+}
+
+// cells are a's columns of the expanded schedule table, empty for a process
+// that never ran.
+func (a *processActivity) cells() []string {
+	if a == nil {
+		return []string{"", "0", "0"}
+	}
+	runs := make([]string, len(a.Runs))
+	for i, run := range a.Runs {
+		runs[i] = fmt.Sprintf("%d-%d", run.Start, run.Stop)
+	}
+	return []string{strings.Join(runs, " "), fmt.Sprint(a.Preemptions), fmt.Sprint(len(a.Runs))}
+}
+
+// processLabel is how tables show a process: its PID, then its name if it
+// has one.
+func processLabel(pid int64, name string) string {
+	if name == "" {
+		return strconv.FormatInt(pid, 10)
+	}
+	return fmt.Sprintf("%d (%s)", pid, name)
+}
+
+// ProcessNames maps the PID of each named process to its name, and is nil
+// if none is named.
+func ProcessNames(processes []scheduler.ProcessMetrics) map[int64]string {
+	var names map[int64]string
+	for _, p := range processes {
+		if p.Name == "" {
+			continue
+		}
+		if names == nil {
+			names = make(map[int64]string)
+		}
+		names[p.PID] = p.Name
+	}
+	return names
+}
+
+// fillScheduleRow writes m's row of the schedule table into row.
+func fillScheduleRow(row []string, m *scheduler.ProcessMetrics) {
+	row[0] = processLabel(m.PID, m.Name)
+	row[1] = strconv.FormatInt(m.Priority, 10)
+	row[2] = strconv.FormatInt(m.Burst, 10)
+	row[3] = strconv.FormatInt(m.Arrival, 10)
+	row[4] = strconv.FormatInt(m.Response, 10)
+	row[5] = strconv.FormatInt(m.Wait, 10)
+	row[6] = strconv.FormatInt(m.Turnaround, 10)
+	row[7] = strconv.FormatInt(m.Exit, 10)
+}
+
+// Result renders a run as its title, Gantt chart and schedule table.
+func Result(w io.Writer, title string, result scheduler.RunResult) {
+	Run(w, title, result, false)
+}
+
+// Run is Result, with the schedule table expanded by each
+// process's runs, preemptions and context switches if expanded.
+func Run(w io.Writer, title string, result scheduler.RunResult, expanded bool) {
+	schedule := makeScheduleRows(len(result.Processes))
+	for i := range result.Processes {
+		fillScheduleRow(schedule[i], &result.Processes[i])
+	}
+	if expanded {
+		activity := runActivity(result)
+		for i, m := range result.Processes {
+			schedule[i] = append(schedule[i], activity[m.PID].cells()...)
+		}
+	}
+	names := ProcessNames(result.Processes)
+	Title(w, title)
+	if len(result.CPUs) > 1 {
+		outputCPUs(w, result.Gantt, result.CPUs, names)
+	} else {
+		NamedGantt(w, result.Gantt, names)
+		CPUTime(w, result.Gantt, scheduler.CPUUsage(result.Gantt, 1))
+		WriteLocks(w, result, names, chartWidth())
+	}
+	Schedule(w, schedule, expanded, result.AvgResponse, result.AvgWait, result.AvgTurnaround, result.Throughput)
+	Spread(w, result)
+	outputBoosts(w, result.Processes)
+	Quanta(w, result.Processes)
+	outputQueues(w, result.Processes)
+	outputShares(w, result.Processes)
+	outputNice(w, result.Processes)
+	outputThrottling(w, result.Processes)
+	outputEntitlements(w, result.Processes)
+	outputVRuntimes(w, result.VRuntimes, names)
+	outputPredictions(w, result.Predictions, names)
+	outputDeadlines(w, result)
+}
+
+// outputCPUs prints a Gantt chart for each CPU of a MultiCPU run, the
+// processes in names by name, then their utilization.
+func outputCPUs(w io.Writer, gantt []scheduler.TimeSlice, cpus []scheduler.CPUStats, names map[int64]string) {
+	rows := make([][]scheduler.TimeSlice, len(cpus))
+	for _, s := range gantt {
+		rows[s.CPU] = append(rows[s.CPU], s)
+	}
+	for i, row := range rows {
+		_, _ = fmt.Fprintf(w, "CPU %d ", i)
+		NamedGantt(w, row, names)
+	}
+	CPUTime(w, gantt, cpus)
+}
+
+// CPUTime prints how each CPU spent the run, from 0 to the last
+// completion: busy with processes, switching context if that costs
+// anything, and otherwise idle, before the first arrival included. It
+// prints nothing for a run without a Gantt chart.
+func CPUTime(w io.Writer, gantt []scheduler.TimeSlice, cpus []scheduler.CPUStats) {
+	if len(gantt) == 0 {
+		return
+	}
+	var end int64
+	switching := make([]int64, len(cpus))
+	for _, s := range gantt {
+		if s.PID == scheduler.SwitchPID {
+			switching[s.CPU] += s.Stop - s.Start
+		}
+		if s.Stop > end {
+			end = s.Stop
+		}
+	}
+	switches := false
+	for _, t := range switching {
+		switches = switches || t > 0
+	}
+	_, _ = fmt.Fprintln(w, "CPU utilization")
+	table := tablewriter.NewWriter(w)
+	header := []string{"CPU", "Total", "Busy", "Idle", "Utilization"}
+	if switches {
+		header = []string{"CPU", "Total", "Busy", "Switching", "Idle", "Utilization"}
+	}
+	table.SetHeader(header)
+	for i, c := range cpus {
+		row := []string{fmt.Sprint(c.CPU), fmt.Sprint(end), fmt.Sprint(c.Busy)}
+		if switches {
+			row = append(row, fmt.Sprint(switching[i]))
+		}
+		row = append(row, fmt.Sprint(end-c.Busy-switching[i]), fmt.Sprintf("%.1f%%", 100*c.Utilization))
+		table.Append(row)
+	}
+	table.Render()
+}
+
+// Spread prints how wait and turnaround spread over the processes, which
+// the averages hide, then Jain's fairness index, and nothing for a summary
+// without per-process metrics.
+func Spread(w io.Writer, result scheduler.RunResult) {
+	if result.WaitStats == nil || result.TurnaroundStats == nil {
+		return
+	}
+	_, _ = fmt.Fprintln(w, "Spread")
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Metric", "Min", "Median", "P95", "Max", "Stddev"})
+	for _, row := range []struct {
+		name string
+		d    *scheduler.Distribution
+	}{{"Wait", result.WaitStats}, {"Turnaround", result.TurnaroundStats}} {
+		table.Append([]string{row.name, fmt.Sprint(row.d.Min), fmt.Sprintf("%.1f", row.d.Median), fmt.Sprint(row.d.P95), fmt.Sprint(row.d.Max), fmt.Sprintf("%.2f", row.d.Stddev)})
+	}
+	table.Render()
+	_, _ = fmt.Fprintf(w, "Jain's fairness index: %.3f (1 when every process spends the same share of its time running)\n", result.Fairness)
+}
+
+// outputBoosts prints how often aging boosted each process and the priority
+// it was aged from and to, and nothing if no process was boosted.
+func outputBoosts(w io.Writer, processes []scheduler.ProcessMetrics) {
+	var rows [][]string
+	for _, p := range processes {
+		if p.Boosts > 0 {
+			rows = append(rows, []string{processLabel(p.PID, p.Name), fmt.Sprint(p.Priority), fmt.Sprint(p.Boosts), fmt.Sprint(p.Priority - p.Boosts)})
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintln(w, "Aging boosts")
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"ID", "Priority", "Boosts", "Aged to"})
+	table.AppendBulk(rows)
+	table.Render()
+}
+
+// Quanta prints each process's weight and the quantum it ran with,
+// and nothing if no process was weighed, as they are only under wrr.
+func Quanta(w io.Writer, processes []scheduler.ProcessMetrics) {
+	var rows [][]string
+	for _, p := range processes {
+		if p.Weight > 0 {
+			rows = append(rows, []string{processLabel(p.PID, p.Name), fmt.Sprint(p.Priority), fmt.Sprint(p.Weight), fmt.Sprint(p.Quantum)})
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintln(w, "Weighted quanta")
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"ID", "Priority", "Weight", "Quantum"})
+	table.AppendBulk(rows)
+	table.Render()
+}
+
+// outputQueues prints the queue each process was in, and nothing if none
+// was, as they are only under mlq.
+func outputQueues(w io.Writer, processes []scheduler.ProcessMetrics) {
+	var rows [][]string
+	for _, p := range processes {
+		if p.Queue != "" {
+			rows = append(rows, []string{processLabel(p.PID, p.Name), fmt.Sprint(p.Priority), p.Queue})
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintln(w, "Queues")
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"ID", "Priority", "Queue"})
+	table.AppendBulk(rows)
+	table.Render()
+}
+
+// outputShares prints each process's tickets and how its share of the CPU
+// compares with its share of the tickets, and nothing if no tickets were
+// held, as they are only by proportional-share schedulers.
+func outputShares(w io.Writer, processes []scheduler.ProcessMetrics) {
+	var rows [][]string
+	for _, p := range processes {
+		if p.Tickets > 0 {
+			rows = append(rows, []string{
+				processLabel(p.PID, p.Name),
+				fmt.Sprint(p.Priority),
+				fmt.Sprint(p.Tickets),
+				fmt.Sprintf("%.1f%%", 100*p.TicketShare),
+				fmt.Sprintf("%.1f%%", 100*p.CPUShare),
+			})
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintln(w, "Proportional shares")
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"ID", "Priority", "Tickets", "Ticket share", "CPU share"})
+	table.AppendBulk(rows)
+	table.Render()
+}
+
+// outputNice prints the nice value each process was weighed by and its
+// weight, and nothing if none was, as they are only under the schedulers
+// that weigh by nice value.
+func outputNice(w io.Writer, processes []scheduler.ProcessMetrics) {
+	var rows [][]string
+	for _, p := range processes {
+		if p.Nice != nil {
+			rows = append(rows, []string{processLabel(p.PID, p.Name), fmt.Sprint(p.Priority), fmt.Sprint(*p.Nice), fmt.Sprint(p.NiceWeight)})
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintln(w, "Nice weights")
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"ID", "Priority", "Nice", "Weight"})
+	table.AppendBulk(rows)
+	table.Render()
+}
+
+// outputThrottling prints how long each process of a capped group was
+// throttled, and nothing if none was, as they are only under group caps.
+func outputThrottling(w io.Writer, processes []scheduler.ProcessMetrics) {
+	var rows [][]string
+	for _, p := range processes {
+		if p.Throttled > 0 {
+			rows = append(rows, []string{processLabel(p.PID, p.Name), p.Group, fmt.Sprint(p.Throttled)})
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintln(w, "Group throttling")
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"ID", "Group", "Throttled"})
+	table.AppendBulk(rows)
+	table.Render()
+}
+
+// outputEntitlements prints the CPU time each process was entitled to
+// against what it had, both as shares of its time in the system, and
+// nothing if no process was entitled to any, as they are only under
+// guaranteed.
+func outputEntitlements(w io.Writer, processes []scheduler.ProcessMetrics) {
+	var rows [][]string
+	for _, p := range processes {
+		if p.Entitlement > 0 {
+			rows = append(rows, []string{
+				processLabel(p.PID, p.Name),
+				fmt.Sprintf("%.2f", p.Entitlement),
+				fmt.Sprint(p.Burst),
+				fmt.Sprintf("%.1f%%", 100*p.Entitlement/float64(p.Turnaround)),
+				fmt.Sprintf("%.1f%%", 100*float64(p.Burst)/float64(p.Turnaround)),
+			})
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintln(w, "Guaranteed shares")
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"ID", "Entitled", "CPU", "Entitled share", "Actual share"})
+	table.AppendBulk(rows)
+	table.Render()
+}
+
+// outputVRuntimes prints the virtual runtime of each task cfs dispatched,
+// as it was dispatched, the processes in names by name too, and nothing for
+// other schedulers.
+func outputVRuntimes(w io.Writer, samples []scheduler.VRuntimeSample, names map[int64]string) {
+	if len(samples) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintln(w, "Virtual runtime at dispatch")
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Time", "ID", "Vruntime"})
+	for _, s := range samples {
+		table.Append([]string{fmt.Sprint(s.Time), processLabel(s.PID, names[s.PID]), fmt.Sprintf("%.2f", s.VRuntime)})
+	}
+	table.Render()
+}
+
+// outputPredictions prints each CPU burst sjf-predict predicted against
+// how long it ran, the processes in names by name too, then the mean
+// absolute error, and nothing for other schedulers.
+func outputPredictions(w io.Writer, predictions []scheduler.BurstPrediction, names map[int64]string) {
+	if len(predictions) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintln(w, "Burst predictions")
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"ID", "Burst", "Predicted", "Actual", "Error"})
+	var total float64
+	for _, p := range predictions {
+		miss := p.Predicted - float64(p.Actual)
+		total += math.Abs(miss)
+		table.Append([]string{processLabel(p.PID, names[p.PID]), fmt.Sprint(p.Burst), fmt.Sprintf("%.2f", p.Predicted), fmt.Sprint(p.Actual), fmt.Sprintf("%+.2f", miss)})
+	}
+	table.Render()
+	_, _ = fmt.Fprintf(w, "Mean absolute error: %.2f\n", total/float64(len(predictions)))
+}
+
+// outputDeadlines prints when each process with a deadline completed
+// against it, then how many missed and, for edf, whether the processes are
+// schedulable. It prints nothing if no process has a deadline.
+func outputDeadlines(w io.Writer, result scheduler.RunResult) {
+	var rows [][]string
+	for _, p := range result.Processes {
+		if p.Deadline == 0 {
+			continue
+		}
+		met := "yes"
+		if p.Lateness > 0 {
+			met = "no"
+		}
+		rows = append(rows, []string{processLabel(p.PID, p.Name), fmt.Sprint(p.Deadline), fmt.Sprint(p.Exit), fmt.Sprint(p.Lateness), met})
+	}
+	if len(rows) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintln(w, "Deadlines")
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"ID", "Deadline", "Exit", "Lateness", "Met"})
+	table.AppendBulk(rows)
+	table.Render()
+	_, _ = fmt.Fprintf(w, "%d of %d deadlines missed\n", result.DeadlineMisses, len(rows))
+	if result.Schedulable != nil {
+		if *result.Schedulable {
+			_, _ = fmt.Fprintln(w, "Schedulable: EDF meets every deadline")
+		} else {
+			_, _ = fmt.Fprintln(w, "Not schedulable: even EDF misses a deadline")
+		}
+	}
+}
+
+// WriteLocks charts, under a run's Gantt chart, what every process that
+// blocked on a mutex, held one that was waited on or ran while another was
+// blocked, as a middling process does in a priority inversion, did each
+// tick, rows wrapping at width columns: ran (#), ran on a priority it inherited (^),
+// was blocked on a lock (b) or waited to run (-). The stretches blocked and
+// the priorities inherited follow as tables. It prints nothing for a run in
+// which no process blocked on a lock.
+func WriteLocks(w io.Writer, result scheduler.RunResult, names map[int64]string, width int) {
+	if len(result.LockWaits) == 0 {
+		return
+	}
+	var end int64
+	for _, s := range result.Gantt {
+		if s.Stop > end {
+			end = s.Stop
+		}
+	}
+	lanes := make(map[int64][]byte)
+	for _, l := range result.LockWaits {
+		lanes[l.PID], lanes[l.Holder] = nil, nil
+		stop := l.Stop
+		if stop < 0 {
+			stop = end
+		}
+		for _, s := range result.Gantt {
+			if s.PID >= 0 && s.Start < stop && s.Stop > l.Start {
+				lanes[s.PID] = nil
+			}
+		}
+	}
+	var order []int64
+	label := 0
+	for _, p := range result.Processes {
+		if _, ok := lanes[p.PID]; !ok {
+			continue
+		}
+		lane := []byte(strings.Repeat(" ", int(end)))
+		exit := p.Exit
+		if exit == 0 {
+			exit = end
+		}
+		for t := p.Arrival; t < exit && t < end; t++ {
+			lane[t] = '-'
+		}
+		lanes[p.PID] = lane
+		order = append(order, p.PID)
+		if n := len(processLabel(p.PID, names[p.PID])); n > label {
+			label = n
+		}
+	}
+	for _, l := range result.LockWaits {
+		stop := l.Stop
+		if stop < 0 {
+			stop = end
+		}
+		if lane := lanes[l.PID]; lane != nil {
+			for t := l.Start; t < stop; t++ {
+				lane[t] = 'b'
+			}
+		}
+	}
+	own := make(map[int64]int64, len(result.Processes))
+	for _, p := range result.Processes {
+		own[p.PID] = p.Priority
+	}
+	for _, s := range result.Gantt {
+		lane := lanes[s.PID]
+		if lane == nil {
+			continue
+		}
+		for t := s.Start; t < s.Stop; t++ {
+			lane[t] = '#'
+			if inheritedAt(result.Inheritances, s.PID, t, own[s.PID]) < own[s.PID] {
+				lane[t] = '^'
+			}
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	_, _ = bw.WriteString("Locks: # ran, ^ ran on an inherited priority, b blocked on a lock, - waited\n")
+	span := int64(width - label - 2)
+	if span < 10 {
+		span = 10
+	}
+	for from := int64(0); from < end; from += span {
+		to := from + span
+		if to > end {
+			to = end
+		}
+		// Times label the row's start and every tenth tick that has room.
+		axis := []byte(strings.Repeat(" ", label+1))
+		for t := from; t <= to; t++ {
+			if col := label + 1 + int(t-from); t == from || (t%10 == 0 && col > len(axis)) {
+				axis = append(axis, strings.Repeat(" ", col-len(axis))...)
+				axis = strconv.AppendInt(axis, t, 10)
+			}
+		}
+		_, _ = bw.Write(append(axis, '\n'))
+		for _, pid := range order {
+			_, _ = fmt.Fprintf(bw, "%-*s|%s|\n", label, processLabel(pid, names[pid]), lanes[pid][from:to])
+		}
+		_, _ = bw.WriteString("\n")
+	}
+	_ = bw.Flush()
+
+	_, _ = fmt.Fprintln(w, "Blocked on locks")
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"ID", "Lock", "Held by", "From", "To", "Blocked"})
+	for _, l := range result.LockWaits {
+		to, blocked := "never", "-"
+		if l.Stop >= 0 {
+			to, blocked = fmt.Sprint(l.Stop), fmt.Sprint(l.Stop-l.Start)
+		}
+		table.Append([]string{processLabel(l.PID, names[l.PID]), l.Lock, processLabel(l.Holder, names[l.Holder]), fmt.Sprint(l.Start), to, blocked})
+	}
+	table.Render()
+	if len(result.Inheritances) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintln(w, "Priority inheritance")
+	table = tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Time", "ID", "Lock", "From", "To"})
+	for _, c := range result.Inheritances {
+		table.Append([]string{fmt.Sprint(c.Time), processLabel(c.PID, names[c.PID]), c.Lock, fmt.Sprint(c.From), fmt.Sprint(c.To)})
+	}
+	table.Render()
+}
+
+// inheritedAt is the priority process pid ran at from tick t on, by the
+// last of changes to it at or before t, or own if none was.
+func inheritedAt(changes []scheduler.PriorityChange, pid, t, own int64) int64 {
+	priority := own
+	for _, c := range changes {
+		if c.Time > t {
+			break
+		}
+		if c.PID == pid {
+			priority = c.To
+		}
+	}
+	return priority
+}
+
+// Schedule prints the schedule table: each process's response time,
+// from arrival to first dispatch, then its wait and turnaround, and the
+// averages of all three. Expanded rows go on with the process's runs,
+// preemptions and context switches.
+func Schedule(w io.Writer, rows [][]string, expanded bool, response, wait, turnaround, throughput float64) {
+	_, _ = fmt.Fprintln(w, "Schedule table")
+	table := tablewriter.NewWriter(w)
+	header := []string{"ID", "Priority", "Burst", "Arrival", "Response", "Wait", "Turnaround", "Exit"}
+	footer := []string{"", "", "", "",
+		fmt.Sprintf("Average\n%.2f", response),
+		fmt.Sprintf("Average\n%.2f", wait),
+		fmt.Sprintf("Average\n%.2f", turnaround),
+		fmt.Sprintf("Throughput\n%.2f/t", throughput)}
+	if expanded {
+		header = append(header, "Runs", "Preemptions", "Switches")
+		footer = append(footer, "", "", "")
+	}
+	table.SetHeader(header)
+	table.AppendBulk(rows)
+	table.SetFooter(footer)
+	table.Render()
+}
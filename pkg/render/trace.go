@@ -0,0 +1,73 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Sha-min/CSCE4600/pkg/scheduler"
+)
+
+// traceTick is how many of the trace's microseconds a tick of the schedule
+// takes, so a tick reads as a millisecond in chrome://tracing and Perfetto.
+const traceTick = 1000
+
+type (
+	// chromeTrace is a trace in Chrome's trace_event JSON object format.
+	chromeTrace struct {
+		TraceEvents     []traceEvent `json:"traceEvents"`
+		DisplayTimeUnit string       `json:"displayTimeUnit"`
+	}
+	// traceEvent is one event of a chromeTrace: a complete slice ("X"), with
+	// a start and duration in microseconds, or metadata ("M") naming a
+	// process or thread.
+	traceEvent struct {
+		Name  string                 `json:"name"`
+		Cat   string                 `json:"cat,omitempty"`
+		Phase string                 `json:"ph"`
+		TS    int64                  `json:"ts"`
+		Dur   int64                  `json:"dur,omitempty"`
+		PID   int                    `json:"pid"`
+		TID   int                    `json:"tid"`
+		Args  map[string]interface{} `json:"args,omitempty"`
+	}
+)
+
+// ChromeTrace writes results' Gantt charts in Chrome's trace_event format,
+// to explore in chrome://tracing or Perfetto. Each run is a trace process,
+// named by its RunLabel, with a track per CPU and a slice per dispatch, idle
+// stretch and context switch, a tick to the millisecond. A slice is named by
+// the process that ran, so the viewers' search finds every run of one.
+func ChromeTrace(w io.Writer, results []scheduler.RunResult) error {
+	trace := chromeTrace{TraceEvents: []traceEvent{}, DisplayTimeUnit: "ms"}
+	for i, r := range results {
+		pid := i + 1
+		trace.TraceEvents = append(trace.TraceEvents,
+			traceEvent{Name: "process_name", Phase: "M", PID: pid, Args: map[string]interface{}{"name": scheduler.RunLabel(r)}},
+			traceEvent{Name: "process_sort_index", Phase: "M", PID: pid, Args: map[string]interface{}{"sort_index": i}})
+		cpus := len(r.CPUs)
+		if cpus < 1 {
+			cpus = 1
+		}
+		for cpu := 0; cpu < cpus; cpu++ {
+			trace.TraceEvents = append(trace.TraceEvents,
+				traceEvent{Name: "thread_name", Phase: "M", PID: pid, TID: cpu, Args: map[string]interface{}{"name": fmt.Sprintf("CPU %d", cpu)}})
+		}
+		names := ProcessNames(r.Processes)
+		for _, s := range r.Gantt {
+			e := traceEvent{Phase: "X", TS: s.Start * traceTick, Dur: (s.Stop - s.Start) * traceTick, PID: pid, TID: s.CPU}
+			switch s.PID {
+			case scheduler.IdlePID:
+				e.Name, e.Cat = "idle", "idle"
+			case scheduler.SwitchPID:
+				e.Name, e.Cat = "context switch", "switch"
+			default:
+				e.Name, e.Cat = "PID "+processLabel(s.PID, names[s.PID]), "run"
+				e.Args = map[string]interface{}{"pid": s.PID, "start": s.Start, "stop": s.Stop}
+			}
+			trace.TraceEvents = append(trace.TraceEvents, e)
+		}
+	}
+	enc := json.NewEncoder(w)
+	return enc.Encode(trace)
+}
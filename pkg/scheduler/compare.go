@@ -0,0 +1,79 @@
+package scheduler
+
+import "fmt"
+
+type (
+	// Comparison is schedulers' runs over one workload side by side. Best
+	// names, for each of comparisonMetrics, the schedulers with its best
+	// value, all of them on ties.
+	Comparison struct {
+		Results []RunResult         `json:"results"`
+		Best    map[string][]string `json:"best"`
+	}
+	// ComparisonMetric is one column of a comparison; lower is better
+	// unless higherIsBetter.
+	ComparisonMetric struct {
+		Name           string
+		Title          string
+		Format         string
+		Value          func(RunResult) float64
+		HigherIsBetter bool
+	}
+)
+
+// ComparisonMetrics are the columns of a comparison, in order.
+var ComparisonMetrics = []ComparisonMetric{
+	{Name: "avg_wait", Title: "Avg wait", Format: "%.2f", Value: func(r RunResult) float64 { return r.AvgWait }},
+	{Name: "avg_turnaround", Title: "Avg turnaround", Format: "%.2f", Value: func(r RunResult) float64 { return r.AvgTurnaround }},
+	{Name: "avg_response", Title: "Avg response", Format: "%.2f", Value: func(r RunResult) float64 { return r.AvgResponse }},
+	{Name: "throughput", Title: "Throughput", Format: "%.2f/t", Value: func(r RunResult) float64 { return r.Throughput }, HigherIsBetter: true},
+	{Name: "utilization", Title: "Utilization", Format: "%.1f%%", Value: func(r RunResult) float64 { return 100 * r.Utilization }, HigherIsBetter: true},
+	{Name: "p95_wait", Title: "P95 wait", Format: "%.0f", Value: func(r RunResult) float64 { return r.WaitStats.p95() }},
+	{Name: "wait_stddev", Title: "Wait stddev", Format: "%.2f", Value: func(r RunResult) float64 { return r.WaitStats.stddev() }},
+	{Name: "fairness", Title: "Fairness", Format: "%.3f", Value: func(r RunResult) float64 { return r.Fairness }, HigherIsBetter: true},
+}
+
+// Compare summarizes results, one per scheduler, dropping their Gantt charts,
+// per-process metrics and events.
+func Compare(results []RunResult) Comparison {
+	c := Comparison{Results: make([]RunResult, len(results)), Best: make(map[string][]string, len(ComparisonMetrics))}
+	for i, r := range results {
+		r.Gantt, r.Processes, r.Events = nil, nil, nil
+		c.Results[i] = r
+	}
+	for _, m := range ComparisonMetrics {
+		best := 0
+		for i, r := range c.Results {
+			v, b := m.Value(r), m.Value(c.Results[best])
+			if (m.HigherIsBetter && v > b) || (!m.HigherIsBetter && v < b) {
+				best = i
+			}
+		}
+		for _, r := range c.Results {
+			if m.Value(r) == m.Value(c.Results[best]) {
+				c.Best[m.Name] = append(c.Best[m.Name], RunLabel(r))
+			}
+		}
+	}
+	return c
+}
+
+// IsBest reports whether label has the best value of metric.
+func (c Comparison) IsBest(metric, label string) bool {
+	for _, l := range c.Best[metric] {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+func RunLabel(r RunResult) string {
+	switch {
+	case r.Scheduler == "rr":
+		return fmt.Sprintf("rr (q=%d)", r.Quantum)
+	case r.Inherit:
+		return r.Scheduler + " (inherit)"
+	}
+	return r.Scheduler
+}
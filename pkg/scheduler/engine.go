@@ -0,0 +1,1999 @@
+package scheduler
+
+import (
+	"bufio"
+	"container/heap"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"math/rand"
+	"sort"
+)
+
+//region Simulation engine
+
+const (
+	EventArrive EventKind = iota
+	EventDispatch
+	EventPreempt
+	EventYield
+	EventDonate
+	EventComplete
+	EventThrottle
+	EventUnthrottle
+	EventBlock
+	EventWake
+)
+
+// Semaphore and mutex operations a process can perform part-way through its burst.
+const (
+	SemWait     = "P"
+	SemSignal   = "V"
+	MutexLock   = "lock"
+	MutexUnlock = "unlock"
+)
+
+// lotteryTicketPool is the tickets a priority 0 process holds in a lottery
+// or stride schedule.
+const lotteryTicketPool = 100
+
+// cfsNice0Weight is the weight of a nice 0 task under cfs, the one whose
+// virtual runtime advances at the rate of real time.
+const cfsNice0Weight = 1024
+
+// cfsWeights are the cfs weights of nice -20 to 19, Linux's, each step about
+// 1.25 times the next, so one nice step moves about 10% of the CPU.
+var cfsWeights = NiceWeights{
+	88761, 71755, 56483, 46273, 36291,
+	29154, 23254, 18705, 14949, 11916,
+	9548, 7620, 6100, 4904, 3906,
+	3121, 2501, 1991, 1586, 1277,
+	1024, 820, 655, 526, 423,
+	335, 272, 215, 172, 137,
+	110, 87, 70, 56, 45,
+	36, 29, 23, 18, 15,
+}
+
+// strideOne is the stride of a task holding one ticket; a task with n tickets
+// has stride strideOne/n.
+const strideOne = 1 << 20
+
+const (
+	// CarryDiscard drops whatever is left of the quantum when a task yields or blocks (classic RR).
+	CarryDiscard CarryPolicy = iota
+	// CarryNext adds the unused part of the quantum to the task's next dispatch only.
+	CarryNext
+	// CarryBank accumulates unused slice time across yields, up to Engine.BankCap.
+	CarryBank
+)
+
+const (
+	// WakeFIFO wakes the longest-blocked waiter, giving a strong semaphore.
+	WakeFIFO WakeupPolicy = iota
+	// WakeLIFO wakes the most recently blocked waiter, a weak semaphore that can starve.
+	WakeLIFO
+	// WakePriority wakes the waiter with the lowest Priority value, longest-blocked first on ties.
+	WakePriority
+)
+
+const (
+	// TieFIFO takes whichever tied task joined the queue first, with tasks
+	// arriving together joining in workload order.
+	TieFIFO TieBreak = iota
+	// TieArrival takes the tied task that arrived first, then the lowest PID,
+	// and has tasks arriving together join by PID, as textbook answers do.
+	TieArrival
+	// TiePID takes the tied task with the lowest PID, and has tasks arriving
+	// together join by PID.
+	TiePID
+)
+
+type (
+	// Task is the mutable state the engine keeps for one process during a run,
+	// so the caller's Process values are never modified.
+	Task struct {
+		*Process
+		Remaining    int64
+		Used         int64
+		FirstRun     int64
+		Exit         int64
+		Blocked      int64
+		LongestBlock int64
+		Throttled    int64
+		Boosts       int64
+		Draws        int64
+		Wins         int64
+		odds         float64
+		drawMark     int64
+		oddsMark     float64
+		pass         int64
+		passSet      bool
+		vruntime     float64
+		charged      int64
+		fair         bool
+		nextYield    int
+		NextOp       int
+		nextIO       int
+		ioDone       int64
+		credit       int64
+		blockedAt    int64
+		throttledAt  int64
+		queuedAt     int64
+		queueIndex   int
+		queueSeq     int64
+		agingKey     int64
+		predicted    float64
+		burstMark    int64
+		burstSample  int
+		entitledFrom float64
+		present      bool
+		inherited    int64
+		inheriting   bool
+		waitingOn    *mutex
+		holds        []*mutex
+		lockWait     int
+		weights      *NiceWeights
+		order        PriorityOrder
+	}
+	// SyncOp is an operation on a synchronization object that a process performs
+	// once it has had At units of CPU. A process's ops must be sorted by At.
+	SyncOp struct {
+		At     int64
+		Op     string
+		Object string
+	}
+	// SyncObject is anything a task can block on. Do applies op for t and reports
+	// whether t may carry on, along with any blocked tasks the op released.
+	// Semaphores are the built-in kind; simulations can supply their own.
+	SyncObject interface {
+		Do(t *Task, op string) (proceed bool, woken []*Task)
+	}
+	EventKind    int
+	CarryPolicy  int
+	WakeupPolicy int
+	// TieBreak decides between ready tasks a queue finds equal, such as two
+	// with the same burst under sjf, and the order in which tasks arriving
+	// at the same time join the queue. Ties never preempt the running task.
+	TieBreak int
+	Event    struct {
+		Time int64     `json:"time"`
+		Kind EventKind `json:"kind"`
+		PID  int64     `json:"pid"`
+		Note string    `json:"note,omitempty"`
+	}
+	// ReadyQueue holds the tasks waiting for the CPU. The order in which Pop
+	// hands them out is the scheduling policy.
+	ReadyQueue interface {
+		Push(t *Task)
+		Pop() *Task
+		Remove(t *Task) bool
+		Len() int
+	}
+	// Engine is a discrete-event simulator for a single CPU. Time jumps straight
+	// to the next arrival, completion, yield point or quantum expiry.
+	// • Queue orders the ready tasks
+	// • Quantum bounds each dispatch; 0 lets a task run until it yields or completes
+	//   unless Queue sets each dispatch's slice itself, as cfs does
+	// • Preempt, if set, is asked whether an arriving task should take the CPU
+	// • Carry and BankCap decide what happens to a quantum a task yields, or blocks on I/O or a
+	//   sync object, before using up
+	// • Caps limits a Process.Group to a percentage of every CapPeriod, whatever the queue says
+	// • Semaphores gives the initial value of semaphores named in Process.Ops
+	// • Wakeup picks which blocked task a V or unlock releases
+	// • Objects adds custom synchronization objects; other names are mutexes when
+	//   locked and otherwise semaphores starting at 0
+	// • OnEvent, if set, sees every event as the simulation logs it
+	// • CompactGantt merges a dispatch into the previous Gantt slice when the
+	//   same task carries straight on, e.g. alone in an RR queue
+	// • DropEvents leaves Trace.Events empty; OnEvent still sees every event
+	// • GanttSpill, if set, is sent each Gantt slice as a pid,start,stop CSV
+	//   line once it is finished, and Trace.Gantt stays empty, so long runs keep
+	//   constant memory; ReadGanttSpill reads the slices back
+	// • SwitchCost is the time every dispatch spends switching context first,
+	//   as a SwitchPID slice, unless the task that just ran carries straight on
+	// • TieBreak orders simultaneous arrivals and, in queues that compare
+	//   tasks by a key such as sjf's, tasks with equal keys
+	// • AssumeSorted takes the workload to be in arrival order already and
+	//   skips sorting it, unless TieBreak must reorder simultaneous arrivals;
+	//   a task listed after a later arrival is then admitted only once that
+	//   one has been, so CheckArrivalOrder should vouch for the workload
+	// • Inherit has a task holding a mutex run at the best priority of the
+	//   tasks blocked on it, and of those blocked on mutexes they hold, until
+	//   it unlocks; a mutex then goes to its best-ranked waiter, which may
+	//   preempt the task that unlocked it as an arrival would
+	// • NiceWeights overrides Linux's weights of the nice values it maps,
+	//   for the queues that weigh tasks by nice value
+	// • PriorityOrder says which way Priority runs where those queues read
+	//   a task's weight from it, lacking a nice value
+	Engine struct {
+		Queue         ReadyQueue
+		Quantum       int64
+		Preempt       func(running, arrived *Task) bool
+		Carry         CarryPolicy
+		BankCap       int64
+		Caps          map[string]int64
+		CapPeriod     int64
+		Semaphores    map[string]int64
+		Wakeup        WakeupPolicy
+		Objects       map[string]SyncObject
+		OnEvent       func(Event)
+		DropEvents    bool
+		CompactGantt  bool
+		GanttSpill    io.Writer
+		SwitchCost    int64
+		TieBreak      TieBreak
+		AssumeSorted  bool
+		Inherit       bool
+		NiceWeights   map[int64]int64
+		PriorityOrder PriorityOrder
+	}
+	// Trace is everything an engine run produced. Blocked lists the tasks still
+	// waiting on a sync object when nothing else could run, i.e. a deadlock.
+	// Switches counts the dispatches that switched context, every one but
+	// those of a task carrying straight on, whether or not they cost anything.
+	// LockWaits are the stretches tasks spent blocked on mutexes, in the order
+	// they blocked, and Inheritances every priority a task inherited or gave
+	// back under Engine.Inherit.
+	Trace struct {
+		Tasks        []*Task
+		Gantt        []TimeSlice
+		Events       []Event
+		Blocked      []*Task
+		Switches     int64
+		LockWaits    []LockWait
+		Inheritances []PriorityChange
+
+		onEvent    func(Event)
+		dropEvents bool
+	}
+	// FIFOQueue hands tasks out in push order. Remove leaves a nil tombstone
+	// that Pop skips, and the dead slots are compacted away once they are
+	// most of the slice, so every operation is amortized O(1).
+	FIFOQueue struct {
+		tasks []*Task
+		head  int
+		live  int
+	}
+	// heapQueue pops the task that sorts first under less, equal keys as tie
+	// breaks them. Tasks keep their heap index, so Push, Pop and Remove are
+	// all O(log n).
+	heapQueue struct {
+		tasks  []*Task
+		less   func(a, b *Task) bool
+		pushed int64
+		tie    TieBreak
+	}
+	// taskHeap is heapQueue seen as a heap.Interface.
+	taskHeap heapQueue
+	// ioWait is a heap of the tasks doing I/O, the first done on top.
+	ioWait []*Task
+	// agingQueue is a priority queue where every waiting task gains a boost,
+	// one step of priority, each time the clock passes a multiple of rate,
+	// until it reaches floor, 0 unless an AgingPolicy caps it elsewhere. All
+	// waiting tasks age together toward the same floor, so a task's
+	// effective priority is its agingKey less the boosts since time 0 (or
+	// floor if that is lower) and the heap order never changes. A task
+	// already at the floor or past it never ages, and keys on its priority
+	// alone, which puts it ahead of every task still aging.
+	agingQueue struct {
+		heapQueue
+		rate  int64
+		floor int64
+		now   int64
+	}
+	// drawLedger counts the draws a queue holds toward the tasks waiting in
+	// it, who all enter every one, in O(1) a draw: a task marks where the
+	// running totals stood when it joined and settles up when it leaves.
+	// total is the tickets waiting and perTicket the sum over the draws of
+	// one ticket's chance in each.
+	drawLedger struct {
+		total     int64
+		draws     int64
+		perTicket float64
+	}
+	// lotteryQueue pops a task drawn at random, each waiting task's chance
+	// its tickets over all the tickets waiting. Tasks hold slots in push
+	// order, nil once they leave, and tree is a Fenwick tree of the slots'
+	// tickets, so a draw finds its winner in O(log n). The slots are
+	// compacted once they are mostly empty.
+	lotteryQueue struct {
+		drawLedger
+		slots []*Task
+		tree  []int64
+		live  int
+		rng   *rand.Rand
+		seed  int64
+	}
+	// strideQueue pops the task with the lowest pass, equal passes in push
+	// order, and advances its pass by its stride, so each task's turns
+	// follow its tickets exactly. A task's first pass is that of the last
+	// task popped, so a newcomer cannot monopolize the CPU catching up.
+	strideQueue struct {
+		heapQueue
+		drawLedger
+		pass int64
+	}
+	// cfsQueue is a completely fair queue: it pops the task with the least
+	// virtual runtime, the CPU time it has used scaled down by its weight,
+	// so heavier tasks accrue it more slowly and run for longer. Each
+	// dispatch's slice is the target latency shared out by weight among the
+	// runnable tasks, at least one tick. A task joining the queue, for the
+	// first time or back from I/O, starts no lower than min, the virtual
+	// runtime last dispatched, so it cannot monopolize the CPU catching up.
+	// weight is the queued tasks' total and samples every task's virtual
+	// runtime when it was dispatched, unless dropSamples.
+	cfsQueue struct {
+		heapQueue
+		latency     int64
+		min         float64
+		weight      int64
+		samples     []VRuntimeSample
+		dropSamples bool
+	}
+	// edfQueue pops the task with the earliest deadline, tasks without one
+	// after all those with, equal deadlines in push order.
+	edfQueue struct {
+		heapQueue
+	}
+	// hrrnQueue pops the task with the highest response ratio, the time it
+	// has waited in the queue plus its next CPU burst over that burst, equal
+	// ratios in push order. Ratios change as the clock runs, but among tasks
+	// with the same burst the longest waiting is always highest, so tasks
+	// queue by burst in first-in, first-out groups and Pop only compares the
+	// head of each group.
+	hrrnQueue struct {
+		now    int64
+		groups map[int64]*FIFOQueue
+		bursts []int64
+		live   int
+		seq    int64
+	}
+	// predictQueue is sjf for when bursts are not known ahead: it pops the
+	// task whose next CPU burst is predicted shortest, equal predictions in
+	// push order. A task's first burst is predicted to be DefaultBurstGuess,
+	// and each later one by exponential averaging, τ = α·t + (1−α)·τ, where
+	// t is the burst just ended, measured from the task's last dispatch to
+	// its return to the queue. samples holds each dispatch's prediction and,
+	// once the burst has ended, its actual length, unless dropSamples.
+	predictQueue struct {
+		heapQueue
+		alpha       float64
+		samples     []BurstPrediction
+		dropSamples bool
+	}
+	// BurstPrediction is the length sjf-predict predicted for a CPU burst,
+	// the Burst'th of process PID, against how long it actually ran.
+	BurstPrediction struct {
+		PID       int64   `json:"pid"`
+		Burst     int     `json:"burst"`
+		Predicted float64 `json:"predicted"`
+		Actual    int64   `json:"actual"`
+		task      *Task
+	}
+	// guaranteedQueue is guaranteed, or fair-share, scheduling: every task
+	// in the system, whether ready, running or blocked, is entitled to an
+	// equal share of the CPU while it is there, and Pop hands out the task
+	// most under-served, the one whose CPU used over its entitlement so far
+	// is lowest, equal ratios as tie breaks them. entitled is the CPU time
+	// each task in the system has been entitled to since time 0, which
+	// grows by 1/n a tick with n tasks present; a task's entitlement is what
+	// it has grown by since it arrived, until it completes. Ratios change
+	// as the clock runs, so Pop scans every waiting task.
+	guaranteedQueue struct {
+		FIFOQueue
+		now      int64
+		entitled float64
+		present  []*Task
+		shares   map[int64]float64
+		seq      int64
+		tie      TieBreak
+	}
+	// wrrQueue is round-robin with a quantum per task: the base quantum
+	// times the weight its Priority maps to, 1 if it maps to none.
+	wrrQueue struct {
+		FIFOQueue
+		quantum int64
+		weights map[int64]int64
+	}
+	// VRuntimeSample is a task's virtual runtime when cfs dispatched it.
+	VRuntimeSample struct {
+		Time     int64   `json:"time"`
+		PID      int64   `json:"pid"`
+		VRuntime float64 `json:"vruntime"`
+	}
+	// slicedQueue is a ReadyQueue that sets the length of each dispatch
+	// itself rather than taking the engine's Quantum. The engine asks slice
+	// for the slice of t, just popped, when it dispatches it at now.
+	slicedQueue interface {
+		ReadyQueue
+		slice(t *Task, now int64) int64
+	}
+	// clockedQueue is a ReadyQueue whose best task can come to outrank the
+	// running one as time passes, not just when tasks arrive. The engine keeps
+	// it told the time and, when it preempts, asks preemptAt when that will
+	// be, -1 meaning never, so it can stop there and no sooner.
+	clockedQueue interface {
+		ReadyQueue
+		setTime(now int64)
+		preemptAt(running *Task) int64
+	}
+	// tieBrokenQueue is a ReadyQueue that compares tasks by a key, told
+	// before a run how to order tasks whose keys are equal.
+	tieBrokenQueue interface {
+		ReadyQueue
+		breakTiesBy(tb TieBreak)
+	}
+	// cpuGroup is the bandwidth accounting for one capped group in the current period.
+	cpuGroup struct {
+		quota     int64
+		used      int64
+		throttled []*Task
+	}
+	semaphore struct {
+		value   int64
+		waiters []*Task
+		wakeup  WakeupPolicy
+	}
+	// mutex is a lock with an owner: only the task holding it may unlock it,
+	// and unlocking hands it straight to a waiter.
+	mutex struct {
+		name    string
+		owner   *Task
+		waiters []*Task
+		wakeup  WakeupPolicy
+	}
+	// LockWait is a stretch process PID spent blocked on mutex Lock, from
+	// Start until Stop, or -1 if it never got it, while Holder held it.
+	LockWait struct {
+		PID    int64  `json:"pid"`
+		Lock   string `json:"lock"`
+		Holder int64  `json:"holder"`
+		Start  int64  `json:"start"`
+		Stop   int64  `json:"stop"`
+	}
+	// PriorityChange is process PID's priority changing at Time under
+	// priority inheritance, From one To another: raised to that of a task
+	// blocked on Lock, which it or a task it waits on holds, or given back
+	// when it unlocked Lock.
+	PriorityChange struct {
+		Time int64  `json:"time"`
+		PID  int64  `json:"pid"`
+		From int64  `json:"from"`
+		To   int64  `json:"to"`
+		Lock string `json:"lock"`
+	}
+)
+
+func (c CarryPolicy) String() string {
+	switch c {
+	case CarryDiscard:
+		return "discard"
+	case CarryNext:
+		return "carry"
+	case CarryBank:
+		return "bank"
+	default:
+		return fmt.Sprintf("CarryPolicy(%d)", int(c))
+	}
+}
+
+// ParseCarryPolicy accepts the names printed by CarryPolicy.String.
+func ParseCarryPolicy(s string) (CarryPolicy, error) {
+	for _, c := range []CarryPolicy{CarryDiscard, CarryNext, CarryBank} {
+		if c.String() == s {
+			return c, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unknown carry policy %q, want discard, carry or bank", ErrInvalidArgs, s)
+}
+
+func (c CarryPolicy) MarshalText() ([]byte, error) { return []byte(c.String()), nil }
+
+func (c *CarryPolicy) UnmarshalText(text []byte) error {
+	var err error
+	*c, err = ParseCarryPolicy(string(text))
+	return err
+}
+
+func (p WakeupPolicy) String() string {
+	switch p {
+	case WakeFIFO:
+		return "fifo"
+	case WakeLIFO:
+		return "lifo"
+	case WakePriority:
+		return "priority"
+	default:
+		return fmt.Sprintf("WakeupPolicy(%d)", int(p))
+	}
+}
+
+// ParseWakeupPolicy accepts the names printed by WakeupPolicy.String.
+func ParseWakeupPolicy(s string) (WakeupPolicy, error) {
+	for _, p := range []WakeupPolicy{WakeFIFO, WakeLIFO, WakePriority} {
+		if p.String() == s {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unknown wakeup policy %q", ErrInvalidArgs, s)
+}
+
+func (tb TieBreak) String() string {
+	switch tb {
+	case TieFIFO:
+		return "fifo"
+	case TieArrival:
+		return "arrival"
+	case TiePID:
+		return "pid"
+	default:
+		return fmt.Sprintf("TieBreak(%d)", int(tb))
+	}
+}
+
+// ParseTieBreak accepts the names printed by TieBreak.String.
+func ParseTieBreak(s string) (TieBreak, error) {
+	for _, tb := range []TieBreak{TieFIFO, TieArrival, TiePID} {
+		if tb.String() == s {
+			return tb, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unknown tie-break rule %q, want fifo, arrival or pid", ErrInvalidArgs, s)
+}
+
+func (tb TieBreak) MarshalText() ([]byte, error) { return []byte(tb.String()), nil }
+
+func (tb *TieBreak) UnmarshalText(text []byte) error {
+	var err error
+	*tb, err = ParseTieBreak(string(text))
+	return err
+}
+
+// first reports whether a goes ahead of b, two tasks a queue finds equal.
+func (tb TieBreak) first(a, b *Task) bool {
+	if tb == TieArrival && a.ArrivalTime != b.ArrivalTime {
+		return a.ArrivalTime < b.ArrivalTime
+	}
+	if tb != TieFIFO && a.ProcessID != b.ProcessID {
+		return a.ProcessID < b.ProcessID
+	}
+	return a.queueSeq < b.queueSeq
+}
+
+// sortArrivals orders pending tasks by arrival time, tasks arriving together
+// in workload order under TieFIFO and by PID otherwise. A workload assumed
+// sorted is left as it is unless simultaneous arrivals go by PID.
+func (tb TieBreak) sortArrivals(pending []*Task, assumeSorted bool) {
+	if assumeSorted && tb == TieFIFO {
+		return
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		a, b := pending[i], pending[j]
+		if a.ArrivalTime != b.ArrivalTime || tb == TieFIFO {
+			return a.ArrivalTime < b.ArrivalTime
+		}
+		return a.ProcessID < b.ProcessID
+	})
+}
+
+func (k EventKind) String() string {
+	switch k {
+	case EventArrive:
+		return "arrive"
+	case EventDispatch:
+		return "dispatch"
+	case EventPreempt:
+		return "preempt"
+	case EventYield:
+		return "yield"
+	case EventDonate:
+		return "donate"
+	case EventComplete:
+		return "complete"
+	case EventThrottle:
+		return "throttle"
+	case EventUnthrottle:
+		return "unthrottle"
+	case EventBlock:
+		return "block"
+	case EventWake:
+		return "wake"
+	default:
+		return fmt.Sprintf("EventKind(%d)", int(k))
+	}
+}
+
+// Simulate runs processes through the engine and returns the resulting trace.
+func (e *Engine) Simulate(processes []Process) Trace {
+	var (
+		tr      = Trace{Tasks: make([]*Task, len(processes)), onEvent: e.OnEvent, dropEvents: e.DropEvents}
+		tasks   = make([]Task, len(processes))
+		pending = make([]*Task, len(processes))
+		byPID   map[int64]*Task
+		now     int64
+		done    int
+		running *Task
+		budget  int64
+		period  = e.CapPeriod
+		groups  = make(map[string]*cpuGroup, len(e.Caps))
+		refill  int64
+		objects = make(map[string]SyncObject, len(e.Semaphores)+len(e.Objects))
+		blocked = make(map[*Task]bool)
+	)
+	clocked, _ := e.Queue.(clockedQueue)
+	sliced, _ := e.Queue.(slicedQueue)
+	// timed is whether dispatches have a slice to use up.
+	timed := e.Quantum > 0 || sliced != nil
+	var inIO ioWait
+	for name, value := range e.Semaphores {
+		objects[name] = &semaphore{value: value, wakeup: e.Wakeup}
+	}
+	for name, obj := range e.Objects {
+		objects[name] = obj
+	}
+	if period <= 0 {
+		period = DefaultCapPeriod
+	}
+	for name, pct := range e.Caps {
+		// Round tiny caps up to one unit so a capped group can always make progress.
+		quota := period * pct / 100
+		if quota < 1 {
+			quota = 1
+		}
+		groups[name] = &cpuGroup{quota: quota}
+	}
+	for i := range processes {
+		tasks[i] = Task{Process: &processes[i], Remaining: processes[i].BurstDuration, FirstRun: -1}
+		tr.Tasks[i] = &tasks[i]
+	}
+	weighByNice(tasks, niceTable(e.NiceWeights), e.PriorityOrder)
+	// Only donation looks tasks up by PID, so most workloads skip the map.
+	for i := range processes {
+		if processes[i].DonateTo != 0 {
+			byPID = make(map[int64]*Task, len(processes))
+			for _, t := range tr.Tasks {
+				byPID[t.ProcessID] = t
+			}
+			break
+		}
+	}
+	copy(pending, tr.Tasks)
+	e.TieBreak.sortArrivals(pending, e.AssumeSorted)
+	if q, ok := e.Queue.(tieBrokenQueue); ok {
+		q.breakTiesBy(e.TieBreak)
+	}
+
+	// admit queues the tasks that have arrived or finished their I/O by now,
+	// in the order they did so.
+	admit := func() []*Task {
+		var arrived []*Task
+		for {
+			if len(inIO) > 0 && inIO[0].ioDone <= now && (len(pending) == 0 || inIO[0].ioDone < pending[0].ArrivalTime) {
+				t := inIO.pop()
+				e.Queue.Push(t)
+				tr.log(now, EventWake, t.ProcessID, "io")
+				arrived = append(arrived, t)
+				continue
+			}
+			if len(pending) == 0 || pending[0].ArrivalTime > now {
+				return arrived
+			}
+			arrived = append(arrived, pending[0])
+			e.Queue.Push(pending[0])
+			tr.log(now, EventArrive, pending[0].ProcessID, "")
+			pending = pending[1:]
+		}
+	}
+	// dispatch gives t the CPU, after a context switch unless t just had it,
+	// and reports whether it switched, which moves the clock on.
+	dispatch := func(t *Task, slice int64) bool {
+		running, budget = t, slice
+		n := len(tr.Gantt)
+		carriesOn := n > 0 && tr.Gantt[n-1].PID == t.ProcessID && tr.Gantt[n-1].Stop == now
+		if !carriesOn {
+			tr.Switches++
+		}
+		switched := e.SwitchCost > 0 && !carriesOn
+		if switched {
+			e.spill(&tr)
+			tr.Gantt = append(tr.Gantt, TimeSlice{PID: SwitchPID, Start: now, Stop: now + e.SwitchCost})
+			now += e.SwitchCost
+		}
+		if t.FirstRun < 0 {
+			t.FirstRun = now
+		}
+		tr.log(now, EventDispatch, t.ProcessID, "")
+		if e.CompactGantt && carriesOn {
+			return switched
+		}
+		e.spill(&tr)
+		tr.Gantt = append(tr.Gantt, TimeSlice{PID: t.ProcessID, Start: now, Stop: now})
+		return switched
+	}
+
+	// preempt puts the running task back in the queue. If it had not run
+	// yet, as when a task arrived during its context switch, its empty slice
+	// goes and it has still to make its first run.
+	preempt := func(note string) {
+		if last := tr.Gantt[len(tr.Gantt)-1]; last.Start == last.Stop {
+			tr.Gantt = tr.Gantt[:len(tr.Gantt)-1]
+			if running.FirstRun == now && running.Used == 0 {
+				running.FirstRun = -1
+			}
+		}
+		e.Queue.Push(running)
+		tr.log(now, EventPreempt, running.ProcessID, note)
+		running = nil
+	}
+
+	// idle records the CPU sitting idle from start until now.
+	idle := func(start int64) {
+		if start >= now {
+			return
+		}
+		if n := len(tr.Gantt); n > 0 && tr.Gantt[n-1].PID == IdlePID && tr.Gantt[n-1].Stop == start {
+			tr.Gantt[n-1].Stop = now
+			return
+		}
+		e.spill(&tr)
+		tr.Gantt = append(tr.Gantt, TimeSlice{PID: IdlePID, Start: start, Stop: now})
+	}
+
+	// next pops the first ready task whose group still has quota, setting throttled ones aside.
+	next := func() *Task {
+		for e.Queue.Len() > 0 {
+			t := e.Queue.Pop()
+			g := groups[t.Group]
+			if g == nil || g.used < g.quota {
+				return t
+			}
+			g.throttled = append(g.throttled, t)
+			t.throttledAt = now
+			tr.log(now, EventThrottle, t.ProcessID, t.Group)
+		}
+		return nil
+	}
+
+	// sync performs the running task's sync operations that are due at its
+	// current progress and reports whether it blocked on one of them.
+	sync := func() bool {
+		t := running
+		for t.NextOp < len(t.Ops) && t.Ops[t.NextOp].At <= t.Used {
+			op := t.Ops[t.NextOp]
+			t.NextOp++
+			obj := objects[op.Object]
+			if obj == nil {
+				if op.Op == MutexLock || op.Op == MutexUnlock {
+					m := &mutex{name: op.Object, wakeup: e.Wakeup}
+					if e.Inherit {
+						m.wakeup = WakePriority
+					}
+					obj = m
+				} else {
+					obj = &semaphore{wakeup: e.Wakeup}
+				}
+				objects[op.Object] = obj
+			}
+			holder := ownerOf(obj)
+			proceed, woken := obj.Do(t, op.Op)
+			m, locked := obj.(*mutex)
+			if locked && e.Inherit {
+				e.inherit(&tr, now, t, m, op.Op, proceed, woken)
+			}
+			var preemptor *Task
+			for _, w := range woken {
+				w.Blocked += now - w.blockedAt
+				if now-w.blockedAt > w.LongestBlock {
+					w.LongestBlock = now - w.blockedAt
+				}
+				if locked {
+					tr.LockWaits[w.lockWait].Stop = now
+				}
+				delete(blocked, w)
+				e.Queue.Push(w)
+				tr.log(now, EventWake, w.ProcessID, op.Object)
+				if e.Inherit && locked && e.Preempt != nil && t.Remaining > 0 && e.Preempt(t, w) {
+					preemptor = w
+				}
+			}
+			if !proceed {
+				if locked {
+					t.lockWait = len(tr.LockWaits)
+					tr.LockWaits = append(tr.LockWaits, LockWait{PID: t.ProcessID, Lock: op.Object, Holder: holder, Start: now, Stop: -1})
+				}
+				blocked[t] = true
+				t.blockedAt = now
+				if timed {
+					t.credit = e.carry(budget)
+				}
+				tr.log(now, EventBlock, t.ProcessID, op.Object)
+				if last := tr.Gantt[len(tr.Gantt)-1]; last.Start == last.Stop {
+					tr.Gantt = tr.Gantt[:len(tr.Gantt)-1]
+				}
+				running = nil
+				return true
+			}
+			if preemptor != nil {
+				preempt(fmt.Sprintf("by %d", preemptor.ProcessID))
+				return true
+			}
+		}
+		return false
+	}
+
+	for done < len(tr.Tasks) {
+		if clocked != nil {
+			clocked.setTime(now)
+		}
+		if len(groups) > 0 && now >= refill {
+			refill = (now/period + 1) * period
+			for _, name := range sortedGroupNames(groups) {
+				g := groups[name]
+				g.used = 0
+				for _, t := range g.throttled {
+					t.Throttled += now - t.throttledAt
+					e.Queue.Push(t)
+					tr.log(now, EventUnthrottle, t.ProcessID, name)
+				}
+				g.throttled = nil
+			}
+		}
+		for _, t := range admit() {
+			if running != nil && e.Preempt != nil && e.Preempt(running, t) {
+				preempt(fmt.Sprintf("by %d", t.ProcessID))
+			}
+		}
+		if running != nil && e.Preempt != nil && clocked != nil && clocked.preemptAt(running) == now {
+			preempt("aged task waiting")
+		}
+		if running == nil {
+			t := next()
+			if t == nil {
+				idleFrom := now
+				if now = e.idleUntil(pending, inIO, groups, refill); now < 0 {
+					tr.Blocked = blockedTasks(tr.Tasks, blocked)
+					break
+				}
+				idle(idleFrom)
+				continue
+			}
+			slice := e.Quantum
+			if sliced != nil {
+				slice = sliced.slice(t, now)
+			}
+			// Levels without a quantum, such as mlq's fcfs one, slice
+			// MaxInt64, which credit must not overflow.
+			if slice > math.MaxInt64-t.credit {
+				slice = math.MaxInt64
+			} else {
+				slice += t.credit
+			}
+			switched := dispatch(t, slice)
+			t.credit = 0
+			if switched {
+				// Arrivals during the switch may yet preempt t.
+				continue
+			}
+		}
+		if sync() {
+			continue
+		}
+		group := groups[running.Group]
+
+		run := running.Remaining
+		if timed && budget < run {
+			run = budget
+		}
+		if y := running.untilYield(); y > 0 && y < run {
+			run = y
+		}
+		if running.NextOp < len(running.Ops) && running.Ops[running.NextOp].At-running.Used < run {
+			run = running.Ops[running.NextOp].At - running.Used
+		}
+		if u := running.untilIO(); u >= 0 && u < run {
+			run = u
+		}
+		if e.Preempt != nil && len(pending) > 0 && pending[0].ArrivalTime-now < run {
+			run = pending[0].ArrivalTime - now
+		}
+		if e.Preempt != nil && len(inIO) > 0 && inIO[0].ioDone-now < run {
+			run = inIO[0].ioDone - now
+		}
+		if e.Preempt != nil && clocked != nil {
+			if at := clocked.preemptAt(running); at >= 0 && at-now < run {
+				run = at - now
+			}
+		}
+		if group != nil {
+			if left := group.quota - group.used; left < run {
+				run = left
+			}
+			if refill-now < run {
+				run = refill - now
+			}
+		}
+		now += run
+		if clocked != nil {
+			clocked.setTime(now)
+		}
+		running.Remaining -= run
+		running.Used += run
+		budget -= run
+		if group != nil {
+			group.used += run
+		}
+		tr.Gantt[len(tr.Gantt)-1].Stop = now
+		if sync() {
+			continue
+		}
+
+		switch {
+		case running.Remaining == 0:
+			running.Exit = now
+			tr.log(now, EventComplete, running.ProcessID, "")
+			running = nil
+			done++
+		case running.untilIO() == 0:
+			// A sync op at the same point may have left an empty slice.
+			if last := tr.Gantt[len(tr.Gantt)-1]; last.Start == last.Stop {
+				tr.Gantt = tr.Gantt[:len(tr.Gantt)-1]
+			}
+			if timed {
+				running.credit = e.carry(budget)
+			}
+			d := inIO.push(running, now)
+			tr.log(now, EventBlock, running.ProcessID, fmt.Sprintf("io %d", d))
+			running = nil
+		case running.untilYield() == 0:
+			// As before I/O, a sync op at the same point, or a preemption
+			// due the moment the task was dispatched, may have left an
+			// empty slice.
+			if last := tr.Gantt[len(tr.Gantt)-1]; last.Start == last.Stop {
+				tr.Gantt = tr.Gantt[:len(tr.Gantt)-1]
+			}
+			running.nextYield++
+			admit()
+			yielder := running
+			running = nil
+			donee := byPID[yielder.DonateTo]
+			// A donee whose group has used up its share waits for the
+			// refill like any other, and gets no more of the slice than
+			// its group has left.
+			var capped *cpuGroup
+			if donee != nil && now < refill {
+				capped = groups[donee.Group]
+			}
+			if capped != nil && capped.used == capped.quota {
+				donee = nil
+			}
+			if donee != nil && (!timed || budget > 0) && e.Queue.Remove(donee) {
+				tr.log(now, EventYield, yielder.ProcessID, "")
+				tr.log(now, EventDonate, yielder.ProcessID, fmt.Sprintf("to %d", donee.ProcessID))
+				if capped != nil && timed && capped.quota-capped.used < budget {
+					budget = capped.quota - capped.used
+				}
+				dispatch(donee, budget)
+			} else if timed {
+				yielder.credit = e.carry(budget)
+				tr.log(now, EventYield, yielder.ProcessID, fmt.Sprintf("carries %d", yielder.credit))
+			} else {
+				tr.log(now, EventYield, yielder.ProcessID, "")
+			}
+			e.Queue.Push(yielder)
+		case timed && budget == 0:
+			admit()
+			e.Queue.Push(running)
+			tr.log(now, EventPreempt, running.ProcessID, "quantum expired")
+			running = nil
+		case group != nil && group.used == group.quota && now < refill:
+			group.throttled = append(group.throttled, running)
+			running.throttledAt = now
+			tr.log(now, EventThrottle, running.ProcessID, running.Group)
+			running = nil
+		}
+	}
+	e.spill(&tr)
+
+	return tr
+}
+
+// spill sends the slices in tr.Gantt, all finished, to GanttSpill.
+func (e *Engine) spill(tr *Trace) {
+	if e.GanttSpill == nil {
+		return
+	}
+	for _, s := range tr.Gantt {
+		if s.Start < s.Stop {
+			_, _ = fmt.Fprintf(e.GanttSpill, "%d,%d,%d\n", s.PID, s.Start, s.Stop)
+		}
+	}
+	tr.Gantt = tr.Gantt[:0]
+}
+
+// ReadGanttSpill reads back the slices an Engine wrote to its GanttSpill.
+func ReadGanttSpill(r io.Reader) ([]TimeSlice, error) {
+	var gantt []TimeSlice
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		var s TimeSlice
+		if _, err := fmt.Sscanf(sc.Text(), "%d,%d,%d", &s.PID, &s.Start, &s.Stop); err != nil {
+			return nil, fmt.Errorf("%w: Gantt spill line %d: %v", ErrInvalidArgs, line, err)
+		}
+		gantt = append(gantt, s)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("%w: reading Gantt spill", err)
+	}
+	return gantt, nil
+}
+
+// idleUntil is the next instant the CPU could have work: an arrival, the end
+// of an I/O or, when a capped group is throttled, the start of the next period. It is -1 when
+// nothing will ever become ready again.
+func (e *Engine) idleUntil(pending []*Task, inIO ioWait, groups map[string]*cpuGroup, refill int64) int64 {
+	wake := int64(-1)
+	if len(pending) > 0 {
+		wake = pending[0].ArrivalTime
+	}
+	if len(inIO) > 0 && (wake < 0 || inIO[0].ioDone < wake) {
+		wake = inIO[0].ioDone
+	}
+	for _, g := range groups {
+		if len(g.throttled) > 0 && (wake < 0 || refill < wake) {
+			wake = refill
+		}
+	}
+	return wake
+}
+
+// blockedTasks lists the tasks stuck on a sync object, in the order of tasks.
+func blockedTasks(tasks []*Task, blocked map[*Task]bool) []*Task {
+	var stuck []*Task
+	for _, t := range tasks {
+		if blocked[t] {
+			stuck = append(stuck, t)
+		}
+	}
+	return stuck
+}
+
+// Do implements SyncObject with P and V.
+func (s *semaphore) Do(t *Task, op string) (bool, []*Task) {
+	switch op {
+	case SemWait:
+		if s.value > 0 {
+			s.value--
+			return true, nil
+		}
+		s.waiters = append(s.waiters, t)
+		return false, nil
+	case SemSignal:
+		if len(s.waiters) == 0 {
+			s.value++
+			return true, nil
+		}
+		var w *Task
+		w, s.waiters = s.wakeup.pick(s.waiters)
+		return true, []*Task{w}
+	default:
+		return true, nil
+	}
+}
+
+// Do implements SyncObject with lock and unlock. An unlock by a task that
+// does not hold the mutex is ignored.
+func (m *mutex) Do(t *Task, op string) (bool, []*Task) {
+	switch op {
+	case MutexLock:
+		if m.owner == nil {
+			m.owner = t
+			return true, nil
+		}
+		m.waiters = append(m.waiters, t)
+		return false, nil
+	case MutexUnlock:
+		if m.owner != t {
+			return true, nil
+		}
+		if len(m.waiters) == 0 {
+			m.owner = nil
+			return true, nil
+		}
+		m.owner, m.waiters = m.wakeup.pick(m.waiters)
+		return true, []*Task{m.owner}
+	default:
+		return true, nil
+	}
+}
+
+// ownerOf is the PID of the task holding obj if it is a mutex, or 0.
+func ownerOf(obj SyncObject) int64 {
+	if m, ok := obj.(*mutex); ok && m.owner != nil {
+		return m.owner.ProcessID
+	}
+	return 0
+}
+
+// inherit keeps priorities inherited through m up to date after t's op on
+// it. A task blocking on m lends its rank to m's owner, and on down the
+// chain of owners blocked on mutexes of their own; an unlock gives back what
+// t inherited through m and passes it, with m, to the waiter woken.
+func (e *Engine) inherit(tr *Trace, now int64, t *Task, m *mutex, op string, proceed bool, woken []*Task) {
+	switch {
+	case op == MutexLock && proceed:
+		t.holds = append(t.holds, m)
+	case op == MutexLock:
+		t.waitingOn = m
+		for via := m; via != nil && via.owner != nil; via = via.owner.waitingOn {
+			o := via.owner
+			if !e.rerank(tr, now, o, via.name) {
+				return
+			}
+			// A queued owner must be reordered by its new rank.
+			if e.Queue.Remove(o) {
+				e.Queue.Push(o)
+			}
+		}
+	case op == MutexUnlock:
+		for i, h := range t.holds {
+			if h == m {
+				t.holds = append(t.holds[:i], t.holds[i+1:]...)
+				break
+			}
+		}
+		e.rerank(tr, now, t, m.name)
+		for _, w := range woken {
+			w.waitingOn = nil
+			w.holds = append(w.holds, m)
+			e.rerank(tr, now, w, m.name)
+		}
+	}
+}
+
+// rerank sets t's inherited priority to the best rank of the tasks waiting
+// on the mutexes it holds, logging the change to tr against lock, and
+// reports whether its rank changed.
+func (e *Engine) rerank(tr *Trace, now int64, t *Task, lock string) bool {
+	from, best := t.rank(), t.Priority
+	for _, m := range t.holds {
+		for _, w := range m.waiters {
+			if r := w.rank(); r < best {
+				best = r
+			}
+		}
+	}
+	t.inherited, t.inheriting = best, best < t.Priority
+	if best == from {
+		return false
+	}
+	tr.Inheritances = append(tr.Inheritances, PriorityChange{Time: now, PID: t.ProcessID, From: from, To: best, Lock: lock})
+	return true
+}
+
+// rank is the priority t is scheduled at: its own, or a better one it
+// inherited through a mutex it holds.
+func (t *Task) rank() int64 {
+	if t.inheriting {
+		return t.inherited
+	}
+	return t.Priority
+}
+
+// pick removes the waiter the policy wakes next. Waiters are in blocking order.
+func (p WakeupPolicy) pick(waiters []*Task) (*Task, []*Task) {
+	i := 0
+	switch p {
+	case WakeLIFO:
+		i = len(waiters) - 1
+	case WakePriority:
+		for j := range waiters {
+			if waiters[j].rank() < waiters[i].rank() {
+				i = j
+			}
+		}
+	}
+	w := waiters[i]
+	return w, append(waiters[:i], waiters[i+1:]...)
+}
+
+func sortedGroupNames(groups map[string]*cpuGroup) []string {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// carry is how much of an unused slice a yielding task keeps for its next dispatch.
+func (e *Engine) carry(leftover int64) int64 {
+	switch e.Carry {
+	case CarryNext:
+		if leftover > e.Quantum {
+			return e.Quantum
+		}
+		return leftover
+	case CarryBank:
+		if leftover > e.BankCap {
+			return e.BankCap
+		}
+		return leftover
+	default:
+		return 0
+	}
+}
+
+// untilYield is the CPU time left before the task's next yield point, or -1
+// if it has none left.
+func (t *Task) untilYield() int64 {
+	for t.nextYield < len(t.Yields) && t.Yields[t.nextYield] < t.Used {
+		t.nextYield++
+	}
+	if t.nextYield == len(t.Yields) {
+		return -1
+	}
+	return t.Yields[t.nextYield] - t.Used
+}
+
+func (tr *Trace) log(at int64, kind EventKind, pid int64, note string) {
+	ev := Event{Time: at, Kind: kind, PID: pid, Note: note}
+	if !tr.dropEvents {
+		tr.Events = append(tr.Events, ev)
+	}
+	if tr.onEvent != nil {
+		tr.onEvent(ev)
+	}
+}
+
+func (q *FIFOQueue) Push(t *Task) {
+	t.queueIndex = len(q.tasks)
+	q.tasks = append(q.tasks, t)
+	q.live++
+}
+
+func (q *FIFOQueue) Pop() *Task {
+	t := q.peek()
+	q.tasks[q.head] = nil
+	q.head++
+	q.drop(t)
+	return t
+}
+
+func (q *FIFOQueue) Remove(t *Task) bool {
+	i := t.queueIndex
+	if i < q.head || i >= len(q.tasks) || q.tasks[i] != t {
+		return false
+	}
+	q.tasks[i] = nil
+	q.drop(t)
+	return true
+}
+
+func (q *FIFOQueue) Len() int { return q.live }
+
+// peek is the task Pop would return, skipping past removed slots.
+func (q *FIFOQueue) peek() *Task {
+	for q.tasks[q.head] == nil {
+		q.head++
+	}
+	return q.tasks[q.head]
+}
+
+// drop accounts for t leaving the queue and compacts the slice when more than
+// half of it is popped or removed slots.
+func (q *FIFOQueue) drop(t *Task) {
+	t.queueIndex = -1
+	q.live--
+	if q.live == 0 {
+		q.tasks, q.head = q.tasks[:0], 0
+		return
+	}
+	if len(q.tasks) < 64 || q.live > len(q.tasks)/2 {
+		return
+	}
+	n := 0
+	for _, t := range q.tasks[q.head:] {
+		if t != nil {
+			t.queueIndex = n
+			q.tasks[n] = t
+			n++
+		}
+	}
+	for i := n; i < len(q.tasks); i++ {
+		q.tasks[i] = nil
+	}
+	q.tasks, q.head = q.tasks[:n], 0
+}
+
+func (q *heapQueue) Push(t *Task) {
+	t.queueSeq = q.pushed
+	q.pushed++
+	heap.Push((*taskHeap)(q), t)
+}
+
+func (q *heapQueue) Pop() *Task { return heap.Pop((*taskHeap)(q)).(*Task) }
+
+func (q *heapQueue) Remove(t *Task) bool {
+	i := t.queueIndex
+	if i < 0 || i >= len(q.tasks) || q.tasks[i] != t {
+		return false
+	}
+	heap.Remove((*taskHeap)(q), i)
+	return true
+}
+
+func (q *heapQueue) Len() int { return len(q.tasks) }
+
+func (q *heapQueue) breakTiesBy(tb TieBreak) { q.tie = tb }
+
+func (h *taskHeap) Len() int { return len(h.tasks) }
+
+func (h *taskHeap) Less(i, j int) bool {
+	a, b := h.tasks[i], h.tasks[j]
+	if h.less(a, b) {
+		return true
+	}
+	return !h.less(b, a) && h.tie.first(a, b)
+}
+
+func (h *taskHeap) Swap(i, j int) {
+	h.tasks[i], h.tasks[j] = h.tasks[j], h.tasks[i]
+	h.tasks[i].queueIndex = i
+	h.tasks[j].queueIndex = j
+}
+
+func (h *taskHeap) Push(x interface{}) {
+	t := x.(*Task)
+	t.queueIndex = len(h.tasks)
+	h.tasks = append(h.tasks, t)
+}
+
+func (h *taskHeap) Pop() interface{} {
+	n := len(h.tasks) - 1
+	t := h.tasks[n]
+	h.tasks[n] = nil
+	h.tasks = h.tasks[:n]
+	t.queueIndex = -1
+	return t
+}
+
+// byRemaining orders tasks by what is left of their current CPU burst,
+// shortest first.
+func byRemaining(a, b *Task) bool { return a.nextBurst() < b.nextBurst() }
+
+// newSRTFEngine makes an engine for shortest remaining time first: sjf's
+// queue, with an arrival taking the CPU when it has strictly less left to run.
+func newSRTFEngine() *Engine {
+	return &Engine{
+		Queue:   &heapQueue{less: byRemaining},
+		Preempt: func(running, arrived *Task) bool { return arrived.nextBurst() < running.nextBurst() },
+	}
+}
+
+// untilIO is how much more CPU t needs before its next I/O, or -1 if it has
+// none left.
+func (t *Task) untilIO() int64 {
+	if t.nextIO < len(t.IO) {
+		return t.IO[t.nextIO].At - t.Used
+	}
+	return -1
+}
+
+// nextBurst is what is left of t's current CPU burst.
+func (t *Task) nextBurst() int64 {
+	if u := t.untilIO(); u >= 0 {
+		return u
+	}
+	return t.Remaining
+}
+
+// push starts t's next I/O at now and returns how long it takes.
+func (w *ioWait) push(t *Task, now int64) int64 {
+	d := t.IO[t.nextIO].Duration
+	t.nextIO++
+	t.blockedAt, t.ioDone = now, now+d
+	heap.Push(w, t)
+	return d
+}
+
+// pop takes the first task to finish its I/O off w, counting the I/O as
+// time blocked.
+func (w *ioWait) pop() *Task {
+	t := heap.Pop(w).(*Task)
+	t.Blocked += t.ioDone - t.blockedAt
+	if t.ioDone-t.blockedAt > t.LongestBlock {
+		t.LongestBlock = t.ioDone - t.blockedAt
+	}
+	return t
+}
+
+func (w ioWait) Len() int { return len(w) }
+
+func (w ioWait) Less(i, j int) bool {
+	if w[i].ioDone != w[j].ioDone {
+		return w[i].ioDone < w[j].ioDone
+	}
+	return w[i].blockedAt < w[j].blockedAt
+}
+
+func (w ioWait) Swap(i, j int) { w[i], w[j] = w[j], w[i] }
+
+func (w *ioWait) Push(x interface{}) { *w = append(*w, x.(*Task)) }
+
+func (w *ioWait) Pop() interface{} {
+	old := *w
+	t := old[len(old)-1]
+	old[len(old)-1] = nil
+	*w = old[:len(old)-1]
+	return t
+}
+
+// byPriority orders tasks by Priority, or any better one they inherited,
+// lowest value first.
+func byPriority(a, b *Task) bool { return a.rank() < b.rank() }
+
+// newAgingEngine makes an engine for preemptive priority scheduling with
+// aging: a waiting task is boosted one step every rate ticks, as far as
+// priority floor, and takes the CPU from the running task as soon as it
+// outranks it, on arrival or later.
+func newAgingEngine(rate, floor int64) *Engine {
+	return &Engine{
+		Queue:   newAgingQueue(rate, floor),
+		Preempt: func(running, arrived *Task) bool { return arrived.effectivePriority() < running.effectivePriority() },
+	}
+}
+
+// effectivePriority is a task's Priority, or any better one it inherited,
+// after its boosts, outside the queue.
+func (t *Task) effectivePriority() int64 { return t.rank() - t.Boosts }
+
+func newAgingQueue(rate, floor int64) *agingQueue {
+	q := &agingQueue{rate: rate, floor: floor}
+	q.less = func(a, b *Task) bool { return a.agingKey < b.agingKey }
+	return q
+}
+
+func (q *agingQueue) Push(t *Task) {
+	t.agingKey = t.effectivePriority()
+	if t.agingKey > q.floor {
+		t.agingKey += q.now / q.rate
+	}
+	q.heapQueue.Push(t)
+}
+
+func (q *agingQueue) Pop() *Task {
+	t := q.heapQueue.Pop()
+	q.settle(t)
+	return t
+}
+
+func (q *agingQueue) Remove(t *Task) bool {
+	if !q.heapQueue.Remove(t) {
+		return false
+	}
+	q.settle(t)
+	return true
+}
+
+// settle adds the boosts t gained while it waited to t.Boosts.
+func (q *agingQueue) settle(t *Task) {
+	eff := t.effectivePriority()
+	gained := eff + q.now/q.rate - t.agingKey
+	if gained > eff-q.floor {
+		gained = eff - q.floor
+	}
+	if gained > 0 {
+		t.Boosts += gained
+	}
+}
+
+func (q *agingQueue) setTime(now int64) { q.now = now }
+
+// preemptAt is the first multiple of rate at which the best waiting task's
+// boosts lift it above running, or now if they already have. Boosts stop at
+// the floor, so two boosted tasks never take turns preempting each other.
+func (q *agingQueue) preemptAt(running *Task) int64 {
+	if len(q.tasks) == 0 {
+		return -1
+	}
+	best, r := q.tasks[0], running.effectivePriority()
+	if best.effectivePriority() >= r && r <= q.floor {
+		return -1
+	}
+	// It outranks running once now/rate exceeds the gap between the two.
+	at := (best.agingKey - r + 1) * q.rate
+	if at < q.now {
+		return q.now
+	}
+	return at
+}
+
+// newLotteryEngine makes an engine for lottery scheduling: every quantum,
+// or every dispatch if quantum is 0, goes to a task drawn by tickets from a
+// generator seeded with seed, so the same seed gives the same run.
+func newLotteryEngine(quantum, seed int64) *Engine {
+	return &Engine{Queue: newLotteryQueue(seed), Quantum: quantum}
+}
+
+// tickets is how many lottery tickets t holds, never less than one. A
+// process with a nice value holds lotteryTicketPool scaled by its weight
+// against that of nice 0, so tickets share the CPU as cfs weights do.
+// Otherwise it holds lotteryTicketPool over one more than its Priority, in
+// the run's order, so each step down in priority holds fewer.
+func (t *Task) tickets() int64 {
+	n := int64(lotteryTicketPool)
+	if t.Nice != nil {
+		n = t.weight() * lotteryTicketPool / cfsNice0Weight
+	} else if p := t.ordered(); p > 0 {
+		n = lotteryTicketPool / (p + 1)
+	}
+	if n > 0 {
+		return n
+	}
+	return 1
+}
+
+func newLotteryQueue(seed int64) *lotteryQueue {
+	return &lotteryQueue{rng: rand.New(rand.NewSource(seed)), seed: seed}
+}
+
+func (q *lotteryQueue) Push(t *Task) {
+	if len(q.slots)+1 >= len(q.tree) {
+		q.rebuild(2*q.live + 16)
+	}
+	q.join(t)
+	t.queueIndex = len(q.slots)
+	q.slots = append(q.slots, t)
+	q.add(t.queueIndex, t.tickets())
+	q.live++
+}
+
+// Pop draws a ticket and hands out the task holding it.
+func (q *lotteryQueue) Pop() *Task {
+	// Find the slot whose tickets take the running total past the one
+	// drawn, descending the tree.
+	ticket, i := q.rng.Int63n(q.total), 0
+	for step := 1 << bits.Len(uint(len(q.tree)-1)) >> 1; step > 0; step >>= 1 {
+		if i+step < len(q.tree) && q.tree[i+step] <= ticket {
+			i += step
+			ticket -= q.tree[i]
+		}
+	}
+	t := q.slots[i]
+	q.draw(t)
+	q.remove(t)
+	return t
+}
+
+func (q *lotteryQueue) Remove(t *Task) bool {
+	i := t.queueIndex
+	if i < 0 || i >= len(q.slots) || q.slots[i] != t {
+		return false
+	}
+	q.remove(t)
+	return true
+}
+
+func (q *lotteryQueue) Len() int { return q.live }
+
+// remove takes t out of its slot, compacting the slots if most are empty.
+func (q *lotteryQueue) remove(t *Task) {
+	q.leave(t)
+	q.add(t.queueIndex, -t.tickets())
+	q.slots[t.queueIndex], t.queueIndex = nil, -1
+	q.live--
+	if len(q.slots) >= 64 && q.live < len(q.slots)/4 {
+		q.rebuild(len(q.tree) - 1)
+	}
+}
+
+// add adds n tickets to slot i in the tree.
+func (q *lotteryQueue) add(i int, n int64) {
+	for i++; i < len(q.tree); i += i & -i {
+		q.tree[i] += n
+	}
+}
+
+// rebuild packs the waiting tasks into the first slots, in order, with room
+// for size slots in all.
+func (q *lotteryQueue) rebuild(size int) {
+	slots := make([]*Task, 0, size)
+	for _, t := range q.slots {
+		if t != nil {
+			t.queueIndex = len(slots)
+			slots = append(slots, t)
+		}
+	}
+	q.slots, q.tree = slots, make([]int64, size+1)
+	for i, t := range slots {
+		q.tree[i+1] = t.tickets()
+	}
+	for i := 1; i <= size; i++ {
+		if j := i + i&-i; j <= size {
+			q.tree[j] += q.tree[i]
+		}
+	}
+}
+
+// join enters t in the draws to come.
+func (l *drawLedger) join(t *Task) {
+	t.drawMark, t.oddsMark = l.draws, l.perTicket
+	l.total += t.tickets()
+}
+
+// draw counts a draw among the waiting tasks, which winner won.
+func (l *drawLedger) draw(winner *Task) {
+	l.draws++
+	l.perTicket += 1 / float64(l.total)
+	winner.Wins++
+}
+
+// leave adds the draws held while t waited to its Draws and odds.
+func (l *drawLedger) leave(t *Task) {
+	t.Draws += l.draws - t.drawMark
+	t.odds += float64(t.tickets()) * (l.perTicket - t.oddsMark)
+	l.total -= t.tickets()
+}
+
+// newStrideEngine makes an engine for stride scheduling: every quantum, or
+// every dispatch if quantum is 0, goes to the task with the lowest pass.
+func newStrideEngine(quantum int64) *Engine {
+	q := &strideQueue{}
+	q.less = func(a, b *Task) bool { return a.pass < b.pass }
+	return &Engine{Queue: q, Quantum: quantum}
+}
+
+func (q *strideQueue) Push(t *Task) {
+	if !t.passSet {
+		t.pass, t.passSet = q.pass, true
+	}
+	q.join(t)
+	q.heapQueue.Push(t)
+}
+
+// Pop hands out the task with the lowest pass, counting it as a draw the
+// task won, and moves its pass on by its stride.
+func (q *strideQueue) Pop() *Task {
+	q.draw(q.tasks[0])
+	t := q.heapQueue.Pop()
+	q.leave(t)
+	q.pass = t.pass
+	t.pass += strideOne / t.tickets()
+	return t
+}
+
+func (q *strideQueue) Remove(t *Task) bool {
+	if !q.heapQueue.Remove(t) {
+		return false
+	}
+	q.leave(t)
+	return true
+}
+
+// lotterySeed is the seed of q if it is a lottery queue, or 0.
+func lotterySeed(q ReadyQueue) int64 {
+	if l, ok := q.(*lotteryQueue); ok {
+		return l.seed
+	}
+	return 0
+}
+
+// newCFSEngine makes an engine for completely fair scheduling with the
+// given target latency.
+func newCFSEngine(latency int64) *Engine {
+	q := &cfsQueue{latency: latency}
+	q.less = func(a, b *Task) bool { return a.vruntime < b.vruntime }
+	return &Engine{Queue: q}
+}
+
+// currentVRuntime is t's virtual runtime counting the CPU it has used since
+// it was last queued.
+func (t *Task) currentVRuntime() float64 {
+	return t.vruntime + float64((t.Used-t.charged)*cfsNice0Weight)/float64(t.weight())
+}
+
+func (q *cfsQueue) Push(t *Task) {
+	t.vruntime, t.charged, t.fair = t.currentVRuntime(), t.Used, true
+	if t.vruntime < q.min {
+		t.vruntime = q.min
+	}
+	q.weight += t.weight()
+	q.heapQueue.Push(t)
+}
+
+func (q *cfsQueue) Pop() *Task {
+	t := q.heapQueue.Pop()
+	q.weight -= t.weight()
+	if t.vruntime > q.min {
+		q.min = t.vruntime
+	}
+	return t
+}
+
+func (q *cfsQueue) Remove(t *Task) bool {
+	if !q.heapQueue.Remove(t) {
+		return false
+	}
+	q.weight -= t.weight()
+	return true
+}
+
+// slice is t's share by weight of the target latency among itself and the
+// tasks still queued.
+func (q *cfsQueue) slice(t *Task, now int64) int64 {
+	if !q.dropSamples {
+		q.samples = append(q.samples, VRuntimeSample{Time: now, PID: t.ProcessID, VRuntime: t.vruntime})
+	}
+	w := t.weight()
+	if s := q.latency * w / (w + q.weight); s > 1 {
+		return s
+	}
+	return 1
+}
+
+// cfsLatency is the target latency of q if it is a cfs queue, or 0.
+func cfsLatency(q ReadyQueue) int64 {
+	if c, ok := q.(*cfsQueue); ok {
+		return c.latency
+	}
+	return 0
+}
+
+// newEDFEngine makes an engine for earliest deadline first: the task due
+// soonest runs, and an arrival due sooner takes the CPU.
+func newEDFEngine() *Engine {
+	q := &edfQueue{}
+	q.less = func(a, b *Task) bool { return a.dueBy() < b.dueBy() }
+	return &Engine{
+		Queue:   q,
+		Preempt: func(running, arrived *Task) bool { return arrived.dueBy() < running.dueBy() },
+	}
+}
+
+// dueBy is t's deadline, or the end of time if it has none.
+func (t *Task) dueBy() int64 {
+	if t.Deadline == 0 {
+		return math.MaxInt64
+	}
+	return t.Deadline
+}
+
+// newHRRNEngine makes an engine for highest response ratio next: sjf, except
+// that waiting raises a task's ratio, so a long job cannot starve.
+func newHRRNEngine() *Engine {
+	return &Engine{Queue: &hrrnQueue{groups: make(map[int64]*FIFOQueue)}}
+}
+
+func (q *hrrnQueue) Push(t *Task) {
+	t.queuedAt, t.queueSeq = q.now, q.seq
+	q.seq++
+	burst := t.nextBurst()
+	g := q.groups[burst]
+	if g == nil {
+		g = &FIFOQueue{}
+		q.groups[burst] = g
+		q.bursts = append(q.bursts, burst)
+	}
+	g.Push(t)
+	q.live++
+}
+
+func (q *hrrnQueue) Pop() *Task {
+	var best *FIFOQueue
+	for i := 0; i < len(q.bursts); i++ {
+		g := q.groups[q.bursts[i]]
+		if g.Len() == 0 {
+			delete(q.groups, q.bursts[i])
+			q.bursts[i] = q.bursts[len(q.bursts)-1]
+			q.bursts = q.bursts[:len(q.bursts)-1]
+			i--
+			continue
+		}
+		if best == nil || q.higherRatio(g.peek(), best.peek()) {
+			best = g
+		}
+	}
+	q.live--
+	return best.Pop()
+}
+
+func (q *hrrnQueue) Remove(t *Task) bool {
+	g := q.groups[t.nextBurst()]
+	if g == nil || !g.Remove(t) {
+		return false
+	}
+	q.live--
+	return true
+}
+
+func (q *hrrnQueue) Len() int { return q.live }
+
+// higherRatio reports whether a's response ratio is above b's, or equal
+// with a pushed first, comparing (waitA+burstA)*burstB with
+// (waitB+burstB)*burstA in 128 bits so neither rounding nor overflow can
+// reorder them.
+func (q *hrrnQueue) higherRatio(a, b *Task) bool {
+	ba, bb := uint64(a.nextBurst()), uint64(b.nextBurst())
+	ahi, alo := bits.Mul64(uint64(q.now-a.queuedAt)+ba, bb)
+	bhi, blo := bits.Mul64(uint64(q.now-b.queuedAt)+bb, ba)
+	if ahi != bhi || alo != blo {
+		return ahi > bhi || ahi == bhi && alo > blo
+	}
+	return a.queueSeq < b.queueSeq
+}
+
+func (q *hrrnQueue) setTime(now int64) { q.now = now }
+
+// preemptAt is never: hrrn only chooses when the CPU comes free.
+func (q *hrrnQueue) preemptAt(*Task) int64 { return -1 }
+
+// newPredictEngine makes an engine for sjf with each burst predicted by
+// exponential averaging with weight alpha on the last burst.
+func newPredictEngine(alpha float64) *Engine {
+	q := &predictQueue{alpha: alpha}
+	q.less = func(a, b *Task) bool { return a.predicted < b.predicted }
+	return &Engine{Queue: q}
+}
+
+// Push ends the burst t ran since its last dispatch, if it ran, and folds
+// it into t's prediction; a task new to the queue gets the initial guess.
+func (q *predictQueue) Push(t *Task) {
+	if t.predicted == 0 {
+		t.predicted = DefaultBurstGuess
+	}
+	if ran := t.Used - t.burstMark; ran > 0 {
+		if t.burstSample > 0 {
+			q.samples[t.burstSample-1].Actual = ran
+			q.samples[t.burstSample-1].task = nil
+			t.burstSample = 0
+		}
+		t.predicted = q.alpha*float64(ran) + (1-q.alpha)*t.predicted
+		t.burstMark = t.Used
+	}
+	q.heapQueue.Push(t)
+}
+
+// Pop starts the burst of the task predicted shortest.
+func (q *predictQueue) Pop() *Task {
+	t := q.heapQueue.Pop()
+	t.burstMark = t.Used
+	if !q.dropSamples && t.burstSample == 0 {
+		q.samples = append(q.samples, BurstPrediction{PID: t.ProcessID, Predicted: t.predicted, task: t})
+		t.burstSample = len(q.samples)
+	}
+	return t
+}
+
+// predictions are q's samples once the run is over: the bursts that ended
+// by completing are measured, those that never ran are dropped, and each
+// is numbered among its process's bursts.
+func (q *predictQueue) predictions() []BurstPrediction {
+	var (
+		out    []BurstPrediction
+		bursts = make(map[int64]int)
+	)
+	for _, s := range q.samples {
+		if s.task != nil {
+			s.Actual, s.task = s.task.Used-s.task.burstMark, nil
+		}
+		if s.Actual == 0 {
+			continue
+		}
+		bursts[s.PID]++
+		s.Burst = bursts[s.PID]
+		out = append(out, s)
+	}
+	return out
+}
+
+// newGuaranteedEngine makes an engine for guaranteed scheduling, choosing
+// again every quantum.
+func newGuaranteedEngine(quantum int64) *Engine {
+	return &Engine{Queue: &guaranteedQueue{shares: make(map[int64]float64)}, Quantum: quantum}
+}
+
+// Push queues t, which on its first push arrives in the system.
+func (q *guaranteedQueue) Push(t *Task) {
+	if !t.present {
+		t.present, t.entitledFrom = true, q.entitled
+		q.present = append(q.present, t)
+	}
+	t.queueSeq = q.seq
+	q.seq++
+	q.FIFOQueue.Push(t)
+}
+
+// Pop hands out the waiting task with the lowest ratio of CPU used to
+// entitlement. A task entitled to nothing yet has used nothing either, and
+// counts as the most under-served.
+func (q *guaranteedQueue) Pop() *Task {
+	var best *Task
+	for _, t := range q.tasks[q.head:] {
+		if t != nil && (best == nil || q.moreUnderServed(t, best)) {
+			best = t
+		}
+	}
+	q.FIFOQueue.Remove(best)
+	return best
+}
+
+// moreUnderServed reports whether a's ratio is below b's, comparing
+// usedA·entitledB with usedB·entitledA so that no entitlement divides.
+func (q *guaranteedQueue) moreUnderServed(a, b *Task) bool {
+	ea, eb := q.entitled-a.entitledFrom, q.entitled-b.entitledFrom
+	switch {
+	case ea == 0 && eb != 0:
+		return true
+	case eb == 0 && ea != 0:
+		return false
+	}
+	if ra, rb := float64(a.Used)*eb, float64(b.Used)*ea; ra != rb {
+		return ra < rb
+	}
+	return q.tie.first(a, b)
+}
+
+// setTime grows every present task's entitlement by its share of the time
+// since the last call, then lets go of the tasks that have completed,
+// recording what they were entitled to.
+func (q *guaranteedQueue) setTime(now int64) {
+	if n := len(q.present); n > 0 {
+		q.entitled += float64(now-q.now) / float64(n)
+	}
+	q.now = now
+	q.settle()
+}
+
+// settle records the entitlement of each completed task and drops it from
+// the tasks present.
+func (q *guaranteedQueue) settle() {
+	kept := q.present[:0]
+	for _, t := range q.present {
+		if t.Remaining == 0 {
+			q.shares[t.ProcessID] = q.entitled - t.entitledFrom
+			continue
+		}
+		kept = append(kept, t)
+	}
+	for i := len(kept); i < len(q.present); i++ {
+		q.present[i] = nil
+	}
+	q.present = kept
+}
+
+// preemptAt is never: guaranteed only chooses again once the quantum is up.
+func (q *guaranteedQueue) preemptAt(*Task) int64 { return -1 }
+
+func (q *guaranteedQueue) breakTiesBy(tb TieBreak) { q.tie = tb }
+
+// newStaticPriorityEngine makes an engine for fixed-priority preemptive
+// scheduling, as rate monotonic uses: the lowest Priority value runs, and an
+// arrival with a strictly lower one takes the CPU. Unlike ppriority nothing
+// ages, so a task's rank never changes.
+func newStaticPriorityEngine() *Engine {
+	return &Engine{
+		Queue:   &heapQueue{less: byPriority},
+		Preempt: func(running, arrived *Task) bool { return arrived.rank() < running.rank() },
+	}
+}
+
+// newWRREngine makes an engine for weighted round-robin with the given base
+// quantum and weights by Priority.
+func newWRREngine(quantum int64, weights map[int64]int64) *Engine {
+	return &Engine{Queue: &wrrQueue{quantum: quantum, weights: weights}, Quantum: quantum}
+}
+
+// weight is what a task of the given Priority weighs in q.
+func (q *wrrQueue) weight(priority int64) int64 {
+	if w, ok := q.weights[priority]; ok {
+		return w
+	}
+	return 1
+}
+
+func (q *wrrQueue) slice(t *Task, _ int64) int64 {
+	return q.quantum * q.weight(t.Priority)
+}
+
+//endregion
@@ -0,0 +1,787 @@
+package scheduler
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// mixedWorkload is the command's testdata/workloads/mixed.csv: bursts,
+// arrivals and priorities that each order the processes differently.
+var mixedWorkload = []Process{
+	{ProcessID: 1, BurstDuration: 10, ArrivalTime: 0, Priority: 5},
+	{ProcessID: 2, BurstDuration: 1, ArrivalTime: 1, Priority: 1},
+	{ProcessID: 3, BurstDuration: 2, ArrivalTime: 2, Priority: 4},
+	{ProcessID: 4, BurstDuration: 1, ArrivalTime: 3, Priority: 2},
+	{ProcessID: 5, BurstDuration: 5, ArrivalTime: 4, Priority: 3},
+	{ProcessID: 6, BurstDuration: 3, ArrivalTime: 5, Priority: 1},
+}
+
+func TestEngine_Simulate(t *testing.T) {
+	t.Parallel()
+	type args struct {
+		quantum   int64
+		processes []Process
+	}
+	tests := []struct {
+		name  string
+		args  args
+		want  []TimeSlice
+		exits []int64
+	}{
+		{
+			name: "cooperative runs to completion",
+			args: args{
+				processes: []Process{
+					{ProcessID: 1, ArrivalTime: 0, BurstDuration: 5},
+					{ProcessID: 2, ArrivalTime: 1, BurstDuration: 3},
+				},
+			},
+			want: []TimeSlice{
+				{PID: 1, Start: 0, Stop: 5},
+				{PID: 2, Start: 5, Stop: 8},
+			},
+			exits: []int64{5, 8},
+		},
+		{
+			name: "yield points give up the cpu",
+			args: args{
+				processes: []Process{
+					{ProcessID: 1, ArrivalTime: 0, BurstDuration: 5, Yields: []int64{2}},
+					{ProcessID: 2, ArrivalTime: 1, BurstDuration: 3},
+				},
+			},
+			want: []TimeSlice{
+				{PID: 1, Start: 0, Stop: 2},
+				{PID: 2, Start: 2, Stop: 5},
+				{PID: 1, Start: 5, Stop: 8},
+			},
+			exits: []int64{8, 5},
+		},
+		{
+			name: "donation skips the ready queue",
+			args: args{
+				processes: []Process{
+					{ProcessID: 1, ArrivalTime: 0, BurstDuration: 4, Yields: []int64{1}, DonateTo: 3},
+					{ProcessID: 2, ArrivalTime: 0, BurstDuration: 2},
+					{ProcessID: 3, ArrivalTime: 0, BurstDuration: 2},
+				},
+			},
+			want: []TimeSlice{
+				{PID: 1, Start: 0, Stop: 1},
+				{PID: 3, Start: 1, Stop: 3},
+				{PID: 2, Start: 3, Stop: 5},
+				{PID: 1, Start: 5, Stop: 8},
+			},
+			exits: []int64{8, 5, 3},
+		},
+		{
+			name: "donee inherits the leftover quantum",
+			args: args{
+				quantum: 4,
+				processes: []Process{
+					{ProcessID: 1, ArrivalTime: 0, BurstDuration: 3, Yields: []int64{1}, DonateTo: 2},
+					{ProcessID: 2, ArrivalTime: 0, BurstDuration: 6},
+				},
+			},
+			want: []TimeSlice{
+				{PID: 1, Start: 0, Stop: 1},
+				{PID: 2, Start: 1, Stop: 4},
+				{PID: 1, Start: 4, Stop: 6},
+				{PID: 2, Start: 6, Stop: 9},
+			},
+			exits: []int64{6, 9},
+		},
+		{
+			name: "idle gap before a late arrival",
+			args: args{
+				processes: []Process{
+					{ProcessID: 1, ArrivalTime: 3, BurstDuration: 2},
+				},
+			},
+			want: []TimeSlice{
+				{PID: IdlePID, Start: 0, Stop: 3},
+				{PID: 1, Start: 3, Stop: 5},
+			},
+			exits: []int64{5},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			engine := Engine{Queue: &FIFOQueue{}, Quantum: tt.args.quantum}
+			tr := engine.Simulate(tt.args.processes)
+			if !reflect.DeepEqual(tr.Gantt, tt.want) {
+				t.Errorf("Simulate() gantt = %v, want %v", tr.Gantt, tt.want)
+			}
+			for i := range tr.Tasks {
+				if tr.Tasks[i].Exit != tt.exits[i] {
+					t.Errorf("Simulate() exit of %d = %d, want %d", tr.Tasks[i].ProcessID, tr.Tasks[i].Exit, tt.exits[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReadyQueues(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		queue ReadyQueue
+		less  func(a, b *Task) bool // nil for push order
+	}{
+		{name: "fifo", queue: &FIFOQueue{}},
+		{name: "heap", queue: &heapQueue{less: byRemaining}, less: byRemaining},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// model is the queue as a plain slice in push order; popping takes
+			// the first task that nothing before or after sorts ahead of.
+			var model, tasks, got, want []*Task
+			pop := func() {
+				best := 0
+				for i := range model {
+					if tt.less != nil && tt.less(model[i], model[best]) {
+						best = i
+					}
+				}
+				want = append(want, model[best])
+				model = append(model[:best:best], model[best+1:]...)
+				got = append(got, tt.queue.Pop())
+			}
+			// Enough tasks to compact several times, with every third one
+			// removed and pops interleaved with pushes.
+			for i := 0; i < 500; i++ {
+				task := &Task{Process: &Process{}, Remaining: int64(i * 7 % 13)}
+				tasks = append(tasks, task)
+				model = append(model, task)
+				tt.queue.Push(task)
+				if i%3 == 2 {
+					for j, m := range model {
+						if m == tasks[i-1] {
+							model = append(model[:j:j], model[j+1:]...)
+							if !tt.queue.Remove(m) {
+								t.Fatalf("Remove(task %d) = false", i-1)
+							}
+							break
+						}
+					}
+				}
+				if i%5 == 4 {
+					pop()
+				}
+			}
+			if tt.queue.Remove(got[0]) {
+				t.Error("Remove() of a popped task = true")
+			}
+			for len(model) > 0 {
+				if tt.queue.Len() != len(model) {
+					t.Fatalf("Len() = %d, want %d", tt.queue.Len(), len(model))
+				}
+				pop()
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("pop %d = %+v, want %+v", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEngine_GanttMemory(t *testing.T) {
+	t.Parallel()
+	processes := mixedWorkload
+	full := Engine{Queue: &FIFOQueue{}, Quantum: 1}
+	want := full.Simulate(processes).Gantt
+
+	compact := Engine{Queue: &FIFOQueue{}, Quantum: 1, CompactGantt: true}
+	if got := compact.Simulate(processes).Gantt; !reflect.DeepEqual(got, MergeSlices(want)) || len(got) == len(want) {
+		t.Errorf("compacted Gantt = %v, want %v", got, MergeSlices(want))
+	}
+
+	var spill bytes.Buffer
+	spilled := Engine{Queue: &FIFOQueue{}, Quantum: 1, GanttSpill: &spill}
+	if tr := spilled.Simulate(processes); len(tr.Gantt) != 0 {
+		t.Errorf("spilled run kept %d slices in memory", len(tr.Gantt))
+	}
+	got, err := ReadGanttSpill(&spill)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("spilled Gantt = %v, want %v", got, want)
+	}
+	if _, err := ReadGanttSpill(strings.NewReader("1,0,2\n2;2;3\n")); !errors.Is(err, ErrInvalidArgs) {
+		t.Errorf("ReadGanttSpill() of a bad line error = %v, want %v", err, ErrInvalidArgs)
+	}
+}
+
+func TestEngine_longBursts(t *testing.T) {
+	t.Parallel()
+	// The engine steps from one event to the next, so bursts in the
+	// trillions cost no more than bursts of one tick, given time slices to
+	// match.
+	const tera = 1_000_000_000_000
+	processes := []Process{
+		{ProcessID: 1, ArrivalTime: 0, BurstDuration: 3 * tera, Priority: 2},
+		{ProcessID: 2, ArrivalTime: tera, BurstDuration: tera, Priority: 1},
+		{ProcessID: 3, ArrivalTime: 2 * tera, BurstDuration: 2 * tera, Priority: 3},
+	}
+	for _, name := range SortedSchedulerNames() {
+		result, err := RunSchedulerParams(name, SchedulerParams{Quantum: tera, Latency: 3 * tera}, processes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Gantt) > 10 {
+			t.Errorf("%s cut the run into %d slices", name, len(result.Gantt))
+		}
+		var last int64
+		for _, m := range result.Processes {
+			if m.Exit > last {
+				last = m.Exit
+			}
+		}
+		if last != 6*tera {
+			t.Errorf("%s finished at %d, want %d", name, last, int64(6*tera))
+		}
+	}
+}
+
+func TestEngine_SwitchCost(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name            string
+		engine          *Engine
+		processes       []Process
+		want            []TimeSlice
+		wantUtilization float64
+		wantResponse    int64
+	}{
+		{
+			name:      "task carrying on pays once",
+			engine:    &Engine{Queue: &FIFOQueue{}, Quantum: 2, SwitchCost: 1},
+			processes: []Process{{ProcessID: 1, ArrivalTime: 0, BurstDuration: 5}},
+			want: []TimeSlice{
+				{PID: SwitchPID, Start: 0, Stop: 1},
+				{PID: 1, Start: 1, Stop: 3},
+				{PID: 1, Start: 3, Stop: 5},
+				{PID: 1, Start: 5, Stop: 6},
+			},
+			wantUtilization: 5.0 / 6,
+			wantResponse:    1,
+		},
+		{
+			name:   "every dispatch of another task pays",
+			engine: &Engine{Queue: &FIFOQueue{}, Quantum: 2, SwitchCost: 1},
+			processes: []Process{
+				{ProcessID: 1, ArrivalTime: 0, BurstDuration: 3},
+				{ProcessID: 2, ArrivalTime: 0, BurstDuration: 2},
+			},
+			want: []TimeSlice{
+				{PID: SwitchPID, Start: 0, Stop: 1},
+				{PID: 1, Start: 1, Stop: 3},
+				{PID: SwitchPID, Start: 3, Stop: 4},
+				{PID: 2, Start: 4, Stop: 6},
+				{PID: SwitchPID, Start: 6, Stop: 7},
+				{PID: 1, Start: 7, Stop: 8},
+			},
+			wantUtilization: 5.0 / 8,
+			wantResponse:    1,
+		},
+		{
+			name:   "arrival during a switch preempts",
+			engine: withParams(newSRTFEngine(), SchedulerParams{SwitchCost: 2}),
+			processes: []Process{
+				{ProcessID: 1, ArrivalTime: 0, BurstDuration: 5},
+				{ProcessID: 2, ArrivalTime: 1, BurstDuration: 1},
+			},
+			want: []TimeSlice{
+				{PID: SwitchPID, Start: 0, Stop: 2},
+				{PID: SwitchPID, Start: 2, Stop: 4},
+				{PID: 2, Start: 4, Stop: 5},
+				{PID: SwitchPID, Start: 5, Stop: 7},
+				{PID: 1, Start: 7, Stop: 12},
+			},
+			wantUtilization: 6.0 / 12,
+			wantResponse:    7,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result := tt.engine.Schedule(tt.processes)
+			if !reflect.DeepEqual(result.Gantt, tt.want) {
+				t.Errorf("Schedule() gantt = %v, want %v", result.Gantt, tt.want)
+			}
+			if result.Utilization != tt.wantUtilization {
+				t.Errorf("Utilization = %v, want %v", result.Utilization, tt.wantUtilization)
+			}
+			if got := result.Processes[0].Response; got != tt.wantResponse {
+				t.Errorf("P1 response = %d, want %d", got, tt.wantResponse)
+			}
+			if result.SwitchCost != tt.engine.SwitchCost {
+				t.Errorf("SwitchCost = %d, want %d", result.SwitchCost, tt.engine.SwitchCost)
+			}
+		})
+	}
+}
+
+func TestEngine_Carry(t *testing.T) {
+	t.Parallel()
+	processes := []Process{
+		{ProcessID: 1, ArrivalTime: 0, BurstDuration: 8, Yields: []int64{1}},
+		{ProcessID: 2, ArrivalTime: 0, BurstDuration: 8},
+	}
+	tests := []struct {
+		name    string
+		carry   CarryPolicy
+		bankCap int64
+		want    []TimeSlice
+	}{
+		{
+			name:  "discard",
+			carry: CarryDiscard,
+			want: []TimeSlice{
+				{PID: 1, Start: 0, Stop: 1},
+				{PID: 2, Start: 1, Stop: 5},
+				{PID: 1, Start: 5, Stop: 9},
+				{PID: 2, Start: 9, Stop: 13},
+				{PID: 1, Start: 13, Stop: 16},
+			},
+		},
+		{
+			name:  "carry",
+			carry: CarryNext,
+			want: []TimeSlice{
+				{PID: 1, Start: 0, Stop: 1},
+				{PID: 2, Start: 1, Stop: 5},
+				{PID: 1, Start: 5, Stop: 12},
+				{PID: 2, Start: 12, Stop: 16},
+			},
+		},
+		{
+			name:    "bank",
+			carry:   CarryBank,
+			bankCap: 1,
+			want: []TimeSlice{
+				{PID: 1, Start: 0, Stop: 1},
+				{PID: 2, Start: 1, Stop: 5},
+				{PID: 1, Start: 5, Stop: 10},
+				{PID: 2, Start: 10, Stop: 14},
+				{PID: 1, Start: 14, Stop: 16},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			engine := Engine{Queue: &FIFOQueue{}, Quantum: 4, Carry: tt.carry, BankCap: tt.bankCap}
+			if got := engine.Simulate(processes).Gantt; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Simulate() gantt = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngine_Caps(t *testing.T) {
+	t.Parallel()
+	engine := Engine{Queue: &FIFOQueue{}, Caps: map[string]int64{"A": 40}, CapPeriod: 10}
+	tr := engine.Simulate([]Process{
+		{ProcessID: 1, ArrivalTime: 0, BurstDuration: 8, Group: "A"},
+		{ProcessID: 2, ArrivalTime: 0, BurstDuration: 4},
+	})
+	want := []TimeSlice{
+		{PID: 1, Start: 0, Stop: 4},
+		{PID: 2, Start: 4, Stop: 8},
+		{PID: IdlePID, Start: 8, Stop: 10},
+		{PID: 1, Start: 10, Stop: 14},
+	}
+	if !reflect.DeepEqual(tr.Gantt, want) {
+		t.Errorf("Simulate() gantt = %v, want %v", tr.Gantt, want)
+	}
+	var throttles, unthrottles int
+	for _, ev := range tr.Events {
+		switch ev.Kind {
+		case EventThrottle:
+			throttles++
+		case EventUnthrottle:
+			unthrottles++
+		}
+	}
+	if throttles != 1 || unthrottles != 1 {
+		t.Errorf("Simulate() throttled %d and unthrottled %d times, want 1 and 1", throttles, unthrottles)
+	}
+}
+
+func TestEngine_IO(t *testing.T) {
+	t.Parallel()
+	// P1 runs 2, does 3 of I/O, then runs 2 more.
+	p1 := Process{ProcessID: 1, ArrivalTime: 0, BurstDuration: 4, IO: []IOBurst{{At: 2, Duration: 3}}}
+	p2 := Process{ProcessID: 2, ArrivalTime: 0, BurstDuration: 6}
+	tests := []struct {
+		name      string
+		scheduler string
+		params    SchedulerParams
+		processes []Process
+		want      []TimeSlice
+		wantWait  int64
+	}{
+		{
+			name:      "idle during io",
+			scheduler: "fcfs",
+			processes: []Process{p1},
+			want:      []TimeSlice{{PID: 1, Start: 0, Stop: 2}, {PID: IdlePID, Start: 2, Stop: 5}, {PID: 1, Start: 5, Stop: 7}},
+		},
+		{
+			name:      "fcfs queues the return",
+			scheduler: "fcfs",
+			processes: []Process{p1, p2},
+			want:      []TimeSlice{{PID: 1, Start: 0, Stop: 2}, {PID: 2, Start: 2, Stop: 8}, {PID: 1, Start: 8, Stop: 10}},
+			wantWait:  3,
+		},
+		{
+			name:      "srtf preempts on the return",
+			scheduler: "srtf",
+			processes: []Process{p1, p2},
+			want: []TimeSlice{
+				{PID: 1, Start: 0, Stop: 2},
+				{PID: 2, Start: 2, Stop: 5},
+				{PID: 1, Start: 5, Stop: 7},
+				{PID: 2, Start: 7, Stop: 10},
+			},
+		},
+		{
+			name:      "two cpus",
+			scheduler: "rr",
+			params:    SchedulerParams{CPUs: 2},
+			processes: []Process{p1, p2},
+			want: []TimeSlice{
+				{PID: 1, Start: 0, Stop: 2},
+				{PID: 2, Start: 2, Stop: 4},
+				{PID: 2, Start: 4, Stop: 6},
+				{PID: IdlePID, Start: 6, Stop: 7},
+				{PID: 2, Start: 0, Stop: 2, CPU: 1},
+				{PID: IdlePID, Start: 2, Stop: 5, CPU: 1},
+				{PID: 1, Start: 5, Stop: 7, CPU: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := RunSchedulerParams(tt.scheduler, tt.params, tt.processes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result.Gantt, tt.want) {
+				t.Errorf("gantt = %v, want %v", result.Gantt, tt.want)
+			}
+			if got := result.Processes[0].Wait; got != tt.wantWait {
+				t.Errorf("P1 waited %d, want %d with its I/O not counted", got, tt.wantWait)
+			}
+		})
+	}
+}
+
+func TestEngine_PredictedBursts(t *testing.T) {
+	t.Parallel()
+	// P1 runs in bursts of 2 with 1 of I/O between them; P2 runs 5 at once.
+	// Both are first predicted DefaultBurstGuess, but by P2's arrival P1's
+	// first burst has brought its prediction down to 6, so it goes first.
+	processes := []Process{
+		{ProcessID: 1, BurstDuration: 6, IO: []IOBurst{{At: 2, Duration: 1}, {At: 4, Duration: 1}}},
+		{ProcessID: 2, ArrivalTime: 3, BurstDuration: 5},
+	}
+	result, err := RunSchedulerParams("sjf-predict", SchedulerParams{Alpha: 0.5}, processes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantGantt := []TimeSlice{
+		{PID: 1, Start: 0, Stop: 2},
+		{PID: IdlePID, Start: 2, Stop: 3},
+		{PID: 1, Start: 3, Stop: 5},
+		{PID: 2, Start: 5, Stop: 10},
+		{PID: 1, Start: 10, Stop: 12},
+	}
+	if !reflect.DeepEqual(result.Gantt, wantGantt) {
+		t.Errorf("gantt = %v, want %v", result.Gantt, wantGantt)
+	}
+	wantPredictions := []BurstPrediction{
+		{PID: 1, Burst: 1, Predicted: 10, Actual: 2},
+		{PID: 1, Burst: 2, Predicted: 6, Actual: 2},
+		{PID: 2, Burst: 1, Predicted: 10, Actual: 5},
+		{PID: 1, Burst: 3, Predicted: 4, Actual: 2},
+	}
+	if !reflect.DeepEqual(result.Predictions, wantPredictions) {
+		t.Errorf("predictions = %+v, want %+v", result.Predictions, wantPredictions)
+	}
+	if got := result.Processes[0].PredictionError; got != 14.0/3 {
+		t.Errorf("P1's prediction error = %v, want %v", got, 14.0/3)
+	}
+	if result.Alpha != 0.5 {
+		t.Errorf("alpha = %v, want 0.5", result.Alpha)
+	}
+	if _, err := RunSchedulerParams("sjf-predict", SchedulerParams{Alpha: 1.5}, processes); !errors.Is(err, ErrInvalidArgs) {
+		t.Errorf("alpha 1.5 = %v, want ErrInvalidArgs", err)
+	}
+}
+
+func TestEngine_Guaranteed(t *testing.T) {
+	t.Parallel()
+	// P2 arrives entitled to nothing and so goes first. From then on the
+	// two share the CPU, each entitled to half of it, until P2 is done at 4
+	// having been entitled to 1.5, and P1 has the rest.
+	processes := []Process{
+		{ProcessID: 1, BurstDuration: 4},
+		{ProcessID: 2, ArrivalTime: 1, BurstDuration: 2},
+	}
+	result, err := RunSchedulerParams("guaranteed", SchedulerParams{Quantum: 1}, processes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TimeSlice{
+		{PID: 1, Start: 0, Stop: 1},
+		{PID: 2, Start: 1, Stop: 2},
+		{PID: 1, Start: 2, Stop: 3},
+		{PID: 2, Start: 3, Stop: 4},
+		{PID: 1, Start: 4, Stop: 6},
+	}
+	if got := MergeSlices(result.Gantt); !reflect.DeepEqual(got, want) {
+		t.Errorf("gantt = %v, want %v", got, want)
+	}
+	for i, want := range []float64{4.5, 1.5} {
+		if got := result.Processes[i].Entitlement; got != want {
+			t.Errorf("P%d entitled to %v, want %v", i+1, got, want)
+		}
+	}
+}
+
+func TestEngine_Semaphores(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		quantum     int64
+		wakeup      WakeupPolicy
+		sems        map[string]int64
+		processes   []Process
+		want        []TimeSlice
+		wantBlocked []int64
+	}{
+		{
+			name: "signal wakes the waiter",
+			sems: map[string]int64{"s": 0},
+			processes: []Process{
+				{ProcessID: 1, BurstDuration: 2, Ops: []SyncOp{{At: 1, Op: SemWait, Object: "s"}}},
+				{ProcessID: 2, BurstDuration: 3, Ops: []SyncOp{{At: 2, Op: SemSignal, Object: "s"}}},
+			},
+			want: []TimeSlice{
+				{PID: 1, Start: 0, Stop: 1},
+				{PID: 2, Start: 1, Stop: 4},
+				{PID: 1, Start: 4, Stop: 5},
+			},
+		},
+		{
+			name:   "fifo wakeup",
+			wakeup: WakeFIFO,
+			processes: []Process{
+				{ProcessID: 1, BurstDuration: 1, Ops: []SyncOp{{Op: SemWait, Object: "s"}}},
+				{ProcessID: 2, BurstDuration: 1, Ops: []SyncOp{{Op: SemWait, Object: "s"}}},
+				{ProcessID: 3, BurstDuration: 2, Ops: []SyncOp{{At: 0, Op: SemSignal, Object: "s"}, {At: 1, Op: SemSignal, Object: "s"}}},
+			},
+			want: []TimeSlice{
+				{PID: 3, Start: 0, Stop: 2},
+				{PID: 1, Start: 2, Stop: 3},
+				{PID: 2, Start: 3, Stop: 4},
+			},
+		},
+		{
+			name:   "lifo wakeup",
+			wakeup: WakeLIFO,
+			processes: []Process{
+				{ProcessID: 1, BurstDuration: 1, Ops: []SyncOp{{Op: SemWait, Object: "s"}}},
+				{ProcessID: 2, BurstDuration: 1, Ops: []SyncOp{{Op: SemWait, Object: "s"}}},
+				{ProcessID: 3, BurstDuration: 2, Ops: []SyncOp{{At: 0, Op: SemSignal, Object: "s"}, {At: 1, Op: SemSignal, Object: "s"}}},
+			},
+			want: []TimeSlice{
+				{PID: 3, Start: 0, Stop: 2},
+				{PID: 2, Start: 2, Stop: 3},
+				{PID: 1, Start: 3, Stop: 4},
+			},
+		},
+		{
+			name:   "priority wakeup",
+			wakeup: WakePriority,
+			processes: []Process{
+				{ProcessID: 1, BurstDuration: 1, Priority: 2, Ops: []SyncOp{{Op: SemWait, Object: "s"}}},
+				{ProcessID: 2, BurstDuration: 1, Priority: 1, Ops: []SyncOp{{Op: SemWait, Object: "s"}}},
+				{ProcessID: 3, BurstDuration: 2, Ops: []SyncOp{{At: 0, Op: SemSignal, Object: "s"}, {At: 1, Op: SemSignal, Object: "s"}}},
+			},
+			want: []TimeSlice{
+				{PID: 3, Start: 0, Stop: 2},
+				{PID: 2, Start: 2, Stop: 3},
+				{PID: 1, Start: 3, Stop: 4},
+			},
+		},
+		{
+			name:    "mutex hands off on unlock",
+			quantum: 1,
+			processes: []Process{
+				{ProcessID: 1, BurstDuration: 3, Ops: []SyncOp{{At: 0, Op: MutexLock, Object: "m"}, {At: 2, Op: MutexUnlock, Object: "m"}}},
+				{ProcessID: 2, BurstDuration: 2, Ops: []SyncOp{{At: 0, Op: MutexUnlock, Object: "m"}, {At: 0, Op: MutexLock, Object: "m"}, {At: 2, Op: MutexUnlock, Object: "m"}}},
+			},
+			want: []TimeSlice{
+				{PID: 1, Start: 0, Stop: 1},
+				{PID: 1, Start: 1, Stop: 2},
+				{PID: 2, Start: 2, Stop: 3},
+				{PID: 1, Start: 3, Stop: 4},
+				{PID: 2, Start: 4, Stop: 5},
+			},
+		},
+		{
+			name:    "deadlock",
+			quantum: 1,
+			sems:    map[string]int64{"a": 1, "b": 1},
+			processes: []Process{
+				{ProcessID: 1, BurstDuration: 3, Ops: []SyncOp{{At: 0, Op: SemWait, Object: "a"}, {At: 2, Op: SemWait, Object: "b"}}},
+				{ProcessID: 2, BurstDuration: 3, Ops: []SyncOp{{At: 0, Op: SemWait, Object: "b"}, {At: 2, Op: SemWait, Object: "a"}}},
+			},
+			want: []TimeSlice{
+				{PID: 1, Start: 0, Stop: 1},
+				{PID: 2, Start: 1, Stop: 2},
+				{PID: 1, Start: 2, Stop: 3},
+				{PID: 2, Start: 3, Stop: 4},
+			},
+			wantBlocked: []int64{1, 2},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			engine := Engine{Queue: &FIFOQueue{}, Quantum: tt.quantum, Semaphores: tt.sems, Wakeup: tt.wakeup}
+			tr := engine.Simulate(tt.processes)
+			if !reflect.DeepEqual(tr.Gantt, tt.want) {
+				t.Errorf("Simulate() gantt = %v, want %v", tr.Gantt, tt.want)
+			}
+			var blocked []int64
+			for _, task := range tr.Blocked {
+				blocked = append(blocked, task.ProcessID)
+			}
+			if !reflect.DeepEqual(blocked, tt.wantBlocked) {
+				t.Errorf("Simulate() blocked = %v, want %v", blocked, tt.wantBlocked)
+			}
+		})
+	}
+}
+
+func TestEngine_PriorityInheritance(t *testing.T) {
+	t.Parallel()
+	// Low locks r, high blocks on it, and medium, needing no lock, would
+	// run ahead of low and so of high too.
+	inversion := []Process{
+		{ProcessID: 1, BurstDuration: 5, Priority: 3, Ops: []SyncOp{{At: 1, Op: MutexLock, Object: "r"}, {At: 4, Op: MutexUnlock, Object: "r"}}},
+		{ProcessID: 2, BurstDuration: 3, ArrivalTime: 2, Priority: 1, Ops: []SyncOp{{At: 1, Op: MutexLock, Object: "r"}, {At: 2, Op: MutexUnlock, Object: "r"}}},
+		{ProcessID: 3, BurstDuration: 6, ArrivalTime: 3, Priority: 2},
+	}
+	// 3 blocks on b, held by 2, which is blocked on a, held by 1.
+	chain := []Process{
+		{ProcessID: 1, BurstDuration: 6, Priority: 5, Ops: []SyncOp{{At: 1, Op: MutexLock, Object: "a"}, {At: 4, Op: MutexUnlock, Object: "a"}}},
+		{ProcessID: 2, BurstDuration: 4, ArrivalTime: 2, Priority: 3, Ops: []SyncOp{
+			{At: 0, Op: MutexLock, Object: "b"}, {At: 1, Op: MutexLock, Object: "a"}, {At: 2, Op: MutexUnlock, Object: "a"}, {At: 3, Op: MutexUnlock, Object: "b"},
+		}},
+		{ProcessID: 3, BurstDuration: 3, ArrivalTime: 4, Priority: 1, Ops: []SyncOp{{At: 1, Op: MutexLock, Object: "b"}, {At: 2, Op: MutexUnlock, Object: "b"}}},
+	}
+	tests := []struct {
+		name       string
+		inherit    bool
+		processes  []Process
+		want       []TimeSlice
+		wantWaits  []LockWait
+		wantRaises []PriorityChange
+	}{
+		{
+			name:      "inversion",
+			processes: inversion,
+			want: []TimeSlice{
+				{PID: 1, Start: 0, Stop: 2},
+				{PID: 2, Start: 2, Stop: 3},
+				{PID: 3, Start: 3, Stop: 9},
+				{PID: 1, Start: 9, Stop: 12},
+				{PID: 2, Start: 12, Stop: 14},
+			},
+			wantWaits: []LockWait{{PID: 2, Lock: "r", Holder: 1, Start: 3, Stop: 11}},
+		},
+		{
+			name:      "inheritance",
+			inherit:   true,
+			processes: inversion,
+			want: []TimeSlice{
+				{PID: 1, Start: 0, Stop: 2},
+				{PID: 2, Start: 2, Stop: 3},
+				{PID: 1, Start: 3, Stop: 5},
+				{PID: 2, Start: 5, Stop: 7},
+				{PID: 3, Start: 7, Stop: 13},
+				{PID: 1, Start: 13, Stop: 14},
+			},
+			wantWaits: []LockWait{{PID: 2, Lock: "r", Holder: 1, Start: 3, Stop: 5}},
+			wantRaises: []PriorityChange{
+				{Time: 3, PID: 1, From: 3, To: 1, Lock: "r"},
+				{Time: 5, PID: 1, From: 1, To: 3, Lock: "r"},
+			},
+		},
+		{
+			name:      "transitive",
+			inherit:   true,
+			processes: chain,
+			want: []TimeSlice{
+				{PID: 1, Start: 0, Stop: 2},
+				{PID: 2, Start: 2, Stop: 3},
+				{PID: 1, Start: 3, Stop: 4},
+				{PID: 3, Start: 4, Stop: 5},
+				{PID: 1, Start: 5, Stop: 6},
+				{PID: 2, Start: 6, Stop: 8},
+				{PID: 3, Start: 8, Stop: 10},
+				{PID: 2, Start: 10, Stop: 11},
+				{PID: 1, Start: 11, Stop: 13},
+			},
+			wantWaits: []LockWait{
+				{PID: 2, Lock: "a", Holder: 1, Start: 3, Stop: 6},
+				{PID: 3, Lock: "b", Holder: 2, Start: 5, Stop: 8},
+			},
+			wantRaises: []PriorityChange{
+				{Time: 3, PID: 1, From: 5, To: 3, Lock: "a"},
+				{Time: 5, PID: 2, From: 3, To: 1, Lock: "b"},
+				{Time: 5, PID: 1, From: 3, To: 1, Lock: "a"},
+				{Time: 6, PID: 1, From: 1, To: 5, Lock: "a"},
+				{Time: 8, PID: 2, From: 1, To: 3, Lock: "b"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			engine := newStaticPriorityEngine()
+			engine.Inherit = tt.inherit
+			tr := engine.Simulate(tt.processes)
+			if !reflect.DeepEqual(tr.Gantt, tt.want) {
+				t.Errorf("Simulate() gantt = %v, want %v", tr.Gantt, tt.want)
+			}
+			if !reflect.DeepEqual(tr.LockWaits, tt.wantWaits) {
+				t.Errorf("Simulate() lock waits = %+v, want %+v", tr.LockWaits, tt.wantWaits)
+			}
+			if !reflect.DeepEqual(tr.Inheritances, tt.wantRaises) {
+				t.Errorf("Simulate() inheritances = %+v, want %+v", tr.Inheritances, tt.wantRaises)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package scheduler
+
+import (
+	"fmt"
+	"math"
+)
+
+//region Multilevel queue scheduling
+
+// Ways a multilevel queue serves its queues.
+const (
+	// MLQStrict always serves the first queue with a task ready, and a task
+	// arriving in an earlier queue preempts one from a later queue.
+	MLQStrict = "strict"
+	// MLQWeighted serves the queues in turn, each for its Slice.
+	MLQWeighted = "weighted"
+)
+
+type (
+	// MLQConfig declares a multilevel queue scheduler: how it serves its
+	// Queues, MLQStrict by default, and the queues in order of precedence.
+	MLQConfig struct {
+		Service string     `json:"service,omitempty"`
+		Queues  []MLQLevel `json:"queues"`
+	}
+	// MLQLevel is one queue of a multilevel queue. It holds the processes
+	// whose Priority is from MinPriority to MaxPriority and orders them by
+	// Policy, fcfs, sjf or rr, the last with Quantum or else the run's
+	// quantum. Slice is how long the queue is served per turn under
+	// MLQWeighted service. A process in no queue's band joins the last.
+	MLQLevel struct {
+		Name        string `json:"name"`
+		MinPriority int64  `json:"min_priority"`
+		MaxPriority int64  `json:"max_priority"`
+		Policy      string `json:"policy"`
+		Quantum     int64  `json:"quantum,omitempty"`
+		Slice       int64  `json:"slice,omitempty"`
+	}
+	// mlqQueue is the ready queue of a multilevel queue scheduler, a queue
+	// per level. Under weighted service turn is the level being served and
+	// left what remains of its turn, which setTime charges as the clock runs
+	// while a task it handed out is dispatched. With an AgingPolicy, rate
+	// and floor are its Every and Cap, and aged counts the multiples of rate
+	// the clock has passed, each a boost to every waiting task.
+	mlqQueue struct {
+		config MLQConfig
+		levels []ReadyQueue
+		slices []int64
+		turn   int
+		left   int64
+		active bool
+		clock  int64
+		rate   int64
+		floor  int64
+		aged   int64
+	}
+)
+
+// defaultMLQConfig is the multilevel queue used without a configuration
+// file: priorities 0 and 1 are interactive, round-robin with the run's
+// quantum, and every other a batch job served first-come, first-serve only
+// when no interactive one is ready.
+var defaultMLQConfig = MLQConfig{
+	Service: MLQStrict,
+	Queues: []MLQLevel{
+		{Name: "interactive", MinPriority: math.MinInt64, MaxPriority: 1, Policy: "rr"},
+		{Name: "batch", MinPriority: 2, MaxPriority: math.MaxInt64, Policy: "fcfs"},
+	},
+}
+
+// Validate reports the first problem with c, or nil.
+func (c MLQConfig) Validate() error {
+	if c.Service != "" && c.Service != MLQStrict && c.Service != MLQWeighted {
+		return fmt.Errorf("%w: unknown service %q, want %s or %s", ErrInvalidArgs, c.Service, MLQStrict, MLQWeighted)
+	}
+	if len(c.Queues) == 0 {
+		return fmt.Errorf("%w: no queues", ErrInvalidArgs)
+	}
+	names := make(map[string]bool, len(c.Queues))
+	for i, l := range c.Queues {
+		switch {
+		case l.Name == "":
+			return fmt.Errorf("%w: queue %d has no name", ErrInvalidArgs, i+1)
+		case names[l.Name]:
+			return fmt.Errorf("%w: queue %q given twice", ErrInvalidArgs, l.Name)
+		case l.MinPriority > l.MaxPriority:
+			return fmt.Errorf("%w: queue %q: min priority %d is above max priority %d", ErrInvalidArgs, l.Name, l.MinPriority, l.MaxPriority)
+		case l.Policy != "fcfs" && l.Policy != "sjf" && l.Policy != "rr":
+			return fmt.Errorf("%w: queue %q: unknown policy %q, want fcfs, sjf or rr", ErrInvalidArgs, l.Name, l.Policy)
+		case l.Quantum < 0:
+			return fmt.Errorf("%w: queue %q: quantum %d is negative", ErrInvalidArgs, l.Name, l.Quantum)
+		case l.Quantum > 0 && l.Policy != "rr":
+			return fmt.Errorf("%w: queue %q: only rr takes a quantum", ErrInvalidArgs, l.Name)
+		case c.Service == MLQWeighted && l.Slice < 1:
+			return fmt.Errorf("%w: queue %q: weighted service needs a slice of at least 1", ErrInvalidArgs, l.Name)
+		case c.Service != MLQWeighted && l.Slice != 0:
+			return fmt.Errorf("%w: queue %q: only weighted service takes a slice", ErrInvalidArgs, l.Name)
+		}
+		names[l.Name] = true
+		for _, earlier := range c.Queues[:i] {
+			if l.MinPriority <= earlier.MaxPriority && earlier.MinPriority <= l.MaxPriority {
+				return fmt.Errorf("%w: queues %q and %q share priorities", ErrInvalidArgs, earlier.Name, l.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// newMLQEngine makes an engine for the multilevel queue c, or
+// defaultMLQConfig if c is nil, whose rr queues without a quantum of their
+// own take quantum. Aging, if set, lifts waiting tasks up through the
+// queues' bands.
+func newMLQEngine(c *MLQConfig, quantum int64, aging *AgingPolicy) *Engine {
+	if c == nil {
+		c = &defaultMLQConfig
+	}
+	q := &mlqQueue{config: *c, levels: make([]ReadyQueue, len(c.Queues)), slices: make([]int64, len(c.Queues))}
+	for i, l := range c.Queues {
+		switch l.Policy {
+		case "sjf":
+			q.levels[i] = &heapQueue{less: byRemaining}
+		default:
+			q.levels[i] = &FIFOQueue{}
+		}
+		switch {
+		case l.Policy != "rr":
+			q.slices[i] = math.MaxInt64
+		case l.Quantum > 0:
+			q.slices[i] = l.Quantum
+		default:
+			q.slices[i] = quantum
+		}
+	}
+	q.turn, q.left = -1, 0
+	if aging != nil {
+		q.rate, q.floor = aging.Every, aging.Cap
+	}
+	e := &Engine{Queue: q, Quantum: quantum}
+	if c.Service != MLQWeighted {
+		e.Preempt = func(running, arrived *Task) bool {
+			return q.level(arrived.effectivePriority()) < q.level(running.effectivePriority())
+		}
+	}
+	return e
+}
+
+// level is the index of the queue holding tasks of the given Priority.
+func (q *mlqQueue) level(priority int64) int {
+	for i, l := range q.config.Queues {
+		if priority >= l.MinPriority && priority <= l.MaxPriority {
+			return i
+		}
+	}
+	return len(q.levels) - 1
+}
+
+func (q *mlqQueue) Push(t *Task) { q.levels[q.level(t.effectivePriority())].Push(t) }
+
+func (q *mlqQueue) Pop() *Task {
+	q.active = false
+	if q.config.Service != MLQWeighted {
+		for _, l := range q.levels {
+			if l.Len() > 0 {
+				return l.Pop()
+			}
+		}
+		return nil
+	}
+	if q.turn >= 0 && q.left > 0 && q.levels[q.turn].Len() > 0 {
+		return q.levels[q.turn].Pop()
+	}
+	// The turn is up or its queue empty: on to the next queue with a task.
+	for i := 1; i <= len(q.levels); i++ {
+		next := (q.turn + i) % len(q.levels)
+		if q.levels[next].Len() > 0 {
+			q.turn, q.left = next, q.config.Queues[next].Slice
+			return q.levels[next].Pop()
+		}
+	}
+	return nil
+}
+
+func (q *mlqQueue) Remove(t *Task) bool { return q.levels[q.level(t.effectivePriority())].Remove(t) }
+
+func (q *mlqQueue) Len() int {
+	n := 0
+	for _, l := range q.levels {
+		n += l.Len()
+	}
+	return n
+}
+
+// slice is the quantum of t's queue, or its length if it takes none, cut
+// short under weighted service to what is left of the queue's turn.
+func (q *mlqQueue) slice(t *Task, now int64) int64 {
+	s := q.slices[q.level(t.effectivePriority())]
+	if q.config.Service == MLQWeighted {
+		q.active, q.clock = true, now
+		if q.left < s {
+			s = q.left
+		}
+	}
+	return s
+}
+
+// setTime charges the time since the last call to the queue whose turn it
+// is, while a task it handed out runs, and ages the waiting tasks for every
+// multiple of the aging rate the clock has passed.
+func (q *mlqQueue) setTime(now int64) {
+	if q.active {
+		q.left -= now - q.clock
+	}
+	q.clock = now
+	if q.rate > 0 && now/q.rate > q.aged {
+		q.age(now/q.rate - q.aged)
+		q.aged = now / q.rate
+	}
+}
+
+// age boosts every waiting task n steps, or as far as the floor, moving
+// those it lifts into a higher band to the back of that band's queue.
+func (q *mlqQueue) age(n int64) {
+	var waiting []*Task
+	for _, l := range q.levels {
+		for l.Len() > 0 {
+			waiting = append(waiting, l.Pop())
+		}
+	}
+	for _, t := range waiting {
+		if gain := t.effectivePriority() - q.floor; gain > 0 {
+			if gain > n {
+				gain = n
+			}
+			t.Boosts += gain
+		}
+		q.Push(t)
+	}
+}
+
+// preemptAt is never: a turn ends with its task's slice.
+func (q *mlqQueue) preemptAt(*Task) int64 { return -1 }
+
+//endregion
@@ -0,0 +1,220 @@
+package scheduler
+
+import "fmt"
+
+//region Multiprocessor scheduling
+
+type (
+	// MultiCPU simulates CPUs identical processors sharing one ready queue.
+	// Free CPUs take the queue's next task in CPU order, and a task runs to
+	// completion or, if Quantum is set, for at most a quantum before it goes
+	// back in the queue. Tasks leave their CPU for I/O bursts and queue
+	// again when those are done. Every dispatch but a task carrying straight
+	// on costs SwitchCost first, as on one CPU. Yields, sync operations and
+	// group caps are single-CPU engine features it ignores. TieBreak and
+	// AssumeSorted, NiceWeights and PriorityOrder are as on one CPU.
+	MultiCPU struct {
+		CPUs          int
+		Queue         ReadyQueue
+		Quantum       int64
+		SwitchCost    int64
+		TieBreak      TieBreak
+		AssumeSorted  bool
+		NiceWeights   map[int64]int64
+		PriorityOrder PriorityOrder
+	}
+	// CPUStats is one CPU's share of a multiprocessor run. Utilization is
+	// Busy over the time to the last completion.
+	CPUStats struct {
+		CPU         int     `json:"cpu"`
+		Busy        int64   `json:"busy"`
+		Utilization float64 `json:"utilization"`
+	}
+	// cpuState is what one CPU is doing: running task from start until
+	// stop, or nothing if task is nil.
+	cpuState struct {
+		task        *Task
+		start, stop int64
+		last        TimeSlice
+	}
+)
+
+// Simulate runs processes across the CPUs. Each TimeSlice in the trace's
+// Gantt chart has the CPU it ran on, and gaps on a CPU are idle slices.
+func (m *MultiCPU) Simulate(processes []Process) Trace {
+	var (
+		tr      = Trace{Tasks: make([]*Task, len(processes))}
+		tasks   = make([]Task, len(processes))
+		pending = make([]*Task, len(processes))
+		cpus    = make([]cpuState, m.CPUs)
+		inIO    ioWait
+		now     int64
+		done    int
+	)
+	for i := range processes {
+		tasks[i] = Task{Process: &processes[i], Remaining: processes[i].BurstDuration, FirstRun: -1}
+		tr.Tasks[i] = &tasks[i]
+	}
+	weighByNice(tasks, niceTable(m.NiceWeights), m.PriorityOrder)
+	copy(pending, tr.Tasks)
+	m.TieBreak.sortArrivals(pending, m.AssumeSorted)
+	if q, ok := m.Queue.(tieBrokenQueue); ok {
+		q.breakTiesBy(m.TieBreak)
+	}
+	for i := range cpus {
+		cpus[i].last = TimeSlice{PID: IdlePID, CPU: i}
+	}
+
+	for done < len(tr.Tasks) {
+		for {
+			if len(inIO) > 0 && inIO[0].ioDone <= now && (len(pending) == 0 || inIO[0].ioDone < pending[0].ArrivalTime) {
+				t := inIO.pop()
+				m.Queue.Push(t)
+				tr.log(now, EventWake, t.ProcessID, "io")
+				continue
+			}
+			if len(pending) == 0 || pending[0].ArrivalTime > now {
+				break
+			}
+			m.Queue.Push(pending[0])
+			tr.log(now, EventArrive, pending[0].ProcessID, "")
+			pending = pending[1:]
+		}
+		// Tasks stopping now leave their CPUs after the arrivals, as a
+		// quantum expiry does on one CPU.
+		for i := range cpus {
+			c := &cpus[i]
+			if c.task == nil || c.stop != now {
+				continue
+			}
+			t := c.task
+			t.Remaining -= c.stop - c.start
+			t.Used += c.stop - c.start
+			c.task = nil
+			switch {
+			case t.Remaining == 0:
+				t.Exit = now
+				tr.log(now, EventComplete, t.ProcessID, fmt.Sprintf("cpu %d", i))
+				done++
+			case t.untilIO() == 0:
+				d := inIO.push(t, now)
+				tr.log(now, EventBlock, t.ProcessID, fmt.Sprintf("cpu %d io %d", i, d))
+			default:
+				m.Queue.Push(t)
+				tr.log(now, EventPreempt, t.ProcessID, fmt.Sprintf("cpu %d quantum expired", i))
+			}
+		}
+		for i := range cpus {
+			if c := &cpus[i]; c.task == nil && m.Queue.Len() > 0 {
+				m.dispatch(&tr, c, i, m.Queue.Pop(), now)
+			}
+		}
+
+		next := int64(-1)
+		if len(pending) > 0 {
+			next = pending[0].ArrivalTime
+		}
+		if len(inIO) > 0 && (next < 0 || inIO[0].ioDone < next) {
+			next = inIO[0].ioDone
+		}
+		for _, c := range cpus {
+			if c.task != nil && (next < 0 || c.stop < next) {
+				next = c.stop
+			}
+		}
+		if next < 0 {
+			break
+		}
+		now = next
+	}
+	tr.Gantt = fillIdle(tr.Gantt, m.CPUs)
+	return tr
+}
+
+// dispatch starts t on CPU i at now, after a context switch unless t just
+// ran there.
+func (m *MultiCPU) dispatch(tr *Trace, c *cpuState, i int, t *Task, now int64) {
+	start := now
+	carriesOn := c.last.PID == t.ProcessID && c.last.Stop == now
+	if !carriesOn {
+		tr.Switches++
+	}
+	if m.SwitchCost > 0 && !carriesOn {
+		tr.Gantt = append(tr.Gantt, TimeSlice{PID: SwitchPID, Start: now, Stop: now + m.SwitchCost, CPU: i})
+		start += m.SwitchCost
+	}
+	run := t.nextBurst()
+	if m.Quantum > 0 && m.Quantum < run {
+		run = m.Quantum
+	}
+	c.task, c.start, c.stop = t, start, start+run
+	if t.FirstRun < 0 {
+		t.FirstRun = start
+	}
+	tr.log(start, EventDispatch, t.ProcessID, fmt.Sprintf("cpu %d", i))
+	c.last = TimeSlice{PID: t.ProcessID, Start: start, Stop: c.stop, CPU: i}
+	tr.Gantt = append(tr.Gantt, c.last)
+}
+
+// fillIdle orders gantt by CPU and fills every gap on a CPU, up to the end
+// of the run, with an idle slice.
+func fillIdle(gantt []TimeSlice, cpus int) []TimeSlice {
+	var end int64
+	rows := make([][]TimeSlice, cpus)
+	for _, s := range gantt {
+		rows[s.CPU] = append(rows[s.CPU], s)
+		if s.Stop > end {
+			end = s.Stop
+		}
+	}
+	filled := make([]TimeSlice, 0, len(gantt)+cpus)
+	for cpu, row := range rows {
+		var at int64
+		for _, s := range row {
+			if s.Start > at {
+				filled = append(filled, TimeSlice{PID: IdlePID, Start: at, Stop: s.Start, CPU: cpu})
+			}
+			filled = append(filled, s)
+			at = s.Stop
+		}
+		if at < end {
+			filled = append(filled, TimeSlice{PID: IdlePID, Start: at, Stop: end, CPU: cpu})
+		}
+	}
+	return filled
+}
+
+// Schedule makes MultiCPU a Scheduler. Utilization is over every CPU, and
+// CPUs has each one's own.
+func (m *MultiCPU) Schedule(processes []Process) RunResult {
+	result := traceResult(m.Simulate(processes), false)
+	result.Quantum, result.SwitchCost, result.Seed = m.Quantum, m.SwitchCost, lotterySeed(m.Queue)
+	result.TieBreak, result.NiceWeights, result.PriorityOrder = m.TieBreak, m.NiceWeights, m.PriorityOrder
+	result.Utilization /= float64(m.CPUs)
+	result.CPUs = CPUUsage(result.Gantt, m.CPUs)
+	return result
+}
+
+// CPUUsage works out each CPU's busy time and utilization from a Gantt chart
+// with idle slices filled in.
+func CPUUsage(gantt []TimeSlice, cpus int) []CPUStats {
+	stats := make([]CPUStats, cpus)
+	var end int64
+	for _, s := range gantt {
+		if s.PID != IdlePID && s.PID != SwitchPID {
+			stats[s.CPU].Busy += s.Stop - s.Start
+		}
+		if s.Stop > end {
+			end = s.Stop
+		}
+	}
+	for i := range stats {
+		stats[i].CPU = i
+		if end > 0 {
+			stats[i].Utilization = float64(stats[i].Busy) / float64(end)
+		}
+	}
+	return stats
+}
+
+//endregion
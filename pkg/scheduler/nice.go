@@ -0,0 +1,133 @@
+package scheduler
+
+import "fmt"
+
+//region Nice values and weights
+
+// PriorityOrder is which way Priority runs, which the proportional-share
+// schedulers need to weigh a process by it: whether a lower value is the
+// better priority or a higher one is.
+type PriorityOrder int
+
+const (
+	// LowerFirst takes a lower Priority value as the better priority, as
+	// Linux's nice values and priorities do.
+	LowerFirst PriorityOrder = iota
+	// HigherFirst takes a higher Priority value as the better priority, as
+	// Windows and Java thread priorities do.
+	HigherFirst
+)
+
+const (
+	// MinNice and MaxNice bound a process's nice value, as on Linux.
+	MinNice = -20
+	MaxNice = 19
+	// maxNiceWeight bounds the weight a nice value can be given, so sums of
+	// weights and a target latency shared out by them cannot overflow.
+	maxNiceWeight = 1 << 30
+)
+
+// NiceWeights is the weight of each nice value from MinNice to MaxNice, in
+// that order.
+type NiceWeights [MaxNice - MinNice + 1]int64
+
+func (o PriorityOrder) String() string {
+	switch o {
+	case LowerFirst:
+		return "lower-first"
+	case HigherFirst:
+		return "higher-first"
+	default:
+		return fmt.Sprintf("PriorityOrder(%d)", int(o))
+	}
+}
+
+// ParsePriorityOrder accepts the names printed by PriorityOrder.String.
+func ParsePriorityOrder(s string) (PriorityOrder, error) {
+	for _, o := range []PriorityOrder{LowerFirst, HigherFirst} {
+		if o.String() == s {
+			return o, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unknown priority order %q, want lower-first or higher-first", ErrInvalidArgs, s)
+}
+
+func (o PriorityOrder) MarshalText() ([]byte, error) { return []byte(o.String()), nil }
+
+func (o *PriorityOrder) UnmarshalText(text []byte) error {
+	var err error
+	*o, err = ParsePriorityOrder(string(text))
+	return err
+}
+
+// DefaultNiceWeights are Linux's weights, those cfs has always used.
+func DefaultNiceWeights() NiceWeights { return cfsWeights }
+
+// NiceWeightTable is DefaultNiceWeights with the weights in overrides, a
+// map of nice value to weight, in place of Linux's. Every nice value must
+// be from MinNice to MaxNice and every weight from 1 to 2^30.
+func NiceWeightTable(overrides map[int64]int64) (NiceWeights, error) {
+	table := cfsWeights
+	for nice, weight := range overrides {
+		switch {
+		case nice < MinNice || nice > MaxNice:
+			return NiceWeights{}, fmt.Errorf("%w: nice value %d is not within %d to %d", ErrInvalidArgs, nice, MinNice, MaxNice)
+		case weight < 1 || weight > maxNiceWeight:
+			return NiceWeights{}, fmt.Errorf("%w: weight %d of nice %d is not within 1 to %d", ErrInvalidArgs, weight, nice, maxNiceWeight)
+		}
+		table[nice-MinNice] = weight
+	}
+	return table, nil
+}
+
+// weighByNice has tasks weighed by table, or by Linux's weights if it is
+// nil, and take their Priority in order where they have no nice value.
+func weighByNice(tasks []Task, table *NiceWeights, order PriorityOrder) {
+	for i := range tasks {
+		tasks[i].weights, tasks[i].order = table, order
+	}
+}
+
+// niceTable is the table a run with the given overrides weighs tasks by, or
+// nil for Linux's. The overrides were checked before the run.
+func niceTable(overrides map[int64]int64) *NiceWeights {
+	if len(overrides) == 0 {
+		return nil
+	}
+	table, _ := NiceWeightTable(overrides)
+	return &table
+}
+
+// ordered is t's Priority with lower values better whatever the run's
+// order, negated if higher priorities come first.
+func (t *Task) ordered() int64 {
+	if t.order == HigherFirst {
+		return -t.Priority
+	}
+	return t.Priority
+}
+
+// nice is the nice value t is weighed by: its own if its process has one,
+// or else its Priority read in the run's order, held to MinNice to MaxNice.
+func (t *Task) nice() int64 {
+	if t.Nice != nil {
+		return *t.Nice
+	}
+	nice := t.ordered()
+	if nice < MinNice {
+		nice = MinNice
+	} else if nice > MaxNice {
+		nice = MaxNice
+	}
+	return nice
+}
+
+// weight is the weight of t's nice value in the run's table.
+func (t *Task) weight() int64 {
+	if t.weights != nil {
+		return t.weights[t.nice()-MinNice]
+	}
+	return cfsWeights[t.nice()-MinNice]
+}
+
+//endregion
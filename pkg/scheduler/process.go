@@ -0,0 +1,133 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+)
+
+type (
+	// Process is one row of a workload, the same for every scheduler and
+	// never modified by one; the state of a process during a run lives in
+	// Task, so copies and concurrent runs cannot see each other's progress.
+	// Deadline, unless 0, is the time by which the process should complete.
+	// Nice, if set, is the process's nice value, from MinNice to MaxNice,
+	// which the proportional-share schedulers weigh it by in place of its
+	// Priority.
+	Process struct {
+		ProcessID     int64
+		ArrivalTime   int64
+		BurstDuration int64
+		Priority      int64
+		Name          string
+		Yields        []int64
+		DonateTo      int64
+		Group         string
+		Ops           []SyncOp
+		IO            []IOBurst
+		Deadline      int64
+		Nice          *int64
+	}
+	// IOBurst is an I/O a process starts once it has had At units of CPU,
+	// leaving the CPU for Duration. BurstDuration counts only CPU time, and a
+	// process's I/O bursts are sorted by At.
+	IOBurst struct {
+		At       int64
+		Duration int64
+	}
+	// TimeSlice is a stretch of the Gantt chart: PID ran, or with IdlePID
+	// nothing did, or with SwitchPID the CPU switched context, from Start
+	// until Stop. CPU is which one, for MultiCPU runs.
+	TimeSlice struct {
+		PID   int64 `json:"pid"`
+		Start int64 `json:"start"`
+		Stop  int64 `json:"stop"`
+		CPU   int   `json:"cpu,omitempty"`
+	}
+)
+
+const (
+	// IdlePID is the PID of a TimeSlice in which the CPU had nothing to run.
+	IdlePID = -1
+	// SwitchPID is the PID of a TimeSlice the CPU spent switching context
+	// to the process that runs next.
+	SwitchPID = -2
+	// MinPriority and MaxPriority bound a process's Priority: cfs reads it
+	// as a nice value, which goes down to -20, and 139 is the lowest of
+	// Linux's priorities. The bounds also keep aging far from overflowing.
+	MinPriority = -20
+	MaxPriority = 139
+)
+
+var (
+	ErrInvalidArgs = errors.New("invalid args")
+	// ErrNoProcesses is for a workload with no rows, which has nothing to
+	// schedule or average.
+	ErrNoProcesses = errors.New("no processes scheduled")
+)
+
+// Problem describes what makes p impossible to schedule, or is "".
+func (p *Process) Problem() string {
+	switch {
+	case p.ProcessID == IdlePID:
+		return fmt.Sprintf("PID %d is reserved for idle time", IdlePID)
+	case p.ProcessID == SwitchPID:
+		return fmt.Sprintf("PID %d is reserved for context switches", SwitchPID)
+	case p.BurstDuration < 1:
+		return fmt.Sprintf("burst %d is not positive", p.BurstDuration)
+	case p.ArrivalTime < 0:
+		return fmt.Sprintf("arrival %d is negative", p.ArrivalTime)
+	case p.Priority < MinPriority || p.Priority > MaxPriority:
+		return fmt.Sprintf("priority %d is not within %d to %d", p.Priority, MinPriority, MaxPriority)
+	case p.Nice != nil && (*p.Nice < MinNice || *p.Nice > MaxNice):
+		return fmt.Sprintf("nice %d is not within %d to %d", *p.Nice, MinNice, MaxNice)
+	case p.Deadline != 0 && p.Deadline <= p.ArrivalTime:
+		return fmt.Sprintf("deadline %d is not after arrival %d", p.Deadline, p.ArrivalTime)
+	}
+	for i, y := range p.Yields {
+		if y < 1 || y >= p.BurstDuration {
+			return fmt.Sprintf("yield at %d is not within burst %d", y, p.BurstDuration)
+		}
+		// A task looks for its next yield past the CPU it has used, so one
+		// out of order would be skipped.
+		if i > 0 && y <= p.Yields[i-1] {
+			return fmt.Sprintf("yield at %d does not come after yield at %d", y, p.Yields[i-1])
+		}
+		for _, b := range p.IO {
+			if b.At == y {
+				return fmt.Sprintf("yield at %d is also an I/O burst", y)
+			}
+		}
+	}
+	for i, b := range p.IO {
+		if b.At < 1 || b.At >= p.BurstDuration || (i > 0 && b.At <= p.IO[i-1].At) || b.Duration < 1 {
+			return fmt.Sprintf("I/O burst %d is not between two CPU bursts", i+1)
+		}
+	}
+	return ""
+}
+
+// Makespan is the completion time of the last task to finish.
+func (tr *Trace) Makespan() int64 {
+	var last int64
+	for _, t := range tr.Tasks {
+		if t.Exit > last {
+			last = t.Exit
+		}
+	}
+	return last
+}
+
+// MergeSlices joins back-to-back slices of the same process, so a chart that
+// splits a run at a quantum boundary with no switch still matches one that
+// does not.
+func MergeSlices(gantt []TimeSlice) []TimeSlice {
+	var merged []TimeSlice
+	for _, s := range gantt {
+		if n := len(merged); n > 0 && merged[n-1].PID == s.PID && merged[n-1].Stop == s.Start {
+			merged[n-1].Stop = s.Stop
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
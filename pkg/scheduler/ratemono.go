@@ -0,0 +1,175 @@
+package scheduler
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+//region Rate-monotonic scheduling
+
+// maxPeriodicJobs bounds the jobs a rate-monotonic run releases over its
+// hyperperiod, which grows with the least common multiple of the periods and
+// so can be enormous for a handful of coprime ones.
+const maxPeriodicJobs = 1_000_000
+
+type (
+	// PeriodicTask releases a job needing WCET units of CPU every Period,
+	// the first at time 0, each due Deadline after its release. A Deadline
+	// of 0 means the end of the period.
+	PeriodicTask struct {
+		ID       int64 `json:"id"`
+		Period   int64 `json:"period"`
+		WCET     int64 `json:"wcet"`
+		Deadline int64 `json:"deadline,omitempty"`
+	}
+	// PeriodicJob is one release of a periodic task and how it fared: Job
+	// counts the task's releases from 1 and Deadline is absolute.
+	PeriodicJob struct {
+		Task     int64 `json:"task"`
+		Job      int64 `json:"job"`
+		Release  int64 `json:"release"`
+		Deadline int64 `json:"deadline"`
+		Exit     int64 `json:"exit"`
+		Response int64 `json:"response"`
+		Missed   bool  `json:"missed,omitempty"`
+	}
+	// RMResult is a rate-monotonic run over one hyperperiod. Utilization is
+	// the tasks' total WCET/Period and Bound Liu and Layland's n(2^(1/n)-1),
+	// at or under which rate monotonic meets every deadline, though only
+	// when every deadline is the end of its period, as BoundApplies says.
+	// Run is the engine's run, its Gantt chart by task ID.
+	RMResult struct {
+		Tasks        []PeriodicTask `json:"tasks"`
+		Hyperperiod  int64          `json:"hyperperiod"`
+		Utilization  float64        `json:"utilization"`
+		Bound        float64        `json:"bound"`
+		BoundApplies bool           `json:"bound_applies"`
+		Jobs         []PeriodicJob  `json:"jobs"`
+		Misses       int            `json:"misses"`
+		Run          RunResult      `json:"run"`
+	}
+)
+
+// RunRateMonotonic releases every job of tasks up to their hyperperiod and
+// runs them preemptively, the task with the shortest period first and ties
+// in the order given, reporting which jobs missed their deadlines.
+func RunRateMonotonic(tasks []PeriodicTask) (RMResult, error) {
+	if len(tasks) == 0 {
+		return RMResult{}, fmt.Errorf("%w: no periodic tasks", ErrInvalidArgs)
+	}
+	seen := make(map[int64]bool, len(tasks))
+	for _, t := range tasks {
+		if problem := t.Problem(); problem != "" {
+			return RMResult{}, fmt.Errorf("%w: task %d: %s", ErrInvalidArgs, t.ID, problem)
+		}
+		if seen[t.ID] {
+			return RMResult{}, fmt.Errorf("%w: task %d given twice", ErrInvalidArgs, t.ID)
+		}
+		seen[t.ID] = true
+	}
+	hyper, err := hyperperiod(tasks)
+	if err != nil {
+		return RMResult{}, err
+	}
+
+	// A task's rank, the Priority of its jobs, is its place by period.
+	order := make([]int, len(tasks))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return tasks[order[i]].Period < tasks[order[j]].Period })
+	rank := make([]int64, len(tasks))
+	for r, i := range order {
+		rank[i] = int64(r)
+	}
+
+	result := RMResult{Tasks: tasks, Hyperperiod: hyper, BoundApplies: true}
+	var processes []Process
+	for i, t := range tasks {
+		result.Utilization += float64(t.WCET) / float64(t.Period)
+		result.BoundApplies = result.BoundApplies && t.RelativeDeadline() == t.Period
+		for release := int64(0); release < hyper; release += t.Period {
+			processes = append(processes, Process{
+				ProcessID:     t.ID,
+				ArrivalTime:   release,
+				BurstDuration: t.WCET,
+				Priority:      rank[i],
+				Deadline:      release + t.RelativeDeadline(),
+			})
+		}
+	}
+	n := float64(len(tasks))
+	result.Bound = n * (math.Pow(2, 1/n) - 1)
+
+	result.Run = namedResult("rm", newStaticPriorityEngine().Schedule(processes))
+	result.Misses = result.Run.DeadlineMisses
+	result.Jobs = make([]PeriodicJob, len(processes))
+	jobs := make(map[int64]int64, len(tasks))
+	for i, m := range result.Run.Processes {
+		jobs[m.PID]++
+		result.Jobs[i] = PeriodicJob{
+			Task:     m.PID,
+			Job:      jobs[m.PID],
+			Release:  m.Arrival,
+			Deadline: m.Deadline,
+			Exit:     m.Exit,
+			Response: m.Turnaround,
+			Missed:   m.Lateness > 0,
+		}
+	}
+	return result, nil
+}
+
+// Problem describes what is wrong with t, or is "" if nothing is.
+func (t PeriodicTask) Problem() string {
+	switch {
+	case t.Period < 1:
+		return fmt.Sprintf("period %d is not positive", t.Period)
+	case t.WCET < 1:
+		return fmt.Sprintf("WCET %d is not positive", t.WCET)
+	case t.Deadline < 0:
+		return fmt.Sprintf("deadline %d is negative", t.Deadline)
+	case t.Deadline > t.Period:
+		return fmt.Sprintf("deadline %d is after period %d", t.Deadline, t.Period)
+	case t.WCET > t.RelativeDeadline():
+		return fmt.Sprintf("WCET %d does not fit before deadline %d", t.WCET, t.RelativeDeadline())
+	}
+	return ""
+}
+
+// RelativeDeadline is how long after its release each of t's jobs is due.
+func (t PeriodicTask) RelativeDeadline() int64 {
+	if t.Deadline == 0 {
+		return t.Period
+	}
+	return t.Deadline
+}
+
+// hyperperiod is the least common multiple of the tasks' periods, after
+// which their releases repeat, as long as it releases no more than
+// maxPeriodicJobs jobs.
+func hyperperiod(tasks []PeriodicTask) (int64, error) {
+	tooLong := fmt.Errorf("%w: hyperperiod releases more than %d jobs", ErrInvalidArgs, maxPeriodicJobs)
+	hyper := int64(1)
+	for _, t := range tasks {
+		a, b := hyper, t.Period
+		for b != 0 {
+			a, b = b, a%b
+		}
+		step := hyper / a
+		if step > math.MaxInt64/t.Period {
+			return 0, tooLong
+		}
+		hyper = step * t.Period
+	}
+	var jobs int64
+	for _, t := range tasks {
+		if jobs += hyper / t.Period; jobs > maxPeriodicJobs {
+			return 0, tooLong
+		}
+	}
+	return hyper, nil
+}
+
+//endregion
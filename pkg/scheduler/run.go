@@ -0,0 +1,725 @@
+package scheduler
+
+import (
+	"container/heap"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// RunFCFS runs processes in order of arrival, those arriving together in
+// input order, while the metrics keep the input order.
+func RunFCFS(processes []Process) RunResult {
+	var (
+		serviceTime int64
+		result      = RunResult{
+			Scheduler: "fcfs",
+			Gantt:     make([]TimeSlice, 0, len(processes)),
+			Processes: make([]ProcessMetrics, len(processes)),
+		}
+	)
+	for _, i := range arrivalOrder(processes) {
+		if processes[i].ArrivalTime > serviceTime {
+			result.Gantt = append(result.Gantt, TimeSlice{PID: IdlePID, Start: serviceTime, Stop: processes[i].ArrivalTime})
+			serviceTime = processes[i].ArrivalTime
+		}
+		start := serviceTime
+		serviceTime += processes[i].BurstDuration
+		result.Processes[i] = RunToCompletion(&processes[i], start)
+		result.Gantt = append(result.Gantt, TimeSlice{
+			PID:   processes[i].ProcessID,
+			Start: start,
+			Stop:  serviceTime,
+		})
+	}
+	result.summarize()
+	return result
+}
+
+// arrivalOrder indexes processes in arrival order, so nothing is copied and
+// each row lands at its process's own index. Processes arriving together keep
+// their order in the input.
+func arrivalOrder(processes []Process) []int {
+	byArrival := make([]int, len(processes))
+	for i := range byArrival {
+		byArrival[i] = i
+	}
+	sort.SliceStable(byArrival, func(i, j int) bool {
+		return processes[byArrival[i]].ArrivalTime < processes[byArrival[j]].ArrivalTime
+	})
+	return byArrival
+}
+
+// CheckArrivalOrder is an error naming the first process listed after one
+// that arrives later, or nil if processes are in arrival order, as a run that
+// assumes them sorted needs.
+func CheckArrivalOrder(processes []Process) error {
+	for i := 1; i < len(processes); i++ {
+		if processes[i].ArrivalTime < processes[i-1].ArrivalTime {
+			return fmt.Errorf("%w: process %d arrives at %d, before process %d listed ahead of it at %d; sort the workload by arrival or let the run sort it",
+				ErrInvalidArgs, processes[i].ProcessID, processes[i].ArrivalTime, processes[i-1].ProcessID, processes[i-1].ArrivalTime)
+		}
+	}
+	return nil
+}
+
+// RunToCompletion is the metrics of p when it runs its whole burst from start.
+func RunToCompletion(p *Process, start int64) ProcessMetrics {
+	wait := start - p.ArrivalTime
+	return ProcessMetrics{
+		PID:        p.ProcessID,
+		Name:       p.Name,
+		Arrival:    p.ArrivalTime,
+		Burst:      p.BurstDuration,
+		Priority:   p.Priority,
+		Response:   wait,
+		Wait:       wait,
+		Turnaround: wait + p.BurstDuration,
+		Exit:       start + p.BurstDuration,
+	}
+}
+
+// RunSRTF runs the process with the shortest remaining burst, preempting it
+// whenever one arrives with less left to run.
+func RunSRTF(processes []Process) RunResult {
+	return namedResult("srtf", newSRTFEngine().Schedule(processes))
+}
+
+// RunPriorityPreemptive runs the process with the lowest Priority value,
+// preempting it whenever one arrives with a strictly lower one, so equal
+// priorities run in arrival order. Unlike RunPreemptivePriority nothing ages.
+func RunPriorityPreemptive(processes []Process) RunResult {
+	return namedResult("priority-preemptive", newStaticPriorityEngine().Schedule(processes))
+}
+
+// RunPreemptivePriority runs the process with the lowest Priority value,
+// preempting it whenever a waiting one outranks it. Every aging ticks spent
+// waiting boosts a process one step, or every DefaultAgingRate if aging is
+// below 1, so low priorities cannot starve.
+func RunPreemptivePriority(processes []Process, aging int64) RunResult {
+	if aging < 1 {
+		aging = DefaultAgingRate
+	}
+	return namedResult("ppriority", newAgingEngine(aging, 0).Schedule(processes))
+}
+
+// RunLottery gives each quantum, DefaultQuantum if quantum is below 1, to a
+// process drawn at random, its chance its tickets, by its nice value's weight
+// or else lotteryTicketPool over one more than its Priority, over all the
+// tickets waiting. The draws start from
+// seed, or DefaultSeed if seed is 0, so a seed always gives the same run.
+func RunLottery(processes []Process, quantum, seed int64) RunResult {
+	if quantum < 1 {
+		quantum = DefaultQuantum
+	}
+	if seed == 0 {
+		seed = DefaultSeed
+	}
+	return namedResult("lottery", newLotteryEngine(quantum, seed).Schedule(processes))
+}
+
+// RunStride gives each quantum, DefaultQuantum if quantum is below 1, to the
+// process with the lowest pass, then advances its pass by its stride, which
+// is inversely proportional to its tickets. It is the deterministic
+// counterpart of RunLottery, each process's share of the CPU following its
+// tickets without the lottery's luck.
+func RunStride(processes []Process, quantum int64) RunResult {
+	if quantum < 1 {
+		quantum = DefaultQuantum
+	}
+	return namedResult("stride", newStrideEngine(quantum).Schedule(processes))
+}
+
+// RunCFS runs the process with the least virtual runtime, its CPU time
+// scaled down by the weight of its nice value, or of its Priority taken as
+// one, for its share by weight of latency, or defaultTargetLatency if latency is
+// below 1.
+func RunCFS(processes []Process, latency int64) RunResult {
+	if latency < 1 {
+		latency = DefaultTargetLatency
+	}
+	return namedResult("cfs", newCFSEngine(latency).Schedule(processes))
+}
+
+// RunEDF runs the process with the earliest deadline, those without one
+// last, preempting it whenever one arrives due sooner.
+func RunEDF(processes []Process) RunResult {
+	return namedResult("edf", newEDFEngine().Schedule(processes))
+}
+
+// RunHRRN runs processes non-preemptively, next the one whose wait plus
+// burst over burst is highest, so short jobs go first as under sjf but long
+// ones climb the longer they wait. Equal ratios go in arrival order.
+func RunHRRN(processes []Process) RunResult {
+	return namedResult("hrrn", newHRRNEngine().Schedule(processes))
+}
+
+// RunPriority runs processes non-preemptively, lowest Priority value first
+// with ties in arrival order.
+func RunPriority(processes []Process) RunResult {
+	engine := Engine{Queue: &heapQueue{less: byPriority}}
+	return namedResult("priority", engine.Schedule(processes))
+}
+
+// RunSJF runs processes non-preemptively, shortest burst first with ties in
+// arrival order.
+func RunSJF(processes []Process) RunResult {
+	var (
+		serviceTime int64
+		result      = RunResult{
+			Scheduler: "sjf",
+			Gantt:     make([]TimeSlice, 0, len(processes)),
+			Processes: make([]ProcessMetrics, len(processes)),
+		}
+	)
+	byArrival := arrivalOrder(processes)
+	ready := &shortestJobHeap{processes: processes, byArrival: byArrival}
+	for arrived := 0; arrived < len(byArrival) || ready.Len() > 0; {
+		for arrived < len(byArrival) && processes[byArrival[arrived]].ArrivalTime <= serviceTime {
+			heap.Push(ready, arrived)
+			arrived++
+		}
+		if ready.Len() == 0 {
+			// No available jobs until the next arrival
+			next := processes[byArrival[arrived]].ArrivalTime
+			result.Gantt = append(result.Gantt, TimeSlice{PID: IdlePID, Start: serviceTime, Stop: next})
+			serviceTime = next
+			continue
+		}
+
+		i := byArrival[heap.Pop(ready).(int)]
+		start := serviceTime
+		serviceTime += processes[i].BurstDuration
+		result.Processes[i] = RunToCompletion(&processes[i], start)
+		result.Gantt = append(result.Gantt, TimeSlice{
+			PID:   processes[i].ProcessID,
+			Start: start,
+			Stop:  serviceTime,
+		})
+	}
+	result.summarize()
+	return result
+}
+
+// shortestJobHeap is a min-heap of positions in byArrival, ordered by the
+// process's burst and then by position so ties go to the earliest arrival.
+type shortestJobHeap struct {
+	processes []Process
+	byArrival []int
+	ready     []int
+}
+
+func (h *shortestJobHeap) Len() int { return len(h.ready) }
+
+func (h *shortestJobHeap) Less(i, j int) bool {
+	a, b := h.ready[i], h.ready[j]
+	burstA, burstB := h.processes[h.byArrival[a]].BurstDuration, h.processes[h.byArrival[b]].BurstDuration
+	if burstA != burstB {
+		return burstA < burstB
+	}
+	return a < b
+}
+
+func (h *shortestJobHeap) Swap(i, j int) { h.ready[i], h.ready[j] = h.ready[j], h.ready[i] }
+
+func (h *shortestJobHeap) Push(x interface{}) { h.ready = append(h.ready, x.(int)) }
+
+func (h *shortestJobHeap) Pop() interface{} {
+	n := len(h.ready) - 1
+	i := h.ready[n]
+	h.ready = h.ready[:n]
+	return i
+}
+
+// RunRR runs processes round-robin, each dispatch lasting at most quantum, or
+// DefaultQuantum if quantum is below 1.
+func RunRR(processes []Process, quantum int64) RunResult {
+	if quantum < 1 {
+		quantum = DefaultQuantum
+	}
+	engine := Engine{Queue: &FIFOQueue{}, Quantum: quantum}
+	return namedResult("rr", engine.Schedule(processes))
+}
+
+// RunWeightedRR runs processes round-robin, each dispatch lasting at most
+// quantum, or DefaultQuantum if quantum is below 1, times the weight that
+// weights gives the process's Priority, 1 if it gives none.
+func RunWeightedRR(processes []Process, quantum int64, weights map[int64]int64) RunResult {
+	if quantum < 1 {
+		quantum = DefaultQuantum
+	}
+	return namedResult("wrr", newWRREngine(quantum, weights).Schedule(processes))
+}
+
+// RunMultilevelQueue runs processes through the multilevel queue config, or
+// defaultMLQConfig if config is nil, its rr queues without a quantum of their
+// own taking quantum, or DefaultQuantum if quantum is below 1.
+func RunMultilevelQueue(processes []Process, quantum int64, config *MLQConfig) RunResult {
+	if quantum < 1 {
+		quantum = DefaultQuantum
+	}
+	return namedResult("mlq", newMLQEngine(config, quantum, nil).Schedule(processes))
+}
+
+// RunCooperative runs processes without a quantum, so a process only gives up the CPU when it
+// reaches one of its yield points or completes. A yielding process that names a DonateTo PID hands
+// the CPU straight to that process instead of going back through the ready queue.
+func RunCooperative(processes []Process) RunResult {
+	engine := Engine{Queue: &FIFOQueue{}}
+	return namedResult("cooperative", engine.Schedule(processes))
+}
+
+func namedResult(name string, result RunResult) RunResult {
+	result.Scheduler = name
+	return result
+}
+
+// summarize works out r's averages and spreads from its per-process
+// metrics, all zero rather than NaN or infinite when nothing ran, and its
+// context switches from its Gantt chart. Utilization counts each whole
+// burst as busy, so it suits runs in which nothing blocks.
+func (r *RunResult) summarize() {
+	var lastCompletion, busy int64
+	r.AvgWait, r.AvgTurnaround, r.AvgResponse, r.Throughput, r.Utilization = 0, 0, 0, 0, 0
+	for _, m := range r.Processes {
+		r.AvgWait += float64(m.Wait)
+		r.AvgTurnaround += float64(m.Turnaround)
+		r.AvgResponse += float64(m.Response)
+		busy += m.Burst
+		if m.Exit > lastCompletion {
+			lastCompletion = m.Exit
+		}
+	}
+	if n := float64(len(r.Processes)); n > 0 {
+		r.AvgWait /= n
+		r.AvgTurnaround /= n
+		r.AvgResponse /= n
+		if lastCompletion > 0 {
+			r.Throughput = n / float64(lastCompletion)
+			r.Utilization = float64(busy) / float64(lastCompletion)
+		}
+	}
+	r.spread()
+	r.ContextSwitches = 0
+	for i, s := range r.Gantt {
+		if s.PID >= 0 && (i == 0 || r.Gantt[i-1].PID != s.PID || r.Gantt[i-1].Stop != s.Start) {
+			r.ContextSwitches++
+		}
+	}
+}
+
+const (
+	// DefaultQuantum is the round-robin quantum when a caller does not give one.
+	DefaultQuantum = 2
+	// DefaultAgingRate is the ticks of waiting per priority boost when a
+	// caller does not give a rate.
+	DefaultAgingRate = 10
+	// DefaultSeed starts a lottery's draws when a caller does not give a
+	// seed.
+	DefaultSeed = 1
+	// DefaultTargetLatency is the time in which cfs aims to run every
+	// runnable task once when a caller does not give one.
+	DefaultTargetLatency = 12
+	// DefaultAlpha is the weight sjf-predict gives the last burst when a
+	// caller does not give one, averaging it equally with the history.
+	DefaultAlpha = 0.5
+	// DefaultBurstGuess is what sjf-predict predicts of a process's first
+	// burst, before it has any history.
+	DefaultBurstGuess = 10
+	// DefaultCapPeriod is the window over which group CPU caps are
+	// accounted when a caller does not give one.
+	DefaultCapPeriod = 10
+)
+
+// CheckQuantum rejects a quantum below 1. A quantum as long as every burst is
+// allowed but lets each process run to completion, so rr behaves as fcfs, and
+// warning says so.
+func CheckQuantum(quantum int64, processes []Process) (warning string, err error) {
+	if quantum < 1 {
+		return "", fmt.Errorf("%w: quantum %d must be at least 1", ErrInvalidArgs, quantum)
+	}
+	for _, p := range processes {
+		if p.BurstDuration > quantum {
+			return "", nil
+		}
+	}
+	if len(processes) == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("quantum %d is at least every burst, so round-robin runs as first-come, first-serve", quantum), nil
+}
+
+type (
+	// RunResult is one scheduler's run over a workload in a form that
+	// serializes cleanly to JSON. Utilization is the share of the time from 0
+	// to the last completion that the CPU was busy, idle stretches included,
+	// averaged over the CPUs, which for a MultiCPU run CPUs has one by one.
+	// DeadlineMisses counts the processes that completed after their
+	// deadlines, and Schedulable, set only by edf, says whether there were
+	// none: edf is optimal for independent processes on one CPU, so if it
+	// misses a deadline no scheduler could meet them all. WaitStats and
+	// TurnaroundStats spread those metrics over the processes, and Fairness
+	// is the Jain index of their shares of their time in the system; a
+	// summary, without per-process metrics, has none of the three.
+	// ContextSwitches counts the dispatches that switched to a process
+	// rather than let the one that had the CPU carry straight on. LockWaits
+	// are the stretches processes spent blocked on mutexes, and
+	// Inheritances the priorities they inherited and gave back when Inherit
+	// was set. NiceWeights and PriorityOrder are as a run of a scheduler
+	// that weighs by nice value was given them. Caps and CapPeriod are the
+	// group CPU caps of the run, if any, and Carry and BankCap what it did
+	// with the rest of a quantum a process yielded.
+	RunResult struct {
+		Scheduler       string            `json:"scheduler"`
+		Quantum         int64             `json:"quantum,omitempty"`
+		Aging           int64             `json:"aging,omitempty"`
+		AgingCap        int64             `json:"aging_cap,omitempty"`
+		Seed            int64             `json:"seed,omitempty"`
+		Latency         int64             `json:"latency,omitempty"`
+		Alpha           float64           `json:"alpha,omitempty"`
+		SwitchCost      int64             `json:"switch_cost,omitempty"`
+		TieBreak        TieBreak          `json:"tie_break,omitempty"`
+		Inherit         bool              `json:"inherit,omitempty"`
+		NiceWeights     map[int64]int64   `json:"nice_weights,omitempty"`
+		PriorityOrder   PriorityOrder     `json:"priority_order,omitempty"`
+		Caps            map[string]int64  `json:"caps,omitempty"`
+		CapPeriod       int64             `json:"cap_period,omitempty"`
+		Carry           CarryPolicy       `json:"carry,omitempty"`
+		BankCap         int64             `json:"bank_cap,omitempty"`
+		Gantt           []TimeSlice       `json:"gantt"`
+		Processes       []ProcessMetrics  `json:"processes"`
+		AvgWait         float64           `json:"avg_wait"`
+		AvgTurnaround   float64           `json:"avg_turnaround"`
+		AvgResponse     float64           `json:"avg_response"`
+		Throughput      float64           `json:"throughput"`
+		Utilization     float64           `json:"utilization"`
+		ContextSwitches int64             `json:"context_switches"`
+		WaitStats       *Distribution     `json:"wait_stats,omitempty"`
+		TurnaroundStats *Distribution     `json:"turnaround_stats,omitempty"`
+		Fairness        float64           `json:"fairness,omitempty"`
+		Events          []Event           `json:"events,omitempty"`
+		CPUs            []CPUStats        `json:"cpus,omitempty"`
+		VRuntimes       []VRuntimeSample  `json:"vruntimes,omitempty"`
+		Predictions     []BurstPrediction `json:"predictions,omitempty"`
+		DeadlineMisses  int               `json:"deadline_misses,omitempty"`
+		Schedulable     *bool             `json:"schedulable,omitempty"`
+		LockWaits       []LockWait        `json:"lock_waits,omitempty"`
+		Inheritances    []PriorityChange  `json:"inheritances,omitempty"`
+	}
+	// ProcessMetrics is the per-process row of a RunResult. In a lottery or
+	// stride schedule, where each dispatch is a draw among the waiting,
+	// TicketShare is a process's average share of the tickets in the draws
+	// it entered and CPUShare the share of them it won, which is its share
+	// of the CPU while it competed when every win runs a full quantum.
+	// Under wrr, Weight is a process's weight and Quantum the slice it runs
+	// per turn. Under mlq, Queue names the queue a process was in. Under
+	// cfs, VRuntime is a process's virtual runtime at the end. Lateness is
+	// how long after its Deadline, if it has one, a process completed,
+	// negative if it was early. Under sjf-predict, PredictionError is a
+	// process's mean absolute error over its predicted bursts. Under
+	// guaranteed, Entitlement is the CPU time a process was entitled to, an
+	// equal share with every process in the system while it was, against
+	// the Burst it had. Nice and NiceWeight are the nice value a process was
+	// weighed by and its weight, under cfs and, for a process given a nice
+	// value, lottery and stride. Under group caps, Throttled is how long a
+	// process of a capped Group was held back after its group had used up
+	// its share of the period.
+	ProcessMetrics struct {
+		PID             int64   `json:"pid"`
+		Name            string  `json:"name,omitempty"`
+		Arrival         int64   `json:"arrival"`
+		Burst           int64   `json:"burst"`
+		Priority        int64   `json:"priority"`
+		Response        int64   `json:"response"`
+		Wait            int64   `json:"wait"`
+		Turnaround      int64   `json:"turnaround"`
+		Exit            int64   `json:"exit"`
+		Boosts          int64   `json:"boosts,omitempty"`
+		Weight          int64   `json:"weight,omitempty"`
+		Quantum         int64   `json:"quantum,omitempty"`
+		Queue           string  `json:"queue,omitempty"`
+		Tickets         int64   `json:"tickets,omitempty"`
+		TicketShare     float64 `json:"ticket_share,omitempty"`
+		CPUShare        float64 `json:"cpu_share,omitempty"`
+		VRuntime        float64 `json:"vruntime,omitempty"`
+		Deadline        int64   `json:"deadline,omitempty"`
+		Lateness        int64   `json:"lateness,omitempty"`
+		PredictionError float64 `json:"prediction_error,omitempty"`
+		Entitlement     float64 `json:"entitlement,omitempty"`
+		Nice            *int64  `json:"nice,omitempty"`
+		NiceWeight      int64   `json:"nice_weight,omitempty"`
+		Group           string  `json:"group,omitempty"`
+		Throttled       int64   `json:"throttled,omitempty"`
+	}
+)
+
+// RunScheduler runs processes through the named registered scheduler. The
+// quantum only matters to those that take one, where 0 means DefaultQuantum.
+func RunScheduler(name string, quantum int64, processes []Process) (RunResult, error) {
+	return RunSchedulerEvents(name, quantum, processes, nil)
+}
+
+// RunSchedulerParams is RunScheduler with every tunable. Each only matters
+// to the schedulers that take it, and 0 means its default.
+func RunSchedulerParams(name string, params SchedulerParams, processes []Process) (RunResult, error) {
+	return runScheduler(name, params, processes, nil, false)
+}
+
+// RunSchedulerEvents is RunScheduler with onEvent called for each event as
+// the engine logs it. Schedulers other than the engine's report no events.
+func RunSchedulerEvents(name string, quantum int64, processes []Process, onEvent func(Event)) (RunResult, error) {
+	return runScheduler(name, SchedulerParams{Quantum: quantum}, processes, onEvent, false)
+}
+
+// SummarizeScheduler is RunScheduler for workloads too big to keep every
+// detail of: the result has the averages but no Gantt chart, events or
+// per-process metrics. TestMillionProcesses holds a million-process workload
+// to a time and memory budget this way.
+func SummarizeScheduler(name string, quantum int64, processes []Process) (RunResult, error) {
+	return runScheduler(name, SchedulerParams{Quantum: quantum}, processes, nil, true)
+}
+
+// SummarizeSchedulerParams is SummarizeScheduler with every tunable, as
+// RunSchedulerParams takes them.
+func SummarizeSchedulerParams(name string, params SchedulerParams, processes []Process) (RunResult, error) {
+	return runScheduler(name, params, processes, nil, true)
+}
+
+func runScheduler(name string, params SchedulerParams, processes []Process, onEvent func(Event), summaryOnly bool) (RunResult, error) {
+	info, err := LookupScheduler(name)
+	if err != nil {
+		return RunResult{}, err
+	}
+	if params.Quantum < 0 {
+		return RunResult{}, fmt.Errorf("%w: quantum must not be negative", ErrInvalidArgs)
+	}
+	if params.Aging < 0 {
+		return RunResult{}, fmt.Errorf("%w: aging rate must not be negative", ErrInvalidArgs)
+	}
+	if params.SwitchCost < 0 {
+		return RunResult{}, fmt.Errorf("%w: context switch cost must not be negative", ErrInvalidArgs)
+	}
+	if params.Latency < 0 {
+		return RunResult{}, fmt.Errorf("%w: target latency must not be negative", ErrInvalidArgs)
+	}
+	if params.CPUs < 0 {
+		return RunResult{}, fmt.Errorf("%w: CPU count must not be negative", ErrInvalidArgs)
+	}
+	if params.Alpha < 0 || params.Alpha > 1 {
+		return RunResult{}, fmt.Errorf("%w: alpha %g is not within 0 to 1", ErrInvalidArgs, params.Alpha)
+	}
+	if !info.Inherit {
+		params.Inherit = false
+	}
+	if params.Inherit && params.CPUs > 1 {
+		return RunResult{}, fmt.Errorf("%w: priority inheritance runs on a single CPU", ErrInvalidArgs)
+	}
+	if !info.Ages {
+		params.AgingPolicy = nil
+	}
+	if !info.Nice {
+		params.NiceWeights, params.PriorityOrder = nil, LowerFirst
+	}
+	if !info.Quantum {
+		params.Carry, params.BankCap = CarryDiscard, 0
+	}
+	switch {
+	case params.Carry < CarryDiscard || params.Carry > CarryBank:
+		return RunResult{}, fmt.Errorf("%w: unknown carry policy %v", ErrInvalidArgs, params.Carry)
+	case params.BankCap < 0:
+		return RunResult{}, fmt.Errorf("%w: bank cap must not be negative", ErrInvalidArgs)
+	case params.BankCap > 0 && params.Carry != CarryBank:
+		return RunResult{}, fmt.Errorf("%w: a bank cap needs the bank carry policy", ErrInvalidArgs)
+	case params.Carry != CarryDiscard && params.CPUs > 1:
+		return RunResult{}, fmt.Errorf("%w: carry policies run on a single CPU", ErrInvalidArgs)
+	}
+	if _, err := NiceWeightTable(params.NiceWeights); err != nil {
+		return RunResult{}, err
+	}
+	if params.PriorityOrder < LowerFirst || params.PriorityOrder > HigherFirst {
+		return RunResult{}, fmt.Errorf("%w: unknown priority order %v", ErrInvalidArgs, params.PriorityOrder)
+	}
+	if a := params.AgingPolicy; a != nil {
+		switch {
+		case a.Every < 1:
+			return RunResult{}, fmt.Errorf("%w: aging every %d ticks must be every 1 or more", ErrInvalidArgs, a.Every)
+		case a.Cap < MinPriority || a.Cap > MaxPriority:
+			return RunResult{}, fmt.Errorf("%w: aging cap %d is not within %d to %d", ErrInvalidArgs, a.Cap, MinPriority, MaxPriority)
+		case params.CPUs > 1:
+			return RunResult{}, fmt.Errorf("%w: aging runs on a single CPU", ErrInvalidArgs)
+		}
+	}
+	for group, pct := range params.Caps {
+		switch {
+		case group == "":
+			return RunResult{}, fmt.Errorf("%w: a group cap needs a group name", ErrInvalidArgs)
+		case pct < 1 || pct > 100:
+			return RunResult{}, fmt.Errorf("%w: cap %d%% of group %s is not within 1 to 100", ErrInvalidArgs, pct, group)
+		case params.CPUs > 1:
+			return RunResult{}, fmt.Errorf("%w: group caps run on a single CPU", ErrInvalidArgs)
+		}
+	}
+	if params.CapPeriod < 0 {
+		return RunResult{}, fmt.Errorf("%w: cap period must not be negative", ErrInvalidArgs)
+	}
+	if len(params.Caps) == 0 {
+		params.Caps, params.CapPeriod = nil, 0
+	} else if params.CapPeriod == 0 {
+		params.CapPeriod = DefaultCapPeriod
+	}
+	if params.TieBreak < TieFIFO || params.TieBreak > TiePID {
+		return RunResult{}, fmt.Errorf("%w: unknown tie-break rule %v", ErrInvalidArgs, params.TieBreak)
+	}
+	if params.CPUs > 1 && !info.MultiCPU {
+		return RunResult{}, fmt.Errorf("%w: %s runs on a single CPU", ErrInvalidArgs, name)
+	}
+	if params.AssumeSorted {
+		if err := CheckArrivalOrder(processes); err != nil {
+			return RunResult{}, err
+		}
+	}
+	if !info.Quantum {
+		params.Quantum = 0
+	} else if params.Quantum == 0 {
+		params.Quantum = DefaultQuantum
+	}
+	if params.Carry == CarryBank && params.BankCap == 0 {
+		params.BankCap = params.Quantum
+		if params.Quantum <= math.MaxInt64/2 {
+			params.BankCap *= 2
+		}
+	}
+	if !info.Aging {
+		params.Aging = 0
+	} else if params.Aging == 0 {
+		params.Aging = DefaultAgingRate
+	}
+	if !info.Seed {
+		params.Seed = 0
+	} else if params.Seed == 0 {
+		params.Seed = DefaultSeed
+	}
+	if !info.Weights {
+		params.Weights = nil
+	}
+	if !info.Latency {
+		params.Latency = 0
+	} else if params.Latency == 0 {
+		params.Latency = DefaultTargetLatency
+	}
+	if !info.Alpha {
+		params.Alpha = 0
+	} else if params.Alpha == 0 {
+		params.Alpha = DefaultAlpha
+	}
+
+	var result RunResult
+	switch s := info.New(params).(type) {
+	case *Engine:
+		// The engine can stream events and skip the details as it goes,
+		// which a million-process summary needs.
+		s.OnEvent = onEvent
+		if summaryOnly {
+			s.DropEvents, s.GanttSpill = true, io.Discard
+			switch q := s.Queue.(type) {
+			case *cfsQueue:
+				q.dropSamples = true
+			case *predictQueue:
+				q.dropSamples = true
+			}
+		}
+		result = traceResult(s.Simulate(processes), summaryOnly)
+		s.describe(&result)
+	default:
+		result = s.Schedule(processes)
+		if summaryOnly {
+			result.Gantt, result.Processes, result.Events = nil, nil, nil
+		}
+	}
+	result.Scheduler = name
+	return result, nil
+}
+
+// traceResult computes a run's metrics from its trace, leaving out the Gantt
+// chart and per-process metrics if summaryOnly.
+func traceResult(tr Trace, summaryOnly bool) RunResult {
+	result := RunResult{Gantt: tr.Gantt, Events: tr.Events, ContextSwitches: tr.Switches, LockWaits: tr.LockWaits, Inheritances: tr.Inheritances}
+	if summaryOnly {
+		result.Gantt, result.LockWaits, result.Inheritances = nil, nil, nil
+	} else {
+		result.Processes = make([]ProcessMetrics, len(tr.Tasks))
+	}
+	var lastCompletion, busy int64
+	for i, t := range tr.Tasks {
+		m := ProcessMetrics{
+			PID:        t.ProcessID,
+			Name:       t.Name,
+			Arrival:    t.ArrivalTime,
+			Burst:      t.BurstDuration,
+			Priority:   t.Priority,
+			Response:   t.FirstRun - t.ArrivalTime,
+			Turnaround: t.Exit - t.ArrivalTime,
+			Exit:       t.Exit,
+			Boosts:     t.Boosts,
+			Group:      t.Group,
+			Throttled:  t.Throttled,
+		}
+		m.Wait = m.Turnaround - m.Burst - t.Blocked
+		if t.Deadline != 0 {
+			m.Deadline, m.Lateness = t.Deadline, t.Exit-t.Deadline
+			if m.Lateness > 0 {
+				result.DeadlineMisses++
+			}
+		}
+		if t.fair {
+			m.VRuntime = t.currentVRuntime()
+		}
+		if !summaryOnly && (t.fair || (t.Draws > 0 && t.Nice != nil)) {
+			nice := t.nice()
+			m.Nice, m.NiceWeight = &nice, t.weight()
+		}
+		if t.Draws > 0 {
+			m.Tickets = t.tickets()
+			m.TicketShare = t.odds / float64(t.Draws)
+			m.CPUShare = float64(t.Wins) / float64(t.Draws)
+		}
+		if !summaryOnly {
+			result.Processes[i] = m
+		}
+		result.AvgWait += float64(m.Wait)
+		result.AvgTurnaround += float64(m.Turnaround)
+		result.AvgResponse += float64(m.Response)
+		busy += t.Used
+		if t.Exit > lastCompletion {
+			lastCompletion = t.Exit
+		}
+	}
+	if n := float64(len(tr.Tasks)); n > 0 {
+		result.AvgWait /= n
+		result.AvgTurnaround /= n
+		result.AvgResponse /= n
+		if lastCompletion > 0 {
+			result.Throughput = n / float64(lastCompletion)
+			result.Utilization = float64(busy) / float64(lastCompletion)
+		}
+	}
+	result.spread()
+	return result
+}
+
+// MarshalText writes event kinds by name in JSON.
+func (k EventKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText reads event kinds written by MarshalText.
+func (k *EventKind) UnmarshalText(text []byte) error {
+	for kind := EventArrive; kind <= EventWake; kind++ {
+		if kind.String() == string(text) {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unknown event kind %q", ErrInvalidArgs, text)
+}
@@ -0,0 +1,343 @@
+// Package scheduler simulates CPU scheduling algorithms over a workload of
+// processes. Each algorithm is registered by name with RegisterScheduler and
+// run with RunScheduler, which returns the Gantt chart and per-process
+// metrics of the run.
+package scheduler
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+//region Scheduler registry
+
+type (
+	// Scheduler is a scheduling algorithm. Schedule runs processes through it,
+	// leaving them as they were, and returns the run's chart and metrics.
+	Scheduler interface {
+		Schedule(processes []Process) RunResult
+	}
+	// SchedulerInfo describes a registered algorithm:
+	// • Title heads its printed report
+	// • Quantum says whether it takes one; New is given 0 otherwise
+	// • Aging likewise says whether it takes an aging rate
+	// • Seed likewise says whether it draws at random from a seed
+	// • Latency likewise says whether it takes a target latency
+	// • Weights likewise says whether it weighs processes by Priority
+	// • Alpha likewise says whether it predicts bursts by exponential averaging
+	// • MultiCPU says whether it can run on more than one CPU
+	// • Inherit says whether it ranks processes by Priority, so that a
+	//   process holding a mutex can inherit a better one
+	// • Ages says whether an AgingPolicy can be attached to it
+	// • Nice says whether it shares the CPU out by weight, reading each
+	//   process's weight from its nice value
+	// • New makes a Scheduler for a single run
+	SchedulerInfo struct {
+		Title    string
+		Quantum  bool
+		Aging    bool
+		Seed     bool
+		Latency  bool
+		Weights  bool
+		Alpha    bool
+		MultiCPU bool
+		Inherit  bool
+		Ages     bool
+		Nice     bool
+		New      func(params SchedulerParams) Scheduler
+	}
+	// SchedulerParams are the tunables a run passes to New:
+	// • Quantum bounds each dispatch of a round-robin style scheduler
+	// • Aging is the ticks a waiting process needs for each priority boost
+	// • SwitchCost is the time every scheduler charges per context switch
+	// • CPUs is how many processors share the ready queue; 0 means one
+	// • Seed starts the random draws, the same seed giving the same run
+	// • Latency is the time in which cfs aims to run every runnable task once
+	// • Weights maps a Priority to its weight, how many quanta a process of
+	//   that priority runs per turn under wrr; unmapped priorities weigh 1
+	// • MLQ declares mlq's queues; nil means defaultMLQConfig
+	// • Alpha, from 0 to 1, is how much sjf-predict weighs a process's last
+	//   burst against its earlier ones when predicting the next
+	// • TieBreak orders tasks arriving together and, for the schedulers that
+	//   compare tasks by a key such as burst or priority, tasks that tie
+	// • AssumeSorted skips sorting a workload by arrival, for one known to be
+	//   in order already; a run checks that it is, in one pass
+	// • Inherit turns on priority inheritance for the schedulers that rank by
+	//   Priority: a process holding a mutex runs at the best priority of
+	//   those blocked on it, so a middling one cannot hold up a high one.
+	//   Inherit runs on a single CPU
+	// • AgingPolicy, if set, ages the waiting processes of priority, mlq and
+	//   ppriority, the schedulers that take one, in place of ppriority's Aging
+	// • NiceWeights maps a nice value to the weight the schedulers that
+	//   weigh by nice value give it, in place of Linux's; others keep theirs
+	// • PriorityOrder says whether a lower or a higher Priority is better
+	//   where those schedulers weigh a process without a nice value by it
+	// • Caps maps a process Group to the percentage of every CapPeriod its
+	//   processes may run between them; a group that has used its share is
+	//   throttled until the next period starts. Caps run on a single CPU
+	// • Carry decides what the schedulers that take a quantum do with the
+	//   rest of one a process yields or blocks in, and BankCap bounds what CarryBank
+	//   saves up; 0 means twice the quantum. Carry runs on a single CPU
+	SchedulerParams struct {
+		Quantum       int64
+		Aging         int64
+		SwitchCost    int64
+		CPUs          int
+		Seed          int64
+		Latency       int64
+		Weights       map[int64]int64
+		MLQ           *MLQConfig
+		Alpha         float64
+		TieBreak      TieBreak
+		AssumeSorted  bool
+		Inherit       bool
+		AgingPolicy   *AgingPolicy
+		NiceWeights   map[int64]int64
+		PriorityOrder PriorityOrder
+		Caps          map[string]int64
+		CapPeriod     int64
+		Carry         CarryPolicy
+		BankCap       int64
+	}
+	// AgingPolicy keeps low priorities from starving: each time the clock
+	// passes a multiple of Every, every process waiting to run gains a
+	// boost, one step of priority, until it reaches Cap, 0 unless set. A
+	// process keeps its boosts for the rest of the run. Priority, mlq and
+	// ppriority take a policy; the other schedulers ignore it.
+	AgingPolicy struct {
+		Every int64 `json:"every"`
+		Cap   int64 `json:"cap,omitempty"`
+	}
+)
+
+var (
+	schedulerRegistry = map[string]SchedulerInfo{}
+	// schedulerOrder is the order the algorithms were registered in, which
+	// the printed reports follow.
+	schedulerOrder []string
+)
+
+func init() {
+	RegisterScheduler("fcfs", SchedulerInfo{
+		Title:    "First-come, first-serve",
+		MultiCPU: true,
+		New:      func(p SchedulerParams) Scheduler { return onCPUs(&Engine{Queue: &FIFOQueue{}}, p) },
+	})
+	RegisterScheduler("sjf", SchedulerInfo{
+		Title:    "Shortest-job-first",
+		MultiCPU: true,
+		New:      func(p SchedulerParams) Scheduler { return onCPUs(&Engine{Queue: &heapQueue{less: byRemaining}}, p) },
+	})
+	RegisterScheduler("srtf", SchedulerInfo{
+		Title: "Shortest-remaining-time-first",
+		New:   func(p SchedulerParams) Scheduler { return withParams(newSRTFEngine(), p) },
+	})
+	RegisterScheduler("priority", SchedulerInfo{
+		Title:    "Priority",
+		MultiCPU: true,
+		Inherit:  true,
+		Ages:     true,
+		New: func(p SchedulerParams) Scheduler {
+			if a := p.AgingPolicy; a != nil {
+				return onCPUs(&Engine{Queue: newAgingQueue(a.Every, a.Cap)}, p)
+			}
+			return onCPUs(&Engine{Queue: &heapQueue{less: byPriority}}, p)
+		},
+	})
+	RegisterScheduler("rr", SchedulerInfo{
+		Title:    "Round-robin",
+		Quantum:  true,
+		MultiCPU: true,
+		New:      func(p SchedulerParams) Scheduler { return onCPUs(&Engine{Queue: &FIFOQueue{}, Quantum: p.Quantum}, p) },
+	})
+	RegisterScheduler("priority-preemptive", SchedulerInfo{
+		Title:   "Preemptive priority without aging",
+		Inherit: true,
+		New:     func(p SchedulerParams) Scheduler { return withParams(newStaticPriorityEngine(), p) },
+	})
+	RegisterScheduler("wrr", SchedulerInfo{
+		Title:   "Weighted round-robin",
+		Quantum: true,
+		Weights: true,
+		New:     func(p SchedulerParams) Scheduler { return withParams(newWRREngine(p.Quantum, p.Weights), p) },
+	})
+	RegisterScheduler("mlq", SchedulerInfo{
+		Title:   "Multilevel queue",
+		Quantum: true,
+		Ages:    true,
+		New:     func(p SchedulerParams) Scheduler { return withParams(newMLQEngine(p.MLQ, p.Quantum, p.AgingPolicy), p) },
+	})
+	RegisterScheduler("ppriority", SchedulerInfo{
+		Title: "Preemptive priority",
+		Aging: true,
+		Ages:  true,
+		New: func(p SchedulerParams) Scheduler {
+			if a := p.AgingPolicy; a != nil {
+				return withParams(newAgingEngine(a.Every, a.Cap), p)
+			}
+			return withParams(newAgingEngine(p.Aging, 0), p)
+		},
+	})
+	RegisterScheduler("lottery", SchedulerInfo{
+		Title:    "Lottery",
+		Quantum:  true,
+		Seed:     true,
+		MultiCPU: true,
+		Nice:     true,
+		New:      func(p SchedulerParams) Scheduler { return onCPUs(newLotteryEngine(p.Quantum, p.Seed), p) },
+	})
+	RegisterScheduler("stride", SchedulerInfo{
+		Title:    "Stride",
+		Quantum:  true,
+		MultiCPU: true,
+		Nice:     true,
+		New:      func(p SchedulerParams) Scheduler { return onCPUs(newStrideEngine(p.Quantum), p) },
+	})
+	RegisterScheduler("cfs", SchedulerInfo{
+		Title:   "Completely fair",
+		Latency: true,
+		Nice:    true,
+		New:     func(p SchedulerParams) Scheduler { return withParams(newCFSEngine(p.Latency), p) },
+	})
+	RegisterScheduler("edf", SchedulerInfo{
+		Title: "Earliest deadline first",
+		New:   func(p SchedulerParams) Scheduler { return withParams(newEDFEngine(), p) },
+	})
+	RegisterScheduler("hrrn", SchedulerInfo{
+		Title: "Highest response ratio next",
+		New:   func(p SchedulerParams) Scheduler { return withParams(newHRRNEngine(), p) },
+	})
+	RegisterScheduler("guaranteed", SchedulerInfo{
+		Title:   "Guaranteed",
+		Quantum: true,
+		New:     func(p SchedulerParams) Scheduler { return withParams(newGuaranteedEngine(p.Quantum), p) },
+	})
+	RegisterScheduler("sjf-predict", SchedulerInfo{
+		Title: "Shortest-job-first by predicted burst",
+		Alpha: true,
+		New:   func(p SchedulerParams) Scheduler { return withParams(newPredictEngine(p.Alpha), p) },
+	})
+}
+
+// withParams has e charge the context-switch cost, break ties, sort
+// arrivals, inherit priorities, weigh nice values, cap groups and carry
+// yielded quanta as p says.
+func withParams(e *Engine, p SchedulerParams) *Engine {
+	e.SwitchCost, e.TieBreak, e.AssumeSorted, e.Inherit = p.SwitchCost, p.TieBreak, p.AssumeSorted, p.Inherit
+	e.NiceWeights, e.PriorityOrder = p.NiceWeights, p.PriorityOrder
+	e.Caps, e.CapPeriod = p.Caps, p.CapPeriod
+	e.Carry, e.BankCap = p.Carry, p.BankCap
+	return e
+}
+
+// onCPUs is e with p's tunables, as withParams sets them, for a single
+// CPU, or a MultiCPU sharing e's queue and quantum among p's CPUs.
+func onCPUs(e *Engine, p SchedulerParams) Scheduler {
+	withParams(e, p)
+	if p.CPUs > 1 {
+		return &MultiCPU{CPUs: p.CPUs, Queue: e.Queue, Quantum: e.Quantum, SwitchCost: e.SwitchCost, TieBreak: e.TieBreak, AssumeSorted: e.AssumeSorted,
+			NiceWeights: e.NiceWeights, PriorityOrder: e.PriorityOrder}
+	}
+	return e
+}
+
+// RegisterScheduler makes an algorithm available by name to the CLI, the
+// server and every other caller of RunScheduler. It panics if name is taken.
+func RegisterScheduler(name string, info SchedulerInfo) {
+	if _, ok := schedulerRegistry[name]; ok {
+		panic(fmt.Sprintf("scheduler %q registered twice", name))
+	}
+	schedulerRegistry[name] = info
+	schedulerOrder = append(schedulerOrder, name)
+}
+
+// LookupScheduler is the registered algorithm called name.
+func LookupScheduler(name string) (SchedulerInfo, error) {
+	info, ok := schedulerRegistry[name]
+	if !ok {
+		return SchedulerInfo{}, fmt.Errorf("%w: unknown scheduler %q", ErrInvalidArgs, name)
+	}
+	return info, nil
+}
+
+// SchedulerNames are the registered algorithms in the order they were
+// registered, which the printed reports follow.
+func SchedulerNames() []string {
+	return append([]string(nil), schedulerOrder...)
+}
+
+// SortedSchedulerNames are the registered algorithms in alphabetical order.
+func SortedSchedulerNames() []string {
+	names := append([]string(nil), schedulerOrder...)
+	sort.Strings(names)
+	return names
+}
+
+// Schedule makes the engine a Scheduler.
+func (e *Engine) Schedule(processes []Process) RunResult {
+	result := traceResult(e.Simulate(processes), false)
+	e.describe(&result)
+	return result
+}
+
+// describe records e's tunables in r, along with a cfs run's virtual
+// runtimes, whether an edf run met every deadline, each process's weight
+// and quantum under wrr, its queue under mlq, its entitlement under
+// guaranteed and the bursts sjf-predict predicted.
+func (e *Engine) describe(r *RunResult) {
+	r.Quantum, r.SwitchCost, r.TieBreak, r.Inherit = e.Quantum, e.SwitchCost, e.TieBreak, e.Inherit
+	r.NiceWeights, r.PriorityOrder = e.NiceWeights, e.PriorityOrder
+	r.Caps, r.CapPeriod = e.Caps, e.CapPeriod
+	r.Carry, r.BankCap = e.Carry, e.BankCap
+	r.Aging, r.AgingCap = e.aging()
+	r.Seed, r.Latency = lotterySeed(e.Queue), cfsLatency(e.Queue)
+	switch q := e.Queue.(type) {
+	case *cfsQueue:
+		r.VRuntimes = q.samples
+	case *edfQueue:
+		schedulable := r.DeadlineMisses == 0
+		r.Schedulable = &schedulable
+	case *mlqQueue:
+		for i := range r.Processes {
+			r.Processes[i].Queue = q.config.Queues[q.level(r.Processes[i].Priority)].Name
+		}
+	case *guaranteedQueue:
+		q.settle()
+		for i := range r.Processes {
+			r.Processes[i].Entitlement = q.shares[r.Processes[i].PID]
+		}
+	case *predictQueue:
+		r.Alpha, r.Predictions = q.alpha, q.predictions()
+		errs := make(map[int64]float64)
+		bursts := make(map[int64]int)
+		for _, p := range r.Predictions {
+			errs[p.PID] += math.Abs(p.Predicted - float64(p.Actual))
+			bursts[p.PID]++
+		}
+		for i := range r.Processes {
+			if n := bursts[r.Processes[i].PID]; n > 0 {
+				r.Processes[i].PredictionError = errs[r.Processes[i].PID] / float64(n)
+			}
+		}
+	case *wrrQueue:
+		for i := range r.Processes {
+			m := &r.Processes[i]
+			m.Weight = q.weight(m.Priority)
+			m.Quantum = q.quantum * m.Weight
+		}
+	}
+}
+
+// aging is the rate and cap of the engine's aging, 0 and 0 if it has none.
+func (e *Engine) aging() (rate, limit int64) {
+	switch q := e.Queue.(type) {
+	case *agingQueue:
+		return q.rate, q.floor
+	case *mlqQueue:
+		return q.rate, q.floor
+	}
+	return 0, 0
+}
+
+//endregion
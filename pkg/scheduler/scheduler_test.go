@@ -0,0 +1,547 @@
+package scheduler
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSchedulerRegistry(t *testing.T) {
+	t.Parallel()
+	processes := mixedWorkload
+	for _, name := range SchedulerNames() {
+		info := schedulerRegistry[name]
+		var params SchedulerParams
+		if info.Quantum {
+			params.Quantum = DefaultQuantum
+		}
+		if info.Aging {
+			params.Aging = DefaultAgingRate
+		}
+		if info.Seed {
+			params.Seed = DefaultSeed
+		}
+		if info.Latency {
+			params.Latency = DefaultTargetLatency
+		}
+		if info.Alpha {
+			params.Alpha = DefaultAlpha
+		}
+		got := info.New(params).Schedule(processes)
+		got.Scheduler = name
+		want, err := RunScheduler(name, 0, processes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s Schedule() = %+v, want RunScheduler()'s %+v", name, got, want)
+		}
+	}
+}
+
+func TestRegisterScheduler_twice(t *testing.T) {
+	t.Parallel()
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterScheduler() of a taken name did not panic")
+		}
+	}()
+	RegisterScheduler("fcfs", schedulerRegistry["fcfs"])
+}
+
+func TestTieBreak(t *testing.T) {
+	t.Parallel()
+	// P2 and P1 tie on burst once P3 is done, P2 having arrived first but
+	// P1 having the lower PID.
+	sjfTie := []Process{
+		{ProcessID: 3, BurstDuration: 5},
+		{ProcessID: 2, BurstDuration: 3, ArrivalTime: 1},
+		{ProcessID: 1, BurstDuration: 3, ArrivalTime: 2},
+	}
+	// P2 and P1 tie on priority and arrival, P2 coming first in the workload.
+	together := []Process{
+		{ProcessID: 2, BurstDuration: 3, Priority: 1},
+		{ProcessID: 1, BurstDuration: 3, Priority: 1},
+	}
+	threeTogether := []Process{
+		{ProcessID: 3, BurstDuration: 2},
+		{ProcessID: 2, BurstDuration: 2},
+		{ProcessID: 1, BurstDuration: 2},
+	}
+	tests := []struct {
+		name      string
+		scheduler string
+		processes []Process
+		tieBreak  TieBreak
+		cpus      int
+		wantOrder []int64
+	}{
+		{name: "sjf fifo", scheduler: "sjf", processes: sjfTie, tieBreak: TieFIFO, wantOrder: []int64{3, 2, 1}},
+		{name: "sjf arrival", scheduler: "sjf", processes: sjfTie, tieBreak: TieArrival, wantOrder: []int64{3, 2, 1}},
+		{name: "sjf pid", scheduler: "sjf", processes: sjfTie, tieBreak: TiePID, wantOrder: []int64{3, 1, 2}},
+		{name: "srtf pid", scheduler: "srtf", processes: together, tieBreak: TiePID, wantOrder: []int64{1, 2}},
+		{name: "priority fifo", scheduler: "priority", processes: together, tieBreak: TieFIFO, wantOrder: []int64{2, 1}},
+		{name: "priority arrival", scheduler: "priority", processes: together, tieBreak: TieArrival, wantOrder: []int64{1, 2}},
+		{name: "ppriority pid", scheduler: "ppriority", processes: together, tieBreak: TiePID, wantOrder: []int64{1, 2}},
+		{name: "rr fifo", scheduler: "rr", processes: together, tieBreak: TieFIFO, wantOrder: []int64{2, 1, 2, 1}},
+		{name: "rr pid", scheduler: "rr", processes: together, tieBreak: TiePID, wantOrder: []int64{1, 2, 1, 2}},
+		// The chart lists CPU 0's slices first: the first process taken and
+		// the third, then on CPU 1 the second.
+		{name: "fcfs fifo on two cpus", scheduler: "fcfs", processes: threeTogether, tieBreak: TieFIFO, cpus: 2, wantOrder: []int64{3, 1, 2}},
+		{name: "fcfs pid on two cpus", scheduler: "fcfs", processes: threeTogether, tieBreak: TiePID, cpus: 2, wantOrder: []int64{1, 3, 2}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := RunSchedulerParams(tt.scheduler, SchedulerParams{Quantum: 2, TieBreak: tt.tieBreak, CPUs: tt.cpus}, tt.processes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var order []int64
+			for _, s := range MergeSlices(got.Gantt) {
+				if s.PID != IdlePID {
+					order = append(order, s.PID)
+				}
+			}
+			if !reflect.DeepEqual(order, tt.wantOrder) {
+				t.Errorf("ran %v, want %v", order, tt.wantOrder)
+			}
+			if got.TieBreak != tt.tieBreak {
+				t.Errorf("TieBreak = %v, want %v", got.TieBreak, tt.tieBreak)
+			}
+		})
+	}
+}
+
+func TestAssumeSorted(t *testing.T) {
+	t.Parallel()
+	// P3 is listed first but arrives last.
+	unsorted := []Process{
+		{ProcessID: 3, BurstDuration: 6, ArrivalTime: 6},
+		{ProcessID: 1, BurstDuration: 5},
+		{ProcessID: 4, BurstDuration: 2, ArrivalTime: 6},
+		{ProcessID: 2, BurstDuration: 9, ArrivalTime: 3},
+	}
+	if err := CheckArrivalOrder(unsorted); err == nil || !strings.Contains(err.Error(), "process 1 arrives at 0, before process 3") {
+		t.Errorf("CheckArrivalOrder() = %v, want process 1 named", err)
+	}
+	sorted := []Process{unsorted[1], unsorted[3], unsorted[0], unsorted[2]}
+	if err := CheckArrivalOrder(sorted); err != nil {
+		t.Errorf("CheckArrivalOrder() of a sorted workload = %v", err)
+	}
+	for _, name := range []string{"fcfs", "rr"} {
+		for _, cpus := range []int{1, 2} {
+			// Sorting, the unsorted workload runs as the sorted one does.
+			got, err := RunSchedulerParams(name, SchedulerParams{CPUs: cpus}, unsorted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, m := range got.Processes {
+				if m.Wait < 0 {
+					t.Errorf("%s on %d CPUs: P%d waited %d", name, cpus, m.PID, m.Wait)
+				}
+			}
+			want, err := RunSchedulerParams(name, SchedulerParams{CPUs: cpus}, sorted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.Gantt, want.Gantt) {
+				t.Errorf("%s on %d CPUs ran the unsorted workload as %v, want %v", name, cpus, got.Gantt, want.Gantt)
+			}
+			// Assuming it sorted gives the same run without sorting.
+			assumed, err := RunSchedulerParams(name, SchedulerParams{CPUs: cpus, AssumeSorted: true}, sorted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(assumed, want) {
+				t.Errorf("%s on %d CPUs assuming sorted = %+v, want %+v", name, cpus, assumed, want)
+			}
+			if _, err := RunSchedulerParams(name, SchedulerParams{CPUs: cpus, AssumeSorted: true}, unsorted); !errors.Is(err, ErrInvalidArgs) {
+				t.Errorf("%s on %d CPUs assuming an unsorted workload sorted: error = %v, want ErrInvalidArgs", name, cpus, err)
+			}
+		}
+	}
+}
+
+// starvedBehindStream is P1, at priority 3 with 2 to run, and a priority 1
+// process arriving every 4 ticks with 4 to run, which keep P1 off the CPU
+// until 42 unless aging lifts it level with them, when it wins the tie by
+// having waited longer, or above them.
+func starvedBehindStream() []Process {
+	processes := []Process{{ProcessID: 1, BurstDuration: 2, Priority: 3}}
+	for pid := int64(2); pid <= 11; pid++ {
+		processes = append(processes, Process{ProcessID: pid, ArrivalTime: (pid - 2) * 4, BurstDuration: 4, Priority: 1})
+	}
+	return processes
+}
+
+func TestRunPreemptivePriority_aging(t *testing.T) {
+	t.Parallel()
+	processes := starvedBehindStream()
+	tests := []struct {
+		name       string
+		aging      int64
+		wantExit   int64
+		wantBoosts int64
+	}{
+		{name: "starves without aging", aging: 1000, wantExit: 42, wantBoosts: 0},
+		{name: "aged level with the stream", aging: 5, wantExit: 14, wantBoosts: 2},
+		{name: "aged above the stream", aging: 1, wantExit: 5, wantBoosts: 3},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result := RunPreemptivePriority(processes, tt.aging)
+			if result.Aging != tt.aging {
+				t.Errorf("Aging = %d, want %d", result.Aging, tt.aging)
+			}
+			if m := result.Processes[0]; m.Exit != tt.wantExit || m.Boosts != tt.wantBoosts {
+				t.Errorf("P1 exits at %d with %d boosts, want %d with %d", m.Exit, m.Boosts, tt.wantExit, tt.wantBoosts)
+			}
+		})
+	}
+}
+
+func TestAgingPolicy(t *testing.T) {
+	t.Parallel()
+	processes := starvedBehindStream()
+	tests := []struct {
+		name       string
+		scheduler  string
+		aging      *AgingPolicy
+		wantExit   int64
+		wantBoosts int64
+	}{
+		{name: "priority starves", scheduler: "priority", wantExit: 42},
+		{name: "priority aged", scheduler: "priority", aging: &AgingPolicy{Every: 5}, wantExit: 14, wantBoosts: 2},
+		{name: "priority capped short of the stream", scheduler: "priority", aging: &AgingPolicy{Every: 1, Cap: 2}, wantExit: 42, wantBoosts: 1},
+		{name: "mlq starves", scheduler: "mlq", wantExit: 42},
+		{name: "mlq aged into the interactive queue", scheduler: "mlq", aging: &AgingPolicy{Every: 5}, wantExit: 12, wantBoosts: 2},
+		{name: "ppriority takes the policy's rate", scheduler: "ppriority", aging: &AgingPolicy{Every: 1000}, wantExit: 42},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := RunSchedulerParams(tt.scheduler, SchedulerParams{AgingPolicy: tt.aging}, processes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p := result.Processes[0]; p.Exit != tt.wantExit || p.Boosts != tt.wantBoosts {
+				t.Errorf("P1 exits at %d after %d boosts, want %d and %d", p.Exit, p.Boosts, tt.wantExit, tt.wantBoosts)
+			}
+			if tt.aging != nil && (result.Aging != tt.aging.Every || result.AgingCap != tt.aging.Cap) {
+				t.Errorf("run aged every %d up to %d, want %+v", result.Aging, result.AgingCap, *tt.aging)
+			}
+		})
+	}
+	for _, params := range []SchedulerParams{
+		{AgingPolicy: &AgingPolicy{}},
+		{AgingPolicy: &AgingPolicy{Every: 1, Cap: MaxPriority + 1}},
+		{AgingPolicy: &AgingPolicy{Every: 1}, CPUs: 2},
+	} {
+		if _, err := RunSchedulerParams("priority", params, processes); !errors.Is(err, ErrInvalidArgs) {
+			t.Errorf("RunSchedulerParams(%+v) error = %v, want ErrInvalidArgs", *params.AgingPolicy, err)
+		}
+	}
+}
+
+func TestNiceWeights(t *testing.T) {
+	t.Parallel()
+	nice := func(n int64) *int64 { return &n }
+	byPriority := []Process{{ProcessID: 1, BurstDuration: 20, Priority: 2}, {ProcessID: 2, BurstDuration: 20, Priority: -3}}
+	byNice := []Process{{ProcessID: 1, BurstDuration: 20, Priority: 2, Nice: nice(0)}, {ProcessID: 2, BurstDuration: 20, Priority: -3, Nice: nice(5)}}
+	tests := []struct {
+		name        string
+		scheduler   string
+		processes   []Process
+		params      SchedulerParams
+		wantTickets []int64
+		wantWeights []int64
+	}{
+		{name: "stride by priority", scheduler: "stride", processes: byPriority, wantTickets: []int64{33, 100}},
+		{name: "stride by priority, higher first", scheduler: "stride", processes: byPriority, params: SchedulerParams{PriorityOrder: HigherFirst}, wantTickets: []int64{100, 25}},
+		// 335 is nice 5's weight, under a third of nice 0's 1024.
+		{name: "stride by nice", scheduler: "stride", processes: byNice, wantTickets: []int64{100, 32}, wantWeights: []int64{1024, 335}},
+		{name: "lottery by overridden nice", scheduler: "lottery", processes: byNice, params: SchedulerParams{NiceWeights: map[int64]int64{5: 2048}}, wantTickets: []int64{100, 200}, wantWeights: []int64{1024, 2048}},
+		{name: "cfs by priority", scheduler: "cfs", processes: byPriority, wantWeights: []int64{655, 1991}},
+		{name: "cfs by priority, higher first", scheduler: "cfs", processes: byPriority, params: SchedulerParams{PriorityOrder: HigherFirst}, wantWeights: []int64{1586, 526}},
+		{name: "cfs by nice", scheduler: "cfs", processes: byNice, wantWeights: []int64{1024, 335}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := RunSchedulerParams(tt.scheduler, tt.params, tt.processes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, p := range result.Processes {
+				if tt.wantTickets != nil && p.Tickets != tt.wantTickets[i] {
+					t.Errorf("P%d holds %d tickets, want %d", p.PID, p.Tickets, tt.wantTickets[i])
+				}
+				if tt.wantWeights != nil && p.NiceWeight != tt.wantWeights[i] {
+					t.Errorf("P%d weighs %d, want %d", p.PID, p.NiceWeight, tt.wantWeights[i])
+				}
+				if tt.wantWeights == nil && p.Nice != nil {
+					t.Errorf("P%d was weighed by nice %d, want its priority", p.PID, *p.Nice)
+				}
+			}
+			if result.PriorityOrder != tt.params.PriorityOrder || !reflect.DeepEqual(result.NiceWeights, tt.params.NiceWeights) {
+				t.Errorf("run read priorities %v with weights %v, want %v and %v", result.PriorityOrder, result.NiceWeights, tt.params.PriorityOrder, tt.params.NiceWeights)
+			}
+		})
+	}
+	if r, err := RunSchedulerParams("fcfs", SchedulerParams{PriorityOrder: HigherFirst, NiceWeights: map[int64]int64{0: 1}}, byNice); err != nil || r.PriorityOrder != LowerFirst || r.NiceWeights != nil {
+		t.Errorf("fcfs kept a priority order and nice weights: %v, %v, %v", r.PriorityOrder, r.NiceWeights, err)
+	}
+	for _, params := range []SchedulerParams{
+		{NiceWeights: map[int64]int64{MaxNice + 1: 1}},
+		{NiceWeights: map[int64]int64{0: 0}},
+		{PriorityOrder: HigherFirst + 1},
+	} {
+		if _, err := RunSchedulerParams("cfs", params, byNice); !errors.Is(err, ErrInvalidArgs) {
+			t.Errorf("RunSchedulerParams(%+v) error = %v, want ErrInvalidArgs", params, err)
+		}
+	}
+}
+
+func TestCarryPolicy(t *testing.T) {
+	t.Parallel()
+	processes := []Process{
+		{ProcessID: 1, BurstDuration: 8, Yields: []int64{1}},
+		{ProcessID: 2, BurstDuration: 6},
+		{ProcessID: 3, BurstDuration: 4, ArrivalTime: 1},
+	}
+	// P1 yields a quantum of 3 after 1, so its next dispatch at 7 runs 3, or
+	// 5 with the 2 it kept.
+	tests := []struct {
+		name        string
+		params      SchedulerParams
+		wantStop    int64
+		wantBankCap int64
+	}{
+		{name: "discard", params: SchedulerParams{Quantum: 3}, wantStop: 10},
+		{name: "carry", params: SchedulerParams{Quantum: 3, Carry: CarryNext}, wantStop: 12},
+		{name: "bank", params: SchedulerParams{Quantum: 3, Carry: CarryBank}, wantStop: 12, wantBankCap: 6},
+		{name: "small bank", params: SchedulerParams{Quantum: 3, Carry: CarryBank, BankCap: 1}, wantStop: 11, wantBankCap: 1},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := RunSchedulerParams("rr", tt.params, processes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := result.Gantt[3]; s.PID != 1 || s.Start != 7 || s.Stop != tt.wantStop {
+				t.Errorf("P1's second dispatch = %+v, want 7 to %d", s, tt.wantStop)
+			}
+			if result.Carry != tt.params.Carry || result.BankCap != tt.wantBankCap {
+				t.Errorf("run carried %v up to %d, want %v up to %d", result.Carry, result.BankCap, tt.params.Carry, tt.wantBankCap)
+			}
+		})
+	}
+	if r, err := RunSchedulerParams("fcfs", SchedulerParams{Carry: CarryBank, BankCap: 4}, processes); err != nil || r.Carry != CarryDiscard || r.BankCap != 0 {
+		t.Errorf("fcfs kept a carry policy: %v, %d, %v", r.Carry, r.BankCap, err)
+	}
+	for _, params := range []SchedulerParams{
+		{Carry: CarryBank + 1},
+		{Carry: CarryBank, BankCap: -1},
+		{Carry: CarryNext, BankCap: 4},
+		{Carry: CarryNext, CPUs: 2},
+	} {
+		if _, err := RunSchedulerParams("rr", params, processes); !errors.Is(err, ErrInvalidArgs) {
+			t.Errorf("RunSchedulerParams(%+v) error = %v, want ErrInvalidArgs", params, err)
+		}
+	}
+}
+
+func TestCarryPolicy_io(t *testing.T) {
+	t.Parallel()
+	// P1 blocks on I/O after 1 of each of its first two quanta of 4. Discarded,
+	// what it leaves is lost; carried, its third dispatch runs 4+4; banked,
+	// it saves 3 and then 6 and finishes its last 9 in one go.
+	processes := []Process{
+		{ProcessID: 1, BurstDuration: 11, IO: []IOBurst{{At: 1, Duration: 1}, {At: 2, Duration: 1}}},
+		{ProcessID: 2, BurstDuration: 30},
+	}
+	for _, tt := range []struct {
+		carry    CarryPolicy
+		wantExit int64
+	}{{CarryDiscard, 27}, {CarryNext, 23}, {CarryBank, 19}} {
+		result, err := RunSchedulerParams("rr", SchedulerParams{Quantum: 4, Carry: tt.carry}, processes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := result.Processes[0].Exit; got != tt.wantExit {
+			t.Errorf("%v: P1 exits at %d, want %d", tt.carry, got, tt.wantExit)
+		}
+	}
+}
+
+func TestCarryPolicy_mlqBatch(t *testing.T) {
+	t.Parallel()
+	// mlq's fcfs level slices MaxInt64, which carried credit must not wrap.
+	processes := []Process{
+		{ProcessID: 1, BurstDuration: 6, Priority: 3, Yields: []int64{2}},
+		{ProcessID: 2, BurstDuration: 4, Priority: 3, Yields: []int64{1}},
+	}
+	want := []TimeSlice{{PID: 1, Start: 0, Stop: 2}, {PID: 2, Start: 2, Stop: 3}, {PID: 1, Start: 3, Stop: 7}, {PID: 2, Start: 7, Stop: 10}}
+	for _, carry := range []CarryPolicy{CarryNext, CarryBank} {
+		result, err := RunSchedulerParams("mlq", SchedulerParams{Carry: carry}, processes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(result.Gantt, want) {
+			t.Errorf("%v: gantt = %v, want %v", carry, result.Gantt, want)
+		}
+	}
+}
+
+func TestYield_emptySlice(t *testing.T) {
+	t.Parallel()
+	// P3 blocks on m at 9, wakes holding it at 14 and is dispatched at 19
+	// with no time to run before it unlocks and yields, which must not
+	// leave a 19-19 slice.
+	processes := []Process{
+		{ProcessID: 2, ArrivalTime: 17, BurstDuration: 4, Ops: []SyncOp{{At: 2, Op: MutexLock, Object: "m"}, {At: 2, Op: MutexUnlock, Object: "m"}}},
+		{ProcessID: 3, BurstDuration: 7, Priority: 2, Yields: []int64{1, 3, 6}, Ops: []SyncOp{{At: 6, Op: MutexLock, Object: "m"}, {At: 6, Op: MutexUnlock, Object: "m"}}},
+		{ProcessID: 6, ArrivalTime: 4, BurstDuration: 1},
+		{ProcessID: 8, BurstDuration: 10, Priority: 2, Yields: []int64{1, 6}, Ops: []SyncOp{{At: 2, Op: MutexLock, Object: "m"}, {At: 7, Op: MutexUnlock, Object: "m"}}, IO: []IOBurst{{At: 2, Duration: 2}}},
+	}
+	result, err := RunSchedulerParams("ppriority", SchedulerParams{Quantum: 2}, processes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TimeSlice{
+		{PID: 3, Start: 0, Stop: 1}, {PID: 8, Start: 1, Stop: 2}, {PID: 3, Start: 2, Stop: 4}, {PID: 6, Start: 4, Stop: 5},
+		{PID: 8, Start: 5, Stop: 6}, {PID: 3, Start: 6, Stop: 9}, {PID: 8, Start: 9, Stop: 13}, {PID: 8, Start: 13, Stop: 17},
+		{PID: 2, Start: 17, Stop: 19}, {PID: 2, Start: 19, Stop: 21}, {PID: 3, Start: 21, Stop: 22},
+	}
+	if !reflect.DeepEqual(result.Gantt, want) {
+		t.Errorf("gantt = %v, want %v", result.Gantt, want)
+	}
+}
+
+func TestGroupCaps(t *testing.T) {
+	t.Parallel()
+	processes := []Process{
+		{ProcessID: 1, BurstDuration: 6, Group: "batch"},
+		{ProcessID: 2, BurstDuration: 6, Group: "batch"},
+		{ProcessID: 3, BurstDuration: 4},
+	}
+	// batch may run 5 of every 10, so P1 is throttled at 5, P3 runs and the
+	// CPU idles until the period ends, and P2 waits out a second period.
+	result, err := RunSchedulerParams("fcfs", SchedulerParams{Caps: map[string]int64{"batch": 50}}, processes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantGantt := []TimeSlice{{PID: 1, Start: 0, Stop: 5}, {PID: 3, Start: 5, Stop: 9}, {PID: IdlePID, Start: 9, Stop: 10}, {PID: 1, Start: 10, Stop: 11}, {PID: 2, Start: 11, Stop: 15}, {PID: IdlePID, Start: 15, Stop: 20}, {PID: 2, Start: 20, Stop: 22}}
+	if !reflect.DeepEqual(result.Gantt, wantGantt) {
+		t.Errorf("gantt = %v, want %v", result.Gantt, wantGantt)
+	}
+	for i, want := range []int64{5, 10, 0} {
+		if got := result.Processes[i].Throttled; got != want {
+			t.Errorf("P%d was throttled for %d, want %d", i+1, got, want)
+		}
+	}
+	if result.CapPeriod != DefaultCapPeriod {
+		t.Errorf("cap period = %d, want %d", result.CapPeriod, DefaultCapPeriod)
+	}
+	for _, params := range []SchedulerParams{
+		{Caps: map[string]int64{"batch": 0}},
+		{Caps: map[string]int64{"batch": 101}},
+		{Caps: map[string]int64{"": 50}},
+		{Caps: map[string]int64{"batch": 50}, CapPeriod: -1},
+		{Caps: map[string]int64{"batch": 50}, CPUs: 2},
+	} {
+		if _, err := RunSchedulerParams("fcfs", params, processes); !errors.Is(err, ErrInvalidArgs) {
+			t.Errorf("RunSchedulerParams(%+v) error = %v, want ErrInvalidArgs", params, err)
+		}
+	}
+}
+
+func TestGroupCaps_donation(t *testing.T) {
+	t.Parallel()
+	// P2 yields to P3 once P1 has used most or all of group a's 5 of every
+	// 10, so the donation must wait for the refill or stop at what is left.
+	processes := []Process{
+		{ProcessID: 1, BurstDuration: 5, Group: "a"},
+		{ProcessID: 2, BurstDuration: 3, Yields: []int64{1}, DonateTo: 3},
+		{ProcessID: 3, BurstDuration: 4, Group: "a"},
+	}
+	for _, name := range []string{"fcfs", "rr", "cfs"} {
+		result, err := RunSchedulerParams(name, SchedulerParams{Quantum: 4, Caps: map[string]int64{"a": 50}}, processes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		used := make(map[int64]int64)
+		for _, s := range result.Gantt {
+			if s.Start >= s.Stop {
+				t.Errorf("%s: empty slice %v in %v", name, s, result.Gantt)
+			}
+			if s.PID == 1 || s.PID == 3 {
+				used[s.Start/10] += s.Stop - s.Start
+			}
+		}
+		for period, u := range used {
+			if u > 5 {
+				t.Errorf("%s: group a ran %d in period %d, over its 5: %v", name, u, period, result.Gantt)
+			}
+		}
+	}
+}
+
+func TestSpread(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		values       []int64
+		want         *Distribution
+		shares       []float64
+		wantFairness float64
+	}{
+		{name: "none", want: nil, wantFairness: 0},
+		{name: "one", values: []int64{7}, want: &Distribution{Min: 7, Median: 7, P95: 7, Max: 7}, shares: []float64{0.5}, wantFairness: 1},
+		{name: "even count", values: []int64{4, 1, 3, 2}, want: &Distribution{Min: 1, Median: 2.5, P95: 4, Max: 4, Stddev: math.Sqrt(1.25)}, shares: []float64{1, 0, 0, 0}, wantFairness: 0.25},
+		{
+			name:   "p95 by nearest rank",
+			values: []int64{20, 19, 18, 17, 16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1, 0},
+			want:   &Distribution{Min: 0, Median: 10, P95: 19, Max: 20, Stddev: math.Sqrt(440.0 / 12)},
+			shares: []float64{1, 1, 2, 2}, wantFairness: 0.9,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := distribute(tt.values)
+			if (got == nil) != (tt.want == nil) || got != nil && (got.Min != tt.want.Min || got.Median != tt.want.Median || got.P95 != tt.want.P95 || got.Max != tt.want.Max || math.Abs(got.Stddev-tt.want.Stddev) > 1e-9) {
+				t.Errorf("distribute() = %+v, want %+v", got, tt.want)
+			}
+			if got := JainIndex(tt.shares); math.Abs(got-tt.wantFairness) > 1e-9 {
+				t.Errorf("JainIndex() = %v, want %v", got, tt.wantFairness)
+			}
+		})
+	}
+
+	result, err := RunScheduler("fcfs", 0, mixedWorkload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.WaitStats == nil || result.TurnaroundStats == nil || result.Fairness <= 0 || result.Fairness > 1 {
+		t.Errorf("fcfs run spread = %+v, %+v, fairness %v", result.WaitStats, result.TurnaroundStats, result.Fairness)
+	}
+	summary, err := SummarizeScheduler("fcfs", 0, mixedWorkload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.WaitStats != nil || summary.Fairness != 0 {
+		t.Errorf("summary spread = %+v, fairness %v, want none", summary.WaitStats, summary.Fairness)
+	}
+}
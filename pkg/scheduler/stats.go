@@ -0,0 +1,99 @@
+package scheduler
+
+import (
+	"math"
+	"sort"
+)
+
+// Distribution is how a metric, such as wait, spread over a run's
+// processes, which an average alone hides: a scheduler can have a low
+// average wait while starving a few. P95 is the nearest-rank 95th
+// percentile, the wait that 95% of processes did not exceed, and Stddev
+// the population standard deviation.
+type Distribution struct {
+	Min    int64   `json:"min"`
+	Median float64 `json:"median"`
+	P95    int64   `json:"p95"`
+	Max    int64   `json:"max"`
+	Stddev float64 `json:"stddev"`
+}
+
+// distribute is the Distribution of values, which it sorts, or nil if there
+// are none.
+func distribute(values []int64) *Distribution {
+	n := len(values)
+	if n == 0 {
+		return nil
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	d := &Distribution{Min: values[0], Max: values[n-1], P95: values[(95*n+99)/100-1]}
+	if n%2 == 1 {
+		d.Median = float64(values[n/2])
+	} else {
+		d.Median = float64(values[n/2-1]+values[n/2]) / 2
+	}
+	var sum, squares float64
+	for _, v := range values {
+		sum += float64(v)
+	}
+	mean := sum / float64(n)
+	for _, v := range values {
+		squares += (float64(v) - mean) * (float64(v) - mean)
+	}
+	d.Stddev = math.Sqrt(squares / float64(n))
+	return d
+}
+
+// JainIndex is Jain's fairness index of shares, (Σx)² / (n·Σx²): 1 when
+// every share is equal, falling towards 1/n as one process takes it all. It
+// is 0 for no shares.
+func JainIndex(shares []float64) float64 {
+	var sum, squares float64
+	for _, x := range shares {
+		sum += x
+		squares += x * x
+	}
+	if squares == 0 {
+		return 0
+	}
+	return sum * sum / (float64(len(shares)) * squares)
+}
+
+// spread works out the distributions of r's waits and turnarounds and its
+// fairness from its per-process metrics, leaving them unset if it has none,
+// as a summary does not. Fairness is the Jain index of each process's burst
+// over its turnaround, the share of its time in the system it spent running,
+// so long jobs are not counted unfair for waiting as long as they run.
+func (r *RunResult) spread() {
+	r.WaitStats, r.TurnaroundStats, r.Fairness = nil, nil, 0
+	if len(r.Processes) == 0 {
+		return
+	}
+	waits := make([]int64, len(r.Processes))
+	turnarounds := make([]int64, len(r.Processes))
+	shares := make([]float64, len(r.Processes))
+	for i, m := range r.Processes {
+		waits[i], turnarounds[i] = m.Wait, m.Turnaround
+		if m.Turnaround > 0 {
+			shares[i] = float64(m.Burst) / float64(m.Turnaround)
+		}
+	}
+	r.WaitStats, r.TurnaroundStats = distribute(waits), distribute(turnarounds)
+	r.Fairness = JainIndex(shares)
+}
+
+// p95 is d's P95, or 0 if there is no d.
+func (d *Distribution) p95() float64 {
+	if d == nil {
+		return 0
+	}
+	return float64(d.P95)
+}
+
+// stddev is d's Stddev, or 0 if there is no d.
+func (d *Distribution) stddev() float64 {
+	if d == nil {
+		return 0
+	}
+	return d.Stddev
+}